@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// checkpointSchemaVersion is bumped whenever a checkpoint field is renamed,
+// removed or changes meaning.
+const checkpointSchemaVersion = 1
+
+// checkpoint is written after every generation. It carries the base scenario
+// and the GA settings, so -resume needs nothing else to carry on.
+type checkpoint struct {
+	SchemaVersion int                `json:"schema_version"`
+	Team          string             `json:"team"`
+	Params        gaParams           `json:"params"`
+	Scenario      *game.ScenarioFile `json:"scenario"`
+	Generation    int                `json:"generation"`
+	NextID        int                `json:"next_id"`
+	Population    []*genome          `json:"population"` // ranked, best first
+	BestEver      *genome            `json:"best_ever"`
+	History       []genStats         `json:"history"`
+}
+
+func checkpointPath(dir string, gen int) string {
+	return filepath.Join(dir, fmt.Sprintf("gen-%04d.json", gen))
+}
+
+// save writes the checkpoint as gen-NNNN.json and the best genome so far as
+// best-scenario.json: the base scenario with the genome on the evolved team,
+// ready for headless-report -scenario-file.
+func (c *checkpoint) save(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	if err := writeJSON(checkpointPath(dir, c.Generation), c); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, "best-scenario.json"), c.bestScenario(c.Scenario))
+}
+
+// bestScenario applies the best genome so far to sc. The scenario is renamed
+// so reports make clear which genome they ran.
+func (c *checkpoint) bestScenario(sc *game.ScenarioFile) *game.ScenarioFile {
+	out := c.BestEver.applyTo(sc, c.Team)
+	out.Name = fmt.Sprintf("%s-evolved-g%d-%d", sc.Name, c.BestEver.Generation, c.BestEver.ID)
+	out.Description = strings.TrimSpace(fmt.Sprintf("%s Team %s uses genome %d (fitness %.3f, best as of generation %d).",
+		sc.Description, c.Team, c.BestEver.ID, c.BestEver.Fitness, c.Generation))
+	return out
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return nil, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	if c.SchemaVersion != checkpointSchemaVersion {
+		return nil, fmt.Errorf("checkpoint %s: schema version %d, want %d", path, c.SchemaVersion, checkpointSchemaVersion)
+	}
+	if c.Scenario == nil || len(c.Population) == 0 || c.BestEver == nil {
+		return nil, fmt.Errorf("checkpoint %s: missing scenario or population", path)
+	}
+	if _, ok := teamOf(c.Team); !ok {
+		return nil, fmt.Errorf("checkpoint %s: unknown team %q", path, c.Team)
+	}
+	if err := c.Scenario.Validate(); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: scenario: %w", path, err)
+	}
+	return &c, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
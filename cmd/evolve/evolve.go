@@ -0,0 +1,141 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// gaParams are the evolution settings. They are saved in every checkpoint so
+// a resumed run breeds exactly as the original would have.
+type gaParams struct {
+	Population    int     `json:"population"`
+	Generations   int     `json:"generations"`
+	Runs          int     `json:"runs"` // battles per genome per generation
+	Elite         int     `json:"elite"`
+	Tournament    int     `json:"tournament"`
+	CrossoverRate float64 `json:"crossover_rate"`
+	MutationRate  float64 `json:"mutation_rate"`
+	MutationScale float64 `json:"mutation_scale"`
+	Budget        float64 `json:"budget"`
+	MaxTicks      int     `json:"max_ticks"`
+	Seed          int64   `json:"seed"`
+}
+
+// generationRNG seeds generation gen's randomness from the run seed alone, so
+// breeding and battle seeds can be reproduced after a resume.
+func (p gaParams) generationRNG(gen int) *rand.Rand {
+	return rand.New(rand.NewSource(p.Seed*1_000_003 + int64(gen))) // #nosec G404 -- reproducible search, not security
+}
+
+// battleSeeds draws the seeds every genome of a generation fights on.
+func (p gaParams) battleSeeds(rng *rand.Rand) []int64 {
+	seeds := make([]int64, p.Runs)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	return seeds
+}
+
+// genStats summarises one evaluated generation.
+type genStats struct {
+	Generation int     `json:"generation"`
+	Best       float64 `json:"best"`
+	BestID     int     `json:"best_id"`
+	Mean       float64 `json:"mean"`
+	Worst      float64 `json:"worst"`
+	Diversity  float64 `json:"diversity"` // mean per-gene std dev as a fraction of its range
+}
+
+// rank sorts the population best first; ties go to the older genome.
+func rank(pop []*genome) {
+	sort.SliceStable(pop, func(i, j int) bool {
+		if pop[i].Fitness != pop[j].Fitness {
+			return pop[i].Fitness > pop[j].Fitness
+		}
+		return pop[i].ID < pop[j].ID
+	})
+}
+
+// summarize computes the stats of a ranked population.
+func summarize(gen int, pop []*genome) genStats {
+	st := genStats{Generation: gen, Best: pop[0].Fitness, BestID: pop[0].ID, Worst: pop[len(pop)-1].Fitness}
+	for _, g := range pop {
+		st.Mean += g.Fitness
+	}
+	st.Mean /= float64(len(pop))
+	for _, gn := range genes {
+		mean, sq := 0.0, 0.0
+		for _, g := range pop {
+			mean += g.Genes[gn.name]
+		}
+		mean /= float64(len(pop))
+		for _, g := range pop {
+			d := g.Genes[gn.name] - mean
+			sq += d * d
+		}
+		st.Diversity += math.Sqrt(sq/float64(len(pop))) / (gn.max - gn.min)
+	}
+	st.Diversity /= float64(len(genes))
+	return st
+}
+
+// initialPopulation is the baseline soldier plus random genomes, all within
+// budget. IDs start at 1.
+func initialPopulation(p gaParams, rng *rand.Rand) []*genome {
+	pop := make([]*genome, p.Population)
+	for i := range pop {
+		g := baselineGenome()
+		if i > 0 {
+			g = randomGenome(rng)
+		}
+		g.ID = i + 1
+		g.repair(p.Budget)
+		pop[i] = g
+	}
+	return pop
+}
+
+// breed returns the next generation from a ranked population: the elite
+// carried over unchanged, then children of tournament winners. nextID is the
+// first free genome ID; the new value is returned.
+func breed(p gaParams, ranked []*genome, gen, nextID int, rng *rand.Rand) ([]*genome, int) {
+	next := make([]*genome, 0, p.Population)
+	for i := 0; i < p.Elite && i < len(ranked); i++ {
+		e := *ranked[i]
+		e.Genes = make(map[string]float64, len(genes))
+		for k, v := range ranked[i].Genes {
+			e.Genes[k] = v
+		}
+		e.Eval = nil
+		next = append(next, &e)
+	}
+	for len(next) < p.Population {
+		a := tournament(ranked, p.Tournament, rng)
+		var child *genome
+		if rng.Float64() < p.CrossoverRate {
+			child = crossover(a, tournament(ranked, p.Tournament, rng), rng)
+		} else {
+			child = crossover(a, a, rng)
+			child.Parents = []int{a.ID}
+		}
+		child.mutate(p.MutationRate, p.MutationScale, rng)
+		child.repair(p.Budget)
+		child.ID = nextID
+		child.Generation = gen
+		nextID++
+		next = append(next, child)
+	}
+	return next, nextID
+}
+
+// tournament picks k genomes at random and returns the fittest.
+func tournament(pop []*genome, k int, rng *rand.Rand) *genome {
+	best := pop[rng.Intn(len(pop))]
+	for i := 1; i < k; i++ {
+		if c := pop[rng.Intn(len(pop))]; c.Fitness > best.Fitness {
+			best = c
+		}
+	}
+	return best
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// Fitness weights. Each component is 0-1, so a perfect battle scores 4:
+// win outright, lose nobody, kill everyone and grade A+ across the team.
+const (
+	weightOutcome   = 1.0
+	weightSurvival  = 1.0
+	weightAttrition = 1.0
+	weightGrade     = 1.0
+
+	// Outcome credit from the evolved team's side. A battle that runs out of
+	// ticks scores below a draw so the GA is pushed toward decisive results.
+	outcomeWin          = 1.0
+	outcomeDraw         = 0.5
+	outcomeInconclusive = 0.25
+	outcomeLoss         = 0.0
+)
+
+// battleScore is one battle seen from the evolved team.
+type battleScore struct {
+	outcome   game.BattleOutcome
+	survival  float64 // own survivors / own total
+	attrition float64 // enemy dead / enemy total
+	grade     float64 // mean GradePerformance score of the team, 0-1
+}
+
+// credit is the outcome component: outcomeWin, outcomeDraw,
+// outcomeInconclusive or outcomeLoss.
+func (b battleScore) credit(team game.Team) float64 {
+	switch b.outcome {
+	case game.OutcomeDraw:
+		return outcomeDraw
+	case game.OutcomeRedVictory, game.OutcomeBlueVictory:
+		if (b.outcome == game.OutcomeRedVictory) == (team == game.TeamRed) {
+			return outcomeWin
+		}
+		return outcomeLoss
+	default:
+		return outcomeInconclusive
+	}
+}
+
+func (b battleScore) fitness(team game.Team) float64 {
+	return weightOutcome*b.credit(team) + weightSurvival*b.survival + weightAttrition*b.attrition + weightGrade*b.grade
+}
+
+// evaluation aggregates a genome's battles in one generation.
+type evaluation struct {
+	Battles   int     `json:"battles"`
+	Wins      int     `json:"wins"`
+	Draws     int     `json:"draws"`
+	Losses    int     `json:"losses"`
+	Survival  float64 `json:"survival"`
+	Attrition float64 `json:"attrition"`
+	Grade     float64 `json:"grade"`
+}
+
+// fight runs one seeded battle of sc and scores it for team.
+func fight(sc *game.ScenarioFile, team game.Team, seed int64, maxTicks int) battleScore {
+	ts := game.NewTestSimFromScenario(sc, seed)
+	ts.RunScenario(sc, maxTicks)
+	r := ts.Outcome()
+	own, ownTotal, enemy, enemyTotal := r.RedSurvivors, r.RedTotal, r.BlueSurvivors, r.BlueTotal
+	if team == game.TeamBlue {
+		own, ownTotal, enemy, enemyTotal = enemy, enemyTotal, own, ownTotal
+	}
+	b := battleScore{outcome: r.Outcome}
+	if ownTotal > 0 {
+		b.survival = float64(own) / float64(ownTotal)
+	}
+	if enemyTotal > 0 {
+		b.attrition = float64(enemyTotal-enemy) / float64(enemyTotal)
+	}
+	n := 0
+	for _, g := range ts.SoldierGrades() {
+		if g.Team == team {
+			b.grade += g.Score / 100
+			n++
+		}
+	}
+	if n > 0 {
+		b.grade /= float64(n)
+	}
+	return b
+}
+
+// evaluate scores every genome on the same seeds, so differences within a
+// generation come from the genomes and not the dice. Battles run on a pool of
+// workers; each builds its own TestSim, so results do not depend on the
+// worker count. done, if set, is called once per finished battle.
+func evaluate(pop []*genome, sc *game.ScenarioFile, team string, seeds []int64, maxTicks, workers int, done func()) {
+	t, _ := teamOf(team)
+	type job struct{ g, s int }
+	scores := make([][]battleScore, len(pop))
+	scenarios := make([]*game.ScenarioFile, len(pop))
+	for i, g := range pop {
+		scores[i] = make([]battleScore, len(seeds))
+		scenarios[i] = g.applyTo(sc, team)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan job)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				scores[j.g][j.s] = fight(scenarios[j.g], t, seeds[j.s], maxTicks)
+				if done != nil {
+					mu.Lock()
+					done()
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for g := range pop {
+		for s := range seeds {
+			queue <- job{g, s}
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, g := range pop {
+		ev := &evaluation{Battles: len(seeds)}
+		total := 0.0
+		for _, b := range scores[i] {
+			total += b.fitness(t)
+			ev.Survival += b.survival
+			ev.Attrition += b.attrition
+			ev.Grade += b.grade
+			switch b.credit(t) {
+			case outcomeWin:
+				ev.Wins++
+			case outcomeDraw:
+				ev.Draws++
+			case outcomeLoss:
+				ev.Losses++
+			}
+		}
+		n := float64(len(seeds))
+		ev.Survival /= n
+		ev.Attrition /= n
+		ev.Grade /= n
+		g.Fitness = total / n
+		g.Eval = ev
+	}
+}
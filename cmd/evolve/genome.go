@@ -0,0 +1,173 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// gene is one evolvable parameter. Names match the scenario profile fields
+// so a genome maps straight onto a ScenarioProfile.
+type gene struct {
+	name     string
+	min, max float64
+	trained  bool // counts against the training budget
+}
+
+// genes is the genome layout: SkillStats, the PsychState traits and the
+// GoalThresholds. Threshold bounds are the ranges EvolveThresholds clamps to,
+// so nothing evolves into a value the soldier would immediately lose.
+var genes = []gene{
+	{"marksmanship", 0.05, 1, true},
+	{"fieldcraft", 0, 1, true},
+	{"discipline", 0, 1, true},
+	{"first_aid", 0, 1, true},
+	{"composure", 0, 1, true},
+	{"experience", 0, 1, true},
+	{"engage_shot_quality", 0.05, 0.60, false},
+	{"long_range_shot_quality", 0.10, 0.65, false},
+	{"push_on_miss_momentum", 0.05, 0.60, false},
+	{"hold_on_hit_momentum", 0.05, 0.50, false},
+	{"cover_fear", 0.30, 0.90, false},
+}
+
+// genome is one candidate soldier. Every soldier of the evolved team gets the
+// same genome, so a battle scores the genome rather than a lucky individual.
+type genome struct {
+	ID         int                `json:"id"`
+	Generation int                `json:"generation"` // generation it was born in
+	Parents    []int              `json:"parents,omitempty"`
+	Genes      map[string]float64 `json:"genes"`
+	Fitness    float64            `json:"fitness"`
+	Eval       *evaluation        `json:"eval,omitempty"`
+}
+
+// baselineGenome is the stock soldier: DefaultProfile and the default
+// thresholds. Seeding it into generation 0 keeps a reference point in the
+// population.
+func baselineGenome() *genome {
+	p := game.DefaultProfile()
+	th := game.DefaultSimConfig().Thresholds
+	return &genome{Genes: map[string]float64{
+		"marksmanship":            p.Skills.Marksmanship,
+		"fieldcraft":              p.Skills.Fieldcraft,
+		"discipline":              p.Skills.Discipline,
+		"first_aid":               p.Skills.FirstAid,
+		"composure":               p.Psych.Composure,
+		"experience":              p.Psych.Experience,
+		"engage_shot_quality":     th.EngageShotQuality,
+		"long_range_shot_quality": th.LongRangeShotQuality,
+		"push_on_miss_momentum":   th.PushOnMissMomentum,
+		"hold_on_hit_momentum":    th.HoldOnHitMomentum,
+		"cover_fear":              th.CoverFear,
+	}}
+}
+
+// defaultBudget is the baseline soldier's total of trained genes. Without a
+// budget every skill simply climbs to 1 and the GA finds nothing interesting.
+func defaultBudget() float64 {
+	return baselineGenome().trainedTotal()
+}
+
+func (g *genome) trainedTotal() float64 {
+	sum := 0.0
+	for _, gn := range genes {
+		if gn.trained {
+			sum += g.Genes[gn.name]
+		}
+	}
+	return sum
+}
+
+// randomGenome draws every gene uniformly within its bounds.
+func randomGenome(rng *rand.Rand) *genome {
+	g := &genome{Genes: make(map[string]float64, len(genes))}
+	for _, gn := range genes {
+		g.Genes[gn.name] = gn.min + rng.Float64()*(gn.max-gn.min)
+	}
+	return g
+}
+
+// crossover blends two parents gene by gene at a random point between them.
+func crossover(a, b *genome, rng *rand.Rand) *genome {
+	child := &genome{Genes: make(map[string]float64, len(genes)), Parents: []int{a.ID, b.ID}}
+	for _, gn := range genes {
+		u := rng.Float64()
+		child.Genes[gn.name] = a.Genes[gn.name] + u*(b.Genes[gn.name]-a.Genes[gn.name])
+	}
+	return child
+}
+
+// mutate adds Gaussian noise to each gene with probability rate. scale is
+// the standard deviation as a fraction of the gene's range.
+func (g *genome) mutate(rate, scale float64, rng *rand.Rand) {
+	for _, gn := range genes {
+		if rng.Float64() >= rate {
+			continue
+		}
+		v := g.Genes[gn.name] + rng.NormFloat64()*scale*(gn.max-gn.min)
+		g.Genes[gn.name] = math.Max(gn.min, math.Min(gn.max, v))
+	}
+}
+
+// repair clamps every gene into bounds and, when the trained genes exceed
+// budget, shrinks each one's share above its minimum by the same factor.
+func (g *genome) repair(budget float64) {
+	floor, above := 0.0, 0.0
+	for _, gn := range genes {
+		v := math.Max(gn.min, math.Min(gn.max, g.Genes[gn.name]))
+		g.Genes[gn.name] = v
+		if gn.trained {
+			floor += gn.min
+			above += v - gn.min
+		}
+	}
+	if budget <= 0 || floor+above <= budget {
+		return
+	}
+	k := math.Max(0, budget-floor) / above
+	for _, gn := range genes {
+		if gn.trained {
+			g.Genes[gn.name] = gn.min + (g.Genes[gn.name]-gn.min)*k
+		}
+	}
+}
+
+// profile converts the genome into scenario profile overrides, keeping any
+// non-genetic fields (fitness, morale, stance) from base.
+func (g *genome) profile(base *game.ScenarioProfile) *game.ScenarioProfile {
+	var p game.ScenarioProfile
+	if base != nil {
+		p = *base
+	}
+	v := func(name string) *float64 {
+		x := g.Genes[name]
+		return &x
+	}
+	p.Marksmanship = v("marksmanship")
+	p.Fieldcraft = v("fieldcraft")
+	p.Discipline = v("discipline")
+	p.FirstAid = v("first_aid")
+	p.Composure = v("composure")
+	p.Experience = v("experience")
+	p.EngageShotQuality = v("engage_shot_quality")
+	p.LongRangeShotQuality = v("long_range_shot_quality")
+	p.PushOnMissMomentum = v("push_on_miss_momentum")
+	p.HoldOnHitMomentum = v("hold_on_hit_momentum")
+	p.CoverFear = v("cover_fear")
+	return &p
+}
+
+// applyTo returns a copy of sc with the genome on every soldier of team.
+func (g *genome) applyTo(sc *game.ScenarioFile, team string) *game.ScenarioFile {
+	cp := *sc
+	cp.Soldiers = make([]game.ScenarioSoldier, len(sc.Soldiers))
+	for i, ss := range sc.Soldiers {
+		if ss.Team == team {
+			ss.Profile = g.profile(ss.Profile)
+		}
+		cp.Soldiers[i] = ss
+	}
+	return &cp
+}
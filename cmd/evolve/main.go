@@ -0,0 +1,210 @@
+// Command evolve runs a genetic algorithm over soldier parameters: skills,
+// psychological traits and goal thresholds. Each genome is scored by putting
+// it on every soldier of one team in a headless scenario and fighting a few
+// seeded battles. A checkpoint is written after every generation, together
+// with a scenario file that runs the best genome so far.
+//
+//	go run ./cmd/evolve -population 24 -generations 10 -runs 3 -out evolve-out
+//	go run ./cmd/headless-report -scenario-file evolve-out/best-scenario.json -runs 50
+//	go run ./cmd/evolve -resume evolve-out/gen-0009.json -generations 20
+//	go run ./cmd/evolve -apply evolve-out/gen-0019.json -scenario-file other.json > other-evolved.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+func main() {
+	var p gaParams
+	var scenarioFile, team, outDir, resume, apply string
+	var workers int
+
+	flag.StringVar(&scenarioFile, "scenario-file", "cmd/headless-report/scenarios/mutual-advance.json", "scenario to evolve on")
+	flag.StringVar(&team, "team", "red", "team that carries the genome (red or blue); the other keeps its scenario profiles")
+	flag.IntVar(&p.Population, "population", 24, "genomes per generation")
+	flag.IntVar(&p.Generations, "generations", 10, "generations to run (total, including any resumed ones)")
+	flag.IntVar(&p.Runs, "runs", 3, "battles per genome per generation, on seeds shared by the whole generation")
+	flag.IntVar(&p.Elite, "elite", 2, "best genomes carried unchanged into the next generation")
+	flag.IntVar(&p.Tournament, "tournament", 3, "tournament size for parent selection")
+	flag.Float64Var(&p.CrossoverRate, "crossover", 0.7, "probability a child has two parents")
+	flag.Float64Var(&p.MutationRate, "mutation", 0.2, "per-gene mutation probability")
+	flag.Float64Var(&p.MutationScale, "mutation-scale", 0.1, "mutation std dev as a fraction of the gene's range")
+	flag.Float64Var(&p.Budget, "budget", defaultBudget(), "cap on the sum of skill and trait genes (the stock soldier's total by default; 0 = no cap)")
+	flag.IntVar(&p.MaxTicks, "ticks", 0, "ticks per battle (0 = the scenario's stop.max_ticks)")
+	flag.Int64Var(&p.Seed, "seed", 1, "seed for breeding and battle seeds")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "battles to simulate in parallel")
+	flag.StringVar(&outDir, "out", "evolve-out", "directory for gen-NNNN.json checkpoints and best-scenario.json")
+	flag.StringVar(&resume, "resume", "", "continue from this checkpoint (its scenario, team and settings win over the flags, except -generations)")
+	flag.StringVar(&apply, "apply", "", "print -scenario-file (or the checkpoint's scenario) with this checkpoint's best genome applied, then exit")
+	flag.Parse()
+
+	if apply != "" {
+		c, err := loadCheckpoint(apply)
+		if err != nil {
+			fail(err)
+		}
+		sc := c.Scenario
+		if flagSet("scenario-file") {
+			if sc, err = game.LoadScenarioFile(scenarioFile); err != nil {
+				fail(err)
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(c.bestScenario(sc)); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	var c *checkpoint
+	if resume != "" {
+		var err error
+		if c, err = loadCheckpoint(resume); err != nil {
+			fail(err)
+		}
+		c.Params.Generations = p.Generations
+	} else {
+		sc, err := game.LoadScenarioFile(scenarioFile)
+		if err != nil {
+			fail(err)
+		}
+		if err := p.validate(); err != nil {
+			fail(err)
+		}
+		if _, ok := teamOf(team); !ok {
+			fail(fmt.Errorf("unknown -team %q (want red or blue)", team))
+		}
+		if p.MaxTicks == 0 && sc.Stop.MaxTicks == 0 {
+			fail(fmt.Errorf("scenario %s has no stop.max_ticks; set -ticks", sc.Name))
+		}
+		c = &checkpoint{SchemaVersion: checkpointSchemaVersion, Team: team, Params: p, Scenario: sc, Generation: -1}
+	}
+	if err := run(c, workers, outDir, os.Stdout, os.Stderr); err != nil {
+		fail(err)
+	}
+}
+
+// run evolves from c until c.Params.Generations have been evaluated,
+// saving a checkpoint after each. A fresh run has Generation -1. Each
+// generation's summary goes to report; progress may be nil.
+func run(c *checkpoint, workers int, outDir string, report, progress io.Writer) error {
+	p := c.Params
+	var pop []*genome
+	if c.Generation < 0 {
+		pop = initialPopulation(p, p.generationRNG(0))
+		c.NextID = len(pop) + 1
+	}
+	for gen := c.Generation + 1; gen < p.Generations; gen++ {
+		rng := p.generationRNG(gen)
+		if gen > 0 {
+			pop, c.NextID = breed(p, c.Population, gen, c.NextID, rng)
+		}
+		seeds := p.battleSeeds(rng)
+
+		start := time.Now()
+		total, done := len(pop)*len(seeds), 0
+		evaluate(pop, c.Scenario, c.Team, seeds, p.MaxTicks, workers, func() {
+			done++
+			if progress != nil {
+				fmt.Fprintf(progress, "\rgeneration %d: %d/%d battles", gen, done, total)
+			}
+		})
+		if progress != nil {
+			fmt.Fprintln(progress)
+		}
+		rank(pop)
+
+		st := summarize(gen, pop)
+		c.Generation = gen
+		c.Population = pop
+		c.History = append(c.History, st)
+		if c.BestEver == nil || pop[0].Fitness > c.BestEver.Fitness {
+			best := *pop[0]
+			c.BestEver = &best
+		}
+		printGeneration(report, st, pop, time.Since(start))
+		if err := c.save(outDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p gaParams) validate() error {
+	switch {
+	case p.Population < 2:
+		return fmt.Errorf("-population must be >= 2")
+	case p.Generations < 1:
+		return fmt.Errorf("-generations must be >= 1")
+	case p.Runs < 1:
+		return fmt.Errorf("-runs must be >= 1")
+	case p.Elite < 0 || p.Elite >= p.Population:
+		return fmt.Errorf("-elite must be in [0, population)")
+	case p.Tournament < 1:
+		return fmt.Errorf("-tournament must be >= 1")
+	case p.CrossoverRate < 0 || p.CrossoverRate > 1, p.MutationRate < 0 || p.MutationRate > 1:
+		return fmt.Errorf("-crossover and -mutation must be in [0,1]")
+	case p.MutationScale < 0, p.Budget < 0, p.MaxTicks < 0:
+		return fmt.Errorf("-mutation-scale, -budget and -ticks must be >= 0")
+	}
+	return nil
+}
+
+func printGeneration(w io.Writer, st genStats, pop []*genome, took time.Duration) {
+	fmt.Fprintf(w, "Generation %d (%s)\n", st.Generation, took.Round(time.Millisecond))
+	fmt.Fprintf(w, "  Best:      %.3f (genome #%d)\n", st.Best, st.BestID)
+	fmt.Fprintf(w, "  Mean:      %.3f\n", st.Mean)
+	fmt.Fprintf(w, "  Worst:     %.3f\n", st.Worst)
+	fmt.Fprintf(w, "  Diversity: %.3f\n", st.Diversity)
+	fmt.Fprintln(w, "  Top genomes:")
+	for i := 0; i < 3 && i < len(pop); i++ {
+		g := pop[i]
+		ev := g.Eval
+		fmt.Fprintf(w, "    #%d: %.3f  W/D/L %d/%d/%d  survival %.2f  attrition %.2f  grade %.2f\n",
+			g.ID, g.Fitness, ev.Wins, ev.Draws, ev.Losses, ev.Survival, ev.Attrition, ev.Grade)
+		fmt.Fprintf(w, "        %s\n", geneString(g))
+	}
+}
+
+func geneString(g *genome) string {
+	parts := make([]string, len(genes))
+	for i, gn := range genes {
+		parts[i] = fmt.Sprintf("%s=%.2f", gn.name, g.Genes[gn.name])
+	}
+	return strings.Join(parts, " ")
+}
+
+func teamOf(name string) (game.Team, bool) {
+	switch name {
+	case "red":
+		return game.TeamRed, true
+	case "blue":
+		return game.TeamBlue, true
+	}
+	return game.TeamRed, false
+}
+
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+func TestRepair_KeepsTrainedGenesWithinBudget(t *testing.T) {
+	budget := defaultBudget()
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 200; i++ {
+		g := randomGenome(rng)
+		g.repair(budget)
+		if total := g.trainedTotal(); total > budget+1e-9 {
+			t.Fatalf("genome %d spends %.3f, budget %.3f", i, total, budget)
+		}
+		for _, gn := range genes {
+			if v := g.Genes[gn.name]; v < gn.min || v > gn.max {
+				t.Fatalf("%s=%.3f outside [%g, %g]", gn.name, v, gn.min, gn.max)
+			}
+		}
+	}
+	base := baselineGenome()
+	want := baselineGenome()
+	base.repair(budget)
+	if !reflect.DeepEqual(base.Genes, want.Genes) {
+		t.Fatal("the stock soldier should fit the default budget untouched")
+	}
+}
+
+func TestBreed_ReproducibleAndKeepsElite(t *testing.T) {
+	p := gaParams{Population: 8, Runs: 1, Elite: 2, Tournament: 3, CrossoverRate: 0.7, MutationRate: 0.3, MutationScale: 0.1, Budget: defaultBudget(), Seed: 3}
+	ranked := initialPopulation(p, p.generationRNG(0))
+	for i, g := range ranked {
+		g.Fitness = float64(len(ranked) - i)
+	}
+	a, nextA := breed(p, ranked, 1, 9, p.generationRNG(1))
+	b, nextB := breed(p, ranked, 1, 9, p.generationRNG(1))
+	if nextA != nextB || len(a) != p.Population {
+		t.Fatalf("got %d genomes, next IDs %d/%d", len(a), nextA, nextB)
+	}
+	ids := map[int]bool{}
+	for i := range a {
+		if !reflect.DeepEqual(a[i].Genes, b[i].Genes) || a[i].ID != b[i].ID {
+			t.Fatalf("genome %d differs between identical breeds", i)
+		}
+		if ids[a[i].ID] {
+			t.Fatalf("duplicate genome ID %d", a[i].ID)
+		}
+		ids[a[i].ID] = true
+	}
+	for i := 0; i < p.Elite; i++ {
+		if a[i].ID != ranked[i].ID || !reflect.DeepEqual(a[i].Genes, ranked[i].Genes) {
+			t.Fatalf("elite %d not carried over unchanged", i)
+		}
+	}
+	a[0].Genes["marksmanship"] = 0
+	if ranked[0].Genes["marksmanship"] == 0 {
+		t.Fatal("elite copies must not share genes with their originals")
+	}
+}
+
+// smallScenario is a quick 2v2 skirmish for end-to-end runs.
+func smallScenario() *game.ScenarioFile {
+	return &game.ScenarioFile{
+		Name: "skirmish",
+		Map:  game.ScenarioMap{Width: 800, Height: 400},
+		Soldiers: []game.ScenarioSoldier{
+			{ID: 0, Team: "red", Start: [2]float64{100, 190}, Objective: [2]float64{700, 190}},
+			{ID: 1, Team: "red", Start: [2]float64{100, 210}, Objective: [2]float64{700, 210}},
+			{ID: 2, Team: "blue", Start: [2]float64{700, 190}, Objective: [2]float64{100, 190}},
+			{ID: 3, Team: "blue", Start: [2]float64{700, 210}, Objective: [2]float64{100, 210}},
+		},
+		Squads: []game.ScenarioSquad{{Team: "red", Members: []int{0, 1}}, {Team: "blue", Members: []int{2, 3}}},
+		Stop:   game.ScenarioStop{MaxTicks: 300, OnTeamEliminated: true},
+	}
+}
+
+func TestRun_ResumeMatchesUninterruptedRun(t *testing.T) {
+	p := gaParams{Population: 4, Generations: 3, Runs: 1, Elite: 1, Tournament: 2, CrossoverRate: 0.7, MutationRate: 0.3, MutationScale: 0.1, Budget: defaultBudget(), Seed: 5}
+	fresh := func() *checkpoint {
+		return &checkpoint{SchemaVersion: checkpointSchemaVersion, Team: "red", Params: p, Scenario: smallScenario(), Generation: -1}
+	}
+
+	full := t.TempDir()
+	if err := run(fresh(), 2, full, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop after two generations, then resume to three.
+	part := t.TempDir()
+	short := fresh()
+	short.Params.Generations = 2
+	if err := run(short, 1, part, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loadCheckpoint(checkpointPath(part, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Params.Generations = 3
+	if err := run(c, 1, part, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := loadCheckpoint(checkpointPath(full, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadCheckpoint(checkpointPath(part, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Population, want.Population) || !reflect.DeepEqual(got.History, want.History) {
+		t.Fatal("a resumed run should match the uninterrupted one exactly")
+	}
+
+	sc, err := game.LoadScenarioFile(filepath.Join(full, "best-scenario.json"))
+	if err != nil {
+		t.Fatalf("best-scenario.json does not load: %v", err)
+	}
+	best := want.BestEver
+	for _, ss := range sc.Soldiers {
+		if ss.Team != "red" {
+			if ss.Profile != nil {
+				t.Fatalf("blue soldier %d should keep its own profile", ss.ID)
+			}
+			continue
+		}
+		if ss.Profile == nil || *ss.Profile.CoverFear != best.Genes["cover_fear"] || *ss.Profile.Marksmanship != best.Genes["marksmanship"] {
+			t.Fatalf("red soldier %d does not carry the best genome", ss.ID)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"log"
 
 	"github.com/Garsondee/Soldier-Sense/internal/game"
@@ -9,10 +10,61 @@ import (
 )
 
 func main() {
+	var seed int64
+	var recordPath string
+	var replayPath string
+	var loadPath string
+	var commandDelay float64
+	flag.Int64Var(&seed, "seed", 0, "master seed for a reproducible session (0 = random; restarts always pick a fresh seed)")
+	flag.StringVar(&recordPath, "record", "", "write a replay of each session to this file when it ends")
+	flag.StringVar(&replayPath, "replay", "", "open a recorded replay instead of starting a battle")
+	flag.StringVar(&loadPath, "load", "", "resume a save state written with F9")
+	flag.Float64Var(&commandDelay, "command-delay", 3, "seconds before troops act on painted orders (B toggles paint mode)")
+	flag.Parse()
+
 	ebiten.SetWindowTitle("Soldier Sense")
 	ebiten.SetFullscreen(true)
+
+	if replayPath != "" {
+		rec, err := game.LoadRecording(replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ebiten.RunGame(game.NewReplayViewer(rec)); err != nil && !errors.Is(err, game.ErrQuit) {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	for {
-		err := ebiten.RunGame(game.New())
+		var g *game.Game
+		if loadPath != "" {
+			st, err := game.LoadSaveState(loadPath)
+			if err == nil {
+				g, err = game.RestoreGame(st)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			loadPath = ""
+		} else if seed != 0 {
+			g = game.NewWithSeed(seed)
+			seed = 0
+		} else {
+			g = game.New()
+		}
+		g.SetCommandDelay(int(commandDelay * 60))
+		if recordPath != "" {
+			g.StartRecording()
+		}
+		err := ebiten.RunGame(g)
+		if recordPath != "" {
+			if serr := g.Recording().Save(recordPath); serr != nil {
+				log.Printf("replay not saved: %v", serr)
+			} else {
+				log.Printf("replay saved to %s (seed %d)", recordPath, g.Seed())
+			}
+		}
 		switch {
 		case err == nil:
 			return
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// runJob is one seeded run in a batch.
+type runJob struct {
+	index   int // 1-based run number
+	seed    int64
+	record  string // replay path, empty for no recording
+	keepLog bool   // keep the full SimLog in the stats
+}
+
+// batchJobs lays out runs seeds starting at seedBase. Only run 1 records.
+func batchJobs(runs int, seedBase, seedStep int64, recordPath string) []runJob {
+	jobs := make([]runJob, runs)
+	for i := range jobs {
+		jobs[i] = runJob{index: i + 1, seed: seedBase + int64(i)*seedStep}
+	}
+	if runs > 0 {
+		jobs[0].record = recordPath
+	}
+	return jobs
+}
+
+// runBatch runs every job on a pool of workers and returns the stats in run
+// order. Each run builds its own TestSim, map and RNG from its seed, so runs
+// share nothing and a batch gives the same numbers at any worker count.
+// done, if set, is called from one goroutine at a time as each run finishes.
+func runBatch(jobs []runJob, workers, maxTicks int, sc *game.ScenarioFile, done func(runStats)) []runStats {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	out := make([]runStats, len(jobs))
+	queue := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				rs := runScenario(jobs[i], maxTicks, sc)
+				out[i] = rs
+				if done != nil {
+					mu.Lock()
+					done(rs)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return out
+}
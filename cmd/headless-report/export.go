@@ -0,0 +1,621 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// exportSchemaVersion is bumped whenever a field in the machine-readable
+// output is renamed, removed or changes meaning. Adding fields does not bump it.
+const exportSchemaVersion = 1
+
+// Output formats for -format.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+var exportFormats = []string{formatText, formatJSON, formatNDJSON, formatCSV}
+
+// exportGoals fixes the goal columns of windows.csv.
+var exportGoals = []game.GoalKind{
+	game.GoalAdvance, game.GoalMaintainFormation, game.GoalRegroup, game.GoalHoldPosition,
+	game.GoalSurvive, game.GoalEngage, game.GoalMoveToContact, game.GoalFallback, game.GoalFlank,
+	game.GoalOverwatch, game.GoalPeek, game.GoalHelpCasualty, game.GoalSearch, game.GoalThrowGrenade,
+}
+
+// reportRecord is the whole batch as one JSON document.
+type reportRecord struct {
+	SchemaVersion int            `json:"schema_version"`
+	Scenario      string         `json:"scenario"`
+	Environment   string         `json:"environment"`
+	Intel         string         `json:"intel"`     // "shared" or "radio"
+	RunCount      int            `json:"run_count"` // per grid point in a sweep
+	MaxTicks      int            `json:"max_ticks"`
+	SeedBase      int64          `json:"seed_base"`
+	SeedStep      int64          `json:"seed_step"`
+	Runs          []runRecord    `json:"runs,omitempty"`
+	Summary       *summaryRecord `json:"summary,omitempty"`
+}
+
+type runRecord struct {
+	Run   int   `json:"run"`
+	Seed  int64 `json:"seed"`
+	Ticks int   `json:"ticks"`
+
+	SetupMS float64 `json:"setup_ms"`
+	SimMS   float64 `json:"sim_ms"`
+	PostMS  float64 `json:"post_ms"`
+
+	// First-occurrence ticks; -1 when it never happened.
+	FirstContactTick   int `json:"first_contact_tick"`
+	FirstEngageTick    int `json:"first_engage_tick"`
+	FirstRegroupTick   int `json:"first_regroup_tick"`
+	FirstDeathTick     int `json:"first_death_tick"`
+	FirstPanicTick     int `json:"first_panic_tick"`
+	FirstSurrenderTick int `json:"first_surrender_tick"`
+	FirstBreakTick     int `json:"first_break_tick"`
+
+	IntentChanges        int `json:"intent_changes"`
+	GoalChanges          int `json:"goal_changes"`
+	StateChanges         int `json:"state_changes"`
+	ContactNew           int `json:"contact_new"`
+	ContactLost          int `json:"contact_lost"`
+	StalledEvents        int `json:"stalled_events"`
+	DetachedEvents       int `json:"detached_events"`
+	DisobeyEvents        int `json:"disobey_events"`
+	PanicEvents          int `json:"panic_events"`
+	SurrenderEvents      int `json:"surrender_events"`
+	CohesionBreakEvents  int `json:"cohesion_break_events"`
+	CohesionReformEvents int `json:"cohesion_reform_events"`
+	PeakRefusing         int `json:"peak_refusing"`
+	PeakRefusingTick     int `json:"peak_refusing_tick"`
+
+	RedTotal      int `json:"red_total"`
+	BlueTotal     int `json:"blue_total"`
+	RedSurvivors  int `json:"red_survivors"`
+	BlueSurvivors int `json:"blue_survivors"`
+
+	Stalemate       bool          `json:"stalemate"`
+	StalemateReason string        `json:"stalemate_reason"`
+	Outcome         outcomeRecord `json:"outcome"`
+
+	Grades []gradeRecord `json:"grades,omitempty"`
+	Window *windowRecord `json:"window,omitempty"`
+	Log    []logRecord   `json:"log,omitempty"`
+}
+
+// outcomeRecord mirrors game.BattleOutcomeReason.
+type outcomeRecord struct {
+	Outcome          string `json:"outcome"`
+	Description      string `json:"description"`
+	RedSurvivors     int    `json:"red_survivors"`
+	RedTotal         int    `json:"red_total"`
+	BlueSurvivors    int    `json:"blue_survivors"`
+	BlueTotal        int    `json:"blue_total"`
+	RedSquadsBroken  int    `json:"red_squads_broken"`
+	RedSquadsTotal   int    `json:"red_squads_total"`
+	BlueSquadsBroken int    `json:"blue_squads_broken"`
+	BlueSquadsTotal  int    `json:"blue_squads_total"`
+	RedFled          int    `json:"red_fled"`
+	BlueFled         int    `json:"blue_fled"`
+
+	RedKilled         int `json:"red_killed"`
+	RedIncapacitated  int `json:"red_incapacitated"`
+	RedEvacuated      int `json:"red_evacuated"`
+	BlueKilled        int `json:"blue_killed"`
+	BlueIncapacitated int `json:"blue_incapacitated"`
+	BlueEvacuated     int `json:"blue_evacuated"`
+	RedStopped        int `json:"red_rounds_stopped"`
+	BlueStopped       int `json:"blue_rounds_stopped"`
+}
+
+// gradeRecord mirrors game.SoldierGrade.
+type gradeRecord struct {
+	Label            string   `json:"label"`
+	Team             string   `json:"team"`
+	ID               int      `json:"id"`
+	Grade            string   `json:"grade"`
+	Score            float64  `json:"score"`
+	Survived         bool     `json:"survived"`
+	FirefightScore   float64  `json:"firefight_score"`
+	UnderFireScore   float64  `json:"under_fire_score"`
+	PositioningScore float64  `json:"positioning_score"`
+	AggressionScore  float64  `json:"aggression_score"`
+	ComposureScore   float64  `json:"composure_score"`
+	TeamworkScore    float64  `json:"teamwork_score"`
+	GoodTraits       []string `json:"good_traits"`
+	BadTraits        []string `json:"bad_traits"`
+	CombatTimePct    float64  `json:"combat_time_pct"`
+	PeakFear         float64  `json:"peak_fear"`
+	AvgFear          float64  `json:"avg_fear"`
+	DamageTaken      float64  `json:"damage_taken"`
+}
+
+// windowRecord mirrors game.WindowReport, keyed by team then measure.
+type windowRecord struct {
+	FromTick    int              `json:"from_tick"`
+	ToTick      int              `json:"to_tick"`
+	SampleCount int              `json:"sample_count"`
+	Red         windowTeamRecord `json:"red"`
+	Blue        windowTeamRecord `json:"blue"`
+}
+
+type windowTeamRecord struct {
+	GoalPct            map[string]float64 `json:"goal_pct"`
+	AvgAlive           float64            `json:"avg_alive"`
+	AvgInjured         float64            `json:"avg_injured"`
+	AvgWithContact     float64            `json:"avg_with_contact"`
+	AvgEnemiesSeen     float64            `json:"avg_enemies_seen"`
+	AvgPosture         float64            `json:"avg_posture"`
+	AvgStalledInCombat float64            `json:"avg_stalled_in_combat"`
+	AvgDetached        float64            `json:"avg_detached"`
+	AvgDisobeying      float64            `json:"avg_disobeying"`
+	AvgPanicRetreat    float64            `json:"avg_panic_retreat"`
+	AvgSurrendered     float64            `json:"avg_surrendered"`
+	AvgSquadBroken     float64            `json:"avg_squad_broken_members"`
+	AvgSquadStress     float64            `json:"avg_squad_stress"`
+	AvgCasualtyRate    float64            `json:"avg_casualty_rate"`
+	TotalDead          int                `json:"total_dead"`
+}
+
+// logRecord mirrors game.SimLogEntry.
+type logRecord struct {
+	Tick     int     `json:"tick"`
+	Soldier  string  `json:"soldier"`
+	Team     string  `json:"team"`
+	Category string  `json:"category"`
+	Key      string  `json:"key"`
+	Value    string  `json:"value"`
+	NumVal   float64 `json:"num_val"`
+}
+
+// intervalRecord is an interval; it is null in JSON when there was nothing
+// to estimate.
+type intervalRecord struct {
+	Est float64 `json:"est"`
+	Lo  float64 `json:"lo"`
+	Hi  float64 `json:"hi"`
+	N   int     `json:"n"`
+}
+
+type summaryRecord struct {
+	RedWinRate        *intervalRecord `json:"red_win_rate"`
+	BlueWinRate       *intervalRecord `json:"blue_win_rate"`
+	DrawRate          *intervalRecord `json:"draw_rate"`
+	StalemateRate     *intervalRecord `json:"stalemate_rate"`
+	CasualtyRatio     *intervalRecord `json:"casualty_ratio"`
+	FirstContactTicks *intervalRecord `json:"first_contact_ticks"`
+}
+
+func newRunRecord(rs runStats) runRecord {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	r := runRecord{
+		Run:                  rs.runIndex,
+		Seed:                 rs.seed,
+		Ticks:                rs.ticks,
+		SetupMS:              ms(rs.setupDur),
+		SimMS:                ms(rs.simDur),
+		PostMS:               ms(rs.postDur),
+		FirstContactTick:     rs.firstContactTick,
+		FirstEngageTick:      rs.firstEngageTick,
+		FirstRegroupTick:     rs.firstRegroupTick,
+		FirstDeathTick:       rs.firstDeathTick,
+		FirstPanicTick:       rs.firstPanicTick,
+		FirstSurrenderTick:   rs.firstSurrenderTick,
+		FirstBreakTick:       rs.firstBreakTick,
+		IntentChanges:        rs.intentChanges,
+		GoalChanges:          rs.goalChanges,
+		StateChanges:         rs.stateChanges,
+		ContactNew:           rs.contactNew,
+		ContactLost:          rs.contactLost,
+		StalledEvents:        rs.stalledEvents,
+		DetachedEvents:       rs.detachedEvents,
+		DisobeyEvents:        rs.disobeyEvents,
+		PanicEvents:          rs.panicEvents,
+		SurrenderEvents:      rs.surrenderEvents,
+		CohesionBreakEvents:  rs.cohesionBreakEvents,
+		CohesionReformEvents: rs.cohesionReformEvents,
+		PeakRefusing:         rs.peakRefusing,
+		PeakRefusingTick:     rs.peakRefusingTick,
+		RedTotal:             rs.redTotal,
+		BlueTotal:            rs.blueTotal,
+		RedSurvivors:         rs.redSurvivors,
+		BlueSurvivors:        rs.blueSurvivors,
+		Stalemate:            rs.stalemate,
+		StalemateReason:      rs.stalemateReason,
+		Outcome:              newOutcomeRecord(rs.outcomeReason),
+		Window:               newWindowRecord(rs.windowSummary),
+	}
+	for _, g := range rs.grades {
+		r.Grades = append(r.Grades, newGradeRecord(g))
+	}
+	// Grades come out of a map; order them so output diffs cleanly.
+	sort.Slice(r.Grades, func(i, j int) bool { return r.Grades[i].ID < r.Grades[j].ID })
+	for _, e := range rs.log {
+		r.Log = append(r.Log, logRecord(e))
+	}
+	return r
+}
+
+func newOutcomeRecord(o game.BattleOutcomeReason) outcomeRecord {
+	return outcomeRecord{
+		Outcome:          o.Outcome.String(),
+		Description:      o.Description,
+		RedSurvivors:     o.RedSurvivors,
+		RedTotal:         o.RedTotal,
+		BlueSurvivors:    o.BlueSurvivors,
+		BlueTotal:        o.BlueTotal,
+		RedSquadsBroken:  o.RedSquadsBroken,
+		RedSquadsTotal:   o.RedSquadsTotal,
+		BlueSquadsBroken: o.BlueSquadsBroken,
+		BlueSquadsTotal:  o.BlueSquadsTotal,
+		RedFled:          o.RedFled,
+		BlueFled:         o.BlueFled,
+
+		RedKilled:         o.RedKilled,
+		RedIncapacitated:  o.RedIncapacitated,
+		RedEvacuated:      o.RedEvacuated,
+		BlueKilled:        o.BlueKilled,
+		BlueIncapacitated: o.BlueIncapacitated,
+		BlueEvacuated:     o.BlueEvacuated,
+		RedStopped:        o.RedStopped,
+		BlueStopped:       o.BlueStopped,
+	}
+}
+
+func newGradeRecord(g game.SoldierGrade) gradeRecord {
+	return gradeRecord{
+		Label:            g.Label,
+		Team:             teamLabel(g.Team),
+		ID:               g.ID,
+		Grade:            g.Grade,
+		Score:            g.Score,
+		Survived:         g.Survived,
+		FirefightScore:   g.FirefightScore,
+		UnderFireScore:   g.UnderFireScore,
+		PositioningScore: g.PositioningScore,
+		AggressionScore:  g.AggressionScore,
+		ComposureScore:   g.ComposureScore,
+		TeamworkScore:    g.TeamworkScore,
+		GoodTraits:       nonNil(g.GoodTraits),
+		BadTraits:        nonNil(g.BadTraits),
+		CombatTimePct:    g.CombatTimePct,
+		PeakFear:         g.PeakFear,
+		AvgFear:          g.AvgFear,
+		DamageTaken:      g.DamageTaken,
+	}
+}
+
+// nonNil keeps empty trait lists as [] rather than null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func newWindowRecord(wr *game.WindowReport) *windowRecord {
+	if wr == nil {
+		return nil
+	}
+	goals := func(m map[game.GoalKind]float64) map[string]float64 {
+		out := make(map[string]float64, len(exportGoals))
+		for _, g := range exportGoals {
+			out[g.String()] = m[g]
+		}
+		return out
+	}
+	return &windowRecord{
+		FromTick:    wr.FromTick,
+		ToTick:      wr.ToTick,
+		SampleCount: wr.SampleCount,
+		Red: windowTeamRecord{
+			GoalPct:            goals(wr.RedGoalPct),
+			AvgAlive:           wr.AvgRedAlive,
+			AvgInjured:         wr.AvgRedInjured,
+			AvgWithContact:     wr.AvgRedWithContact,
+			AvgEnemiesSeen:     wr.AvgRedEnemiesSeen,
+			AvgPosture:         wr.AvgRedPosture,
+			AvgStalledInCombat: wr.AvgRedStalledInCombat,
+			AvgDetached:        wr.AvgRedDetached,
+			AvgDisobeying:      wr.AvgRedDisobeying,
+			AvgPanicRetreat:    wr.AvgRedPanicRetreat,
+			AvgSurrendered:     wr.AvgRedSurrendered,
+			AvgSquadBroken:     wr.AvgRedSquadBrokenMembers,
+			AvgSquadStress:     wr.AvgRedSquadStress,
+			AvgCasualtyRate:    wr.AvgRedCasualtyRate,
+			TotalDead:          wr.TotalRedDead,
+		},
+		Blue: windowTeamRecord{
+			GoalPct:            goals(wr.BlueGoalPct),
+			AvgAlive:           wr.AvgBlueAlive,
+			AvgInjured:         wr.AvgBlueInjured,
+			AvgWithContact:     wr.AvgBlueWithContact,
+			AvgEnemiesSeen:     wr.AvgBlueEnemiesSeen,
+			AvgPosture:         wr.AvgBluePosture,
+			AvgStalledInCombat: wr.AvgBlueStalledInCombat,
+			AvgDetached:        wr.AvgBlueDetached,
+			AvgDisobeying:      wr.AvgBlueDisobeying,
+			AvgPanicRetreat:    wr.AvgBluePanicRetreat,
+			AvgSurrendered:     wr.AvgBlueSurrendered,
+			AvgSquadBroken:     wr.AvgBlueSquadBrokenMembers,
+			AvgSquadStress:     wr.AvgBlueSquadStress,
+			AvgCasualtyRate:    wr.AvgBlueCasualtyRate,
+			TotalDead:          wr.TotalBlueDead,
+		},
+	}
+}
+
+func newIntervalRecord(iv interval) *intervalRecord {
+	if iv.n == 0 || math.IsNaN(iv.est) {
+		return nil
+	}
+	return &intervalRecord{Est: iv.est, Lo: iv.lo, Hi: iv.hi, N: iv.n}
+}
+
+func newSummaryRecord(all []runStats) *summaryRecord {
+	s := summarizeBatch(all)
+	return &summaryRecord{
+		RedWinRate:        newIntervalRecord(s.redWin),
+		BlueWinRate:       newIntervalRecord(s.blueWin),
+		DrawRate:          newIntervalRecord(s.draw),
+		StalemateRate:     newIntervalRecord(s.stalemate),
+		CasualtyRatio:     newIntervalRecord(s.casualtyRatio),
+		FirstContactTicks: newIntervalRecord(s.firstContact),
+	}
+}
+
+// writeReport writes the batch in format: json and ndjson go to w (or to a
+// report file in outDir), csv writes one file per table into outDir.
+func writeReport(format, outDir string, w io.Writer, rep reportRecord, all []runStats) error {
+	if format == formatCSV {
+		if outDir == "" {
+			return fmt.Errorf("-format csv writes several tables and needs -out DIR")
+		}
+		return writeCSVTables(outDir, all, rep.Summary)
+	}
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o750); err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(outDir, "report."+format), func(f io.Writer) error {
+			return writeReport(format, "", f, rep, all)
+		})
+	}
+	switch format {
+	case formatJSON:
+		for _, rs := range all {
+			rep.Runs = append(rep.Runs, newRunRecord(rs))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case formatNDJSON:
+		return writeNDJSON(w, rep, all)
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// writeNDJSON writes one object per line, each tagged with a "type": a
+// header, then per run its "run", "grade", "window" and "log" lines, then
+// the "summary". Every line but the header and summary carries "run".
+func writeNDJSON(w io.Writer, rep reportRecord, all []runStats) error {
+	enc := json.NewEncoder(w)
+	summary := rep.Summary
+	rep.Summary = nil
+	if err := enc.Encode(struct {
+		Type string `json:"type"`
+		reportRecord
+	}{"header", rep}); err != nil {
+		return err
+	}
+	for _, rs := range all {
+		r := newRunRecord(rs)
+		grades, window, log := r.Grades, r.Window, r.Log
+		r.Grades, r.Window, r.Log = nil, nil, nil
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			runRecord
+		}{"run", r}); err != nil {
+			return err
+		}
+		for _, g := range grades {
+			if err := enc.Encode(struct {
+				Type string `json:"type"`
+				Run  int    `json:"run"`
+				gradeRecord
+			}{"grade", r.Run, g}); err != nil {
+				return err
+			}
+		}
+		if window != nil {
+			if err := enc.Encode(struct {
+				Type string `json:"type"`
+				Run  int    `json:"run"`
+				windowRecord
+			}{"window", r.Run, *window}); err != nil {
+				return err
+			}
+		}
+		for _, e := range log {
+			if err := enc.Encode(struct {
+				Type string `json:"type"`
+				Run  int    `json:"run"`
+				logRecord
+			}{"log", r.Run, e}); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.Encode(struct {
+		Type string `json:"type"`
+		summaryRecord
+	}{"summary", *summary})
+}
+
+// csvTable is one CSV file: a header and its rows.
+type csvTable struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+func (t *csvTable) add(row ...string) { t.rows = append(t.rows, row) }
+
+func itoa(v int) string            { return strconv.Itoa(v) }
+func ftoa(v float64) string        { return strconv.FormatFloat(v, 'g', -1, 64) }
+func btoa(v bool) string           { return strconv.FormatBool(v) }
+func i64toa(v int64) string        { return strconv.FormatInt(v, 10) }
+func joinTraits(t []string) string { return strings.Join(t, ";") }
+
+// csvTables flattens a batch into runs, grades, windows, log and summary
+// tables. Every table but summary starts with the run number so they join.
+func csvTables(all []runStats, summary *summaryRecord) []*csvTable {
+	runs := &csvTable{name: "runs", header: []string{
+		"run", "seed", "ticks", "setup_ms", "sim_ms", "post_ms",
+		"first_contact_tick", "first_engage_tick", "first_regroup_tick", "first_death_tick",
+		"first_panic_tick", "first_surrender_tick", "first_break_tick",
+		"intent_changes", "goal_changes", "state_changes", "contact_new", "contact_lost",
+		"stalled_events", "detached_events", "disobey_events", "panic_events", "surrender_events",
+		"cohesion_break_events", "cohesion_reform_events", "peak_refusing", "peak_refusing_tick",
+		"red_total", "blue_total", "red_survivors", "blue_survivors",
+		"stalemate", "stalemate_reason",
+		"outcome", "outcome_description", "red_squads_broken", "red_squads_total",
+		"blue_squads_broken", "blue_squads_total", "red_fled", "blue_fled",
+		"red_killed", "red_incapacitated", "red_evacuated", "blue_killed", "blue_incapacitated", "blue_evacuated",
+		"red_rounds_stopped", "blue_rounds_stopped",
+	}}
+	grades := &csvTable{name: "grades", header: []string{
+		"run", "label", "team", "id", "grade", "score", "survived",
+		"firefight_score", "under_fire_score", "positioning_score", "aggression_score",
+		"composure_score", "teamwork_score", "good_traits", "bad_traits",
+		"combat_time_pct", "peak_fear", "avg_fear", "damage_taken",
+	}}
+	windows := &csvTable{name: "windows", header: []string{
+		"run", "team", "from_tick", "to_tick", "sample_count", "avg_alive", "avg_injured", "avg_with_contact", "avg_enemies_seen", "avg_posture",
+		"avg_stalled_in_combat", "avg_detached", "avg_disobeying", "avg_panic_retreat",
+		"avg_surrendered", "avg_squad_broken_members", "avg_squad_stress", "avg_casualty_rate", "total_dead",
+	}}
+	for _, g := range exportGoals {
+		windows.header = append(windows.header, "goal_pct_"+g.String())
+	}
+	logs := &csvTable{name: "log", header: []string{"run", "tick", "soldier", "team", "category", "key", "value", "num_val"}}
+
+	for _, rs := range all {
+		r := newRunRecord(rs)
+		o := r.Outcome
+		runs.add(itoa(r.Run), i64toa(r.Seed), itoa(r.Ticks), ftoa(r.SetupMS), ftoa(r.SimMS), ftoa(r.PostMS),
+			itoa(r.FirstContactTick), itoa(r.FirstEngageTick), itoa(r.FirstRegroupTick), itoa(r.FirstDeathTick),
+			itoa(r.FirstPanicTick), itoa(r.FirstSurrenderTick), itoa(r.FirstBreakTick),
+			itoa(r.IntentChanges), itoa(r.GoalChanges), itoa(r.StateChanges), itoa(r.ContactNew), itoa(r.ContactLost),
+			itoa(r.StalledEvents), itoa(r.DetachedEvents), itoa(r.DisobeyEvents), itoa(r.PanicEvents), itoa(r.SurrenderEvents),
+			itoa(r.CohesionBreakEvents), itoa(r.CohesionReformEvents), itoa(r.PeakRefusing), itoa(r.PeakRefusingTick),
+			itoa(r.RedTotal), itoa(r.BlueTotal), itoa(r.RedSurvivors), itoa(r.BlueSurvivors),
+			btoa(r.Stalemate), r.StalemateReason,
+			o.Outcome, o.Description, itoa(o.RedSquadsBroken), itoa(o.RedSquadsTotal),
+			itoa(o.BlueSquadsBroken), itoa(o.BlueSquadsTotal), itoa(o.RedFled), itoa(o.BlueFled),
+			itoa(o.RedKilled), itoa(o.RedIncapacitated), itoa(o.RedEvacuated), itoa(o.BlueKilled), itoa(o.BlueIncapacitated), itoa(o.BlueEvacuated),
+			itoa(o.RedStopped), itoa(o.BlueStopped))
+		for _, g := range r.Grades {
+			grades.add(itoa(r.Run), g.Label, g.Team, itoa(g.ID), g.Grade, ftoa(g.Score), btoa(g.Survived),
+				ftoa(g.FirefightScore), ftoa(g.UnderFireScore), ftoa(g.PositioningScore), ftoa(g.AggressionScore),
+				ftoa(g.ComposureScore), ftoa(g.TeamworkScore), joinTraits(g.GoodTraits), joinTraits(g.BadTraits),
+				ftoa(g.CombatTimePct), ftoa(g.PeakFear), ftoa(g.AvgFear), ftoa(g.DamageTaken))
+		}
+		if w := r.Window; w != nil {
+			for _, side := range []struct {
+				team string
+				t    windowTeamRecord
+			}{{"red", w.Red}, {"blue", w.Blue}} {
+				t := side.t
+				row := []string{itoa(r.Run), side.team, itoa(w.FromTick), itoa(w.ToTick), itoa(w.SampleCount),
+					ftoa(t.AvgAlive), ftoa(t.AvgInjured), ftoa(t.AvgWithContact), ftoa(t.AvgEnemiesSeen), ftoa(t.AvgPosture),
+					ftoa(t.AvgStalledInCombat), ftoa(t.AvgDetached), ftoa(t.AvgDisobeying), ftoa(t.AvgPanicRetreat),
+					ftoa(t.AvgSurrendered), ftoa(t.AvgSquadBroken), ftoa(t.AvgSquadStress), ftoa(t.AvgCasualtyRate), itoa(t.TotalDead)}
+				for _, g := range exportGoals {
+					row = append(row, ftoa(t.GoalPct[g.String()]))
+				}
+				windows.add(row...)
+			}
+		}
+		for _, e := range r.Log {
+			logs.add(itoa(r.Run), itoa(e.Tick), e.Soldier, e.Team, e.Category, e.Key, e.Value, ftoa(e.NumVal))
+		}
+	}
+
+	sum := &csvTable{name: "summary", header: []string{"metric", "est", "lo", "hi", "n"}}
+	if summary != nil {
+		for _, m := range []struct {
+			name string
+			iv   *intervalRecord
+		}{
+			{"red_win_rate", summary.RedWinRate},
+			{"blue_win_rate", summary.BlueWinRate},
+			{"draw_rate", summary.DrawRate},
+			{"stalemate_rate", summary.StalemateRate},
+			{"casualty_ratio", summary.CasualtyRatio},
+			{"first_contact_ticks", summary.FirstContactTicks},
+		} {
+			if m.iv == nil {
+				sum.add(m.name, "", "", "", "0")
+				continue
+			}
+			sum.add(m.name, ftoa(m.iv.Est), ftoa(m.iv.Lo), ftoa(m.iv.Hi), itoa(m.iv.N))
+		}
+	}
+	return []*csvTable{runs, grades, windows, logs, sum}
+}
+
+func writeCSVTables(dir string, all []runStats, summary *summaryRecord) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	for _, t := range csvTables(all, summary) {
+		if err := writeCSVFile(filepath.Join(dir, t.name+".csv"), t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(path string, t *csvTable) error {
+	return writeFile(path, t.write)
+}
+
+// writeFile creates path and fills it with write. A failed Close is
+// reported too: it can be the only sign the data never reached the disk.
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := os.Create(path) // #nosec G304 -- user-chosen output path
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return write(f)
+}
+
+func (t *csvTable) write(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.header); err != nil {
+		return err
+	}
+	return cw.WriteAll(t.rows)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -130,6 +132,8 @@ type runStats struct {
 
 	soldierPerf         []soldierPerformance
 	problematicSoldiers []soldierPerformance
+
+	log []game.SimLogEntry // kept only for machine-readable output
 }
 
 const (
@@ -354,75 +358,226 @@ func abs(x int) int {
 	return x
 }
 
+// setTeamArmour dresses every soldier on team in kit, overriding the
+// scenario's squad and soldier armour.
+func setTeamArmour(sc *game.ScenarioFile, team, kit string) {
+	for i := range sc.Squads {
+		if sc.Squads[i].Team == team {
+			sc.Squads[i].Armour = ""
+		}
+	}
+	for i := range sc.Soldiers {
+		if sc.Soldiers[i].Team == team {
+			sc.Soldiers[i].Armour = kit
+		}
+	}
+}
+
+// intelName is the scenario's intel mode as reports print it.
+func intelName(sc *game.ScenarioFile) string {
+	mode, _ := game.ParseIntelMode(sc.Intel)
+	return mode.String()
+}
+
 func main() {
 	var runs int
+	var workers int
 	var ticks int
 	var seedBase int64
 	var seedStep int64
 	var scenario string
+	var scenarioFile string
+	var recordPath string
+	var format, outDir string
+	var sweep sweepFlag
+	var hour, rain, fog, wind, windDir float64
+	var intel string
+	var redArmour, blueArmour string
 
 	flag.IntVar(&runs, "runs", 5, "number of headless simulation runs")
-	flag.IntVar(&ticks, "ticks", 3600, "ticks per run")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "runs to simulate in parallel")
+	flag.IntVar(&ticks, "ticks", 3600, "ticks per run (defaults to the scenario's stop.max_ticks when set)")
 	flag.Int64Var(&seedBase, "seed-base", 42, "base RNG seed for run 1")
 	flag.Int64Var(&seedStep, "seed-step", 1, "seed increment between runs")
-	flag.StringVar(&scenario, "scenario", "mutual-advance", "scenario name")
+	flag.StringVar(&scenario, "scenario", "mutual-advance", "built-in scenario name ("+strings.Join(builtinScenarioNames(), ", ")+")")
+	flag.StringVar(&scenarioFile, "scenario-file", "", "path to a JSON scenario file (overrides -scenario)")
+	flag.StringVar(&recordPath, "record", "", "write a replay of run 1 to this file (view with cmd/game -replay)")
+	flag.StringVar(&format, "format", formatText, "output format: "+strings.Join(exportFormats, ", "))
+	flag.StringVar(&outDir, "out", "", "write json/ndjson/csv output into this directory instead of stdout (required for csv)")
+	flag.Var(&sweep, "sweep", "vary a balance parameter: name=v1,v2,... or name=start:stop:step; repeat for a grid, -runs seeds per point ("+strings.Join(game.SimParamNames(), ", ")+")")
+	flag.Float64Var(&hour, "hour", 12, "local time at the start of the battle, 0-24 (overrides the scenario environment)")
+	flag.Float64Var(&rain, "rain", 0, "rain intensity 0-1 (overrides the scenario environment)")
+	flag.Float64Var(&fog, "fog", 0, "fog density 0-1 (overrides the scenario environment)")
+	flag.Float64Var(&wind, "wind", 0, "wind speed in m/s (overrides the scenario environment)")
+	flag.Float64Var(&windDir, "wind-dir", 0, "degrees the wind blows toward, 0 = east (overrides the scenario environment)")
+	flag.StringVar(&intel, "intel", "shared", "how squads share sightings: shared (team-wide map) or radio (per-squad maps fed by radio reports); overrides the scenario")
+	flag.StringVar(&redArmour, "red-armour", "", "armour kit for every red soldier: none, helmet, plate or full (overrides the scenario)")
+	flag.StringVar(&blueArmour, "blue-armour", "", "armour kit for every blue soldier: none, helmet, plate or full (overrides the scenario)")
 	flag.Parse()
 
 	if runs <= 0 {
 		fmt.Println("error: -runs must be > 0")
 		return
 	}
+	switch format {
+	case formatText, formatJSON, formatNDJSON:
+	case formatCSV:
+		if outDir == "" && len(sweep) == 0 {
+			fmt.Println("error: -format csv writes several tables and needs -out DIR")
+			return
+		}
+	default:
+		fmt.Printf("error: unknown -format %q (want %s)\n", format, strings.Join(exportFormats, ", "))
+		return
+	}
+	sc, err := loadScenario(scenario, scenarioFile)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	ticksSet := false
+	flag.Visit(func(f *flag.Flag) {
+		env := func() *game.ScenarioEnv {
+			if sc.Environment == nil {
+				sc.Environment = &game.ScenarioEnv{}
+			}
+			return sc.Environment
+		}
+		switch f.Name {
+		case "ticks":
+			ticksSet = true
+		case "hour":
+			env().Hour = &hour
+		case "rain":
+			env().Rain = &rain
+		case "fog":
+			env().Fog = &fog
+		case "wind":
+			env().Wind = &wind
+		case "wind-dir":
+			env().WindDir = &windDir
+		case "intel":
+			sc.Intel = intel
+		case "red-armour":
+			setTeamArmour(sc, "red", redArmour)
+		case "blue-armour":
+			setTeamArmour(sc, "blue", blueArmour)
+		}
+	})
+	if err := sc.Validate(); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	if !ticksSet && sc.Stop.MaxTicks > 0 {
+		ticks = sc.Stop.MaxTicks
+	}
 	if ticks <= 0 {
 		fmt.Println("error: -ticks must be > 0")
 		return
 	}
-	if scenario != "mutual-advance" {
-		fmt.Printf("error: unsupported scenario %q (supported: mutual-advance)\n", scenario)
+
+	text := format == formatText
+	if len(sweep) > 0 {
+		if err := sweep.check(sc); err != nil {
+			fmt.Printf("error: -sweep: %v\n", err)
+			return
+		}
+		jobs := batchJobs(runs, seedBase, seedStep, "")
+		results := runSweep(sweep, jobs, workers, ticks, sc, func(point, points int) {
+			fmt.Fprintf(os.Stderr, "\rpoint %d/%d done", point, points)
+		})
+		fmt.Fprintln(os.Stderr)
+		if text {
+			fmt.Printf("scenario=%s runs_per_point=%d ticks=%d seed_base=%d seed_step=%d\n\n", sc.Name, runs, ticks, seedBase, seedStep)
+			printSweep(sweep, results)
+			return
+		}
+		rep := reportRecord{
+			SchemaVersion: exportSchemaVersion,
+			Scenario:      sc.Name,
+			Environment:   sc.Environment.Build().String(),
+			Intel:         intelName(sc),
+			RunCount:      runs,
+			MaxTicks:      ticks,
+			SeedBase:      seedBase,
+			SeedStep:      seedStep,
+		}
+		if err := writeSweep(format, outDir, os.Stdout, rep, sweep, results); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
 		return
 	}
+	if text {
+		fmt.Printf("=== Headless Combat Report ===\n")
+		fmt.Printf("scenario=%s runs=%d ticks=%d seed_base=%d seed_step=%d\n", sc.Name, runs, ticks, seedBase, seedStep)
+		fmt.Printf("environment=%s intel=%s\n\n", sc.Environment.Build(), intelName(sc))
+	}
 
-	fmt.Printf("=== Headless Combat Report ===\n")
-	fmt.Printf("scenario=%s runs=%d ticks=%d seed_base=%d seed_step=%d\n\n", scenario, runs, ticks, seedBase, seedStep)
+	jobs := batchJobs(runs, seedBase, seedStep, recordPath)
+	for i := range jobs {
+		jobs[i].keepLog = !text
+	}
+	start := time.Now()
+	finished := 0
+	all := runBatch(jobs, workers, ticks, sc, func(rs runStats) {
+		finished++
+		fmt.Fprintf(os.Stderr, "\rrun %d/%d done", finished, runs)
+	})
+	fmt.Fprintln(os.Stderr)
+
+	if !text {
+		rep := reportRecord{
+			SchemaVersion: exportSchemaVersion,
+			Scenario:      sc.Name,
+			Environment:   sc.Environment.Build().String(),
+			Intel:         intelName(sc),
+			RunCount:      runs,
+			MaxTicks:      ticks,
+			SeedBase:      seedBase,
+			SeedStep:      seedStep,
+			Summary:       newSummaryRecord(all),
+		}
+		if err := writeReport(format, outDir, os.Stdout, rep, all); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	all := make([]runStats, 0, runs)
-	for i := 0; i < runs; i++ {
-		seed := seedBase + int64(i)*seedStep
-		stats := runScenarioMutualAdvance(i+1, seed, ticks)
-		all = append(all, stats)
-		printRun(stats)
+	for _, rs := range all {
+		printRun(rs)
 	}
 
 	printAggregate(all)
+	printConfidence(all)
+	fmt.Printf("\nwall_time=%s workers=%d\n", time.Since(start).Round(time.Millisecond), min(workers, runs))
 }
 
-func runScenarioMutualAdvance(runIndex int, seed int64, ticks int) runStats {
+// runScenario runs one seeded scenario and collects its stats. A non-empty
+// recordPath saves a replay of the run there.
+func runScenario(job runJob, maxTicks int, sc *game.ScenarioFile) runStats {
+	runIndex, seed, recordPath := job.index, job.seed, job.record
 	t0 := time.Now()
 	setupStart := time.Now()
-	bf := game.NewHeadlessBattlefield(seed, 3072, 1728)
-	ts := game.NewTestSim(
-		game.WithHeadlessBattlefield(bf),
-		game.WithSeed(seed),
-		game.WithRedSoldier(0, 80, 864, 2992, 864),
-		game.WithRedSoldier(1, 80, 836, 2992, 836),
-		game.WithRedSoldier(2, 80, 892, 2992, 892),
-		game.WithRedSoldier(3, 80, 808, 2992, 808),
-		game.WithRedSoldier(4, 80, 920, 2992, 920),
-		game.WithRedSoldier(5, 80, 780, 2992, 780),
-		game.WithBlueSoldier(6, 2992, 864, 80, 864),
-		game.WithBlueSoldier(7, 2992, 836, 80, 836),
-		game.WithBlueSoldier(8, 2992, 892, 80, 892),
-		game.WithBlueSoldier(9, 2992, 808, 80, 808),
-		game.WithBlueSoldier(10, 2992, 920, 80, 920),
-		game.WithBlueSoldier(11, 2992, 780, 80, 780),
-		game.WithRedSquad(0, 1, 2, 3, 4, 5),
-		game.WithBlueSquad(6, 7, 8, 9, 10, 11),
-	)
+	ts := game.NewTestSimFromScenario(sc, seed)
+	if recordPath != "" {
+		ts.StartRecording(sc.Name, seed, sc.Map)
+	}
 	setupDur := time.Since(setupStart)
 
 	simStart := time.Now()
-	ts.RunTicks(ticks)
+	ticks := ts.RunScenario(sc, maxTicks)
 	simDur := time.Since(simStart)
 
+	if recordPath != "" {
+		if err := ts.Recording().Save(recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "replay of run %d saved to %s\n", runIndex, recordPath)
+		}
+	}
+
 	postStart := time.Now()
 
 	entries := ts.SimLog.Entries()
@@ -623,19 +778,11 @@ func runScenarioMutualAdvance(runIndex int, seed int64, ticks int) runStats {
 	rs.stalemate, rs.stalemateReason = detectStalemate(rs)
 
 	// Determine battle outcome
-	redSoldiers := ts.AllByTeam(game.TeamRed)
-	blueSoldiers := ts.AllByTeam(game.TeamBlue)
-	redSquads := []*game.Squad{}
-	blueSquads := []*game.Squad{}
-	for _, sq := range ts.Squads {
-		if sq.Team == game.TeamRed {
-			redSquads = append(redSquads, sq)
-		} else if sq.Team == game.TeamBlue {
-			blueSquads = append(blueSquads, sq)
-		}
-	}
-	rs.outcomeReason = game.DetermineBattleOutcome(redSoldiers, blueSoldiers, redSquads, blueSquads)
+	rs.outcomeReason = ts.Outcome()
 	rs.outcome = rs.outcomeReason.Outcome
+	if job.keepLog {
+		rs.log = entries
+	}
 
 	rs.postDur = time.Since(postStart)
 	rs.totalDur = time.Since(t0)
@@ -836,6 +983,10 @@ func printRun(rs runStats) {
 	fmt.Printf("effectiveness_events: stalled_in_combat=%d detached_from_engagement=%d affected_soldiers=%d\n",
 		rs.stalledEvents, rs.detachedEvents, len(rs.affected))
 	fmt.Printf("survivors: red=%d/%d blue=%d/%d\n", rs.redSurvivors, rs.redTotal, rs.blueSurvivors, rs.blueTotal)
+	fmt.Printf("casualties: red killed=%d incapacitated=%d evacuated=%d blue killed=%d incapacitated=%d evacuated=%d\n",
+		rs.outcomeReason.RedKilled, rs.outcomeReason.RedIncapacitated, rs.outcomeReason.RedEvacuated,
+		rs.outcomeReason.BlueKilled, rs.outcomeReason.BlueIncapacitated, rs.outcomeReason.BlueEvacuated)
+	fmt.Printf("armour_stops: red=%d blue=%d\n", rs.outcomeReason.RedStopped, rs.outcomeReason.BlueStopped)
 	fmt.Printf("stalemate_check: verdict=%t reason=%s\n", rs.stalemate, rs.stalemateReason)
 	fmt.Printf("battle_outcome: %s (%s) red_squads_broken=%d/%d blue_squads_broken=%d/%d\n",
 		rs.outcome, rs.outcomeReason.Description,
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -77,3 +82,246 @@ func TestDetectStalemate_FalseWhenAttritionDecisive(t *testing.T) {
 		t.Fatalf("expected stalemate=false under decisive attrition (reason=%s)", reason)
 	}
 }
+
+func TestLoadScenario_BuiltinMutualAdvance(t *testing.T) {
+	sc, err := loadScenario("mutual-advance", "")
+	if err != nil {
+		t.Fatalf("load built-in scenario: %v", err)
+	}
+	if len(sc.Soldiers) != 12 || len(sc.Squads) != 2 {
+		t.Fatalf("expected 12 soldiers in 2 squads, got %d in %d", len(sc.Soldiers), len(sc.Squads))
+	}
+	for i, sq := range sc.Squads {
+		if len(sq.Loadout) != 1 || sq.Loadout[0] != "standard" {
+			t.Fatalf("squad %d loadout %v, want the game's standard issue", i, sq.Loadout)
+		}
+	}
+	if _, err := loadScenario("no-such-scenario", ""); err == nil {
+		t.Fatal("expected error for unknown built-in scenario")
+	}
+}
+
+func TestProportionCI_WilsonBounds(t *testing.T) {
+	iv := proportionCI(0, 20)
+	if iv.est != 0 || iv.lo != 0 || iv.hi < 0.1 || iv.hi > 0.2 {
+		t.Fatalf("0/20 should give [0, ~0.16], got %+v", iv)
+	}
+	iv = proportionCI(50, 100)
+	if iv.lo > 0.41 || iv.lo < 0.39 || iv.hi < 0.59 || iv.hi > 0.61 {
+		t.Fatalf("50/100 should give about [0.40, 0.60], got %+v", iv)
+	}
+	if proportionCI(0, 0).String() != "n/a" {
+		t.Fatal("an empty batch has no interval")
+	}
+}
+
+func TestRatioCI_PoolsRunsAndBracketsEstimate(t *testing.T) {
+	iv := ratioCI([]float64{2, 4, 0, 3}, []float64{1, 2, 0, 3})
+	if iv.est != 1.5 || iv.lo > iv.est || iv.hi < iv.est {
+		t.Fatalf("ratio 9/6 should be 1.5 inside its interval, got %+v", iv)
+	}
+	if iv := ratioCI([]float64{1, 2}, []float64{0, 0}); iv.String() != "n/a" {
+		t.Fatalf("no blue losses should give n/a, got %s", iv)
+	}
+}
+
+func TestRunBatch_ParallelMatchesSerial(t *testing.T) {
+	sc, err := loadScenario("mutual-advance", "")
+	if err != nil {
+		t.Fatalf("load built-in scenario: %v", err)
+	}
+	jobs := batchJobs(4, 7, 3, "")
+	serial := runBatch(jobs, 1, 300, sc, nil)
+	calls := 0
+	parallel := runBatch(jobs, 4, 300, sc, func(runStats) { calls++ })
+	if calls != len(jobs) {
+		t.Fatalf("done called %d times, want %d", calls, len(jobs))
+	}
+	for i := range jobs {
+		a, b := serial[i], parallel[i]
+		if a.runIndex != i+1 || b.runIndex != i+1 || a.seed != 7+int64(i)*3 {
+			t.Fatalf("run %d out of order: serial #%d seed %d, parallel #%d", i, a.runIndex, a.seed, b.runIndex)
+		}
+		if a.ticks != b.ticks || a.stateChanges != b.stateChanges || a.contactNew != b.contactNew ||
+			a.redSurvivors != b.redSurvivors || a.blueSurvivors != b.blueSurvivors {
+			t.Fatalf("run %d differs when run in parallel: %+v vs %+v", i+1, a.grades, b.grades)
+		}
+	}
+}
+
+func exportFixture() []runStats {
+	return []runStats{{
+		runIndex:         1,
+		seed:             42,
+		ticks:            600,
+		firstContactTick: 120,
+		redTotal:         2, blueTotal: 2, redSurvivors: 2, blueSurvivors: 1,
+		outcome:       game.OutcomeRedVictory,
+		outcomeReason: game.BattleOutcomeReason{Outcome: game.OutcomeRedVictory, Description: "blue broke", BlueKilled: 1, RedStopped: 3},
+		grades:        []game.SoldierGrade{{Label: "R0", Team: game.TeamRed, Grade: "B", Score: 71, Survived: true, GoodTraits: []string{"steady_advance"}}},
+		windowSummary: &game.WindowReport{ToTick: 600, SampleCount: 10, RedGoalPct: map[game.GoalKind]float64{game.GoalEngage: 40}},
+		log:           []game.SimLogEntry{{Tick: 120, Soldier: "R0", Team: "red", Category: "vision", Key: "contact_new", Value: "B1 at 300px"}},
+	}}
+}
+
+func TestWriteReport_JSONAndNDJSON(t *testing.T) {
+	all := exportFixture()
+	rep := reportRecord{SchemaVersion: exportSchemaVersion, Scenario: "fixture", RunCount: 1, Summary: newSummaryRecord(all)}
+
+	var buf strings.Builder
+	if err := writeReport(formatJSON, "", &buf, rep, all); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+		Runs          []struct {
+			Outcome struct{ Outcome string } `json:"outcome"`
+			Grades  []struct{ Team string }  `json:"grades"`
+			Window  struct {
+				Red struct {
+					GoalPct map[string]float64 `json:"goal_pct"`
+				} `json:"red"`
+			} `json:"window"`
+			Log []struct{ Key string } `json:"log"`
+		} `json:"runs"`
+		Summary struct {
+			RedWinRate *struct{ Est float64 } `json:"red_win_rate"`
+			Casualty   *struct{ Est float64 } `json:"casualty_ratio"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &doc); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	r := doc.Runs[0]
+	if doc.SchemaVersion != exportSchemaVersion || r.Outcome.Outcome != "red_victory" || r.Grades[0].Team != "red" ||
+		r.Window.Red.GoalPct["engage"] != 40 || r.Log[0].Key != "contact_new" {
+		t.Fatalf("unexpected json document: %+v", doc)
+	}
+	if doc.Summary.RedWinRate == nil || doc.Summary.RedWinRate.Est != 1 || doc.Summary.Casualty == nil || doc.Summary.Casualty.Est != 0 {
+		t.Fatalf("summary should carry the intervals: %+v", doc.Summary)
+	}
+
+	buf.Reset()
+	if err := writeReport(formatNDJSON, "", &buf, rep, all); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad ndjson line %q: %v", line, err)
+		}
+		types = append(types, rec.Type)
+	}
+	if got := strings.Join(types, ","); got != "header,run,grade,window,log,summary" {
+		t.Fatalf("ndjson line types %s", got)
+	}
+}
+
+func TestWriteReport_CSVTables(t *testing.T) {
+	if err := writeReport(formatCSV, "", nil, reportRecord{}, nil); err == nil {
+		t.Fatal("csv without -out should be refused")
+	}
+	dir := t.TempDir()
+	all := exportFixture()
+	if err := writeReport(formatCSV, dir, nil, reportRecord{Summary: newSummaryRecord(all)}, all); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"runs": 1, "grades": 1, "windows": 2, "log": 1, "summary": 6}
+	for name, rows := range want {
+		f, err := os.Open(filepath.Join(dir, name+".csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs, err := csv.NewReader(f).ReadAll() // also checks every row matches the header width
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s.csv: %v", name, err)
+		}
+		if len(recs)-1 != rows {
+			t.Fatalf("%s.csv has %d rows, want %d", name, len(recs)-1, rows)
+		}
+		if name == "runs" {
+			col := map[string]string{}
+			for i, h := range recs[0] {
+				col[h] = recs[1][i]
+			}
+			if col["blue_killed"] != "1" || col["blue_evacuated"] != "0" || col["red_killed"] != "0" ||
+				col["red_rounds_stopped"] != "3" || col["blue_rounds_stopped"] != "0" {
+				t.Fatalf("runs.csv casualty columns: %v", col)
+			}
+		}
+	}
+}
+
+func TestSweepFlag_ParsesListsRangesAndGrid(t *testing.T) {
+	var f sweepFlag
+	if err := f.Set("hit_stress=0.1:0.3:0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("accurate_fire_range=400,500"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f[0].values; len(got) != 3 || math.Abs(got[2]-0.3) > 1e-9 {
+		t.Fatalf("range should give 0.1, 0.2, 0.3, got %v", got)
+	}
+	for _, bad := range []string{"hit_stress=0.2", "no_such_param=1", "hit_stress=2", "cover_fear=0.5:0.1:0.1", "cover_fear"} {
+		if err := f.Set(bad); err == nil {
+			t.Fatalf("%q should be rejected", bad)
+		}
+	}
+
+	grid := f.grid()
+	if len(grid) != 6 || grid[0]["hit_stress"] != 0.1 || grid[1]["accurate_fire_range"] != 500 || grid[5]["accurate_fire_range"] != 500 {
+		t.Fatalf("unexpected grid %v", grid)
+	}
+
+	sc := &game.ScenarioFile{Config: map[string]float64{"cover_fear": 0.5}}
+	pt := grid[3].withConfig(sc)
+	if len(sc.Config) != 1 || pt.Config["cover_fear"] != 0.5 || pt.Config["hit_stress"] != grid[3]["hit_stress"] {
+		t.Fatalf("point config %v, original %v", pt.Config, sc.Config)
+	}
+	cfg, err := pt.SimConfig()
+	if err != nil || cfg.AccurateFireRange != grid[3]["accurate_fire_range"] || cfg.Thresholds.CoverFear != 0.5 {
+		t.Fatalf("scenario config not applied: %+v, %v", cfg, err)
+	}
+	if err := f.check(sc); err != nil {
+		t.Fatalf("valid grid rejected: %v", err)
+	}
+}
+
+func TestSweepFlag_CheckRejectsInvalidGridPoints(t *testing.T) {
+	var f sweepFlag
+	if err := f.Set("radio_garble_threshold=0.2,0.6"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("radio_drop_threshold=0.1,0.4"); err != nil {
+		t.Fatal(err)
+	}
+	// Each value is in range alone; garble 0.2 with drop 0.4 is not.
+	err := f.check(&game.ScenarioFile{})
+	if err == nil || !strings.Contains(err.Error(), "radio_garble_threshold=0.2 radio_drop_threshold=0.4") {
+		t.Fatalf("expected the inverted point to be reported, got %v", err)
+	}
+}
+
+func TestWriteSweepCSV_OneRowPerPoint(t *testing.T) {
+	var f sweepFlag
+	if err := f.Set("hit_stress=0.1,0.2"); err != nil {
+		t.Fatal(err)
+	}
+	results := []sweepResult{{point: sweepPoint{"hit_stress": 0.1}, runs: exportFixture()}, {point: sweepPoint{"hit_stress": 0.2}}}
+	var buf strings.Builder
+	if err := writeSweepCSV(&buf, f, results); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || recs[0][0] != "hit_stress" || recs[1][0] != "0.1" || recs[1][2] != "1" {
+		t.Fatalf("unexpected sweep table %v", recs)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// builtinScenarios holds the scenario library shipped with the tool.
+// Add a JSON file under scenarios/ to make it selectable with -scenario.
+//
+//go:embed scenarios/*.json
+var builtinScenarios embed.FS
+
+// builtinScenarioNames lists the embedded scenario names (file stems).
+func builtinScenarioNames() []string {
+	entries, err := builtinScenarios.ReadDir("scenarios")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadScenario resolves the scenario to run. A -scenario-file path wins over
+// the built-in -scenario name.
+func loadScenario(name, file string) (*game.ScenarioFile, error) {
+	if file != "" {
+		return game.LoadScenarioFile(file)
+	}
+	data, err := builtinScenarios.ReadFile(path.Join("scenarios", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported scenario %q (supported: %s)", name, strings.Join(builtinScenarioNames(), ", "))
+	}
+	sc, err := game.ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("built-in scenario %s: %w", name, err)
+	}
+	return sc, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+const (
+	ciZ              = 1.96 // two-sided 95%
+	bootstrapSamples = 2000
+	bootstrapSeed    = 1 // fixed so a report is reproducible
+)
+
+// interval is an estimate with a 95% confidence interval. n is the number of
+// runs the estimate is drawn from; n == 0 means there was nothing to measure.
+type interval struct {
+	est, lo, hi float64
+	n           int
+}
+
+func (iv interval) String() string {
+	if iv.n == 0 || math.IsNaN(iv.est) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.3f [%.3f, %.3f] (n=%d)", iv.est, iv.lo, iv.hi, iv.n)
+}
+
+// pctString prints a proportion interval as percentages.
+func (iv interval) pctString() string {
+	if iv.n == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%% [%.1f%%, %.1f%%] (n=%d)", iv.est*100, iv.lo*100, iv.hi*100, iv.n)
+}
+
+// proportionCI is the Wilson score interval for k successes in n runs. It
+// stays inside [0, 1] and behaves at 0/n and n/n, where the normal
+// approximation collapses to a zero-width interval.
+func proportionCI(k, n int) interval {
+	if n <= 0 {
+		return interval{}
+	}
+	p := float64(k) / float64(n)
+	nf := float64(n)
+	z2 := ciZ * ciZ
+	denom := 1 + z2/nf
+	centre := (p + z2/(2*nf)) / denom
+	half := ciZ * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf)) / denom
+	iv := interval{est: p, lo: math.Max(0, centre-half), hi: math.Min(1, centre+half), n: n}
+	// The bounds are exact at the ends; don't let rounding leave 1e-17.
+	if k == 0 {
+		iv.lo = 0
+	}
+	if k == n {
+		iv.hi = 1
+	}
+	return iv
+}
+
+// meanCI is the normal-approximation interval on the mean of vals.
+func meanCI(vals []float64) interval {
+	n := len(vals)
+	if n == 0 {
+		return interval{}
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	mean := sum / float64(n)
+	if n < 2 {
+		return interval{est: mean, lo: mean, hi: mean, n: n}
+	}
+	var ss float64
+	for _, v := range vals {
+		ss += (v - mean) * (v - mean)
+	}
+	se := math.Sqrt(ss/float64(n-1)) / math.Sqrt(float64(n))
+	return interval{est: mean, lo: mean - ciZ*se, hi: mean + ciZ*se, n: n}
+}
+
+// ratioCI estimates sum(num)/sum(den) with a percentile bootstrap over runs.
+// Pooling the sums keeps runs where one side lost nobody from blowing the
+// ratio up. est is NaN when no run had a denominator.
+func ratioCI(num, den []float64) interval {
+	n := len(num)
+	ratio := func(idx func(int) int) float64 {
+		var a, b float64
+		for i := 0; i < n; i++ {
+			j := idx(i)
+			a += num[j]
+			b += den[j]
+		}
+		if b == 0 {
+			return math.NaN()
+		}
+		return a / b
+	}
+	est := ratio(func(i int) int { return i })
+	if n == 0 || math.IsNaN(est) {
+		return interval{est: math.NaN(), n: n}
+	}
+	rng := rand.New(rand.NewSource(bootstrapSeed)) // #nosec G404 -- reproducible resampling
+	samples := make([]float64, 0, bootstrapSamples)
+	for s := 0; s < bootstrapSamples; s++ {
+		if r := ratio(func(int) int { return rng.Intn(n) }); !math.IsNaN(r) {
+			samples = append(samples, r)
+		}
+	}
+	if len(samples) == 0 {
+		return interval{est: est, lo: est, hi: est, n: n}
+	}
+	sort.Float64s(samples)
+	at := func(q float64) float64 { return samples[int(q*float64(len(samples)-1))] }
+	return interval{est: est, lo: at(0.025), hi: at(0.975), n: n}
+}
+
+// batchSummary holds the headline numbers of a batch with their intervals.
+type batchSummary struct {
+	redWin, blueWin, draw, stalemate interval
+	casualtyRatio                    interval // red losses per blue loss
+	firstContact                     interval // ticks, over runs that made contact
+}
+
+func summarizeBatch(all []runStats) batchSummary {
+	var red, blue, draws, stalemates int
+	redLost := make([]float64, 0, len(all))
+	blueLost := make([]float64, 0, len(all))
+	var contact []float64
+	for _, rs := range all {
+		switch rs.outcome {
+		case game.OutcomeRedVictory:
+			red++
+		case game.OutcomeBlueVictory:
+			blue++
+		case game.OutcomeDraw:
+			draws++
+		}
+		if rs.stalemate {
+			stalemates++
+		}
+		redLost = append(redLost, float64(rs.redTotal-rs.redSurvivors))
+		blueLost = append(blueLost, float64(rs.blueTotal-rs.blueSurvivors))
+		if rs.firstContactTick >= 0 {
+			contact = append(contact, float64(rs.firstContactTick))
+		}
+	}
+	n := len(all)
+	return batchSummary{
+		redWin:        proportionCI(red, n),
+		blueWin:       proportionCI(blue, n),
+		draw:          proportionCI(draws, n),
+		stalemate:     proportionCI(stalemates, n),
+		casualtyRatio: ratioCI(redLost, blueLost),
+		firstContact:  meanCI(contact),
+	}
+}
+
+func printConfidence(all []runStats) {
+	s := summarizeBatch(all)
+	fmt.Println("\n=== Aggregate Statistics (95% CI) ===")
+	fmt.Printf("red_win_rate=%s\n", s.redWin.pctString())
+	fmt.Printf("blue_win_rate=%s\n", s.blueWin.pctString())
+	fmt.Printf("draw_rate=%s\n", s.draw.pctString())
+	fmt.Printf("stalemate_rate=%s\n", s.stalemate.pctString())
+	fmt.Printf("casualty_ratio=%s (red losses per blue loss)\n", s.casualtyRatio)
+	fmt.Printf("first_contact_ticks=%s\n", s.firstContact)
+}
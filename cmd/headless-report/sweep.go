@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// sweepAxis is one -sweep flag: a SimConfig parameter and the values to try.
+type sweepAxis struct {
+	name   string
+	values []float64
+}
+
+// sweepFlag collects repeated -sweep flags. Each is name=v1,v2,... or
+// name=start:stop:step; several flags form a full grid.
+type sweepFlag []sweepAxis
+
+func (f *sweepFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, a := range *f {
+		parts[i] = fmt.Sprintf("%s=%v", a.name, a.values)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *sweepFlag) Set(v string) error {
+	a, err := parseSweepAxis(v)
+	if err != nil {
+		return err
+	}
+	for _, prev := range *f {
+		if prev.name == a.name {
+			return fmt.Errorf("%s swept twice", a.name)
+		}
+	}
+	*f = append(*f, a)
+	return nil
+}
+
+func parseSweepAxis(v string) (sweepAxis, error) {
+	name, spec, ok := strings.Cut(v, "=")
+	if !ok || name == "" || spec == "" {
+		return sweepAxis{}, fmt.Errorf("want name=v1,v2,... or name=start:stop:step, got %q", v)
+	}
+	a := sweepAxis{name: name}
+	if parts := strings.Split(spec, ":"); len(parts) == 3 {
+		var lim [3]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return sweepAxis{}, fmt.Errorf("%s: %w", name, err)
+			}
+			lim[i] = f
+		}
+		start, stop, step := lim[0], lim[1], lim[2]
+		if step <= 0 || stop < start {
+			return sweepAxis{}, fmt.Errorf("%s: range %s needs start <= stop and step > 0", name, spec)
+		}
+		// Count steps rather than accumulate, so 0.1:0.3:0.1 ends on 0.3.
+		n := int(math.Floor((stop-start)/step+1e-9)) + 1
+		for i := 0; i < n; i++ {
+			a.values = append(a.values, start+float64(i)*step)
+		}
+	} else {
+		for _, p := range strings.Split(spec, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return sweepAxis{}, fmt.Errorf("%s: %w", name, err)
+			}
+			a.values = append(a.values, f)
+		}
+	}
+	probe := game.DefaultSimConfig()
+	for _, val := range a.values {
+		if err := probe.Set(name, val); err != nil {
+			return sweepAxis{}, err
+		}
+	}
+	return a, nil
+}
+
+// sweepPoint is one cell of the grid.
+type sweepPoint map[string]float64
+
+// grid expands the axes into every combination, first axis outermost.
+func (f sweepFlag) grid() []sweepPoint {
+	points := []sweepPoint{{}}
+	for _, a := range f {
+		var next []sweepPoint
+		for _, p := range points {
+			for _, v := range a.values {
+				q := make(sweepPoint, len(p)+1)
+				for k, pv := range p {
+					q[k] = pv
+				}
+				q[a.name] = v
+				next = append(next, q)
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// withConfig returns a copy of sc whose config overrides are sc's plus p.
+// The original scenario is left untouched.
+func (p sweepPoint) withConfig(sc *game.ScenarioFile) *game.ScenarioFile {
+	cp := *sc
+	cp.Config = make(map[string]float64, len(sc.Config)+len(p))
+	for k, v := range sc.Config {
+		cp.Config[k] = v
+	}
+	for k, v := range p {
+		cp.Config[k] = v
+	}
+	return &cp
+}
+
+// check applies every grid point's config to sc, so a combination the
+// config rejects as a whole (e.g. a garble threshold below the drop
+// threshold) is reported before any point runs.
+func (f sweepFlag) check(sc *game.ScenarioFile) error {
+	for _, p := range f.grid() {
+		if _, err := p.withConfig(sc).SimConfig(); err != nil {
+			return fmt.Errorf("%s: %w", p.label(f), err)
+		}
+	}
+	return nil
+}
+
+// sweepResult is one grid point's batch, summarised.
+type sweepResult struct {
+	point sweepPoint
+	runs  []runStats
+}
+
+// runSweep runs the batch of jobs at every grid point.
+func runSweep(axes sweepFlag, jobs []runJob, workers, maxTicks int, sc *game.ScenarioFile, progress func(point, points int)) []sweepResult {
+	grid := axes.grid()
+	out := make([]sweepResult, 0, len(grid))
+	for i, p := range grid {
+		out = append(out, sweepResult{point: p, runs: runBatch(jobs, workers, maxTicks, p.withConfig(sc), nil)})
+		if progress != nil {
+			progress(i+1, len(grid))
+		}
+	}
+	return out
+}
+
+func (p sweepPoint) label(axes sweepFlag) string {
+	parts := make([]string, len(axes))
+	for i, a := range axes {
+		parts[i] = fmt.Sprintf("%s=%g", a.name, p[a.name])
+	}
+	return strings.Join(parts, " ")
+}
+
+func printSweep(axes sweepFlag, results []sweepResult) {
+	fmt.Println("=== Parameter Sweep (95% CI) ===")
+	for _, r := range results {
+		s := summarizeBatch(r.runs)
+		fmt.Printf("%s runs=%d\n", r.point.label(axes), len(r.runs))
+		fmt.Printf("  red_win_rate=%s blue_win_rate=%s\n", s.redWin.pctString(), s.blueWin.pctString())
+		fmt.Printf("  draw_rate=%s stalemate_rate=%s\n", s.draw.pctString(), s.stalemate.pctString())
+		fmt.Printf("  casualty_ratio=%s first_contact_ticks=%s\n", s.casualtyRatio, s.firstContact)
+	}
+}
+
+// sweepRecord is one grid point in json/ndjson output.
+type sweepRecord struct {
+	Params  map[string]float64 `json:"params"`
+	Runs    int                `json:"runs"`
+	Summary *summaryRecord     `json:"summary"`
+}
+
+// writeSweep writes the sweep in format. csv is a single table, so unlike
+// the per-run report it can go to stdout.
+func writeSweep(format, outDir string, w io.Writer, rep reportRecord, axes sweepFlag, results []sweepResult) error {
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o750); err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(outDir, "sweep."+format), func(f io.Writer) error {
+			return writeSweep(format, "", f, rep, axes, results)
+		})
+	}
+	if format == formatCSV {
+		return writeSweepCSV(w, axes, results)
+	}
+	recs := make([]sweepRecord, len(results))
+	for i, r := range results {
+		recs[i] = sweepRecord{Params: r.point, Runs: len(r.runs), Summary: newSummaryRecord(r.runs)}
+	}
+	enc := json.NewEncoder(w)
+	switch format {
+	case formatJSON:
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			reportRecord
+			Points []sweepRecord `json:"points"`
+		}{rep, recs})
+	case formatNDJSON:
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			reportRecord
+		}{"header", rep}); err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := enc.Encode(struct {
+				Type string `json:"type"`
+				sweepRecord
+			}{"point", r}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// writeSweepCSV writes one row per grid point: the swept parameters, then
+// est/lo/hi for each summary metric.
+func writeSweepCSV(w io.Writer, axes sweepFlag, results []sweepResult) error {
+	metrics := []string{"red_win_rate", "blue_win_rate", "draw_rate", "stalemate_rate", "casualty_ratio", "first_contact_ticks"}
+	t := &csvTable{}
+	for _, a := range axes {
+		t.header = append(t.header, a.name)
+	}
+	t.header = append(t.header, "runs")
+	for _, m := range metrics {
+		t.header = append(t.header, m, m+"_lo", m+"_hi")
+	}
+	for _, r := range results {
+		row := make([]string, 0, len(t.header))
+		for _, a := range axes {
+			row = append(row, ftoa(r.point[a.name]))
+		}
+		row = append(row, itoa(len(r.runs)))
+		s := newSummaryRecord(r.runs)
+		for _, iv := range []*intervalRecord{s.RedWinRate, s.BlueWinRate, s.DrawRate, s.StalemateRate, s.CasualtyRatio, s.FirstContactTicks} {
+			if iv == nil {
+				row = append(row, "", "", "")
+				continue
+			}
+			row = append(row, ftoa(iv.Est), ftoa(iv.Lo), ftoa(iv.Hi))
+		}
+		t.add(row...)
+	}
+	return t.write(w)
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Garsondee/Soldier-Sense/internal/game"
+)
+
+// exportSchemaVersion is bumped whenever a field in the machine-readable
+// output is renamed, removed or changes meaning. Adding fields does not bump it.
+const exportSchemaVersion = 1
+
+// Output formats for -format. Anything but text runs the tests headlessly.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+var exportFormats = []string{formatText, formatJSON, formatNDJSON, formatCSV}
+
+// checkFormat rejects a -format/-out pair before any test is run, so a typo
+// does not cost a full headless pass.
+func checkFormat(format, outDir string) error {
+	switch format {
+	case formatText, formatJSON, formatNDJSON:
+		return nil
+	case formatCSV:
+		if outDir == "" {
+			return fmt.Errorf("-format csv writes several tables and needs -out DIR")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown -format %q (want %s)", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// observationRecord mirrors game.LaboratoryObservation plus the verdict.
+type observationRecord struct {
+	Test   string `json:"test"`
+	Ticks  int    `json:"ticks"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason"`
+
+	// First-occurrence ticks; -1 when it never happened.
+	FirstContactTick      int `json:"first_contact_tick"`
+	FirstFearIncreaseTick int `json:"first_fear_increase_tick"`
+	FirstGoalChangeTick   int `json:"first_goal_change_tick"`
+	FirstStanceChangeTick int `json:"first_stance_change_tick"`
+	FirstPanicTick        int `json:"first_panic_tick"`
+	FirstDisobeyTick      int `json:"first_disobey_tick"`
+	CohesionBreakTick     int `json:"cohesion_break_tick"`
+
+	MaxFear              float64 `json:"max_fear"`
+	MaxFearTick          int     `json:"max_fear_tick"`
+	FinalFear            float64 `json:"final_fear"`
+	GoalChanges          int     `json:"goal_changes"`
+	StanceChanges        int     `json:"stance_changes"`
+	FormationSpreadMax   float64 `json:"formation_spread_max"`
+	FormationSpreadFinal float64 `json:"formation_spread_final"`
+
+	Metrics map[string]float64 `json:"metrics"`
+	Flags   map[string]bool    `json:"flags"`
+	Texts   map[string]string  `json:"texts"`
+
+	Events    []eventRecord    `json:"events,omitempty"`
+	Snapshots []snapshotRecord `json:"snapshots,omitempty"`
+}
+
+// eventRecord mirrors game.ObservationEvent.
+type eventRecord struct {
+	Tick        int     `json:"tick"`
+	SoldierID   int     `json:"soldier_id"`
+	Soldier     string  `json:"soldier"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"`
+}
+
+// snapshotRecord is one soldier in a game.SimSnapshot.
+type snapshotRecord struct {
+	Tick      int     `json:"tick"`
+	SoldierID int     `json:"soldier_id"`
+	Soldier   string  `json:"soldier"`
+	Team      string  `json:"team"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	State     string  `json:"state"`
+	Goal      string  `json:"goal"`
+	Fear      float64 `json:"fear"`
+}
+
+// runHeadless runs a test without a window and records its verdict.
+func runHeadless(test *game.LaboratoryTest) observationRecord {
+	obs := game.RunLaboratoryTest(test)
+	r := observationRecord{
+		Test:                  obs.TestName,
+		Ticks:                 obs.Ticks,
+		FirstContactTick:      obs.FirstContactTick,
+		FirstFearIncreaseTick: obs.FirstFearIncreaseTick,
+		FirstGoalChangeTick:   obs.FirstGoalChangeTick,
+		FirstStanceChangeTick: obs.FirstStanceChangeTick,
+		FirstPanicTick:        obs.FirstPanicTick,
+		FirstDisobeyTick:      obs.FirstDisobeyTick,
+		CohesionBreakTick:     obs.CohesionBreakTick,
+		MaxFear:               obs.MaxFear,
+		MaxFearTick:           obs.MaxFearTick,
+		FinalFear:             obs.FinalFear,
+		GoalChanges:           obs.GoalChanges,
+		StanceChanges:         obs.StanceChanges,
+		FormationSpreadMax:    obs.FormationSpreadMax,
+		FormationSpreadFinal:  obs.FormationSpreadFinal,
+		Metrics:               obs.Metrics,
+		Flags:                 obs.Flags,
+		Texts:                 obs.Texts,
+	}
+	if test.Validate != nil {
+		r.Passed, r.Reason = test.Validate(obs)
+	}
+	for _, e := range obs.Events {
+		r.Events = append(r.Events, eventRecord{
+			Tick:        e.Tick,
+			SoldierID:   e.SoldierID,
+			Soldier:     e.SoldierLabel,
+			Type:        e.EventType,
+			Description: e.Description,
+			Value:       e.Value,
+		})
+	}
+	for _, snap := range obs.Snapshots {
+		for _, s := range snap.Soldiers {
+			team := "red"
+			if s.Team == game.TeamBlue {
+				team = "blue"
+			}
+			r.Snapshots = append(r.Snapshots, snapshotRecord{
+				Tick:      snap.Tick,
+				SoldierID: s.ID,
+				Soldier:   s.Label,
+				Team:      team,
+				X:         s.X,
+				Y:         s.Y,
+				State:     s.State.String(),
+				Goal:      s.Goal.String(),
+				Fear:      s.Fear,
+			})
+		}
+	}
+	return r
+}
+
+// writeObservations writes the records in format: json and ndjson go to w
+// (or to a file in outDir), csv writes observations, events and snapshots
+// tables into outDir.
+func writeObservations(format, outDir string, w io.Writer, recs []observationRecord) error {
+	if format == formatCSV {
+		if outDir == "" {
+			return fmt.Errorf("-format csv writes several tables and needs -out DIR")
+		}
+		return writeCSVTables(outDir, recs)
+	}
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o750); err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(outDir, "laboratory."+format), func(f io.Writer) error {
+			return writeObservations(format, "", f, recs)
+		})
+	}
+	enc := json.NewEncoder(w)
+	switch format {
+	case formatJSON:
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			SchemaVersion int                 `json:"schema_version"`
+			Tests         []observationRecord `json:"tests"`
+		}{exportSchemaVersion, recs})
+	case formatNDJSON:
+		// One line per test, with its events and snapshots inline.
+		for _, r := range recs {
+			if err := enc.Encode(struct {
+				SchemaVersion int `json:"schema_version"`
+				observationRecord
+			}{exportSchemaVersion, r}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// writeCSVTables flattens custom metrics, flags and texts into
+// metric_/flag_/text_ columns over the union of keys, sorted, so every row
+// of observations.csv has the same columns.
+func writeCSVTables(dir string, recs []observationRecord) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	metricKeys, flagKeys, textKeys := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, r := range recs {
+		for k := range r.Metrics {
+			metricKeys[k] = true
+		}
+		for k := range r.Flags {
+			flagKeys[k] = true
+		}
+		for k := range r.Texts {
+			textKeys[k] = true
+		}
+	}
+	sorted := func(m map[string]bool) []string {
+		out := make([]string, 0, len(m))
+		for k := range m {
+			out = append(out, k)
+		}
+		sort.Strings(out)
+		return out
+	}
+	metrics, flags, texts := sorted(metricKeys), sorted(flagKeys), sorted(textKeys)
+
+	itoa := strconv.Itoa
+	ftoa := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+
+	obsHeader := []string{
+		"test", "ticks", "passed", "reason",
+		"first_contact_tick", "first_fear_increase_tick", "first_goal_change_tick",
+		"first_stance_change_tick", "first_panic_tick", "first_disobey_tick", "cohesion_break_tick",
+		"max_fear", "max_fear_tick", "final_fear", "goal_changes", "stance_changes",
+		"formation_spread_max", "formation_spread_final",
+	}
+	for _, k := range metrics {
+		obsHeader = append(obsHeader, "metric_"+k)
+	}
+	for _, k := range flags {
+		obsHeader = append(obsHeader, "flag_"+k)
+	}
+	for _, k := range texts {
+		obsHeader = append(obsHeader, "text_"+k)
+	}
+	obsRows := [][]string{obsHeader}
+	eventRows := [][]string{{"test", "tick", "soldier_id", "soldier", "type", "description", "value"}}
+	snapRows := [][]string{{"test", "tick", "soldier_id", "soldier", "team", "x", "y", "state", "goal", "fear"}}
+	for _, r := range recs {
+		row := []string{
+			r.Test, itoa(r.Ticks), strconv.FormatBool(r.Passed), r.Reason,
+			itoa(r.FirstContactTick), itoa(r.FirstFearIncreaseTick), itoa(r.FirstGoalChangeTick),
+			itoa(r.FirstStanceChangeTick), itoa(r.FirstPanicTick), itoa(r.FirstDisobeyTick), itoa(r.CohesionBreakTick),
+			ftoa(r.MaxFear), itoa(r.MaxFearTick), ftoa(r.FinalFear), itoa(r.GoalChanges), itoa(r.StanceChanges),
+			ftoa(r.FormationSpreadMax), ftoa(r.FormationSpreadFinal),
+		}
+		for _, k := range metrics {
+			v, ok := r.Metrics[k]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, ftoa(v))
+		}
+		for _, k := range flags {
+			v, ok := r.Flags[k]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatBool(v))
+		}
+		for _, k := range texts {
+			row = append(row, r.Texts[k])
+		}
+		obsRows = append(obsRows, row)
+		for _, e := range r.Events {
+			eventRows = append(eventRows, []string{r.Test, itoa(e.Tick), itoa(e.SoldierID), e.Soldier, e.Type, e.Description, ftoa(e.Value)})
+		}
+		for _, s := range r.Snapshots {
+			snapRows = append(snapRows, []string{r.Test, itoa(s.Tick), itoa(s.SoldierID), s.Soldier, s.Team,
+				ftoa(s.X), ftoa(s.Y), s.State, s.Goal, ftoa(s.Fear)})
+		}
+	}
+	for name, rows := range map[string][][]string{"observations": obsRows, "events": eventRows, "snapshots": snapRows} {
+		if err := writeCSVFile(filepath.Join(dir, name+".csv"), rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(path string, rows [][]string) error {
+	return writeFile(path, func(w io.Writer) error {
+		return csv.NewWriter(w).WriteAll(rows)
+	})
+}
+
+// writeFile creates path and fills it with write. A failed Close is
+// reported too: it can be the only sign the data never reached the disk.
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := os.Create(path) // #nosec G304 -- user-chosen output path
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return write(f)
+}
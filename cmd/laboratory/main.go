@@ -13,13 +13,39 @@ import (
 func main() {
 	var listTests bool
 	var testName string
+	var format, outDir string
 
 	flag.BoolVar(&listTests, "list", false, "List all available laboratory tests")
 	flag.StringVar(&testName, "test", "", "Run a specific laboratory test by name")
+	flag.StringVar(&format, "format", formatText, "text opens the visual runner; json, ndjson or csv run headlessly (all tests unless -test is set) and write observations")
+	flag.StringVar(&outDir, "out", "", "write json/ndjson/csv output into this directory instead of stdout (required for csv)")
 	flag.Parse()
 
+	if err := checkFormat(format, outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	tests := game.GetAllLaboratoryTests()
 
+	if format != formatText {
+		var recs []observationRecord
+		for _, test := range tests {
+			if testName == "" || test.Name == testName {
+				recs = append(recs, runHeadless(test))
+			}
+		}
+		if len(recs) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: Test '%s' not found\n", testName)
+			os.Exit(1)
+		}
+		if err := writeObservations(format, outDir, os.Stdout, recs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if listTests {
 		fmt.Println("Available Laboratory Tests:")
 		fmt.Println()
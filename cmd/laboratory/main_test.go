@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func observationFixture() []observationRecord {
+	return []observationRecord{
+		{
+			Test: "fear", Ticks: 600, Passed: true, Reason: "fear rose",
+			FirstContactTick: 40, FirstPanicTick: -1, CohesionBreakTick: -1,
+			MaxFear: 0.7, MaxFearTick: 300,
+			Metrics: map[string]float64{"casualty_rate": 0.25},
+			Flags:   map[string]bool{"broke": false},
+			Events:  []eventRecord{{Tick: 40, SoldierID: 1, Soldier: "R1", Type: "contact", Value: 1}},
+			Snapshots: []snapshotRecord{
+				{Tick: 60, SoldierID: 1, Soldier: "R1", Team: "red", X: 10, Y: 20, State: "idle", Goal: "advance"},
+				{Tick: 60, SoldierID: 2, Soldier: "B1", Team: "blue", X: 90, Y: 20, State: "cover", Goal: "engage"},
+			},
+		},
+		{
+			Test: "cohesion", Ticks: 900, Reason: "held together",
+			FirstContactTick: -1, FirstPanicTick: -1, CohesionBreakTick: -1,
+			Metrics: map[string]float64{"cohesion": 0.8},
+			Texts:   map[string]string{"note": "quiet"},
+		},
+	}
+}
+
+func TestCheckFormat_RejectsUnknownAndCSVWithoutOut(t *testing.T) {
+	for _, f := range []string{formatText, formatJSON, formatNDJSON} {
+		if err := checkFormat(f, ""); err != nil {
+			t.Errorf("-format %s: %v", f, err)
+		}
+	}
+	if err := checkFormat(formatCSV, t.TempDir()); err != nil {
+		t.Errorf("-format csv with -out: %v", err)
+	}
+	if err := checkFormat(formatCSV, ""); err == nil {
+		t.Error("csv without -out should be refused")
+	}
+	if err := checkFormat("yaml", ""); err == nil || !strings.Contains(err.Error(), "ndjson") {
+		t.Errorf("unknown format error %v should list the formats", err)
+	}
+}
+
+func TestWriteObservations_JSONAndNDJSON(t *testing.T) {
+	recs := observationFixture()
+
+	var buf strings.Builder
+	if err := writeObservations(formatJSON, "", &buf, recs); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+		Tests         []struct {
+			Test      string             `json:"test"`
+			Passed    bool               `json:"passed"`
+			Metrics   map[string]float64 `json:"metrics"`
+			Snapshots []snapshotRecord   `json:"snapshots"`
+		} `json:"tests"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &doc); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	if doc.SchemaVersion != exportSchemaVersion || len(doc.Tests) != 2 || doc.Tests[0].Test != "fear" ||
+		!doc.Tests[0].Passed || doc.Tests[0].Metrics["casualty_rate"] != 0.25 || len(doc.Tests[0].Snapshots) != 2 {
+		t.Fatalf("unexpected json document: %+v", doc)
+	}
+
+	buf.Reset()
+	if err := writeObservations(formatNDJSON, "", &buf, recs); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			SchemaVersion int    `json:"schema_version"`
+			Test          string `json:"test"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad ndjson line %q: %v", line, err)
+		}
+		if rec.SchemaVersion != exportSchemaVersion {
+			t.Fatalf("ndjson line %q has no schema version", line)
+		}
+		names = append(names, rec.Test)
+	}
+	if got := strings.Join(names, ","); got != "fear,cohesion" {
+		t.Fatalf("ndjson tests %s", got)
+	}
+}
+
+func TestWriteObservations_IntoOutDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeObservations(formatJSON, dir, nil, observationFixture()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "laboratory.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("laboratory.json is not valid json: %s", data)
+	}
+}
+
+func TestWriteObservations_CSVTables(t *testing.T) {
+	if err := writeObservations(formatCSV, "", nil, observationFixture()); err == nil {
+		t.Fatal("csv without -out should be refused")
+	}
+	dir := t.TempDir()
+	if err := writeObservations(formatCSV, dir, nil, observationFixture()); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"observations": 2, "events": 1, "snapshots": 2}
+	for name, rows := range want {
+		f, err := os.Open(filepath.Join(dir, name+".csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs, err := csv.NewReader(f).ReadAll() // also checks every row matches the header width
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s.csv: %v", name, err)
+		}
+		if len(recs)-1 != rows {
+			t.Fatalf("%s.csv has %d rows, want %d", name, len(recs)-1, rows)
+		}
+		if name != "observations" {
+			continue
+		}
+		// Keys are the union over all tests; a test without one leaves it blank.
+		col := map[string]int{}
+		for i, h := range recs[0] {
+			col[h] = i
+		}
+		for _, h := range []string{"metric_casualty_rate", "metric_cohesion", "flag_broke", "text_note"} {
+			if _, ok := col[h]; !ok {
+				t.Fatalf("observations.csv has no %s column: %v", h, recs[0])
+			}
+		}
+		if recs[1][col["metric_casualty_rate"]] != "0.25" || recs[2][col["metric_casualty_rate"]] != "" ||
+			recs[2][col["text_note"]] != "quiet" || recs[1][col["flag_broke"]] != "false" {
+			t.Fatalf("observations.csv rows: %v", recs[1:])
+		}
+	}
+}
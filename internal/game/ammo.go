@@ -0,0 +1,192 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// --- Ammunition constants ---
+
+const (
+	defaultSpareMagazines = 6 // rifle magazines carried besides the loaded one (7 x 30 = 210 rounds)
+
+	ammoLowFraction      = 0.30 // carried rounds / full load below which a soldier starts conserving
+	ammoCriticalFraction = 0.12 // below this only single shots outside point-blank range
+
+	radioAmmoReportCooldown = 240 // ticks between "ammo low" calls from one soldier
+
+	ammoShareRange    = 90.0 // px, how close a donor must be to pass a magazine
+	ammoShareInterval = 90   // ticks between magazine hand-overs within a squad
+	ammoShareKeep     = 2    // spare magazines a donor always keeps for themselves
+)
+
+// fullAmmoLoad returns the rounds a soldier starts with.
+func (s *Soldier) fullAmmoLoad() int {
+	return s.magCapacity * (1 + s.firearm().SpareMags)
+}
+
+// roundsCarried returns the rounds in the loaded magazine plus full spares.
+func (s *Soldier) roundsCarried() int {
+	return s.magRounds + s.spareMags*s.magCapacity
+}
+
+// ammoFraction returns carried rounds as a fraction of a full load.
+func (s *Soldier) ammoFraction() float64 {
+	full := s.fullAmmoLoad()
+	if full <= 0 {
+		return 0
+	}
+	return clamp01(float64(s.roundsCarried()) / float64(full))
+}
+
+// updateAmmoState publishes the ammunition picture to the blackboard.
+func (s *Soldier) updateAmmoState() {
+	bb := &s.blackboard
+	bb.AmmoFraction = s.ammoFraction()
+	bb.OutOfAmmo = s.magRounds <= 0 && s.spareMags <= 0 && s.reloadTimer == 0
+}
+
+// ammoEngagePenalty lowers the urge to engage as ammunition runs out. A dry
+// soldier has no reason to pick GoalEngage at all.
+func ammoEngagePenalty(bb *Blackboard) float64 {
+	if bb.OutOfAmmo {
+		return 1.0
+	}
+	if bb.AmmoFraction < ammoLowFraction {
+		return (ammoLowFraction - bb.AmmoFraction) * 0.9
+	}
+	return 0
+}
+
+// conserveAmmo caps the fire mode a soldier will use for their remaining
+// ammunition. Low on ammo, automatic fire drops to bursts; nearly dry, only
+// point-blank threats get more than aimed single shots.
+func (s *Soldier) conserveAmmo(mode FireMode, dist float64) FireMode {
+	frac := s.ammoFraction()
+	switch {
+	case frac < ammoCriticalFraction && dist > float64(autoRange)/2.0:
+		return FireModeSingle
+	case frac < ammoLowFraction && mode == FireModeAuto && dist > float64(autoRange)/2.0:
+		return FireModeBurst
+	}
+	return mode
+}
+
+// --- Radio: ammo-state reporting ---
+
+// buildAmmoReportMessage reports low or exhausted ammunition to the leader.
+func (s *Soldier) buildAmmoReportMessage(leader *Soldier, tick int) (RadioMessage, bool) {
+	if leader == nil {
+		return RadioMessage{}, false
+	}
+	if s.ammoFraction() >= ammoLowFraction {
+		return RadioMessage{}, false
+	}
+	if tick-s.radioLastAmmoReportTick < radioAmmoReportCooldown {
+		return RadioMessage{}, false
+	}
+	s.radioLastAmmoReportTick = tick
+
+	rounds := s.roundsCarried()
+	summary := fmt.Sprintf("AMMO LOW %d rds", rounds)
+	pri := RadioPriRoutine
+	if rounds == 0 {
+		summary = "AMMO BLACK"
+		pri = RadioPriUrgent
+	}
+	return RadioMessage{
+		TickCreated:   tick,
+		SenderID:      s.id,
+		SenderLabel:   s.label,
+		ReceiverID:    leader.id,
+		ReceiverLabel: leader.label,
+		Type:          RadioMsgAmmoReport,
+		Priority:      pri,
+		Summary:       summary,
+		Rounds:        rounds,
+		Fear:          s.profile.Psych.EffectiveFear(),
+	}, true
+}
+
+// --- Squad: redistribution ---
+
+// noteAmmoRequest records that memberID needs ammunition.
+func (sq *Squad) noteAmmoRequest(memberID int) {
+	for _, id := range sq.ammoRequests {
+		if id == memberID {
+			return
+		}
+	}
+	sq.ammoRequests = append(sq.ammoRequests, memberID)
+}
+
+// redistributeAmmo hands a spare magazine to members who have reported low
+// ammunition, from the nearby member with the most to spare. Casualties are
+// stripped of their magazines first. The leader knows their own state without
+// a radio call. One magazine changes hands per ammoShareInterval.
+func (sq *Squad) redistributeAmmo(tick int) {
+	if sq.Leader != nil && sq.Leader.state != SoldierStateDead && sq.Leader.ammoFraction() < ammoLowFraction {
+		sq.noteAmmoRequest(sq.Leader.id)
+	}
+	if len(sq.ammoRequests) == 0 || tick < sq.ammoShareNextTick {
+		return
+	}
+
+	open := sq.ammoRequests[:0]
+	shared := false
+	for _, id := range sq.ammoRequests {
+		r := sq.memberByID(id)
+		if r == nil || r.state == SoldierStateDead || r.state.IsIncapacitated() || r.ammoFraction() >= ammoLowFraction {
+			continue // no longer needed
+		}
+		if !shared {
+			if donor := sq.ammoDonorFor(r); donor != nil {
+				donor.spareMags--
+				r.spareMags++
+				shared = true
+				sq.ammoShareNextTick = tick + ammoShareInterval
+				if donor.state == SoldierStateDead || donor.state.IsIncapacitated() {
+					r.think(fmt.Sprintf("took a magazine off %s", donor.label))
+				} else {
+					donor.think(fmt.Sprintf("passing a magazine to %s", r.label))
+					r.think(fmt.Sprintf("got a magazine from %s", donor.label))
+				}
+				if r.ammoFraction() >= ammoLowFraction {
+					continue
+				}
+			}
+		}
+		open = append(open, id)
+	}
+	sq.ammoRequests = open
+}
+
+// ammoDonorFor picks who gives r a magazine: the nearest casualty with spares,
+// otherwise the able member in reach with the most spares above their reserve.
+// Only a soldier carrying the same weapon has magazines that fit.
+func (sq *Squad) ammoDonorFor(r *Soldier) *Soldier {
+	var best *Soldier
+	bestScore := math.Inf(-1)
+	for _, m := range sq.Members {
+		if m == r || m.spareMags <= 0 || m.firearm() != r.firearm() {
+			continue
+		}
+		d := math.Hypot(m.x-r.x, m.y-r.y)
+		if d > ammoShareRange {
+			continue
+		}
+		var score float64
+		if m.state == SoldierStateDead || m.state.IsIncapacitated() {
+			score = 100 - d/ammoShareRange
+		} else {
+			if m.spareMags <= ammoShareKeep || m.spareMags <= r.spareMags+1 {
+				continue
+			}
+			score = float64(m.spareMags) - d/ammoShareRange
+		}
+		if score > bestScore {
+			best, bestScore = m, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,113 @@
+package game
+
+import "testing"
+
+func TestAmmo_ReloadDrawsFromSparesUntilDry(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	s.magRounds, s.spareMags = 0, 1
+	cm := NewCombatManager(3)
+
+	cm.ResolveCombat([]*Soldier{s}, nil, []*Soldier{s}, nil, []*Soldier{s})
+	if s.reloadTimer == 0 {
+		t.Fatal("empty magazine with a spare should start a reload")
+	}
+	for s.reloadTimer > 0 {
+		cm.ResolveCombat([]*Soldier{s}, nil, []*Soldier{s}, nil, []*Soldier{s})
+	}
+	if s.magRounds != s.magCapacity || s.spareMags != 0 {
+		t.Fatalf("after reload mag=%d spares=%d, want %d and 0", s.magRounds, s.spareMags, s.magCapacity)
+	}
+
+	s.magRounds = 0
+	cm.ResolveCombat([]*Soldier{s}, nil, []*Soldier{s}, nil, []*Soldier{s})
+	if s.reloadTimer != 0 {
+		t.Fatal("a soldier with no spares must not reload")
+	}
+	s.updateAmmoState()
+	if !s.blackboard.OutOfAmmo {
+		t.Fatal("blackboard should report out of ammo")
+	}
+}
+
+func TestAmmo_ConservesFireModeWhenLow(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	dist := float64(autoRange) * 0.9
+
+	if got := s.conserveAmmo(FireModeAuto, dist); got != FireModeAuto {
+		t.Fatalf("full load: got %s, want auto", got)
+	}
+	s.spareMags = 1
+	if got := s.conserveAmmo(FireModeAuto, dist); got != FireModeBurst {
+		t.Fatalf("low ammo: got %s, want burst", got)
+	}
+	s.spareMags, s.magRounds = 0, 10
+	if got := s.conserveAmmo(FireModeBurst, dist); got != FireModeSingle {
+		t.Fatalf("nearly dry: got %s, want single", got)
+	}
+	if got := s.conserveAmmo(FireModeAuto, float64(autoRange)/4); got != FireModeAuto {
+		t.Fatalf("nearly dry at point blank: got %s, want auto", got)
+	}
+}
+
+func TestSelectGoal_DryRifleDoesNotEngage(t *testing.T) {
+	tick := 0
+	enemy := newTestSoldier(2, 300, 200, TeamBlue, &tick)
+	p := DefaultProfile()
+	bb := &Blackboard{}
+	bb.UpdateThreats([]*Soldier{enemy}, 1)
+	bb.SquadHasContact = true
+	bb.AmmoFraction = 1
+
+	if g := SelectGoal(bb, &p, false, true); g != GoalEngage {
+		t.Fatalf("baseline picked %s, want %s", g, GoalEngage)
+	}
+	bb.AmmoFraction = 0
+	bb.OutOfAmmo = true
+	if g := SelectGoal(bb, &p, false, true); g == GoalEngage {
+		t.Fatal("soldier with no ammunition should not choose to engage")
+	}
+}
+
+func TestAmmo_LowReportLeadsToRedistribution(t *testing.T) {
+	sq, leader, member, tick := makeRadioSquadForTest(t, 100, 160)
+	member.spareMags = 0
+	member.magRounds = 5
+	*tick = 600
+
+	msg, ok := member.buildAmmoReportMessage(leader, *tick)
+	if !ok || msg.Type != RadioMsgAmmoReport || msg.Rounds != 5 {
+		t.Fatalf("expected an ammo report with 5 rounds, got ok=%v %+v", ok, msg)
+	}
+	if _, again := member.buildAmmoReportMessage(leader, *tick+1); again {
+		t.Fatal("ammo report should respect its cooldown")
+	}
+
+	sq.queueRadio(msg)
+	sq.ResolveComms(*tick, nil)
+	*tick = sq.radioInFlight.arrivalTick
+	sq.ResolveComms(*tick, nil)
+	if len(sq.ammoRequests) != 1 || sq.ammoRequests[0] != member.id {
+		t.Fatalf("leader should log the request, got %v", sq.ammoRequests)
+	}
+
+	sq.redistributeAmmo(*tick)
+	if member.spareMags != 1 || leader.spareMags != defaultSpareMagazines-1 {
+		t.Fatalf("member spares=%d leader spares=%d after one hand-over", member.spareMags, leader.spareMags)
+	}
+	sq.redistributeAmmo(*tick + 1)
+	if member.spareMags != 1 {
+		t.Fatal("only one magazine should change hands per interval")
+	}
+	sq.redistributeAmmo(*tick + ammoShareInterval)
+	if member.spareMags != 2 {
+		t.Fatalf("second hand-over after the interval, spares=%d", member.spareMags)
+	}
+
+	member.x = leader.x + ammoShareRange*2
+	sq.redistributeAmmo(*tick + 2*ammoShareInterval)
+	if member.spareMags != 2 {
+		t.Fatal("no hand-over beyond reach")
+	}
+}
@@ -0,0 +1,266 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Armour — plate carriers and helmets in the BodyMap damage model
+// ---------------------------------------------------------------------------
+//
+// A soldier's BodyMap carries the armour they wear. A hit that lands on a
+// region the armour covers may strike it; a struck piece either stops the
+// round outright, leaving only blunt-trauma pain, or is defeated and takes
+// some of the round's energy off the wound. How likely a stop is depends on
+// the piece's protection level against the damage of the round and on its
+// condition, which every strike wears down. A soldier without armour takes
+// no extra random draws, so unarmoured fights play out exactly as before.
+// Armour weight is a load that tires its wearer (PhysicalStats.Load).
+
+const (
+	armourMaxStop      = 0.95 // best stop chance of a piece in full condition
+	armourDefeatedSoak = 0.25 // share of a defeated round's damage a full-condition piece still takes off
+	armourBluntPainCap = 2.0  // blunt pain stops growing past this many times baseDamage
+
+	armourBluntPainHalfLife = 30 * 60 // ticks for a stopped round's pain to halve (30 s)
+	armourBluntPainFloor    = 0.005   // pain below this has worn off
+)
+
+// armourBluntPainEase is the per-tick factor that halves blunt pain every
+// armourBluntPainHalfLife ticks.
+var armourBluntPainEase = math.Pow(0.5, 1.0/armourBluntPainHalfLife)
+
+// ArmourLevel is the protection level of an armour piece.
+type ArmourLevel int
+
+const (
+	ArmourSoft  ArmourLevel = iota // soft panels and helmets — fragments and pistol rounds
+	ArmourRifle                    // rifle plate
+	ArmourHeavy                    // heavy plate, rated against marksman rounds
+)
+
+func (l ArmourLevel) String() string {
+	switch l {
+	case ArmourSoft:
+		return "soft"
+	case ArmourRifle:
+		return "rifle"
+	case ArmourHeavy:
+		return "heavy"
+	default:
+		return "unknown"
+	}
+}
+
+// armourLevelRating is the round damage each level stops reliably. Stop
+// chance falls with the square of rating/damage above it.
+var armourLevelRating = [...]float64{
+	ArmourSoft:  14,
+	ArmourRifle: 30,
+	ArmourHeavy: 40,
+}
+
+// ArmourPiece is one worn item of protection.
+type ArmourPiece struct {
+	Name       string
+	Level      ArmourLevel
+	Coverage   [regionCount]float64 // share of hits on each region that strike the piece
+	Condition  float64              // 1 = new, 0 = worn through
+	Durability float64              // round damage the piece absorbs before it is worn through
+	BluntPain  float64              // pain of a stopped rifle round
+	Weight     float64              // kg
+}
+
+// NewPlateCarrier returns a rifle plate carrier covering most of the torso
+// and part of the abdomen.
+func NewPlateCarrier() ArmourPiece {
+	p := ArmourPiece{Name: "plate", Level: ArmourRifle, Condition: 1, Durability: 500, BluntPain: 0.10, Weight: 11}
+	p.Coverage[RegionTorso] = 0.85
+	p.Coverage[RegionAbdomen] = 0.40
+	return p
+}
+
+// NewHelmet returns a combat helmet. The face is open, so it covers only
+// part of the head, and it rarely stops a rifle round.
+func NewHelmet() ArmourPiece {
+	p := ArmourPiece{Name: "helmet", Level: ArmourSoft, Condition: 1, Durability: 150, BluntPain: 0.12, Weight: 1.5}
+	p.Coverage[RegionHead] = 0.65
+	return p
+}
+
+// ArmourKit names a set of armour issued together.
+type ArmourKit int
+
+const (
+	ArmourKitNone   ArmourKit = iota // no armour
+	ArmourKitHelmet                  // helmet only
+	ArmourKitPlate                   // plate carrier only
+	ArmourKitFull                    // plate carrier and helmet
+)
+
+func (k ArmourKit) String() string {
+	switch k {
+	case ArmourKitNone:
+		return "none"
+	case ArmourKitHelmet:
+		return "helmet"
+	case ArmourKitPlate:
+		return "plate"
+	case ArmourKitFull:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseArmourKit parses an armour kit name as written by String. The empty
+// string is no armour.
+func ParseArmourKit(s string) (ArmourKit, error) {
+	if s == "" {
+		return ArmourKitNone, nil
+	}
+	for k := ArmourKitNone; k <= ArmourKitFull; k++ {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return ArmourKitNone, fmt.Errorf("unknown armour %q", s)
+}
+
+// pieces returns new armour for kit k.
+func (k ArmourKit) pieces() []ArmourPiece {
+	switch k {
+	case ArmourKitHelmet:
+		return []ArmourPiece{NewHelmet()}
+	case ArmourKitPlate:
+		return []ArmourPiece{NewPlateCarrier()}
+	case ArmourKitFull:
+		return []ArmourPiece{NewPlateCarrier(), NewHelmet()}
+	default:
+		return nil
+	}
+}
+
+// stopChance returns the chance p stops a round of the given damage.
+func (p *ArmourPiece) stopChance(damage float64) float64 {
+	if damage <= 0 {
+		return armourMaxStop * p.Condition
+	}
+	r := clamp01(armourLevelRating[p.Level] / damage)
+	return armourMaxStop * p.Condition * r * r
+}
+
+// wear takes a strike of the given damage off p's condition.
+func (p *ArmourPiece) wear(damage float64) {
+	p.Condition = math.Max(0, p.Condition-damage/p.Durability)
+}
+
+// armourHit is the outcome of a round meeting a region's armour.
+type armourHit struct {
+	piece   *ArmourPiece // nil if no armour was struck
+	stopped bool
+	damage  float64 // damage that reaches the body
+}
+
+// strikeArmour decides whether a round of the given damage landing on region
+// strikes armour, and what it does if so. Regions no piece covers take no
+// random draws.
+func (bm *BodyMap) strikeArmour(region BodyRegion, damage float64, rng *rand.Rand) armourHit {
+	for i := range bm.Armour {
+		p := &bm.Armour[i]
+		if p.Coverage[region] <= 0 {
+			continue
+		}
+		if rng.Float64() >= p.Coverage[region] {
+			return armourHit{damage: damage}
+		}
+		stop := rng.Float64() < p.stopChance(damage)
+		soak := armourDefeatedSoak * p.Condition
+		p.wear(damage)
+		if stop {
+			return armourHit{piece: p, stopped: true}
+		}
+		return armourHit{piece: p, damage: damage * (1 - soak)}
+	}
+	return armourHit{damage: damage}
+}
+
+// stoppedWound is the blunt trauma of a round p stopped on region.
+func stoppedWound(p *ArmourPiece, region BodyRegion, damage float64, tick int) Wound {
+	return Wound{
+		Region:        region,
+		Severity:      WoundMinor,
+		Pain:          p.BluntPain * math.Min(damage/baseDamage, armourBluntPainCap),
+		Stopped:       true,
+		Armour:        p.Name,
+		TickInflicted: tick,
+	}
+}
+
+// EaseBluntPain advances stopped-round pain by one tick. Nothing treats a
+// bruise, so it fades on its own: halving every armourBluntPainHalfLife
+// ticks and gone once it is negligible.
+func (bm *BodyMap) EaseBluntPain() {
+	for i := range bm.Wounds {
+		w := &bm.Wounds[i]
+		if !w.Stopped || w.Pain == 0 {
+			continue
+		}
+		w.Pain *= armourBluntPainEase
+		if w.Pain < armourBluntPainFloor {
+			w.Pain = 0
+		}
+	}
+}
+
+// ArmourWeight returns the total weight of the armour worn, in kg.
+func (bm *BodyMap) ArmourWeight() float64 {
+	w := 0.0
+	for _, p := range bm.Armour {
+		w += p.Weight
+	}
+	return w
+}
+
+// StoppedCount returns how many rounds armour has stopped.
+func (bm *BodyMap) StoppedCount() int {
+	n := 0
+	for i := range bm.Wounds {
+		if bm.Wounds[i].Stopped {
+			n++
+		}
+	}
+	return n
+}
+
+// penetratingWounds returns the number of wounds armour did not stop.
+func (bm *BodyMap) penetratingWounds() int {
+	return len(bm.Wounds) - bm.StoppedCount()
+}
+
+// armourSummary describes the armour worn and its condition, e.g.
+// "plate 82% helmet 100%".
+func (bm *BodyMap) armourSummary() string {
+	parts := make([]string, 0, len(bm.Armour))
+	for _, p := range bm.Armour {
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", p.Name, p.Condition*100))
+	}
+	return strings.Join(parts, " ")
+}
+
+// issueArmour dresses s in kit k, replacing whatever they wore, and sets the
+// load it puts on them.
+func (s *Soldier) issueArmour(k ArmourKit) {
+	s.body.Armour = k.pieces()
+	s.profile.Physical.Load = s.body.ArmourWeight()
+}
+
+// issueArmour dresses every member of the squad in kit k.
+func (sq *Squad) issueArmour(k ArmourKit) {
+	for _, m := range sq.Members {
+		m.issueArmour(k)
+	}
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// onlyRegion returns a cover mask that leaves just r exposed.
+func onlyRegion(r BodyRegion) [regionCount]float64 {
+	var m [regionCount]float64
+	for i := range m {
+		m[i] = 1
+	}
+	m[r] = 0
+	return m
+}
+
+func TestArmour_PlateStopsRoundsAndWearsThrough(t *testing.T) {
+	bm := NewBodyMap()
+	bm.Armour = ArmourKitPlate.pieces()
+	rng := rand.New(rand.NewSource(3))
+	mask := onlyRegion(RegionTorso)
+
+	stoppedEarly := 0
+	for i := 0; i < 10; i++ {
+		w, dead := bm.ApplyHit(baseDamage, StanceStanding, mask, i, rng)
+		if w.Stopped {
+			stoppedEarly++
+			if dead || w.BleedRate != 0 || w.Pain <= 0 || w.Armour != "plate" {
+				t.Fatalf("stopped round left %+v dead=%v, want blunt pain only", w, dead)
+			}
+		}
+	}
+	if stoppedEarly < 5 {
+		t.Fatalf("new plate stopped %d of 10 rifle rounds, want most", stoppedEarly)
+	}
+	if c := bm.Armour[0].Condition; c >= 1 {
+		t.Fatalf("plate condition %.2f after 10 strikes, want worn", c)
+	}
+
+	for i := 0; i < 100 && bm.Armour[0].Condition > 0; i++ {
+		bm.ApplyHit(baseDamage, StanceStanding, mask, i, rng)
+	}
+	if bm.Armour[0].Condition != 0 {
+		t.Fatalf("plate condition %.2f after 110 hits, want worn through", bm.Armour[0].Condition)
+	}
+	before := bm.StoppedCount()
+	for i := 0; i < 10; i++ {
+		if w, _ := bm.ApplyHit(1, StanceStanding, mask, i, rng); w.Stopped {
+			t.Fatal("worn-through plate stopped a round")
+		}
+	}
+	if bm.StoppedCount() != before {
+		t.Errorf("stopped count moved from %d to %d", before, bm.StoppedCount())
+	}
+}
+
+func TestArmour_StoppedRoundsNeedNoTreatment(t *testing.T) {
+	bm := NewBodyMap()
+	helmet := NewHelmet()
+	bm.Wounds = append(bm.Wounds, stoppedWound(&helmet, RegionHead, grenadeFragmentDamage, 0))
+	if bm.IsInjured() || bm.HasUntreatedWounds() || bm.WorstUntreatedWound() != nil {
+		t.Fatal("a round stopped by a helmet left a wound to treat")
+	}
+	if bm.TotalPain() <= 0 {
+		t.Error("a stopped round left no blunt-trauma pain")
+	}
+	if bm.penetratingWounds() != 0 {
+		t.Errorf("penetrating wounds %d, want 0", bm.penetratingWounds())
+	}
+}
+
+func TestArmour_BluntPainFadesAfterAStop(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 200, 200, TeamRed, &tick)
+	plate := NewPlateCarrier()
+	s.body.Wounds = append(s.body.Wounds, stoppedWound(&plate, RegionTorso, baseDamage, 0))
+	start := s.body.TotalPain()
+	for ; tick < armourBluntPainHalfLife; tick++ {
+		s.Update()
+	}
+	if got := s.body.TotalPain(); math.Abs(got-start/2) > 0.01 {
+		t.Fatalf("pain %.3f after one half-life, want about %.3f", got, start/2)
+	}
+	for ; tick < 12*armourBluntPainHalfLife; tick++ {
+		s.body.EaseBluntPain()
+	}
+	if got := s.body.TotalPain(); got != 0 {
+		t.Fatalf("pain %.4f long after the stop, want it gone", got)
+	}
+}
+
+func TestArmour_LevelsMatchThreats(t *testing.T) {
+	helmet, plate := NewHelmet(), NewPlateCarrier()
+	if helmet.stopChance(grenadeFragmentDamage*0.6) < 0.9 {
+		t.Errorf("helmet stops %.2f of light fragments, want nearly all", helmet.stopChance(grenadeFragmentDamage*0.6))
+	}
+	if helmet.stopChance(baseDamage) > 0.5 {
+		t.Errorf("helmet stops %.2f of rifle rounds, want under half", helmet.stopChance(baseDamage))
+	}
+	if plate.stopChance(baseDamage) < 0.9 {
+		t.Errorf("plate stops %.2f of rifle rounds, want nearly all", plate.stopChance(baseDamage))
+	}
+	slug := weaponFor(WeaponShotgun).Damage * weaponFor(WeaponShotgun).cqbDamageMul(0)
+	if plate.stopChance(slug) > 0.3 {
+		t.Errorf("plate stops %.2f of point-blank slugs, want few", plate.stopChance(slug))
+	}
+}
+
+func TestArmour_UnarmouredRegionsTakeNoExtraDraws(t *testing.T) {
+	bare := NewBodyMap()
+	armoured := NewBodyMap()
+	armoured.Armour = ArmourKitFull.pieces()
+	r1 := rand.New(rand.NewSource(9))
+	r2 := rand.New(rand.NewSource(9))
+	mask := onlyRegion(RegionLegLeft)
+	for i := 0; i < 20; i++ {
+		w1, _ := bare.ApplyHit(baseDamage, StanceStanding, mask, i, r1)
+		w2, _ := armoured.ApplyHit(baseDamage, StanceStanding, mask, i, r2)
+		if w1 != w2 {
+			t.Fatalf("hit %d: bare %+v, armoured %+v", i, w1, w2)
+		}
+	}
+}
+
+func TestArmour_WeightTiresWearer(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(0, 100, 100, TeamRed, &tick)
+	s.issueArmour(ArmourKitFull)
+	if s.profile.Physical.Load != NewPlateCarrier().Weight+NewHelmet().Weight {
+		t.Fatalf("load %.1f kg, want the plate and helmet", s.profile.Physical.Load)
+	}
+	light := PhysicalStats{FitnessBase: 0.7}
+	heavy := PhysicalStats{FitnessBase: 0.7, Load: s.profile.Physical.Load}
+	light.AccumulateFatigue(1, 1)
+	heavy.AccumulateFatigue(1, 1)
+	if heavy.Fatigue <= light.Fatigue {
+		t.Errorf("armoured fatigue %.4f, unarmoured %.4f; want armour to tire faster", heavy.Fatigue, light.Fatigue)
+	}
+	light.Fatigue, heavy.Fatigue = 0.5, 0.5
+	light.AccumulateFatigue(0, 1)
+	heavy.AccumulateFatigue(0, 1)
+	if heavy.Fatigue <= light.Fatigue {
+		t.Errorf("armoured fatigue %.4f, unarmoured %.4f after rest; want armour to slow recovery", heavy.Fatigue, light.Fatigue)
+	}
+}
+
+func TestScenario_SquadArmour(t *testing.T) {
+	data := strings.Replace(testScenarioJSON,
+		`"squads": [{"team": "red", "members": [0, 1]}]`,
+		`"squads": [{"team": "red", "members": [0, 1], "armour": "full"}]`, 1)
+	data = strings.Replace(data, `{"id": 0, "team": "red", "start": [50, 350], "objective": [1200, 350],`,
+		`{"id": 0, "team": "red", "armour": "helmet", "start": [50, 350], "objective": [1200, 350],`, 1)
+	sc, err := ParseScenario([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ts := NewTestSimFromScenario(sc, 7)
+	if a := ts.Soldiers[0].body.Armour; len(a) != 1 || a[0].Name != "helmet" {
+		t.Errorf("soldier 0 wears %s, want the helmet their own entry names", ts.Soldiers[0].body.armourSummary())
+	}
+	if a := ts.Soldiers[1].body.Armour; len(a) != 2 {
+		t.Errorf("soldier 1 wears %q, want the squad's full kit", ts.Soldiers[1].body.armourSummary())
+	}
+
+	bad := strings.Replace(data, `"full"`, `"chainmail"`, 1)
+	if _, err := ParseScenario([]byte(bad)); err == nil || !strings.Contains(err.Error(), "chainmail") {
+		t.Errorf("unknown armour accepted: %v", err)
+	}
+}
@@ -21,6 +21,7 @@ const (
 	GoalPeek                              // cautious peek around a corner or through a window
 	GoalHelpCasualty                      // render medical aid to wounded squad member
 	GoalSearch                            // cautious search of nearby dangerous/uncertain areas when not in contact
+	GoalThrowGrenade                      // throw a fragmentation grenade at an enemy we can't shoot effectively
 )
 
 func (g GoalKind) String() string {
@@ -51,17 +52,21 @@ func (g GoalKind) String() string {
 		return "help_casualty"
 	case GoalSearch:
 		return "search"
+	case GoalThrowGrenade:
+		return "grenade"
 	default:
 		return "unknown"
 	}
 
 }
 
-func overwatchDistanceFactor(contactRange float64) float64 {
+// overwatchDistanceFactor scales overwatch down once the contact is beyond
+// maxRange, the reach of the soldier's weapon.
+func overwatchDistanceFactor(contactRange, maxRange float64) float64 {
 	if contactRange <= 0 {
 		return 1.0
 	}
-	maxPractical := float64(maxFireRange)
+	maxPractical := maxRange
 	if contactRange <= maxPractical {
 		return 1.0
 	}
@@ -189,6 +194,7 @@ type ThreatFact struct {
 	Confidence float64  // 0-1, decays over time
 	LastTick   int      // tick when last observed
 	IsVisible  bool     // true = currently in vision cone this tick
+	Uncertain  bool     // heard or only half seen, never confirmed by sight
 }
 
 // --- Blackboard ---
@@ -323,6 +329,28 @@ type Blackboard struct {
 	SearchTargetY   float64
 	HasSearchTarget bool
 
+	// --- Grenades ---
+	// GrenadeTarget is the best throw point found this tick; GrenadeTargetValue
+	// (0..~1.5) rates how much a grenade would achieve there.
+	GrenadeTargetX     float64
+	GrenadeTargetY     float64
+	GrenadeTargetValue float64
+	HasGrenadeTarget   bool
+	GrenadeCooldown    int // ticks before another throw is considered
+	// --- Ammunition ---
+	// AmmoFraction is rounds carried over a full load; OutOfAmmo is set once
+	// the loaded magazine and every spare are empty.
+	AmmoFraction float64
+	OutOfAmmo    bool
+
+	// EntryStage is the squad entry-plan stage when this soldier is on the
+	// entry team, EntryStateNone otherwise. EntryStack is this soldier's
+	// place in the stack outside the entry; EntryInside is where the team
+	// makes for once the way in is open.
+	EntryStage                 BuildingEntryState
+	EntryStackX, EntryStackY   float64
+	EntryInsideX, EntryInsideY float64
+
 	// --- Flanking state ---
 	FlankComplete         bool    // true when the perpendicular leg is done
 	FlankCompleteCooldown int     // ticks remaining before flank goal can be selected again
@@ -427,6 +455,12 @@ type Blackboard struct {
 	// suppressed is a hysteresis-smoothed suppression state.
 	// Enter at SuppressThreshold, clear at suppressClearThreshold.
 	suppressed bool
+
+	cfg    *SimConfig // balance constants, nil = defaults
+	weapon *Weapon    // the owner's weapon, nil = the rifle; see firearm
+	// ownThresholds replaces cfg.Thresholds as this soldier's starting and
+	// resting thresholds (set from a scenario profile or an evolved genome).
+	ownThresholds *GoalThresholds
 }
 
 // GoalThresholds controls when a soldier prefers to shoot, push, or seek safety.
@@ -466,7 +500,7 @@ func defaultGoalThresholds() GoalThresholds {
 
 func (bb *Blackboard) ensureInternalDefaults() {
 	if bb.Internal.Thresholds.EngageShotQuality <= 0 {
-		bb.Internal.Thresholds = defaultGoalThresholds()
+		bb.Internal.Thresholds = bb.baseThresholds()
 	}
 	// Default shatter threshold if not yet initialised (set properly by InitCommitment).
 	if bb.ShatterThreshold <= 0 {
@@ -591,7 +625,7 @@ func (bb *Blackboard) DecayDecisionDebt() {
 func (bb *Blackboard) EvolveThresholds(currentGoal GoalKind, stress float64) {
 	bb.ensureInternalDefaults()
 	th := &bb.Internal.Thresholds
-	def := defaultGoalThresholds()
+	def := bb.baseThresholds()
 	bb.Internal.ThresholdAge++
 
 	// Drift rate: slow so thresholds take ~300 ticks to fully move.
@@ -723,9 +757,10 @@ func (bb *Blackboard) RefreshInternalGoals(profile *SoldierProfile, selfX, selfY
 		return
 	}
 
-	estHitChance := estimateHitChanceAtRange(profile, dist)
+	estHitChance := estimateHitChanceAtRange(bb.config(), bb.firearm(), profile, dist)
 	fear := profile.Psych.EffectiveFear()
-	rangePressure := clamp01((dist - accurateFireRange) / (maxFireRange - accurateFireRange))
+	accurateRange, maxRange, _ := bb.config().weaponRanges(bb.firearm())
+	rangePressure := clamp01((dist - accurateRange) / (maxRange - accurateRange))
 
 	bb.Internal.LastRange = dist
 	bb.Internal.LastContactRange = dist
@@ -764,9 +799,9 @@ func (bb *Blackboard) RecordShotOutcome(hit bool, expectedHitChance, shotDist fl
 	return false
 }
 
-func estimateHitChanceAtRange(profile *SoldierProfile, dist float64) float64 {
+func estimateHitChanceAtRange(cfg *SimConfig, w *Weapon, profile *SoldierProfile, dist float64) float64 {
 	accuracy := profile.EffectiveAccuracy()
-	return clamp01(accuracy - shotRangePenalty(dist))
+	return clamp01(accuracy - cfg.shotRangePenalty(w, dist))
 }
 
 // UpdateThreats refreshes the blackboard from the current vision contacts.
@@ -793,6 +828,7 @@ func (bb *Blackboard) UpdateThreats(contacts []*Soldier, currentTick int) {
 				bb.Threats[i].Confidence = 1.0
 				bb.Threats[i].LastTick = currentTick
 				bb.Threats[i].IsVisible = true
+				bb.Threats[i].Uncertain = false
 				found = true
 				break
 			}
@@ -816,7 +852,10 @@ func (bb *Blackboard) UpdateThreats(contacts []*Soldier, currentTick int) {
 		if t.Source != nil && t.Source.state == SoldierStateDead {
 			continue
 		}
-		if !t.IsVisible {
+		if t.Uncertain {
+			// Heard contacts fade steadily; they were never more than a guess.
+			t.Confidence = math.Max(0, t.Confidence-soundThreatDecay)
+		} else if !t.IsVisible {
 			// Decay by tick delta: 0.008/tick ≈ full decay in ~125 ticks (~2s at 60TPS).
 			age := float64(currentTick - t.LastTick)
 			t.Confidence = math.Max(0, t.Confidence-age*0.008)
@@ -828,6 +867,92 @@ func (bb *Blackboard) UpdateThreats(contacts []*Soldier, currentTick int) {
 	bb.Threats = kept
 }
 
+// muzzleFlashConfidence is how sure a soldier is of a position marked only by
+// a muzzle flash.
+const muzzleFlashConfidence = 0.85
+
+// NoteMuzzleFlash records a flash seen at (x, y) from src as a non-visible
+// threat. Threats currently in sight are left alone.
+func (bb *Blackboard) NoteMuzzleFlash(src *Soldier, x, y float64, tick int) {
+	for i := range bb.Threats {
+		t := &bb.Threats[i]
+		if t.Source != src {
+			continue
+		}
+		if !t.IsVisible {
+			t.X, t.Y = x, y
+			t.LastTick = tick
+			t.Confidence = math.Max(t.Confidence, muzzleFlashConfidence)
+			t.Uncertain = false
+		}
+		return
+	}
+	bb.Threats = append(bb.Threats, ThreatFact{
+		Source:     src,
+		X:          x,
+		Y:          y,
+		Confidence: muzzleFlashConfidence,
+		LastTick:   tick,
+	})
+}
+
+// NoteHeardContact records a sound from src, estimated to come from (x, y),
+// as an uncertain threat. A visible or firmer memory of src is left alone;
+// an older heard estimate is moved to the new one. It reports whether the
+// contact is new to this soldier.
+func (bb *Blackboard) NoteHeardContact(src *Soldier, x, y float64, tick int) bool {
+	for i := range bb.Threats {
+		t := &bb.Threats[i]
+		if t.Source != src {
+			continue
+		}
+		if t.IsVisible || (!t.Uncertain && t.Confidence > soundThreatConfidence) {
+			return false
+		}
+		t.X, t.Y = x, y
+		t.LastTick = tick
+		t.Confidence = math.Max(t.Confidence, soundThreatConfidence)
+		t.Uncertain = true
+		return false
+	}
+	bb.Threats = append(bb.Threats, ThreatFact{
+		Source:     src,
+		X:          x,
+		Y:          y,
+		Confidence: soundThreatConfidence,
+		LastTick:   tick,
+		Uncertain:  true,
+	})
+	return true
+}
+
+// NoteSuspectedContact records src, half seen at its current position, as an
+// uncertain threat. A visible or firmer memory of src is left alone.
+func (bb *Blackboard) NoteSuspectedContact(src *Soldier, tick int) {
+	for i := range bb.Threats {
+		t := &bb.Threats[i]
+		if t.Source != src {
+			continue
+		}
+		if t.IsVisible || (!t.Uncertain && t.Confidence > suspectThreatConfidence) {
+			return
+		}
+		t.X, t.Y = src.x, src.y
+		t.LastTick = tick
+		t.Confidence = math.Max(t.Confidence, suspectThreatConfidence)
+		t.Uncertain = true
+		return
+	}
+	bb.Threats = append(bb.Threats, ThreatFact{
+		Source:     src,
+		X:          src.x,
+		Y:          src.y,
+		Confidence: suspectThreatConfidence,
+		LastTick:   tick,
+		Uncertain:  true,
+	})
+}
+
 // VisibleThreatCount returns how many threats are currently visible.
 func (bb *Blackboard) VisibleThreatCount() int {
 	n := 0
@@ -1107,6 +1232,7 @@ func officerOrderBias(goal GoalKind, bb *Blackboard, profile *SoldierProfile) fl
 func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath bool) GoalKind {
 	bb.ensureInternalDefaults()
 	internal := bb.Internal
+	accurateRange, maxRange, _ := bb.config().weaponRanges(bb.firearm())
 
 	visibleThreats := bb.VisibleThreatCount()
 	// underFire is true when rounds are arriving THIS tick OR the soldier is
@@ -1144,11 +1270,11 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 		if internal.ShotMomentum >= internal.Thresholds.HoldOnHitMomentum {
 			engageUtil += 0.15
 		}
-		if internal.LastRange > accurateFireRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality {
+		if internal.LastRange > accurateRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality {
 			engageUtil -= 0.18
 		}
 		// Range band stability: if currently engaging and near threshold, add hysteresis bonus
-		if bb.CurrentGoal == GoalEngage && internal.LastRange > accurateFireRange {
+		if bb.CurrentGoal == GoalEngage && internal.LastRange > accurateRange {
 			// Check if we're near the threshold (within 20% margin)
 			thresholdMargin := math.Abs(internal.LastEstimatedHitChance - internal.Thresholds.LongRangeShotQuality)
 			if thresholdMargin < 0.06 {
@@ -1166,6 +1292,7 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 		if posture > 0 {
 			engageUtil += posture * 0.15
 		}
+		engageUtil -= ammoEngagePenalty(bb)
 	}
 
 	// --- Fallback: retreat away from contact. ---
@@ -1246,12 +1373,12 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 	// Triggered by contact of any kind. Also fires when visible but at long range
 	// with poor shot quality — the soldier needs to push forward to effective range.
 	moveToContactUtil := 0.0
-	lowQualityLongRange := visibleThreats > 0 && internal.LastRange > accurateFireRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
+	lowQualityLongRange := visibleThreats > 0 && internal.LastRange > accurateRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
 	if anyContact && (visibleThreats == 0 || lowQualityLongRange) {
 		moveToContactUtil = 0.50 + profile.Skills.Discipline*0.1 + internal.MoveDesire*0.30
 		if lowQualityLongRange {
 			// Stronger urgency: the further out of range, the more they need to close.
-			rangePressure := clamp01((internal.LastRange - accurateFireRange) / accurateFireRange)
+			rangePressure := clamp01((internal.LastRange - accurateRange) / accurateRange)
 			moveToContactUtil += 0.20 + rangePressure*0.25
 			// Range band stability: if currently moving and near threshold, add hysteresis bonus
 			if bb.CurrentGoal == GoalMoveToContact {
@@ -1356,7 +1483,7 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 		overwatchUtil -= 0.20
 	}
 	if anyContact && visibleThreats == 0 {
-		overwatchUtil *= overwatchDistanceFactor(internal.LastContactRange)
+		overwatchUtil *= overwatchDistanceFactor(internal.LastContactRange, maxRange)
 	}
 	if bb.SquadIntent == IntentEngage && visibleThreats == 0 {
 		overwatchUtil -= 0.25
@@ -1617,6 +1744,17 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 	overwatchUtil += officerOrderBias(GoalOverwatch, bb, profile)
 	searchUtil += officerOrderBias(GoalSearch, bb, profile)
 
+	// --- Weapon: the job the soldier's weapon suits ---
+	advanceUtil += weaponGoalBias(GoalAdvance, bb)
+	holdUtil += weaponGoalBias(GoalHoldPosition, bb)
+	engageUtil += weaponGoalBias(GoalEngage, bb)
+	moveToContactUtil += weaponGoalBias(GoalMoveToContact, bb)
+	flankUtil += weaponGoalBias(GoalFlank, bb)
+	overwatchUtil += weaponGoalBias(GoalOverwatch, bb)
+
+	// --- ThrowGrenade: dislodge enemies in cover or clear a room before entry. ---
+	grenadeUtil := grenadeUtility(bb, profile)
+
 	// --- Pick highest utility ---
 	best := GoalAdvance
 	bestVal := advanceUtil
@@ -1640,6 +1778,7 @@ func SelectGoal(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath
 	check(GoalPeek, peekUtil)
 	check(GoalHelpCasualty, helpCasualtyUtil)
 	check(GoalSearch, searchUtil)
+	check(GoalThrowGrenade, grenadeUtil)
 
 	return best
 }
@@ -1666,8 +1805,8 @@ func SelectGoalWithHysteresis(bb *Blackboard, profile *SoldierProfile, isLeader
 	}
 
 	// Compare utilities: candidate must beat current by margin to switch.
-	currentUtil := goalUtilSingle(bb, profile, isLeader, hasPath, bb.CurrentGoal)
-	candidateUtil := goalUtilSingle(bb, profile, isLeader, hasPath, candidate)
+	currentUtil := goalUtilSingle(bb, profile, isLeader, hasPath, bb.CurrentGoal) + weaponGoalBias(bb.CurrentGoal, bb)
+	candidateUtil := goalUtilSingle(bb, profile, isLeader, hasPath, candidate) + weaponGoalBias(candidate, bb)
 
 	if candidateUtil > currentUtil+margin {
 		return candidate
@@ -1681,6 +1820,7 @@ func SelectGoalWithHysteresis(bb *Blackboard, profile *SoldierProfile, isLeader
 func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasPath bool, goal GoalKind) float64 {
 	bb.ensureInternalDefaults()
 	internal := bb.Internal
+	accurateRange, maxRange, _ := bb.config().weaponRanges(bb.firearm())
 
 	visibleThreats := bb.VisibleThreatCount()
 	underFire := bb.IncomingFireCount > 0 || bb.IsSuppressed()
@@ -1711,7 +1851,7 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 			if internal.ShotMomentum >= internal.Thresholds.HoldOnHitMomentum {
 				u += 0.15
 			}
-			if internal.LastRange > accurateFireRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality {
+			if internal.LastRange > accurateRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality {
 				u -= 0.18
 			}
 			u -= ef * 0.30
@@ -1722,6 +1862,7 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 			if posture > 0 {
 				u += posture * 0.15
 			}
+			u -= ammoEngagePenalty(bb)
 		}
 		if withdrawIntent {
 			u -= 0.20
@@ -1783,11 +1924,11 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 
 	case GoalMoveToContact:
 		u := 0.0
-		lowQ := visibleThreats > 0 && internal.LastRange > accurateFireRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
+		lowQ := visibleThreats > 0 && internal.LastRange > accurateRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
 		if anyContact && (visibleThreats == 0 || lowQ) {
 			u = 0.50 + profile.Skills.Discipline*0.1 + internal.MoveDesire*0.30
 			if lowQ {
-				rp := clamp01((internal.LastRange - accurateFireRange) / accurateFireRange)
+				rp := clamp01((internal.LastRange - accurateRange) / accurateRange)
 				u += 0.20 + rp*0.25
 			}
 			if hasAudioContact && !bb.SquadHasContact {
@@ -1826,7 +1967,7 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 
 	case GoalFlank:
 		u := 0.0
-		lowQ := visibleThreats > 0 && internal.LastRange > accurateFireRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
+		lowQ := visibleThreats > 0 && internal.LastRange > accurateRange && internal.LastEstimatedHitChance < internal.Thresholds.LongRangeShotQuality
 		if anyContact && !bb.FlankComplete {
 			if visibleThreats == 0 {
 				u = 0.35 + profile.Skills.Fieldcraft*0.35 + internal.MoveDesire*0.20
@@ -1878,7 +2019,7 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 			u -= 0.20
 		}
 		if anyContact && visibleThreats == 0 {
-			u *= overwatchDistanceFactor(internal.LastContactRange)
+			u *= overwatchDistanceFactor(internal.LastContactRange, maxRange)
 		}
 		if bb.SquadIntent == IntentEngage && visibleThreats == 0 {
 			u -= 0.25
@@ -2022,6 +2163,9 @@ func goalUtilSingle(bb *Blackboard, profile *SoldierProfile, isLeader bool, hasP
 			}
 		}
 		return u + orderBias
+
+	case GoalThrowGrenade:
+		return grenadeUtility(bb, profile)
 	}
 	return 0
 }
@@ -280,9 +280,9 @@ func TestSelectGoal_EngageIntentNoLOS_PrefersMoveToContactOverOverwatch(t *testi
 }
 
 func TestOverwatchDistanceFactor_AttenuatesFarContact(t *testing.T) {
-	near := overwatchDistanceFactor(float64(maxFireRange) * 0.9)
-	far := overwatchDistanceFactor(float64(maxFireRange) * 1.8)
-	veryFar := overwatchDistanceFactor(float64(maxFireRange) * 2.2)
+	near := overwatchDistanceFactor(float64(maxFireRange)*0.9, maxFireRange)
+	far := overwatchDistanceFactor(float64(maxFireRange)*1.8, maxFireRange)
+	veryFar := overwatchDistanceFactor(float64(maxFireRange)*2.2, maxFireRange)
 
 	if near < 0.95 {
 		t.Fatalf("near contact should keep overwatch appeal high, got %.3f", near)
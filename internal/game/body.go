@@ -143,6 +143,8 @@ type Wound struct {
 	Treated       bool    // true once bleeding is controlled
 	TreatedTick   int
 	TickInflicted int
+	Stopped       bool   // armour stopped the round; the wound is blunt trauma only
+	Armour        string // name of the armour piece the round struck, if any
 }
 
 // ---------------------------------------------------------------------------
@@ -158,6 +160,9 @@ type BodyMap struct {
 
 	// BloodVolume represents circulating blood as a fraction (1.0 = full).
 	BloodVolume float64
+
+	// Armour is the protection worn, checked in order for each hit.
+	Armour []ArmourPiece
 }
 
 // NewBodyMap returns a fully healthy body with no wounds.
@@ -180,10 +185,19 @@ const bleedToBloodFactor = 0.0045
 // ---------------------------------------------------------------------------
 
 // ApplyHit resolves a bullet impact. It rolls a body region from the stance-
-// weighted table (with optional cover masking), creates a Wound, reduces
-// region HP, and returns the wound plus whether the soldier died instantly.
+// weighted table (with optional cover masking), lets any armour over the
+// region stop or blunt the round, creates a Wound, reduces region HP, and
+// returns the wound plus whether the soldier died instantly. A round the
+// armour stops leaves a Stopped wound with blunt-trauma pain and no HP loss.
 func (bm *BodyMap) ApplyHit(damage float64, stance Stance, coverMask [regionCount]float64, tick int, rng *rand.Rand) (Wound, bool) {
 	region := rollHitRegion(stance, coverMask, rng)
+	ah := bm.strikeArmour(region, damage, rng)
+	if ah.stopped {
+		w := stoppedWound(ah.piece, region, damage, tick)
+		bm.Wounds = append(bm.Wounds, w)
+		return w, false
+	}
+	damage = ah.damage
 	severity := determineSeverity(damage, region, rng)
 
 	w := Wound{
@@ -193,6 +207,9 @@ func (bm *BodyMap) ApplyHit(damage float64, stance Stance, coverMask [regionCoun
 		Pain:          severityPain[severity],
 		TickInflicted: tick,
 	}
+	if ah.piece != nil {
+		w.Armour = ah.piece.Name
+	}
 	bm.Wounds = append(bm.Wounds, w)
 
 	bm.HP[region] -= damage
@@ -430,10 +447,11 @@ func (bm *BodyMap) CanSelfAid(conscious bool) bool {
 	return bm.HP[RegionArmLeft] > 0 || bm.HP[RegionArmRight] > 0
 }
 
-// HasUntreatedWounds returns true if any wound is still bleeding.
+// HasUntreatedWounds returns true if any wound is still bleeding. Rounds
+// stopped by armour need no treatment.
 func (bm *BodyMap) HasUntreatedWounds() bool {
 	for i := range bm.Wounds {
-		if !bm.Wounds[i].Treated {
+		if !bm.Wounds[i].Treated && !bm.Wounds[i].Stopped {
 			return true
 		}
 	}
@@ -446,12 +464,12 @@ func (bm *BodyMap) WoundCount() int {
 }
 
 // WorstUntreatedWound returns the untreated wound with the highest bleed rate,
-// or nil if all wounds are treated.
+// or nil if all wounds are treated. Rounds stopped by armour are skipped.
 func (bm *BodyMap) WorstUntreatedWound() *Wound {
 	var worst *Wound
 	for i := range bm.Wounds {
 		w := &bm.Wounds[i]
-		if w.Treated {
+		if w.Treated || w.Stopped {
 			continue
 		}
 		if worst == nil || w.BleedRate > worst.BleedRate {
@@ -22,8 +22,18 @@ type BuildingEntryPlan struct {
 	OverwatchTeam     []*Soldier // soldiers providing cover
 	EntryPointX       float64    // door/breach point
 	EntryPointY       float64
-	InitiatedTick     int
-	StateChangeTick   int
+	// StackX, StackY is where the entry team stacks up outside the entry
+	// point; InsideX, InsideY is the first point they make for once in.
+	StackX, StackY   float64
+	InsideX, InsideY float64
+	// WallX, WallY runs along the entry wall; entry-team members stack in a
+	// line along it.
+	WallX, WallY float64
+	// DoorCol, DoorRow is the door the entry team breaches, when HasDoor.
+	DoorCol, DoorRow int
+	HasDoor          bool
+	InitiatedTick    int
+	StateChangeTick  int
 }
 
 // CreateEntryPlan designates entry and overwatch teams for building assault.
@@ -100,14 +110,29 @@ func CreateEntryPlan(
 	// Find entry point (door closest to squad)
 	fp := footprints[buildingIdx]
 	entryX, entryY := findBestEntryPoint(fp, alive, enemyBearing)
+	doorCol, doorRow, hasDoor := findEntryDoor(alive[0].tileMap, fp, entryX, entryY)
+	if hasDoor {
+		entryX, entryY = CellToWorld(doorCol, doorRow)
+	}
+	entryX, entryY, nx, ny := entryWallPoint(fp, entryX, entryY, hasDoor)
+	stackX, stackY := entryStackPoint(alive[0].navGrid, entryX, entryY, nx, ny)
 
 	return &BuildingEntryPlan{
+		DoorCol:           doorCol,
+		DoorRow:           doorRow,
+		HasDoor:           hasDoor,
 		TargetBuildingIdx: buildingIdx,
 		State:             EntryStateApproaching,
 		EntryTeam:         entryTeam,
 		OverwatchTeam:     overwatchTeam,
 		EntryPointX:       entryX,
 		EntryPointY:       entryY,
+		StackX:            stackX,
+		StackY:            stackY,
+		InsideX:           entryX - nx*entryInsideDist,
+		InsideY:           entryY - ny*entryInsideDist,
+		WallX:             -ny,
+		WallY:             nx,
 		InitiatedTick:     tick,
 		StateChangeTick:   tick,
 	}
@@ -141,6 +166,68 @@ func findBestEntryPoint(
 	return entryX, entryY
 }
 
+const (
+	entryStackDist   = 2 * cellSize    // px; how far outside the entry point the team stacks
+	entryInsideDist  = 2 * cellSize    // px; how far past the entry point the team first makes for
+	entryStackSpread = 0.75 * cellSize // px; spacing between stacked entry-team members
+	entryHoldRadius  = cellSize        // px; close enough to a stack or entry point to stop
+)
+
+// entryWallPoint returns the point on fp's outer wall nearest (x, y) and the
+// outward normal of that wall. A door already sits in the wall and keeps its
+// position; any other entry point is moved onto the wall.
+func entryWallPoint(fp rect, x, y float64, onWall bool) (wx, wy, nx, ny float64) {
+	x0, y0 := float64(fp.x), float64(fp.y)
+	x1, y1 := float64(fp.x+fp.w), float64(fp.y+fp.h)
+	half := float64(cellSize) / 2
+	best := x - x0
+	wx, wy, nx, ny = x0+half, y, -1, 0
+	if d := x1 - x; d < best {
+		best = d
+		wx, wy, nx, ny = x1-half, y, 1, 0
+	}
+	if d := y - y0; d < best {
+		best = d
+		wx, wy, nx, ny = x, y0+half, 0, -1
+	}
+	if d := y1 - y; d < best {
+		wx, wy, nx, ny = x, y1-half, 0, 1
+	}
+	if onWall {
+		return x, y, nx, ny
+	}
+	return wx, wy, nx, ny
+}
+
+// entryStackPoint returns a walkable point entryStackDist out from the entry
+// point (x, y) along the wall normal (nx, ny), stepping further out when
+// that cell is blocked.
+func entryStackPoint(ng *NavGrid, x, y, nx, ny float64) (float64, float64) {
+	sx, sy := x+nx*entryStackDist, y+ny*entryStackDist
+	if ng == nil {
+		return sx, sy
+	}
+	for i := 0; i < 3; i++ {
+		px, py := sx+nx*float64(i*cellSize), sy+ny*float64(i*cellSize)
+		if c, r := WorldToCell(px, py); !ng.IsBlocked(c, r) {
+			return px, py
+		}
+	}
+	return sx, sy
+}
+
+// stackPointFor returns where entry-team member s stacks: the members stand
+// in a line along the wall, centred on the plan's stack point.
+func (plan *BuildingEntryPlan) stackPointFor(s *Soldier) (float64, float64) {
+	for i, m := range plan.EntryTeam {
+		if m == s {
+			off := (float64(i) - float64(len(plan.EntryTeam)-1)/2) * entryStackSpread
+			return plan.StackX + plan.WallX*off, plan.StackY + plan.WallY*off
+		}
+	}
+	return plan.StackX, plan.StackY
+}
+
 // UpdateEntryState advances the entry plan based on team positions and readiness.
 func (plan *BuildingEntryPlan) UpdateEntryState(tick int, footprints []rect) {
 	if plan == nil || plan.TargetBuildingIdx >= len(footprints) {
@@ -149,14 +236,15 @@ func (plan *BuildingEntryPlan) UpdateEntryState(tick int, footprints []rect) {
 
 	switch plan.State {
 	case EntryStateApproaching:
-		// Check if entry team is near entry point
+		// Check if entry team is near entry point or in its place in the stack
 		allNear := true
 		for _, s := range plan.EntryTeam {
-			if s.state == SoldierStateDead {
+			if s.state == SoldierStateDead || s.state.IsIncapacitated() {
 				continue
 			}
 			dist := math.Hypot(s.x-plan.EntryPointX, s.y-plan.EntryPointY)
-			if dist > float64(cellSize)*3 {
+			sx, sy := plan.stackPointFor(s)
+			if dist > float64(cellSize)*3 && math.Hypot(s.x-sx, s.y-sy) > entryHoldRadius {
 				allNear = false
 				break
 			}
@@ -219,6 +307,108 @@ func (plan *BuildingEntryPlan) UpdateEntryState(tick int, footprints []rect) {
 	}
 }
 
+// stageFor returns the entry stage s should act on: the plan state for entry
+// team members, EntryStateNone for everyone else.
+func (plan *BuildingEntryPlan) stageFor(s *Soldier) BuildingEntryState {
+	if plan == nil {
+		return EntryStateNone
+	}
+	for _, m := range plan.EntryTeam {
+		if m == s {
+			return plan.State
+		}
+	}
+	return EntryStateNone
+}
+
+// updateEntryPlan starts, advances and retires the squad's coordinated entry
+// into its claimed building, then publishes each member's entry stage.
+func (sq *Squad) updateEntryPlan(tick int, hasContact bool) {
+	if sq.buildingIntel == nil {
+		return
+	}
+	idx := sq.ClaimedBuildingIdx
+	if plan := sq.entryPlan; plan != nil {
+		if plan.State == EntryStateSecured {
+			sq.buildingIntel.MarkCleared(plan.TargetBuildingIdx, tick)
+			sq.entryPlan = nil
+		} else if plan.TargetBuildingIdx != idx {
+			sq.entryPlan = nil
+		}
+	}
+	if sq.entryPlan == nil && idx >= 0 {
+		cx, cy := sq.squadCentroid()
+		if ShouldInitiateEntry(idx, sq.buildingFootprints, sq.buildingIntel, cx, cy, hasContact, sq.Phase) {
+			sq.entryPlan = CreateEntryPlan(idx, sq.buildingFootprints, sq.Members, sq.EnemyBearing, tick)
+		}
+	}
+	sq.entryPlan.UpdateEntryState(tick, sq.buildingFootprints)
+	sq.entryPlan.breachEntryDoor()
+	for _, m := range sq.Members {
+		bb := &m.blackboard
+		bb.EntryStage = sq.entryPlan.stageFor(m)
+		if bb.EntryStage != EntryStateNone {
+			bb.EntryStackX, bb.EntryStackY = sq.entryPlan.stackPointFor(m)
+			bb.EntryInsideX, bb.EntryInsideY = sq.entryPlan.InsideX, sq.entryPlan.InsideY
+		}
+	}
+}
+
+// shouldStackForEntry reports whether s is on an entry team that is moving
+// up, stacking or going in, and should leave its own goal for that. A grenade
+// thrown from the stack, aid to a casualty and falling back come first.
+func (s *Soldier) shouldStackForEntry(goal GoalKind) bool {
+	switch s.blackboard.EntryStage {
+	case EntryStateApproaching, EntryStateStacking, EntryStateBreaching:
+	default:
+		return false
+	}
+	switch goal {
+	case GoalThrowGrenade, GoalHelpCasualty, GoalFallback:
+		return false
+	}
+	return true
+}
+
+// moveToEntryStack moves s to its place in the stack and holds there facing
+// the entry until the plan breaches, then goes in through the entry. It
+// reports false once s is through, leaving room clearing to its goals.
+func (s *Soldier) moveToEntryStack(dt float64) bool {
+	bb := &s.blackboard
+	targetX, targetY := bb.EntryStackX, bb.EntryStackY
+	if bb.EntryStage == EntryStateBreaching {
+		targetX, targetY = bb.EntryInsideX, bb.EntryInsideY
+	}
+	if math.Hypot(targetX-s.x, targetY-s.y) <= entryHoldRadius {
+		if bb.EntryStage == EntryStateBreaching {
+			return false
+		}
+		s.state = SoldierStateIdle
+		s.path = nil
+		s.pathIndex = 0
+		s.requestStance(StanceCrouching, false)
+		s.vision.UpdateHeading(math.Atan2(bb.EntryInsideY-s.y, bb.EntryInsideX-s.x), turnRate)
+		return true
+	}
+
+	drift := math.Hypot(targetX-s.slotTargetX, targetY-s.slotTargetY)
+	if s.path == nil || s.pathIndex >= len(s.path) || drift > entryHoldRadius {
+		newPath := s.navGrid.FindPath(s.x, s.y, targetX, targetY)
+		if newPath == nil {
+			return false
+		}
+		s.path = newPath
+		s.pathIndex = 0
+		s.slotTargetX = targetX
+		s.slotTargetY = targetY
+	}
+
+	s.requestStance(StanceCrouching, true)
+	s.state = SoldierStateMoving
+	s.moveAlongPath(dt)
+	return true
+}
+
 // GetOptimalDefensivePosition finds the best position within a building for defense.
 // Considers: window coverage, corner positions, sector assignment, enemy bearing.
 func GetOptimalDefensivePosition(
@@ -477,6 +477,93 @@ func TestBuildingEntry_ProgressesStates(t *testing.T) {
 	}
 }
 
+// newEntryTestBattlefield builds a 640x480 field with one 12x12-cell
+// building whose west wall has a doorway at rows 12-15 and a door at
+// (20, 14), the way generated buildings are laid out.
+func newEntryTestBattlefield(locked bool) *HeadlessBattlefield {
+	const w, h = 640, 480
+	fp := rect{x: 20 * cellSize, y: 8 * cellSize, w: 12 * cellSize, h: 12 * cellSize}
+	tm := NewTileMap(w/cellSize, h/cellSize)
+	var walls []rect
+	for r := 8; r <= 19; r++ {
+		for c := 20; c <= 31; c++ {
+			tm.SetGround(c, r, GroundConcrete)
+			tm.AddFlag(c, r, TileFlagIndoor)
+			if r != 8 && r != 19 && c != 20 && c != 31 {
+				continue
+			}
+			if c == 20 && r >= 12 && r <= 15 {
+				continue // doorway
+			}
+			tm.SetObject(c, r, ObjectWall)
+			walls = append(walls, rect{x: c * cellSize, y: r * cellSize, w: cellSize, h: cellSize})
+		}
+	}
+	tm.SetObject(20, 14, ObjectDoor)
+	if locked {
+		tm.AddFlag(20, 14, TileFlagLocked)
+	}
+	ng := NewNavGrid(w, h, walls, soldierRadius, nil, nil)
+	ng.syncDoors(tm)
+	return &HeadlessBattlefield{
+		Width:              w,
+		Height:             h,
+		TileMap:            tm,
+		Buildings:          walls,
+		BuildingFootprints: []rect{fp},
+		NavGrid:            ng,
+		TacticalMap:        NewTacticalMap(w, h, walls, nil, []rect{fp}),
+	}
+}
+
+// newEntryTestSim puts a four-man red squad west of the entry test building,
+// with a blue soldier in sight to the north-east, and gives the squad the
+// building.
+func newEntryTestSim(bf *HeadlessBattlefield) *TestSim {
+	ts := NewTestSim(
+		WithSeed(1),
+		WithHeadlessBattlefield(bf),
+		WithRedSoldier(0, 200, 180, 600, 180),
+		WithRedSoldier(1, 200, 210, 600, 210),
+		WithRedSoldier(2, 220, 240, 600, 240),
+		WithRedSoldier(3, 180, 200, 600, 200),
+		WithBlueSoldier(4, 560, 90, 560, 90),
+		WithRedSquad(0, 1, 2, 3),
+		WithBlueSquad(4),
+	)
+	ts.squads[0].ClaimedBuildingIdx = 0
+	return ts
+}
+
+func TestBuildingEntry_SquadReachesBreachingUnaided(t *testing.T) {
+	ts := newEntryTestSim(newEntryTestBattlefield(false))
+	sq := ts.squads[0]
+	stacked := false
+	ts.RunUntil(func(ts *TestSim) bool {
+		if sq.entryPlan == nil {
+			return false
+		}
+		stacked = stacked || sq.entryPlan.State == EntryStateStacking
+		return sq.entryPlan.State >= EntryStateBreaching
+	}, 1200)
+
+	plan := sq.entryPlan
+	if plan == nil {
+		t.Fatal("squad never started an entry plan")
+	}
+	if plan.State < EntryStateBreaching {
+		t.Fatalf("entry plan stuck at state %d, want breaching", plan.State)
+	}
+	if !stacked {
+		t.Error("entry team breached without stacking first")
+	}
+	for _, m := range plan.EntryTeam {
+		if d := math.Hypot(m.x-plan.EntryPointX, m.y-plan.EntryPointY); d > 4*cellSize {
+			t.Errorf("entry-team soldier %d is %.0fpx from the entry point at breach", m.id, d)
+		}
+	}
+}
+
 func TestGetOptimalDefensivePosition_FindsWindowPositions(t *testing.T) {
 	building := rect{x: 300, y: 300, w: 96, h: 96}
 
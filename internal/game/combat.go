@@ -13,17 +13,17 @@ import (
 // --- Combat constants ---
 
 const (
-	soldierMaxHP        = 100.0              // starting health
-	accurateFireRange   = 450.0              // px, reliable engagement envelope (first half of rifle range)
-	maxFireRange        = 900.0              // px, max rifle range (last half is pot-shot territory)
-	potShotMaxFireRange = maxFireRange * 2.0 // px, extended pot-shot engagement envelope
-	tracerLifetime      = 10                 // ticks a tracer persists
-	tracerSpeed         = 3.0                // bullet travels full distance in this many ticks (faster = more visible)
-	nearMissStress      = 0.08               // fear added to target on miss
-	hitStress           = 0.20               // fear added to target on hit
-	witnessStress       = 0.03               // fear added to nearby friendlies seeing a hit
-	witnessRadius       = 80.0               // px radius for witness stress
-	flashLifetime       = 4                  // ticks a muzzle flash persists
+	soldierMaxHP      = 100.0 // starting health
+	accurateFireRange = 450.0 // px, reliable engagement envelope (first half of rifle range)
+	maxFireRange      = 900.0 // px, max rifle range (last half is pot-shot territory)
+	potShotRangeMul   = 2.0   // pot shots reach this multiple of a weapon's max range
+	tracerLifetime    = 10    // ticks a tracer persists
+	tracerSpeed       = 3.0   // bullet travels full distance in this many ticks (faster = more visible)
+	nearMissStress    = 0.08  // fear added to target on miss
+	hitStress         = 0.20  // fear added to target on hit
+	witnessStress     = 0.03  // fear added to nearby friendlies seeing a hit
+	witnessRadius     = 80.0  // px radius for witness stress
+	flashLifetime     = 4     // ticks a muzzle flash persists
 
 	// Per fire-mode fire intervals (ticks between trigger pulls).
 	// Single: deliberate, slow. Burst: semi-rapid. Auto: rapid.
@@ -58,11 +58,13 @@ const (
 	gunfireMinHeardStrength = 0.12
 )
 
-// shotRangePenalty returns accuracy loss for the given shot distance.
+// shotRangePenalty returns accuracy loss for a shot from weapon w (nil = the
+// rifle) at the given distance.
 // Short range has a BONUS (negative penalty — closer = easier to hit).
 // Inside CQB range, the shooter simply cannot miss much.
-// Beyond accurateFireRange, penalties ramp hard in the pot-shot band.
-func shotRangePenalty(dist float64) float64 {
+// Beyond the accurate fire range, penalties ramp hard in the pot-shot band.
+func (c *SimConfig) shotRangePenalty(w *Weapon, dist float64) float64 {
+	accurateFireRange, maxFireRange, potShotMaxFireRange := c.weaponRanges(w)
 	if dist <= 0 {
 		return -0.32 // point-blank: strong bonus
 	}
@@ -85,15 +87,16 @@ func shotRangePenalty(dist float64) float64 {
 	return 0.12 + 0.66*math.Pow(t, 1.15)
 }
 
-func potShotFactor(dist float64) float64 {
-	if dist <= accurateFireRange {
+func (c *SimConfig) potShotFactor(w *Weapon, dist float64) float64 {
+	accurate, _, potShotMax := c.weaponRanges(w)
+	if dist <= accurate {
 		return 0
 	}
-	return clamp01((dist - accurateFireRange) / (potShotMaxFireRange - accurateFireRange))
+	return clamp01((dist - accurate) / (potShotMax - accurate))
 }
 
 func shouldDeliberatelyAimLongRange(s *Soldier, dist, pressure float64) bool {
-	pot := potShotFactor(dist)
+	pot := s.config().potShotFactor(s.firearm(), dist)
 	aimPreference := 0.80 - pressure*0.75 + s.profile.Skills.Discipline*0.18 + (1.0-pot)*0.08
 	return aimPreference > 0.50
 }
@@ -108,24 +111,14 @@ type fireModeParams struct {
 	missScatter float64 // miss endpoint scatter radius
 }
 
-// fireModeTable maps each FireMode to its parameters.
+// fireModeTable maps each FireMode to its parameters for the standard rifle.
+// Other weapons carry their own (see weaponTable).
 var fireModeTable = map[FireMode]fireModeParams{
 	FireModeSingle: {shots: 1, interval: fireIntervalSingle, spreadRad: 0, accMul: 1.0, damageMul: 1.0, missScatter: missScatterSingle},
 	FireModeBurst:  {shots: 3, interval: fireIntervalBurst, spreadRad: 0.04, accMul: 0.85, damageMul: 1.0, missScatter: missScatterBurst},
 	FireModeAuto:   {shots: 5, interval: fireIntervalAuto, spreadRad: 0.09, accMul: 0.70, damageMul: 1.0, missScatter: missScatterAuto},
 }
 
-// cqbDamageMul returns an extra damage multiplier for close-range fights.
-// Represents higher hit probability on vital areas and terminal ballistics at short range.
-// Uses a smooth fuzzy ramp: 1.0 at cqbRange, 1.8 at point-blank.
-func cqbDamageMul(dist float64) float64 {
-	if dist >= cqbRange {
-		return 1.0
-	}
-	t := 1.0 - dist/cqbRange // 0 at cqbRange, 1 at 0
-	return 1.0 + 0.8*t       // 1.0 → 1.8
-}
-
 // --- Tracer ---
 
 // Tracer is a short-lived visual representing a bullet flight path.
@@ -234,9 +227,21 @@ func (t *Tracer) DrawTracer(screen *ebiten.Image, offX, offY int) {
 
 // GunfireEvent records a shot being fired, for sound propagation.
 type GunfireEvent struct {
-	X, Y float64
-	Team Team
-	Tick int
+	X, Y    float64
+	Team    Team
+	Tick    int
+	Shooter *Soldier // rifleman who fired; nil for explosions
+}
+
+// ShotEvent records one resolved bullet for the replay recorder.
+type ShotEvent struct {
+	ShooterID int
+	TargetID  int
+	FromX     float64
+	FromY     float64
+	ToX       float64
+	ToY       float64
+	Hit       bool
 }
 
 // --- Combat Manager ---
@@ -246,14 +251,36 @@ type CombatManager struct {
 	tracers  []*Tracer
 	flashes  []*MuzzleFlash
 	Gunfires []GunfireEvent // shots fired this tick, consumed by sound system
-	rng      *rand.Rand
-	tick     int // current game tick, set each frame before ResolveCombat
+	Shots    []ShotEvent    // bullets resolved this tick, consumed by the replay recorder
+	// Detonations lists grenade bursts resolved this tick.
+	Detonations []DetonationEvent
+	grenades    []*Grenade
+	blasts      []*blast
+	// Smoke is the obscurant field shared with every soldier's vision.
+	Smoke *SmokeField
+	// Sound carries gunfire, footsteps, shouts and doors to listeners.
+	Sound *SoundField
+	// fired holds this tick's bullets between aiming and landing.
+	fired  []firedShot
+	rng    *rand.Rand
+	rngSrc *countingSource // rng's source, for save-state checks
+	tick   int             // current game tick, set each frame before ResolveCombat
+	cfg    *SimConfig      // balance constants, nil = defaults
+	// tileMap, navGrid and tactical are the terrain fire can break (see
+	// penetration.go); nil on a bare manager.
+	tileMap  *TileMap
+	navGrid  *NavGrid
+	tactical *TacticalMap
 }
 
 // NewCombatManager creates a combat manager with its own RNG.
 func NewCombatManager(seed int64) *CombatManager {
+	src := newCountingSource(seed)
 	return &CombatManager{
-		rng: rand.New(rand.NewSource(seed)), // #nosec G404 -- game only
+		rng:    rand.New(src), // #nosec G404 -- game only
+		rngSrc: src,
+		Smoke:  NewSmokeField(seed),
+		Sound:  NewSoundField(),
 	}
 }
 
@@ -266,154 +293,56 @@ func (cm *CombatManager) ResetFireCounts(soldiers []*Soldier) {
 		s.blackboard.DecayCombatMemory()
 	}
 	cm.Gunfires = cm.Gunfires[:0]
+	cm.Shots = cm.Shots[:0]
 }
 
-// BroadcastGunfire writes heard-gunfire info to enemy soldiers using a
-// distance + occlusion + fieldcraft hearing model, then stamps persistent
-// combat memory so they remain activated for ~60s.
-func (cm *CombatManager) BroadcastGunfire(red, blue []*Soldier, tick int) {
-	for _, ev := range cm.Gunfires {
-		var listeners []*Soldier
-		if ev.Team == TeamRed {
-			listeners = blue
-		} else {
-			listeners = red
-		}
-		for _, s := range listeners {
-			if s.state == SoldierStateDead {
-				continue
-			}
-			heardStrength := gunfireHeardStrength(ev.X, ev.Y, s, red, blue)
-			if heardStrength < gunfireMinHeardStrength {
-				continue
-			}
-			// Single-tick flag — used by immediate decision logic.
-			s.blackboard.HeardGunfireX = ev.X
-			s.blackboard.HeardGunfireY = ev.Y
-			s.blackboard.HeardGunfire = true
-			s.blackboard.HeardGunfireTick = tick
-			// Persistent memory — keeps soldier activated for ~60s after last shot.
-			s.blackboard.RecordGunfireWithStrength(ev.X, ev.Y, heardStrength)
-		}
-		// Shooters also remember they fired (self-activation).
-		var shooters []*Soldier
-		if ev.Team == TeamRed {
-			shooters = red
-		} else {
-			shooters = blue
-		}
-		for _, s := range shooters {
-			if s.state == SoldierStateDead {
-				continue
-			}
-			s.blackboard.RecordGunfireWithStrength(ev.X, ev.Y, 1.0)
-		}
-	}
+// firedShot is a bullet between the two combat phases: the shooter's roll
+// has been made but nothing has happened to the target yet.
+type firedShot struct {
+	shooter *Soldier
+	target  *Soldier
+	shotIdx int
+	hit     bool
+	// coverStrike is a round that would have hit the part of the target
+	// hidden by cover, and struck the cover instead.
+	coverStrike bool
+	fromX       float64
+	fromY       float64
+	toX         float64
+	toY         float64
+	damage      float64
+	witnesses   []*Soldier // shooter's team, for witness stress
 }
 
-// BroadcastGunfireSpatial uses spatial hashing for optimized gunfire propagation.
-// Only checks soldiers within hearing range instead of all soldiers.
-func (cm *CombatManager) BroadcastGunfireSpatial(redHash, blueHash *SpatialHash, red, blue []*Soldier, tick int) {
-	for _, ev := range cm.Gunfires {
-		var listenerHash *SpatialHash
-		if ev.Team == TeamRed {
-			listenerHash = blueHash
-		} else {
-			listenerHash = redHash
-		}
-
-		// Query only soldiers within hearing range using spatial hash.
-		nearbyListeners := listenerHash.QueryRadius(ev.X, ev.Y, gunfireHearingMaxRange)
-
-		for _, s := range nearbyListeners {
-			if s.state == SoldierStateDead {
-				continue
-			}
-			heardStrength := gunfireHeardStrength(ev.X, ev.Y, s, red, blue)
-			if heardStrength < gunfireMinHeardStrength {
-				continue
-			}
-			// Single-tick flag — used by immediate decision logic.
-			s.blackboard.HeardGunfireX = ev.X
-			s.blackboard.HeardGunfireY = ev.Y
-			s.blackboard.HeardGunfire = true
-			s.blackboard.HeardGunfireTick = tick
-			// Persistent memory — keeps soldier activated for ~60s after last shot.
-			s.blackboard.RecordGunfireWithStrength(ev.X, ev.Y, heardStrength)
-		}
-
-		// Shooters also remember they fired (self-activation).
-		var shooters []*Soldier
-		if ev.Team == TeamRed {
-			shooters = red
-		} else {
-			shooters = blue
-		}
-		for _, s := range shooters {
-			if s.state == SoldierStateDead {
-				continue
-			}
-			s.blackboard.RecordGunfireWithStrength(ev.X, ev.Y, 1.0)
-		}
-	}
+// ResolveCombat runs fire decisions for one set of shooters against a set of targets
+// and lands their bullets straight away.
+// allFriendlies is the same-team list (for witness stress propagation).
+// allSoldiers is every soldier on the map (for ricochet near-miss stress).
+func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldier, buildings []rect, allSoldiers []*Soldier) {
+	cm.fireShots(shooters, allFriendlies, buildings, allSoldiers)
+	cm.landShots(buildings, allSoldiers)
 }
 
-func gunfireHeardStrength(srcX, srcY float64, listener *Soldier, red, blue []*Soldier) float64 {
-	dx := srcX - listener.x
-	dy := srcY - listener.y
-	dist := math.Hypot(dx, dy)
-	if dist > gunfireHearingMaxRange {
-		return 0
-	}
-
-	// Distance falloff with a small floor inside range.
-	distanceFactor := 1.0 - dist/gunfireHearingMaxRange
-	if distanceFactor < 0.10 {
-		distanceFactor = 0.10
-	}
-
-	// Building occlusion muffles sound strongly.
-	occlusionFactor := 1.0
-	if !HasLineOfSightWithCover(srcX, srcY, listener.x, listener.y, listener.buildings, listener.covers) {
-		occlusionFactor = gunfireOccludedMul
-	}
-
-	// Fieldcraft slightly improves auditory cue extraction.
-	fieldcraft := 0.0
-	if listener.profile.Skills.Fieldcraft > 0 {
-		fieldcraft = listener.profile.Skills.Fieldcraft
-	}
-	fieldcraftFactor := 0.85 + fieldcraft*0.30
-
-	// Nearby allied listeners reinforce confidence in the heard direction.
-	allies := red
-	if listener.team == TeamBlue {
-		allies = blue
-	}
-	nearbyAllies := 0
-	for _, a := range allies {
-		if a == nil || a == listener || a.state == SoldierStateDead {
-			continue
-		}
-		if math.Hypot(a.x-listener.x, a.y-listener.y) <= 220 {
-			nearbyAllies++
-		}
-	}
-	allyBoost := 1.0 + math.Min(0.12, float64(nearbyAllies)*0.04)
-
-	return clamp01(distanceFactor * occlusionFactor * fieldcraftFactor * allyBoost)
+// ResolveExchange is one tick of fire from both sides at once. Every shooter
+// decides and pulls the trigger against the state at the start of the tick,
+// then all bullets land together, so neither team gets the first shot.
+func (cm *CombatManager) ResolveExchange(red, blue []*Soldier, buildings []rect, allSoldiers []*Soldier) {
+	cm.fireShots(red, red, buildings, allSoldiers)
+	cm.fireShots(blue, blue, buildings, allSoldiers)
+	cm.landShots(buildings, allSoldiers)
 }
 
-// ResolveCombat runs fire decisions for one set of shooters against a set of targets.
-// allFriendlies is the same-team list (for witness stress propagation).
-// allSoldiers is every soldier on the map (for ricochet near-miss stress).
-func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldier, buildings []rect, allSoldiers []*Soldier) {
+// fireShots is the first combat phase: shooters pick targets, spend rounds
+// and roll each bullet. Wounds, suppression and deaths wait for landShots.
+func (cm *CombatManager) fireShots(shooters, allFriendlies []*Soldier, buildings []rect, allSoldiers []*Soldier) {
+	cfg := cm.config()
 	for _, s := range shooters {
 		if s.state == SoldierStateDead || s.state.IsIncapacitated() {
 			continue
 		}
+		w := s.firearm()
 		if s.magCapacity <= 0 {
-			s.magCapacity = defaultMagazineCapacity
+			s.magCapacity = w.MagCapacity
 		}
 		if s.magRounds < 0 {
 			s.magRounds = 0
@@ -435,13 +364,25 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 		if s.reloadTimer > 0 {
 			s.reloadTimer--
 			if s.reloadTimer == 0 {
+				s.spareMags--
 				s.magRounds = s.magCapacity
-				s.think("reload complete")
+				s.think(fmt.Sprintf("reload complete — %d mags left", s.spareMags))
+			}
+			resetBurstState(s)
+			resetAimingState(s)
+			continue
+		}
+		if s.magRounds <= 0 && s.spareMags <= 0 {
+			// Dry: nothing to load until the squad passes a magazine across.
+			if !s.dryNoted {
+				s.dryNoted = true
+				s.think("out of ammo!")
 			}
 			resetBurstState(s)
 			resetAimingState(s)
 			continue
 		}
+		s.dryNoted = false
 		if s.magRounds <= 0 {
 			s.reloadTimer = s.reloadDurationTicks()
 			s.fireCooldown = 0
@@ -493,14 +434,16 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 		dx := target.x - s.x
 		dy := target.y - s.y
 		dist := math.Sqrt(dx*dx + dy*dy)
-		if dist > potShotMaxFireRange {
+		accurateRange, _, potShotRange := cfg.weaponRanges(w)
+		if dist > potShotRange {
 			resetBurstState(s)
 			resetAimingState(s)
 			continue
 		}
 
-		// LOS check (buildings and tall walls block firing lines).
-		if !HasLineOfSightWithCover(s.x, s.y, target.x, target.y, buildings, s.covers) {
+		// LOS check (buildings and tall walls block firing lines; so does dense smoke).
+		if !HasClearLineOfSight(s.x, s.y, target.x, target.y, buildings, s.covers, cm.Smoke) ||
+			(s.tileMap != nil && s.tileMap.closedDoorBetween(s.x, s.y, target.x, target.y)) {
 			resetBurstState(s)
 			resetAimingState(s)
 			continue
@@ -533,7 +476,7 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 		}
 
 		// --- Build fire parameters from current mode ---
-		params := fireModeTable[s.currentFireMode]
+		params := w.fireMode(s.currentFireMode)
 
 		// Turn to face target.
 		targetH := math.Atan2(dy, dx)
@@ -545,11 +488,14 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 		suppressSpread := s.blackboard.SuppressLevel * 0.14
 		fearSpread := s.profile.Psych.EffectiveFear() * 0.10
 		// Stance multiplier: prone tightens spread, standing widens.
-		stanceMul := 1.0 / math.Max(0.3, s.profile.Stance.Profile().AccuracyMul)
+		// The weapon scales it again: an LMG on its bipod is steadier than a rifle.
+		stanceMul := 1.0 / math.Max(0.3, s.profile.Stance.Profile().AccuracyMul) * s.weaponSpreadMul()
 		woundAccMul := math.Max(0.1, s.body.AccuracyMul()) // floor to avoid divide-by-zero
 		baseShooterSpread := (s.aimSpread + suppressSpread + fearSpread) * stanceMul / woundAccMul
 		// Distance-dependent spread: pot-shot band becomes substantially inaccurate.
-		baseShooterSpread += shotRangePenalty(dist) * 0.22
+		baseShooterSpread += cfg.shotRangePenalty(w, dist) * 0.22
+		// Smoke between shooter and target blurs the aim point.
+		baseShooterSpread *= 1 + smokeSpreadMul*cm.Smoke.Obscuration(s.x, s.y, target.x, target.y)
 		if queuedBurst && s.burstBaseSpread > 0 {
 			baseShooterSpread = s.burstBaseSpread
 		}
@@ -575,21 +521,23 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 		}
 		effBodyRadius := baseBodyRadius * (1.0 - coverReduction*0.7)
 
-		// Angular half-size of target at this range.
-		angularHalfSize := math.Atan2(effBodyRadius, math.Max(1, dist))
+		// Angular half-size of target at this range, exposed and whole. A
+		// shotgun's pellet pattern finds the target over a wider arc.
+		angularHalfSize := math.Atan2(effBodyRadius, math.Max(1, dist)) + w.Pattern
+		fullHalfSize := math.Atan2(baseBodyRadius, math.Max(1, dist)) + w.Pattern
 
 		// Expected hit probability for blackboard tracking (used by goal selection).
 		hitChance := clamp01(angularHalfSize / math.Max(0.01, baseShooterSpread+params.spreadRad))
 
 		if !queuedBurst {
 			// Long-range fire requires willingness to pull the trigger.
-			if dist > accurateFireRange {
+			if dist > accurateRange {
 				pressure := clamp01(
 					s.profile.Psych.EffectiveFear() +
 						s.blackboard.SuppressLevel*0.85 +
 						float64(s.blackboard.IncomingFireCount)*0.12,
 				)
-				pot := potShotFactor(dist)
+				pot := cfg.potShotFactor(w, dist)
 				temptation := clamp01(
 					0.34 +
 						s.blackboard.Internal.ShootDesire*0.48 +
@@ -605,7 +553,7 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 
 				if shouldDeliberatelyAimLongRange(s, dist, pressure) &&
 					s.blackboard.IncomingFireCount == 0 && s.blackboard.SuppressLevel < aimingSuppressionBlock {
-					requiredAimTicks := aimingTicksForDistance(dist)
+					requiredAimTicks := cfg.aimingTicksForDistance(w, dist)
 					requiredAimTicks += int(math.Round(float64(aimingBaseTicks) * pot * (1.0 - pressure) * 0.8))
 					if s.aimingTargetID != target.id {
 						s.aimingTargetID = target.id
@@ -633,8 +581,9 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 			baseShooterSpread = 0.005
 		}
 
-		// CQB damage multiplier — short range is much more lethal.
-		dmgMul := cqbDamageMul(dist) * params.damageMul
+		// CQB damage multiplier — short range is much more lethal — and the
+		// weapon's loss of punch at long range.
+		dmgMul := w.cqbDamageMul(dist) * w.falloffMul(dist) * params.damageMul
 
 		shotIdx := 0
 		if queuedBurst {
@@ -646,10 +595,11 @@ func (cm *CombatManager) ResolveCombat(shooters, targets, allFriendlies []*Soldi
 			s.magRounds = 0
 		}
 
-		cm.Gunfires = append(cm.Gunfires, GunfireEvent{X: s.x, Y: s.y, Team: s.team})
+		s.lastFiredTick = s.tickVal()
+		cm.Gunfires = append(cm.Gunfires, GunfireEvent{X: s.x, Y: s.y, Team: s.team, Shooter: s})
 		cm.flashes = append(cm.flashes, &MuzzleFlash{x: s.x, y: s.y, angle: targetH, team: s.team})
 
-		hit := cm.resolveBullet(s, target, shotIdx, baseShooterSpread, params, targetH, dist, angularHalfSize, dmgMul, allFriendlies, buildings, allSoldiers)
+		hit := cm.fireBullet(s, target, shotIdx, baseShooterSpread, params, targetH, dist, angularHalfSize, fullHalfSize, dmgMul, allFriendlies)
 
 		if !queuedBurst {
 			resetAimingState(s)
@@ -737,11 +687,12 @@ func resetAimingState(s *Soldier) {
 	s.aimingTicks = 0
 }
 
-func aimingTicksForDistance(dist float64) int {
-	if dist <= accurateFireRange {
+func (c *SimConfig) aimingTicksForDistance(w *Weapon, dist float64) int {
+	accurate, _, potShotMax := c.weaponRanges(w)
+	if dist <= accurate {
 		return 0
 	}
-	t := clamp01((dist - accurateFireRange) / (potShotMaxFireRange - accurateFireRange))
+	t := clamp01((dist - accurate) / (potShotMax - accurate))
 	return aimingBaseTicks + int(math.Round(float64(aimingExtraTicks)*t))
 }
 
@@ -754,7 +705,9 @@ func findSoldierByID(all []*Soldier, id int) *Soldier {
 	return nil
 }
 
-func (cm *CombatManager) resolveBullet(
+// fireBullet rolls one bullet's deflection, draws its tracer and queues it
+// to land. It reports whether the bullet will hit.
+func (cm *CombatManager) fireBullet(
 	shooter *Soldier,
 	target *Soldier,
 	shotIdx int,
@@ -763,10 +716,9 @@ func (cm *CombatManager) resolveBullet(
 	targetH float64,
 	dist float64,
 	angularHalfSize float64,
+	fullHalfSize float64,
 	dmgMul float64,
 	allFriendlies []*Soldier,
-	buildings []rect,
-	allSoldiers []*Soldier,
 ) bool {
 	// Later shots in a burst have more muzzle climb.
 	burstClimb := params.spreadRad * float64(shotIdx)
@@ -783,13 +735,16 @@ func (cm *CombatManager) resolveBullet(
 	deflection := (u1 + u2) / 2.0 * totalSpread
 	actualAngle := targetH + deflection
 
-	// Hit if deflection falls within the target's angular body size.
+	// Hit if deflection falls within the target's angular body size. A
+	// round within the whole body but outside the exposed part strikes the
+	// target's cover.
 	hit := math.Abs(deflection) <= angularHalfSize
+	coverStrike := !hit && math.Abs(deflection) <= fullHalfSize
 
 	// Tracer endpoint follows actual bullet direction.
 	toX := shooter.x + math.Cos(actualAngle)*(dist+30)
 	toY := shooter.y + math.Sin(actualAngle)*(dist+30)
-	if hit {
+	if hit || coverStrike {
 		toX, toY = target.x, target.y
 	}
 	cm.tracers = append(cm.tracers, &Tracer{
@@ -798,24 +753,57 @@ func (cm *CombatManager) resolveBullet(
 		hit:  hit,
 		team: shooter.team,
 	})
+	cm.Shots = append(cm.Shots, ShotEvent{
+		ShooterID: shooter.id, TargetID: target.id,
+		FromX: shooter.x, FromY: shooter.y,
+		ToX: toX, ToY: toY,
+		Hit: hit,
+	})
+	cm.fired = append(cm.fired, firedShot{
+		shooter: shooter, target: target, shotIdx: shotIdx, hit: hit, coverStrike: coverStrike,
+		fromX: shooter.x, fromY: shooter.y, toX: toX, toY: toY,
+		damage:    shooter.firearm().Damage * dmgMul,
+		witnesses: allFriendlies,
+	})
+	return hit
+}
 
-	if hit {
-		damage := baseDamage * dmgMul
+// landShots is the second combat phase: every queued bullet reaches its
+// target in the order fired. A target killed earlier in the same volley
+// takes no further wounds.
+func (cm *CombatManager) landShots(buildings []rect, allSoldiers []*Soldier) {
+	for _, sh := range cm.fired {
+		cm.landBullet(sh, buildings, allSoldiers)
+	}
+	cm.fired = cm.fired[:0]
+}
 
+func (cm *CombatManager) landBullet(sh firedShot, buildings []rect, allSoldiers []*Soldier) {
+	target := sh.target
+	if target.state == SoldierStateDead {
+		return
+	}
+	hit, damage := sh.hit, sh.damage
+	if cm.tileMap != nil {
+		hit, damage = cm.penetrate(sh)
+	}
+	if hit {
 		// Roll hit region and create wound via body map.
 		var coverMask [regionCount]float64 // TODO: populate from cover geometry
 		wound, instantDeath := target.body.ApplyHit(damage, target.profile.Stance, coverMask, cm.tick, cm.rng)
 
-		target.profile.Psych.ApplyStress(hitStress)
+		target.profile.Psych.ApplyStress(cm.config().HitStress)
 		target.blackboard.IncomingFireCount++
-		target.blackboard.AccumulateSuppression(true, shooter.x, shooter.y, target.x, target.y)
+		target.blackboard.AccumulateSuppression(true, sh.fromX, sh.fromY, target.x, target.y)
 
-		// Initialize casualty state on first wound.
-		if target.body.WoundCount() == 1 {
+		// Initialize casualty state on the first wound armour did not stop.
+		if !wound.Stopped && target.body.penetratingWounds() == 1 {
 			target.casualty = NewCasualtyState(cm.tick)
 		}
 
-		if instantDeath {
+		if wound.Stopped {
+			target.think(fmt.Sprintf("hit %s — round stopped by %s", wound.Region, wound.Armour))
+		} else if instantDeath {
 			target.state = SoldierStateDead
 			target.think(fmt.Sprintf("hit %s (%s) — killed instantly", wound.Region, wound.Severity))
 		} else if target.body.HealthFraction() <= 0 {
@@ -823,59 +811,68 @@ func (cm *CombatManager) resolveBullet(
 			target.think(fmt.Sprintf("hit %s (%s) — incapacitated", wound.Region, wound.Severity))
 		} else {
 			target.think(fmt.Sprintf("hit %s (%s) — taking fire", wound.Region, wound.Severity))
+			cm.cryOut(target)
 		}
-		cm.applyWitnessStress(target, allFriendlies)
-		return true
+		cm.applyWitnessStress(target, sh.witnesses)
+		return
 	}
 
-	target.profile.Psych.ApplyStress(nearMissStress)
+	target.profile.Psych.ApplyStress(cm.config().NearMissStress)
 	target.blackboard.IncomingFireCount++
-	target.blackboard.AccumulateSuppression(false, shooter.x, shooter.y, target.x, target.y)
-	if shotIdx == 0 {
+	target.blackboard.AccumulateSuppression(false, sh.fromX, sh.fromY, target.x, target.y)
+	if sh.shotIdx == 0 {
 		target.think("near miss — incoming fire")
 	}
-	cm.spawnRicochets(shooter.x, shooter.y, toX, toY, shooter.team, buildings, allSoldiers)
-	return false
+	cm.spawnRicochets(sh.fromX, sh.fromY, sh.toX, sh.toY, sh.shooter.team, buildings, allSoldiers)
 }
 
 // selectFireMode uses fuzzy logic to choose the desired fire mode.
 //
 // Fuzzy rule set (priority order):
-//  1. AUTO:   dist ≤ autoRange AND sightline < autoSightlineThresh
+//  1. AUTO:   dist ≤ AutoRange AND sightline < AutoSightline
 //     (CQB: cramped, close, no room to aim)
-//  2. AUTO:   dist ≤ autoRange/2 regardless of terrain
+//  2. AUTO:   dist ≤ AutoRange/2 regardless of terrain
 //     (extreme point-blank — no choice but to spray)
-//  3. BURST:  dist ≤ burstRange
+//  3. BURST:  dist ≤ BurstRange
 //     (mid-range committed — controlled pairs/triples)
 //  4. SINGLE: everything else — deliberate aimed fire
 //
 // Fuzzy blending: the transitions between modes aren't hard thresholds.
 // A soft zone around each boundary lets randomness driven by the soldier's
-// ShootDesire and fear create natural variation in when they switch.
+// ShootDesire and fear create natural variation in when they switch. The
+// bands are the soldier's weapon's, and the result is limited to the modes
+// it has.
 func (cm *CombatManager) selectFireMode(s *Soldier, dist float64) FireMode {
+	return s.firearm().nearestMode(s.conserveAmmo(cm.rangeFireMode(s, dist), dist))
+}
+
+// rangeFireMode picks the fire mode for range, terrain and stress alone.
+func (cm *CombatManager) rangeFireMode(s *Soldier, dist float64) FireMode {
 	sightline := s.blackboard.LocalSightlineScore
 	fear := s.profile.Psych.EffectiveFear()
 	shootDesire := s.blackboard.Internal.ShootDesire
 	stress := clamp01(fear + s.blackboard.SuppressLevel*0.7)
+	w := s.firearm()
+	autoR, burstR, cramped := w.AutoRange, w.BurstRange, w.AutoSightline
 
 	// Stickiness/hysteresis around mode boundaries to prevent chatter.
 	// Current mode gets a deadband where it tends to persist.
 	switch s.currentFireMode {
 	case FireModeAuto:
-		if dist <= float64(autoRange)*1.12 && (sightline < autoSightlineThresh+0.12 || fear > 0.45) {
+		if dist <= autoR*1.12 && (sightline < cramped+0.12 || fear > 0.45) {
 			if cm.rng.Float64() < 0.85 {
 				return FireModeAuto
 			}
 		}
 	case FireModeBurst:
-		if dist >= float64(autoRange)*0.92 && dist <= float64(burstRange)*1.08 {
+		if dist >= autoR*0.92 && dist <= burstR*1.08 {
 			burstStick := clamp01(0.35 + fear*0.25 + shootDesire*0.20)
 			if cm.rng.Float64() < burstStick {
 				return FireModeBurst
 			}
 		}
 	case FireModeSingle:
-		if dist >= float64(burstRange)*0.88 {
+		if dist >= burstR*0.88 {
 			singleStick := clamp01(0.50 + s.profile.Skills.Marksmanship*0.20 - fear*0.15)
 			if cm.rng.Float64() < singleStick {
 				return FireModeSingle
@@ -884,18 +881,18 @@ func (cm *CombatManager) selectFireMode(s *Soldier, dist float64) FireMode {
 	}
 
 	// --- Rule 1: extreme CQB — always auto regardless of terrain.
-	pointBlankRange := float64(autoRange) / 2.0 // 5 tiles / 80px
+	pointBlankRange := autoR / 2.0 // 5 tiles / 80px for the rifle
 	if dist <= pointBlankRange {
 		return FireModeAuto
 	}
 
 	// --- Rule 2: CQB range + enclosed terrain (fuzzy).
-	if dist <= float64(autoRange) {
+	if dist <= autoR {
 		// How enclosed is the terrain? Low sightline = enclosed.
 		// How close are they? Closer = more auto pressure.
 		// How scared are they? Fear drives spray.
-		enclosedFactor := clamp01((autoSightlineThresh - sightline) / autoSightlineThresh)
-		distFactor := clamp01(1.0 - dist/float64(autoRange))
+		enclosedFactor := clamp01((cramped - sightline) / cramped)
+		distFactor := clamp01(1.0 - dist/autoR)
 		fearBoost := fear * 0.3
 		autoMembership := enclosedFactor*0.5 + distFactor*0.35 + fearBoost + shootDesire*0.1
 		if autoMembership > 0.45 {
@@ -911,7 +908,7 @@ func (cm *CombatManager) selectFireMode(s *Soldier, dist float64) FireMode {
 	}
 
 	// --- Rule 3: mid-range burst zone (fuzzy boundary with single).
-	if dist <= float64(burstRange) {
+	if dist <= burstR {
 		// Prefer burst, but experienced calm soldiers may stay on single
 		// for better accuracy. Fear pushes toward burst (spray under stress).
 		burstPressure := clamp01(
@@ -921,7 +918,7 @@ func (cm *CombatManager) selectFireMode(s *Soldier, dist float64) FireMode {
 				s.profile.Skills.Marksmanship*0.2,
 		)
 		if burstPressure > 0.45 {
-			if stress > 0.60 && dist <= float64(autoRange)*1.05 && cm.rng.Float64() < (stress-0.60)*0.35 {
+			if stress > 0.60 && dist <= autoR*1.05 && cm.rng.Float64() < (stress-0.60)*0.35 {
 				return FireModeAuto
 			}
 			return FireModeBurst
@@ -1054,6 +1051,7 @@ func (cm *CombatManager) closestContact(s *Soldier) *Soldier {
 
 // applyWitnessStress adds stress to same-team soldiers near a hit target.
 func (cm *CombatManager) applyWitnessStress(target *Soldier, friendlies []*Soldier) {
+	stress := cm.config().WitnessStress
 	for _, f := range friendlies {
 		if f == target || f.state == SoldierStateDead {
 			continue
@@ -1061,7 +1059,7 @@ func (cm *CombatManager) applyWitnessStress(target *Soldier, friendlies []*Soldi
 		dx := f.x - target.x
 		dy := f.y - target.y
 		if withinRadius2(dx, dy, witnessRadius*witnessRadius) {
-			f.profile.Psych.ApplyStress(witnessStress)
+			f.profile.Psych.ApplyStress(stress)
 		}
 	}
 }
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// mirrorDuel puts one rifleman from each team 40px apart, facing each other,
+// and fights until someone falls. With redOnLeft false the two swap ends,
+// so a pair of calls is a mirror image and any difference that survives
+// the pairing comes from the order the teams are resolved in.
+func mirrorDuel(seed int64, redOnLeft bool) (redDown, blueDown bool) {
+	left, right := 620.0, 660.0
+	if !redOnLeft {
+		left, right = right, left
+	}
+	ts := NewTestSim(WithSeed(seed),
+		WithRedSoldier(1, left, 360, right, 360),
+		WithBlueSoldier(2, right, 360, left, 360))
+	ts.RunUntil(func(ts *TestSim) bool {
+		return ts.aliveCount(TeamRed) == 0 || ts.aliveCount(TeamBlue) == 0
+	}, 300)
+	return ts.aliveCount(TeamRed) == 0, ts.aliveCount(TeamBlue) == 0
+}
+
+// TestCombat_MirrorImageDuelsComeOutEven fights each seed from both ends.
+// Resolving red's fire before blue's used to win red about two duels in
+// three; with simultaneous fire the split must be within chance, and some
+// duels must end with both men down on the same tick.
+func TestCombat_MirrorImageDuelsComeOutEven(t *testing.T) {
+	const seeds = 100
+	red, blue, mutual := 0, 0, 0
+	for seed := int64(1); seed <= seeds; seed++ {
+		for _, redOnLeft := range []bool{true, false} {
+			redDown, blueDown := mirrorDuel(seed, redOnLeft)
+			switch {
+			case redDown && blueDown:
+				mutual++
+			case blueDown:
+				red++
+			case redDown:
+				blue++
+			}
+		}
+	}
+	t.Logf("%d mirrored duels: red won %d, blue won %d, %d mutual", 2*seeds, red, blue, mutual)
+	decided := red + blue
+	if decided < seeds/4 {
+		t.Fatalf("only %d of %d duels were decided — the scenario is not producing a fight", decided, 2*seeds)
+	}
+	// Each decided duel is a coin flip between the teams; allow three sigma.
+	if diff := math.Abs(float64(red - blue)); diff > 3*math.Sqrt(float64(decided)) {
+		t.Fatalf("red won %d, blue won %d: a gap of %.0f is beyond chance", red, blue, diff)
+	}
+	if mutual == 0 {
+		t.Fatal("no duel ended with both down — fire is still resolved one team at a time")
+	}
+}
+
+// TestCombat_ExchangeFiresBeforeAnyBulletLands checks the two phases directly:
+// both shooters roll their bullets before either takes a wound.
+func TestCombat_ExchangeFiresBeforeAnyBulletLands(t *testing.T) {
+	tick := 0
+	red := newTestSoldier(1, 300, 200, TeamRed, &tick)
+	blue := newTestSoldier(2, 330, 200, TeamBlue, &tick)
+	for _, pair := range [][2]*Soldier{{red, blue}, {blue, red}} {
+		s, target := pair[0], pair[1]
+		s.vision.KnownContacts = []*Soldier{target}
+		s.currentFireMode, s.desiredFireMode = FireModeBurst, FireModeBurst
+		s.burstShotsRemaining = 1
+		s.burstShotIndex = 1
+		s.burstTargetID = target.id
+		s.burstBaseSpread = 0.01
+	}
+	cm := NewCombatManager(1)
+	all := []*Soldier{red, blue}
+
+	cm.fireShots([]*Soldier{red}, []*Soldier{red}, nil, all)
+	cm.fireShots([]*Soldier{blue}, []*Soldier{blue}, nil, all)
+	if len(cm.fired) != 2 || red.body.WoundCount() != 0 || blue.body.WoundCount() != 0 {
+		t.Fatalf("after aiming: %d bullets queued, wounds red=%d blue=%d; want 2 and none",
+			len(cm.fired), red.body.WoundCount(), blue.body.WoundCount())
+	}
+	cm.landShots(nil, all)
+	if red.body.WoundCount() != 1 || blue.body.WoundCount() != 1 || len(cm.fired) != 0 {
+		t.Fatalf("after landing: wounds red=%d blue=%d, want one each", red.body.WoundCount(), blue.body.WoundCount())
+	}
+
+	// The public entry point does the same in one call.
+	red2 := newTestSoldier(3, 300, 300, TeamRed, &tick)
+	blue2 := newTestSoldier(4, 330, 300, TeamBlue, &tick)
+	red2.vision.KnownContacts = []*Soldier{blue2}
+	blue2.vision.KnownContacts = []*Soldier{red2}
+	for i := 0; i < fireIntervalSingle+5 && len(cm.Shots) < 2; i++ {
+		cm.ResetFireCounts([]*Soldier{red2, blue2})
+		cm.ResolveExchange([]*Soldier{red2}, []*Soldier{blue2}, nil, []*Soldier{red2, blue2})
+	}
+	if len(cm.Shots) != 2 || cm.Shots[0].ShooterID == cm.Shots[1].ShooterID {
+		t.Fatalf("expected one shot from each side on the same tick, got %+v", cm.Shots)
+	}
+}
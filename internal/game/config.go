@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SimConfig holds the balancing constants that tuning experiments vary:
+// fire envelopes, stress per shot, radio thresholds and the goal thresholds
+// every soldier starts from. A World shares one SimConfig with all its
+// soldiers and its CombatManager, so a scenario or sweep can change them
+// without recompiling. The package constants of the same names are the
+// defaults.
+type SimConfig struct {
+	AccurateFireRange float64 // px, reliable engagement envelope
+	NearMissStress    float64 // fear added to target on miss
+	HitStress         float64 // fear added to target on hit
+	WitnessStress     float64 // fear added to nearby friendlies seeing a hit
+
+	RadioMaxRange            float64 // px, range at which radio quality bottoms out
+	RadioDropThreshold       float64 // quality below which a message is lost
+	RadioGarbleThreshold     float64 // quality below which a message is garbled
+	RadioFearReportThreshold float64 // fear at which a soldier radios that he is shaken
+	RadioWallLoss            float64 // link quality lost per wall between sender and receiver
+
+	Thresholds GoalThresholds // per-soldier starting thresholds; they drift back toward these
+}
+
+// DefaultSimConfig returns the built-in balance.
+func DefaultSimConfig() *SimConfig {
+	return &SimConfig{
+		AccurateFireRange:        accurateFireRange,
+		NearMissStress:           nearMissStress,
+		HitStress:                hitStress,
+		WitnessStress:            witnessStress,
+		RadioMaxRange:            radioMaxReliableRange,
+		RadioDropThreshold:       radioDropThreshold,
+		RadioGarbleThreshold:     radioGarbleThreshold,
+		RadioFearReportThreshold: radioFearReportThreshold,
+		RadioWallLoss:            radioWallLoss,
+		Thresholds:               defaultGoalThresholds(),
+	}
+}
+
+// defaultSimConfig backs soldiers and combat managers that were never wired
+// to a World, such as those built directly in unit tests. Never modify it.
+var defaultSimConfig = DefaultSimConfig()
+
+// simParam names one tunable field for scenario overrides and sweeps.
+type simParam struct {
+	name     string
+	ptr      *float64
+	min, max float64
+}
+
+func (c *SimConfig) params() []simParam {
+	return []simParam{
+		{"accurate_fire_range", &c.AccurateFireRange, cqbRange + 1, maxFireRange - 1},
+		{"near_miss_stress", &c.NearMissStress, 0, 1},
+		{"hit_stress", &c.HitStress, 0, 1},
+		{"witness_stress", &c.WitnessStress, 0, 1},
+		{"radio_max_range", &c.RadioMaxRange, 1, 10000},
+		{"radio_drop_threshold", &c.RadioDropThreshold, 0, 1},
+		{"radio_garble_threshold", &c.RadioGarbleThreshold, 0, 1},
+		{"radio_fear_report_threshold", &c.RadioFearReportThreshold, 0, 1},
+		{"radio_wall_loss", &c.RadioWallLoss, 0, 1},
+		{"engage_shot_quality", &c.Thresholds.EngageShotQuality, 0.01, 1},
+		{"long_range_shot_quality", &c.Thresholds.LongRangeShotQuality, 0, 1},
+		{"push_on_miss_momentum", &c.Thresholds.PushOnMissMomentum, 0, 1},
+		{"hold_on_hit_momentum", &c.Thresholds.HoldOnHitMomentum, 0, 1},
+		{"cover_fear", &c.Thresholds.CoverFear, 0, 1},
+	}
+}
+
+// SimParamNames lists the parameter names Set accepts, in a fixed order.
+func SimParamNames() []string {
+	var c SimConfig
+	ps := c.params()
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.name
+	}
+	return names
+}
+
+func (c *SimConfig) param(name string) (simParam, error) {
+	for _, p := range c.params() {
+		if p.name == name {
+			return p, nil
+		}
+	}
+	return simParam{}, fmt.Errorf("unknown parameter %q (known: %s)", name, strings.Join(SimParamNames(), ", "))
+}
+
+// Get returns the named parameter.
+func (c *SimConfig) Get(name string) (float64, error) {
+	p, err := c.param(name)
+	if err != nil {
+		return 0, err
+	}
+	return *p.ptr, nil
+}
+
+// Set changes the named parameter, refusing values outside its valid range.
+func (c *SimConfig) Set(name string, v float64) error {
+	p, err := c.param(name)
+	if err != nil {
+		return err
+	}
+	if v < p.min || v > p.max {
+		return fmt.Errorf("%s=%g out of range [%g, %g]", name, v, p.min, p.max)
+	}
+	*p.ptr = v
+	return nil
+}
+
+// Apply sets every parameter in overrides, in name order so errors are stable.
+func (c *SimConfig) Apply(overrides map[string]float64) error {
+	names := make([]string, 0, len(overrides))
+	for k := range overrides {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if err := c.Set(k, overrides[k]); err != nil {
+			return err
+		}
+	}
+	if c.RadioGarbleThreshold < c.RadioDropThreshold {
+		return fmt.Errorf("radio_garble_threshold %g is below radio_drop_threshold %g", c.RadioGarbleThreshold, c.RadioDropThreshold)
+	}
+	return nil
+}
+
+// Clone returns an independent copy.
+func (c *SimConfig) Clone() *SimConfig {
+	cp := *c
+	return &cp
+}
+
+// config returns the soldier's balance, or the defaults if it has none.
+func (s *Soldier) config() *SimConfig {
+	if s.cfg == nil {
+		return defaultSimConfig
+	}
+	return s.cfg
+}
+
+// setConfig wires the soldier and its blackboard to cfg. Goal thresholds that
+// have not started drifting yet restart from cfg's.
+func (s *Soldier) setConfig(cfg *SimConfig) {
+	s.cfg = cfg
+	s.blackboard.cfg = cfg
+	if s.blackboard.Internal.ThresholdAge == 0 {
+		s.blackboard.Internal.Thresholds = s.blackboard.baseThresholds()
+	}
+}
+
+func (bb *Blackboard) config() *SimConfig {
+	if bb.cfg == nil {
+		return defaultSimConfig
+	}
+	return bb.cfg
+}
+
+// baseThresholds returns the thresholds the soldier starts from and drifts
+// back toward: its own if it has them, otherwise the config's.
+func (bb *Blackboard) baseThresholds() GoalThresholds {
+	if bb.ownThresholds != nil {
+		return *bb.ownThresholds
+	}
+	return bb.config().Thresholds
+}
+
+func (cm *CombatManager) config() *SimConfig {
+	if cm.cfg == nil {
+		return defaultSimConfig
+	}
+	return cm.cfg
+}
@@ -0,0 +1,59 @@
+package game
+
+import "testing"
+
+func TestSimConfig_SetValidatesNamesAndRanges(t *testing.T) {
+	cfg := DefaultSimConfig()
+	if v, err := cfg.Get("hit_stress"); err != nil || v != hitStress {
+		t.Fatalf("hit_stress default %v (%v), want %v", v, err, hitStress)
+	}
+	if err := cfg.Set("cover_fear", 0.4); err != nil || cfg.Thresholds.CoverFear != 0.4 {
+		t.Fatalf("cover_fear not set: %v", err)
+	}
+	if cfg.Set("cover_fear", 1.5) == nil || cfg.Set("bogus", 1) == nil {
+		t.Fatal("out-of-range values and unknown names must be refused")
+	}
+	if err := cfg.Apply(map[string]float64{"radio_drop_threshold": 0.6, "radio_garble_threshold": 0.5}); err == nil {
+		t.Fatal("a garble threshold below the drop threshold should be refused")
+	}
+	if DefaultSimConfig().Thresholds.CoverFear == 0.4 {
+		t.Fatal("configs must not share state")
+	}
+}
+
+func TestSimConfig_ScenarioOverridesReachSoldiersAndCombat(t *testing.T) {
+	sc := &ScenarioFile{
+		Map:      ScenarioMap{Width: 800, Height: 400},
+		Soldiers: []ScenarioSoldier{{ID: 0, Team: "red", Start: [2]float64{100, 200}, Objective: [2]float64{700, 200}}},
+		Config:   map[string]float64{"hit_stress": 0.9, "engage_shot_quality": 0.6},
+	}
+	if err := sc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ts := NewTestSimFromScenario(sc, 1)
+	s := ts.Soldiers[0]
+	if s.config() != ts.config || ts.combat.config() != ts.config || s.config().HitStress != 0.9 {
+		t.Fatal("soldiers and combat should share the scenario's config")
+	}
+	s.blackboard.ensureInternalDefaults()
+	if got := s.blackboard.Internal.Thresholds.EngageShotQuality; got != 0.6 {
+		t.Fatalf("engage threshold %.2f, want the configured 0.6", got)
+	}
+
+	before := s.profile.Psych.Fear
+	ts.combat.landBullet(firedShot{target: s, hit: true, damage: 1, fromX: 600, fromY: 200}, nil, ts.Soldiers)
+	if gain := s.profile.Psych.Fear - before; gain < 0.5 {
+		t.Fatalf("fear rose %.2f on a hit, want the configured stress to apply", gain)
+	}
+
+	sc.Config["hit_stress"] = 3
+	if sc.Validate() == nil {
+		t.Fatal("a scenario with an out-of-range override should not validate")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("building a sim from an invalid config should not fall back to the defaults")
+		}
+	}()
+	sc.Options(1)
+}
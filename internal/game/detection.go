@@ -0,0 +1,147 @@
+package game
+
+import "math"
+
+// ---------------------------------------------------------------------------
+// Detection — spotting builds up over time
+// ---------------------------------------------------------------------------
+//
+// PerformVisionScan answers the geometric question: who is in the cone with
+// a clear line of sight. updateDetection answers whether the observer has
+// actually noticed them. Each observer keeps a detection level per target
+// that climbs while the target is in sight, at a rate set by how exposed the
+// target is, how much smoke hangs between them and how good the observer is,
+// and bleeds away once sight is lost. Past detectSuspectLevel a target is suspected — something moved over
+// there — and at 1 it is a full contact.
+
+const (
+	detectBaseRate     = 0.20  // level per tick on a standing, moving target at point-blank for an average observer
+	detectHalfRange    = 450.0 // px; the rate halves at this distance and keeps falling with its square
+	detectInstantRange = 60.0  // px; anything in sight this close is seen at once
+	detectSuspectLevel = 0.35  // level at which a target is suspected
+	detectDecay        = 0.02  // level lost per tick out of sight
+	detectStillMul     = 0.45  // a motionless target is much harder to pick out
+	detectFiringMul    = 3.0   // a target that has just fired gives itself away
+	detectFiringTicks  = 30    // ticks a shot keeps drawing the eye
+	detectWatchMul     = 1.5   // an observer peeking or on overwatch scans deliberately
+	detectMovingObsMul = 0.7   // an observer on the move scans less carefully
+
+	suspectThreatConfidence = 0.25 // blackboard confidence in a suspected contact
+)
+
+// detectionTrack is one observer's progress toward spotting one target.
+type detectionTrack struct {
+	target *Soldier
+	level  float64 // 0-1; 1 = fully detected
+	seen   bool    // in sight on the latest scan
+}
+
+// detectionLevel returns how far s has got toward spotting t.
+func (v *VisionState) detectionLevel(t *Soldier) float64 {
+	for _, tr := range v.tracks {
+		if tr.target == t {
+			return tr.level
+		}
+	}
+	return 0
+}
+
+// track returns the detection track for t, starting one if needed.
+func (v *VisionState) track(t *Soldier) *detectionTrack {
+	for i := range v.tracks {
+		if v.tracks[i].target == t {
+			return &v.tracks[i]
+		}
+	}
+	v.tracks = append(v.tracks, detectionTrack{target: t})
+	return &v.tracks[len(v.tracks)-1]
+}
+
+// lightMul is the share of daylight spotting ability left in the current
+// light and weather.
+func (v *VisionState) lightMul() float64 {
+	if v.EnvMul <= 0 {
+		return 1
+	}
+	return v.EnvMul
+}
+
+// targetConspicuity returns how easy t is to pick out, 1 for a man standing
+// and walking in the open. Stance shrinks the silhouette, stillness removes
+// the movement that draws the eye, and long grass or scrub hides anyone who
+// is not standing above it. A shot undoes most of that.
+func targetConspicuity(t *Soldier, tm *TileMap, tick int) float64 {
+	stance := t.profile.Stance
+	c := stance.Profile().ProfileMul
+	if t.state != SoldierStateMoving {
+		c *= detectStillMul
+	}
+	if tm != nil && stance != StanceStanding {
+		c *= groundConcealment(tm.Ground(WorldToCell(t.x, t.y)))
+	}
+	if t.lastFiredTick > 0 && tick-t.lastFiredTick < detectFiringTicks {
+		c *= detectFiringMul
+	}
+	return c
+}
+
+// observerSkill scales how quickly s picks targets out: Fieldcraft, and
+// whether s is deliberately watching or busy moving.
+func (s *Soldier) observerSkill() float64 {
+	m := 0.6 + 0.8*s.profile.Skills.Fieldcraft
+	switch {
+	case s.blackboard.CurrentGoal == GoalPeek || s.blackboard.CurrentGoal == GoalOverwatch:
+		m *= detectWatchMul
+	case s.state == SoldierStateMoving:
+		m *= detectMovingObsMul
+	}
+	return m
+}
+
+// updateDetection advances s's detection of everyone in KnownContacts, which
+// on entry holds the geometric scan. On return KnownContacts holds only the
+// fully detected targets and Suspected the ones in sight but not yet made
+// out. Targets out of sight fade and are forgotten.
+func (s *Soldier) updateDetection(tick int) {
+	v := &s.vision
+	for i := range v.tracks {
+		v.tracks[i].seen = false
+	}
+	rate := detectBaseRate * v.lightMul() * s.observerSkill()
+	for _, t := range v.KnownContacts {
+		tr := v.track(t)
+		tr.seen = true
+		d := math.Hypot(t.x-s.x, t.y-s.y)
+		if d <= detectInstantRange {
+			tr.level = 1
+			continue
+		}
+		rangeMul := 1 / (1 + (d/detectHalfRange)*(d/detectHalfRange))
+		smokeMul := 1 - s.smoke.Obscuration(s.x, s.y, t.x, t.y)
+		tr.level = math.Min(1, tr.level+rate*rangeMul*smokeMul*targetConspicuity(t, s.tileMap, tick))
+	}
+
+	kept := v.tracks[:0]
+	for _, tr := range v.tracks {
+		if !tr.seen {
+			tr.level -= detectDecay
+		}
+		if tr.level > 0 && tr.target.state != SoldierStateDead {
+			kept = append(kept, tr)
+		}
+	}
+	v.tracks = kept
+
+	// Filter in place; each contact is written at or before where it was read.
+	inSight := v.KnownContacts
+	v.KnownContacts = v.KnownContacts[:0]
+	v.Suspected = v.Suspected[:0]
+	for _, t := range inSight {
+		switch l := v.detectionLevel(t); {
+		case l >= 1:
+			v.KnownContacts = append(v.KnownContacts, t)
+		case l >= detectSuspectLevel:
+			v.Suspected = append(v.Suspected, t)
+		}
+	}
+}
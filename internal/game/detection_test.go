@@ -0,0 +1,115 @@
+package game
+
+import "testing"
+
+// watchUntilSpotted runs observer's vision on target each tick and returns
+// the ticks at which target was first suspected and first a full contact,
+// or -1 for either that did not happen within limit ticks.
+func watchUntilSpotted(observer, target *Soldier, tick *int, limit int) (suspected, spotted int) {
+	suspected, spotted = -1, -1
+	for i := 0; i < limit; i++ {
+		*tick = i + 1
+		observer.vision.Heading = 0
+		observer.UpdateVision([]*Soldier{target}, nil)
+		if suspected < 0 && len(observer.vision.Suspected) > 0 {
+			suspected = i
+		}
+		if len(observer.vision.KnownContacts) > 0 {
+			return suspected, i
+		}
+	}
+	return suspected, spotted
+}
+
+func newDetectionPair(dist float64, tick *int) (observer, target *Soldier) {
+	observer = newTestSoldier(0, 100, 200, TeamRed, tick)
+	observer.profile.Skills.Fieldcraft = 0.5
+	target = newTestSoldier(1, 100+dist, 200, TeamBlue, tick)
+	return observer, target
+}
+
+func TestDetection_ConcealedStillTargetTakesLonger(t *testing.T) {
+	tick := 0
+	observer, walker := newDetectionPair(500, &tick)
+	walker.state = SoldierStateMoving
+	suspectedWalker, spottedWalker := watchUntilSpotted(observer, walker, &tick, 2000)
+	if spottedWalker < 0 {
+		t.Fatal("a man walking upright in the open was never spotted")
+	}
+	if spottedWalker == 0 {
+		t.Fatal("a walking man 500px away was spotted on the first glance")
+	}
+	if suspectedWalker < 0 || suspectedWalker >= spottedWalker {
+		t.Errorf("walker suspected at %d, spotted at %d; want suspicion first", suspectedWalker, spottedWalker)
+	}
+
+	observer, hider := newDetectionPair(500, &tick)
+	hider.state = SoldierStateIdle
+	hider.profile.Stance = StanceProne
+	tm := NewTileMap(100, 40)
+	col, row := WorldToCell(hider.x, hider.y)
+	tm.SetGround(col, row, GroundGrassLong)
+	observer.tileMap = tm
+	_, spottedHider := watchUntilSpotted(observer, hider, &tick, 2000)
+	if spottedHider >= 0 && spottedHider < spottedWalker*10 {
+		t.Errorf("prone still man in long grass spotted at %d ticks, walker at %d; want at least 10x longer",
+			spottedHider, spottedWalker)
+	}
+}
+
+func TestDetection_CloseTargetSeenAtOnce(t *testing.T) {
+	tick := 0
+	observer, target := newDetectionPair(detectInstantRange-10, &tick)
+	target.profile.Stance = StanceProne
+	if _, spotted := watchUntilSpotted(observer, target, &tick, 1); spotted != 0 {
+		t.Fatal("a target inside the instant range should be seen on the first scan")
+	}
+}
+
+func TestDetection_FieldcraftAndFiringSpeedSpotting(t *testing.T) {
+	tick := 0
+	novice, target := newDetectionPair(900, &tick)
+	novice.profile.Skills.Fieldcraft = 0.1
+	_, slow := watchUntilSpotted(novice, target, &tick, 5000)
+
+	expert, target := newDetectionPair(900, &tick)
+	expert.profile.Skills.Fieldcraft = 0.9
+	_, fast := watchUntilSpotted(expert, target, &tick, 5000)
+	if fast < 0 || slow < 0 || fast >= slow {
+		t.Errorf("expert spotted at %d ticks, novice at %d; want the expert first", fast, slow)
+	}
+
+	novice, shooter := newDetectionPair(900, &tick)
+	novice.profile.Skills.Fieldcraft = 0.1
+	shooter.lastFiredTick = 1
+	_, firing := watchUntilSpotted(novice, shooter, &tick, 5000)
+	if firing < 0 || firing >= slow {
+		t.Errorf("firing target spotted at %d ticks, silent one at %d; want the shooter first", firing, slow)
+	}
+}
+
+func TestDetection_SuspectedContactFadesOutOfSight(t *testing.T) {
+	tick := 0
+	observer, target := newDetectionPair(700, &tick)
+	for len(observer.vision.Suspected) == 0 {
+		tick++
+		observer.UpdateVision([]*Soldier{target}, nil)
+		if tick > 2000 {
+			t.Fatal("target never suspected")
+		}
+	}
+	observer.blackboard.UpdateThreats(observer.vision.KnownContacts, tick)
+	observer.blackboard.NoteSuspectedContact(target, tick)
+	if len(observer.blackboard.Threats) != 1 || !observer.blackboard.Threats[0].Uncertain || observer.blackboard.Threats[0].IsVisible {
+		t.Fatalf("suspected contact should be an uncertain, unseen threat: %+v", observer.blackboard.Threats)
+	}
+
+	// Looking away lets the half-made-out target slip from memory.
+	for i := 0; i < int(1/detectDecay)+1; i++ {
+		observer.vision.Heading = 3.14
+		observer.UpdateVision([]*Soldier{target}, nil)
+	}
+	if l := observer.vision.detectionLevel(target); l != 0 {
+		t.Errorf("detection level %.2f after looking away, want 0", l)
+	}
+}
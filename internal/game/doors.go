@@ -0,0 +1,316 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// ---------------------------------------------------------------------------
+// Doors — opening, closing and breaching at runtime
+// ---------------------------------------------------------------------------
+//
+// Closed doors block sight and muffle sound (both read the TileMap live) but
+// not paths: a soldier whose path runs into one stops to open it, or to
+// force it if it is locked. The NavGrid prices a closed door by the time it
+// costs, so routes prefer open doorways. A soldier who has just come
+// through a doorway with a threat on the far side shuts the door behind
+// them, unless a friendly is still coming through. Entry teams breach
+// their entry door rather than opening it.
+
+const (
+	doorOpenTicks       = 20    // a hand on the latch and a push
+	doorCloseTicks      = 15    // pulling it shut behind you
+	doorBreachTicks     = 45    // kicking in or shouldering a locked door
+	doorBreachRange     = 520.0 // px; a breach is heard much further than a creak
+	doorCloseHoldRadius = 40.0  // px; a door is not shut on a friendly this close to it
+	doorBreachReach     = 48.0  // px; how close an entry-team member must be to breach
+	doorLockedPercent   = 35    // share of closed exterior doors that start locked
+	doorPathCost        = 2.0   // extra path cost (cells) of a closed door
+	lockedDoorPathCost  = 4.5   // extra path cost (cells) of a locked door
+)
+
+// doorAction is what a soldier is doing to a door.
+type doorAction int
+
+const (
+	doorActionNone doorAction = iota
+	doorActionOpen
+	doorActionClose
+	doorActionBreach
+)
+
+func (a doorAction) String() string {
+	switch a {
+	case doorActionOpen:
+		return "opening"
+	case doorActionClose:
+		return "closing"
+	case doorActionBreach:
+		return "breaching"
+	default:
+		return "none"
+	}
+}
+
+// doorStartsLocked decides whether the closed exterior door at (col, row)
+// starts locked. It hashes the cell rather than drawing from the generation
+// RNG, so adding locks leaves every other generated feature where it was.
+func doorStartsLocked(col, row int) bool {
+	h := uint32(col)*73856093 ^ uint32(row)*19349663 // #nosec G115 -- bit mixing only
+	return h%100 < doorLockedPercent
+}
+
+// doorLocked reports whether the tile at (col, row) is a locked door.
+func (tm *TileMap) doorLocked(col, row int) bool {
+	t := tm.At(col, row)
+	return t != nil && t.Object == ObjectDoor && t.Flags&TileFlagLocked != 0
+}
+
+// setDoor swings the door at (col, row) open or shut, keeping whatever
+// damage it has taken.
+func (tm *TileMap) setDoor(col, row int, o ObjectType) {
+	if t := tm.At(col, row); t != nil {
+		t.Object = o
+	}
+}
+
+// closedDoorBetween reports whether a closed door lies on the straight line
+// from (ax, ay) to (bx, by), not counting the two end cells.
+func (tm *TileMap) closedDoorBetween(ax, ay, bx, by float64) bool {
+	sc, sr := WorldToCell(ax, ay)
+	tc, tr := WorldToCell(bx, by)
+	dc := absInt(tc - sc)
+	dr := absInt(tr - sr)
+	xStep, yStep := 1, 1
+	if tc < sc {
+		xStep = -1
+	}
+	if tr < sr {
+		yStep = -1
+	}
+	err := dc - dr
+	col, row := sc, sr
+	for col != tc || row != tr {
+		e2 := err * 2
+		if e2 > -dr {
+			err -= dr
+			col += xStep
+		}
+		if e2 < dc {
+			err += dc
+			row += yStep
+		}
+		if (col != tc || row != tr) && tm.ObjectAt(col, row) == ObjectDoor {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDoorCell prices the cell at (col, row) for pathing from the door on
+// it, if any. Call after any change to a door.
+func (ng *NavGrid) syncDoorCell(tm *TileMap, col, row int) {
+	if ng == nil || tm == nil || col < 0 || row < 0 || col >= ng.cols || row >= ng.rows {
+		return
+	}
+	k := row*ng.cols + col
+	switch {
+	case tm.doorLocked(col, row):
+		ng.setExtraCost(k, lockedDoorPathCost)
+	case tm.ObjectAt(col, row) == ObjectDoor:
+		ng.setExtraCost(k, doorPathCost)
+	default:
+		ng.setExtraCost(k, 0)
+	}
+}
+
+// syncDoors prices every door on tm.
+func (ng *NavGrid) syncDoors(tm *TileMap) {
+	if ng == nil || tm == nil {
+		return
+	}
+	for row := 0; row < tm.Rows; row++ {
+		for col := 0; col < tm.Cols; col++ {
+			switch tm.ObjectAt(col, row) {
+			case ObjectDoor, ObjectDoorOpen, ObjectDoorBroken:
+				ng.syncDoorCell(tm, col, row)
+			}
+		}
+	}
+}
+
+// startDoorAction has s spend the next few ticks on the door at (col, row).
+func (s *Soldier) startDoorAction(a doorAction, col, row int) {
+	s.doorAction = a
+	s.doorCol, s.doorRow = col, row
+	switch a {
+	case doorActionOpen:
+		s.doorTimer = doorOpenTicks
+	case doorActionClose:
+		s.doorTimer = doorCloseTicks
+	case doorActionBreach:
+		s.doorTimer = doorBreachTicks
+	}
+	s.think(fmt.Sprintf("%s door", a))
+}
+
+// finishDoorAction changes the door s has been working on.
+func (s *Soldier) finishDoorAction() {
+	tm := s.tileMap
+	col, row := s.doorCol, s.doorRow
+	a := s.doorAction
+	s.doorAction = doorActionNone
+	switch {
+	case a == doorActionOpen && tm.ObjectAt(col, row) == ObjectDoor:
+		tm.setDoor(col, row, ObjectDoorOpen)
+	case a == doorActionClose && tm.ObjectAt(col, row) == ObjectDoorOpen:
+		tm.setDoor(col, row, ObjectDoor)
+		s.doorNoise = soundDoorRange
+	case a == doorActionBreach && tm.ObjectAt(col, row) == ObjectDoor:
+		t := tm.At(col, row)
+		t.Object = ObjectDoorBroken
+		t.Durability = 0
+		t.Flags = t.Flags&^TileFlagLocked | TileFlagDamaged
+		s.doorNoise = doorBreachRange
+		s.think("door breached")
+	default:
+		return
+	}
+	s.navGrid.syncDoorCell(tm, col, row)
+}
+
+// updateDoors runs s's door work for one tick: finish an action under way,
+// or shut a door just walked through if that puts it between s and the
+// enemy.
+func (s *Soldier) updateDoors() {
+	tm := s.tileMap
+	if tm == nil {
+		return
+	}
+	if s.doorTimer > 0 {
+		s.doorTimer--
+		if s.doorTimer == 0 {
+			s.finishDoorAction()
+		}
+		return
+	}
+	col, row := WorldToCell(s.x, s.y)
+	if s.inDoorway && (col != s.doorwayCol || row != s.doorwayRow) {
+		s.inDoorway = false
+		if s.shouldCloseDoorBehind() {
+			s.startDoorAction(doorActionClose, s.doorwayCol, s.doorwayRow)
+			return
+		}
+	}
+	if tm.ObjectAt(col, row) == ObjectDoorOpen {
+		s.inDoorway = true
+		s.doorwayCol, s.doorwayRow = col, row
+	}
+}
+
+// shouldCloseDoorBehind reports whether the open door s has just left
+// stands between s and a threat, with no friendly about to come through.
+func (s *Soldier) shouldCloseDoorBehind() bool {
+	if s.tileMap.ObjectAt(s.doorwayCol, s.doorwayRow) != ObjectDoorOpen {
+		return false
+	}
+	dx, dy := CellToWorld(s.doorwayCol, s.doorwayRow)
+	if s.friendlyNear(dx, dy, doorCloseHoldRadius) {
+		return false
+	}
+	for _, t := range s.blackboard.Threats {
+		if t.Confidence >= suspectThreatConfidence && math.Hypot(t.X-dx, t.Y-dy) < math.Hypot(t.X-s.x, t.Y-s.y) {
+			return true
+		}
+	}
+	return false
+}
+
+// openDoorAhead starts opening, or forcing if it is locked, a closed door
+// in the next cell along s's path. It reports whether s stopped for it.
+func (s *Soldier) openDoorAhead() bool {
+	tm := s.tileMap
+	if tm == nil || s.pathIndex >= len(s.path) {
+		return false
+	}
+	wp := s.path[s.pathIndex]
+	dx, dy := wp[0]-s.x, wp[1]-s.y
+	d := math.Hypot(dx, dy)
+	if d < 1e-6 {
+		return false
+	}
+	reach := math.Min(d, cellSize*0.75)
+	col, row := WorldToCell(s.x+dx/d*reach, s.y+dy/d*reach)
+	if c0, r0 := WorldToCell(s.x, s.y); (c0 == col && r0 == row) || tm.ObjectAt(col, row) != ObjectDoor {
+		return false
+	}
+	a := doorActionOpen
+	if tm.doorLocked(col, row) {
+		a = doorActionBreach
+	}
+	s.startDoorAction(a, col, row)
+	return true
+}
+
+// dropContactsBehindDoors removes contacts hidden by a closed door, which
+// the building geometry used by PerformVisionScan does not include.
+func (s *Soldier) dropContactsBehindDoors() {
+	if s.tileMap == nil {
+		return
+	}
+	kept := s.vision.KnownContacts[:0]
+	for _, c := range s.vision.KnownContacts {
+		if !s.tileMap.closedDoorBetween(s.x, s.y, c.x, c.y) {
+			kept = append(kept, c)
+		}
+	}
+	s.vision.KnownContacts = kept
+}
+
+// findEntryDoor returns the door on fp's outer wall nearest (x, y).
+func findEntryDoor(tm *TileMap, fp rect, x, y float64) (col, row int, ok bool) {
+	if tm == nil {
+		return 0, 0, false
+	}
+	c0, r0 := fp.x/cellSize, fp.y/cellSize
+	c1, r1 := (fp.x+fp.w-1)/cellSize, (fp.y+fp.h-1)/cellSize
+	best := math.MaxFloat64
+	for r := r0 - 1; r <= r1+1; r++ {
+		for c := c0 - 1; c <= c1+1; c++ {
+			if r > r0+1 && r < r1-1 && c > c0+1 && c < c1-1 {
+				continue // interior doors are not entry points
+			}
+			switch tm.ObjectAt(c, r) {
+			case ObjectDoor, ObjectDoorOpen, ObjectDoorBroken:
+			default:
+				continue
+			}
+			wx, wy := CellToWorld(c, r)
+			if d := math.Hypot(wx-x, wy-y); d < best {
+				best, col, row, ok = d, c, r, true
+			}
+		}
+	}
+	return col, row, ok
+}
+
+// breachEntryDoor has the lead entry-team member force the entry door once
+// the team has stacked and the plan moves to breaching.
+func (plan *BuildingEntryPlan) breachEntryDoor() {
+	if plan == nil || !plan.HasDoor || plan.State != EntryStateBreaching {
+		return
+	}
+	dx, dy := CellToWorld(plan.DoorCol, plan.DoorRow)
+	for _, m := range plan.EntryTeam {
+		if m.state == SoldierStateDead || m.state.IsIncapacitated() || m.tileMap == nil {
+			continue
+		}
+		if m.tileMap.ObjectAt(plan.DoorCol, plan.DoorRow) != ObjectDoor || m.doorAction == doorActionBreach {
+			return
+		}
+		if math.Hypot(m.x-dx, m.y-dy) <= doorBreachReach {
+			m.startDoorAction(doorActionBreach, plan.DoorCol, plan.DoorRow)
+			return
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package game
+
+import "testing"
+
+// newDoorTestSoldier puts a soldier at cell (10, 10) of a 50x38-cell tile
+// map with a door at (11, 10) and a path running east through it.
+func newDoorTestSoldier(door ObjectType, tick *int) (*Soldier, *TileMap) {
+	tm := NewTileMap(50, 38)
+	tm.SetObject(11, 10, door)
+	x, y := CellToWorld(10, 10)
+	s := newTestSoldier(0, x, y, TeamRed, tick)
+	s.tileMap = tm
+	s.navGrid.syncDoors(tm)
+	ex, ey := CellToWorld(14, 10)
+	s.path = [][2]float64{{ex, ey}}
+	s.pathIndex = 0
+	return s, tm
+}
+
+// stepDoors runs the door and movement parts of a soldier's tick.
+func stepDoors(s *Soldier) {
+	s.updateDoors()
+	s.moveAlongPath(1)
+}
+
+func TestDoors_ClosedDoorOnPathCostsTimeToOpen(t *testing.T) {
+	tick := 0
+	s, tm := newDoorTestSoldier(ObjectDoor, &tick)
+	x0 := s.x
+	for i := 0; i < doorOpenTicks; i++ {
+		stepDoors(s)
+		if s.x != x0 {
+			t.Fatalf("soldier moved on tick %d while opening the door", i)
+		}
+	}
+	stepDoors(s)
+	if tm.ObjectAt(11, 10) != ObjectDoorOpen {
+		t.Fatalf("door is %d after %d ticks, want open", tm.ObjectAt(11, 10), doorOpenTicks+1)
+	}
+	for i := 0; i < 10; i++ {
+		stepDoors(s)
+	}
+	if s.x <= x0 {
+		t.Error("soldier did not carry on through the opened door")
+	}
+	if c := s.navGrid.extra[10*s.navGrid.cols+11]; c != 0 {
+		t.Errorf("open door still costs %.1f to path through", c)
+	}
+}
+
+func TestDoors_LockedDoorIsBreachedLoudly(t *testing.T) {
+	tick := 0
+	s, tm := newDoorTestSoldier(ObjectDoor, &tick)
+	tm.AddFlag(11, 10, TileFlagLocked)
+	s.navGrid.syncDoors(tm)
+	k := 10*s.navGrid.cols + 11
+	if s.navGrid.extra[k] != lockedDoorPathCost {
+		t.Fatalf("locked door path cost %.1f, want %.1f", s.navGrid.extra[k], lockedDoorPathCost)
+	}
+
+	stepDoors(s)
+	if s.doorAction != doorActionBreach {
+		t.Fatalf("soldier is %s the locked door, want breaching", s.doorAction)
+	}
+	for i := 0; i < doorBreachTicks; i++ {
+		stepDoors(s)
+	}
+	if tm.ObjectAt(11, 10) != ObjectDoorBroken || tm.doorLocked(11, 10) {
+		t.Fatalf("door is %d after the breach, want broken", tm.ObjectAt(11, 10))
+	}
+	if s.doorNoise != doorBreachRange {
+		t.Errorf("breach noise range %.0f, want %.0f", s.doorNoise, doorBreachRange)
+	}
+	if _, ok := s.navGrid.extra[k]; ok {
+		t.Error("breached door still costs extra to path through")
+	}
+}
+
+func TestDoors_ClosedBehindWithThreatBeyond(t *testing.T) {
+	tick := 0
+	s, tm := newDoorTestSoldier(ObjectDoorOpen, &tick)
+	s.path = nil
+	s.x, s.y = CellToWorld(11, 10)
+	s.updateDoors()
+
+	// Walk on out of the doorway with nobody known on the far side.
+	s.x, s.y = CellToWorld(12, 10)
+	s.updateDoors()
+	if s.doorAction != doorActionNone {
+		t.Fatal("door closed behind with no threat beyond it")
+	}
+
+	s.x, s.y = CellToWorld(11, 10)
+	s.updateDoors()
+	tx, ty := CellToWorld(3, 10)
+	s.blackboard.Threats = []ThreatFact{{X: tx, Y: ty, Confidence: 1}}
+	s.x, s.y = CellToWorld(12, 10)
+	s.updateDoors()
+	if s.doorAction != doorActionClose {
+		t.Fatalf("soldier is %s the door, want closing it on the threat", s.doorAction)
+	}
+	for i := 0; i < doorCloseTicks; i++ {
+		s.updateDoors()
+	}
+	if tm.ObjectAt(11, 10) != ObjectDoor {
+		t.Errorf("door is %d, want closed", tm.ObjectAt(11, 10))
+	}
+	if s.doorNoise != soundDoorRange {
+		t.Errorf("closing noise range %.0f, want %.0f", s.doorNoise, soundDoorRange)
+	}
+}
+
+func TestDoors_ClosedDoorBlocksSight(t *testing.T) {
+	tick := 1
+	observer, tm := newDoorTestSoldier(ObjectDoor, &tick)
+	tx, ty := CellToWorld(13, 10)
+	target := newTestSoldier(1, tx, ty, TeamBlue, &tick)
+
+	observer.vision.Heading = 0
+	observer.UpdateVision([]*Soldier{target}, nil)
+	if len(observer.vision.KnownContacts) != 0 {
+		t.Fatal("observer saw through a closed door")
+	}
+
+	tm.setDoor(11, 10, ObjectDoorOpen)
+	observer.UpdateVision([]*Soldier{target}, nil)
+	if len(observer.vision.KnownContacts) != 1 {
+		t.Fatal("observer did not see a close target through the open door")
+	}
+}
+
+func TestDoors_EntryTeamBreachesEntryDoor(t *testing.T) {
+	tick := 0
+	tm := NewTileMap(50, 38)
+	fp := rect{x: 20 * cellSize, y: 10 * cellSize, w: 8 * cellSize, h: 6 * cellSize}
+	tm.SetObject(20, 12, ObjectDoor)
+	tm.SetObject(27, 14, ObjectDoor)
+	tm.SetObject(23, 12, ObjectDoor) // interior door, never an entry point
+
+	x, y := CellToWorld(18, 12)
+	col, row, ok := findEntryDoor(tm, fp, x, y)
+	if !ok || col != 20 || row != 12 {
+		t.Fatalf("entry door (%d, %d) ok=%v, want the west door at (20, 12)", col, row, ok)
+	}
+
+	lead := newTestSoldier(0, x, y, TeamRed, &tick)
+	lead.tileMap = tm
+	plan := &BuildingEntryPlan{State: EntryStateStacking, EntryTeam: []*Soldier{lead}, DoorCol: col, DoorRow: row, HasDoor: true}
+	plan.breachEntryDoor()
+	if lead.doorAction != doorActionNone {
+		t.Fatal("door breached before the team had stacked")
+	}
+	plan.State = EntryStateBreaching
+	plan.breachEntryDoor()
+	if lead.doorAction != doorActionBreach {
+		t.Fatalf("lead is %s the entry door, want breaching", lead.doorAction)
+	}
+}
+
+func TestDoors_EntryPlanBreachesLockedDoorInBattle(t *testing.T) {
+	bf := newEntryTestBattlefield(true)
+	ts := newEntryTestSim(bf)
+	sq := ts.squads[0]
+	var breacher *Soldier
+	ts.RunUntil(func(ts *TestSim) bool {
+		for _, s := range ts.Soldiers {
+			if s.doorAction == doorActionBreach {
+				breacher = s
+			}
+		}
+		return bf.TileMap.ObjectAt(20, 14) != ObjectDoor
+	}, 1500)
+
+	if o := bf.TileMap.ObjectAt(20, 14); o != ObjectDoorBroken {
+		t.Fatalf("locked entry door is %d, want broken", o)
+	}
+	if bf.TileMap.doorLocked(20, 14) {
+		t.Error("breached door is still locked")
+	}
+	if sq.entryPlan == nil || sq.entryPlan.State < EntryStateBreaching {
+		t.Fatal("door was forced without the entry plan reaching breaching")
+	}
+	if breacher == nil || sq.entryPlan.stageFor(breacher) == EntryStateNone {
+		t.Error("door was not breached by an entry-team member")
+	}
+}
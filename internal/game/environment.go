@@ -0,0 +1,275 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// --- Environment constants ---
+
+const (
+	ticksPerSimHour = 60 * 60 * 60 // 60 TPS, real-time clock
+
+	envNightLight      = 0.06   // ambient light under a clear night sky
+	envMinVisionMul    = 0.12   // vision never drops below this fraction of MaxRange
+	envFlashMaxRange   = 1400.0 // px, muzzle flash visible at full dark and no fog
+	envFlashLightLimit = 0.50   // ambient light above which flashes give nothing away
+
+	envWetRate         = 0.002   // wetness approach rate toward rain intensity per tick
+	envDryRate         = 0.00005 // wetness lost per tick once the rain stops
+	envMudIntervalTick = 600     // ticks between mud-spread passes
+	envMudWetness      = 0.30    // wetness above which mud starts to spread
+	envMudSpreadChance = 0.25    // spread chance per pass at full saturation
+)
+
+// Environment is the world's weather and time of day. It scales vision,
+// hearing and movement, and spreads mud while the ground is wet. A nil
+// *Environment behaves like a calm, clear midday.
+type Environment struct {
+	Hour      float64 // local time at tick 0, 0-24
+	TimeScale float64 // sim hours per real hour; 0 means 1
+	Rain      float64 // 0-1 intensity
+	Fog       float64 // 0-1 density
+	WindSpeed float64 // m/s
+	WindDir   float64 // degrees the wind blows toward (0 = +x, 90 = +y)
+
+	wetness float64 // 0-1 ground saturation, lags behind rain
+}
+
+// DefaultEnvironment returns a calm, clear midday: every multiplier is 1.
+func DefaultEnvironment() *Environment {
+	return &Environment{Hour: 12}
+}
+
+// NewEnvironment returns an environment whose ground is already as wet as the
+// current rain would make it.
+func NewEnvironment(hour, rain, fog, windSpeed, windDir float64) *Environment {
+	return &Environment{
+		Hour:      hour,
+		Rain:      clamp01(rain),
+		Fog:       clamp01(fog),
+		WindSpeed: math.Max(0, windSpeed),
+		WindDir:   windDir,
+		wetness:   clamp01(rain),
+	}
+}
+
+// HourAt returns the local time of day at tick.
+func (e *Environment) HourAt(tick int) float64 {
+	if e == nil {
+		return 12
+	}
+	scale := e.TimeScale
+	if scale <= 0 {
+		scale = 1
+	}
+	h := math.Mod(e.Hour+float64(tick)*scale/ticksPerSimHour, 24)
+	if h < 0 {
+		h += 24
+	}
+	return h
+}
+
+// Light returns ambient light at tick, 1 in full daylight down to
+// envNightLight at night, with a twilight ramp around dawn and dusk.
+func (e *Environment) Light(tick int) float64 {
+	if e == nil {
+		return 1
+	}
+	sun := math.Sin((e.HourAt(tick) - 6) / 12 * math.Pi)
+	return math.Max(envNightLight, clamp01((sun+0.12)/0.30))
+}
+
+// Wetness returns current ground saturation, 0-1.
+func (e *Environment) Wetness() float64 {
+	if e == nil {
+		return 0
+	}
+	return e.wetness
+}
+
+// VisionMul scales vision range for light, fog and rain.
+func (e *Environment) VisionMul(tick int) float64 {
+	if e == nil {
+		return 1
+	}
+	light := 0.18 + 0.82*e.Light(tick)
+	return math.Max(envMinVisionMul, light*(1-e.Fog*0.75)*(1-e.Rain*0.30))
+}
+
+// HearingMul scales how well a sound at (sx, sy) is heard at (lx, ly). Rain
+// masks sound, the night is quieter, and sound carries further downwind.
+func (e *Environment) HearingMul(tick int, sx, sy, lx, ly float64) float64 {
+	if e == nil {
+		return 1
+	}
+	mul := (1 - e.Rain*0.35) * (1 + (1-e.Light(tick))*0.10)
+	if e.WindSpeed > 0 {
+		if d := math.Hypot(lx-sx, ly-sy); d > 1 {
+			wx, wy := e.windUnit()
+			along := (wx*(lx-sx) + wy*(ly-sy)) / d
+			mul *= 1 + along*math.Min(1, e.WindSpeed/10)*0.25
+		}
+	}
+	return mul
+}
+
+// MoveMul scales movement speed at (x, y). Darkness makes soldiers pick their
+// footing, rain slows everyone slightly, and mud only bogs soldiers down while
+// the ground is wet.
+func (e *Environment) MoveMul(tick int, tm *TileMap, x, y float64) float64 {
+	if e == nil {
+		return 1
+	}
+	mul := (1 - (1-e.Light(tick))*0.12) * (1 - e.Rain*0.05)
+	if tm != nil && tm.Ground(int(x)/cellSize, int(y)/cellSize) == GroundMud {
+		mul *= 1 - (1-groundMovementMul(GroundMud))*e.wetness
+	}
+	return mul
+}
+
+// FlashRange returns how far away a muzzle flash can be seen at tick. In
+// daylight it is zero; at night a flash gives the shooter away at long range.
+func (e *Environment) FlashRange(tick int) float64 {
+	if e == nil {
+		return 0
+	}
+	dark := clamp01((envFlashLightLimit - e.Light(tick)) / (envFlashLightLimit - envNightLight))
+	return envFlashMaxRange * dark * (1 - e.Fog*0.8)
+}
+
+// WindVector returns the wind as a drift in px per tick for smoke.
+func (e *Environment) WindVector() (float64, float64) {
+	if e == nil || e.WindSpeed <= 0 {
+		return 0, 0
+	}
+	wx, wy := e.windUnit()
+	speed := e.WindSpeed * 0.02 // 5 m/s ≈ 0.10 px/tick
+	return wx * speed, wy * speed
+}
+
+func (e *Environment) windUnit() (float64, float64) {
+	rad := e.WindDir * math.Pi / 180
+	return math.Cos(rad), math.Sin(rad)
+}
+
+// Update advances ground wetness and, on a wet map, spreads mud outward from
+// existing mud and water. tm may be nil (TestSim has no tile map).
+func (e *Environment) Update(tick int, tm *TileMap) {
+	if e == nil {
+		return
+	}
+	if e.Rain > e.wetness {
+		e.wetness += (e.Rain - e.wetness) * envWetRate
+	} else {
+		e.wetness = math.Max(e.Rain, e.wetness-envDryRate)
+	}
+	if tm == nil || tick <= 0 || tick%envMudIntervalTick != 0 || e.wetness <= envMudWetness {
+		return
+	}
+	chance := (e.wetness - envMudWetness) / (1 - envMudWetness) * envMudSpreadChance
+	var spread []int
+	for row := 0; row < tm.Rows; row++ {
+		for col := 0; col < tm.Cols; col++ {
+			if !mudCanSpreadTo(tm.Ground(col, row)) || tm.ObjectAt(col, row) != ObjectNone {
+				continue
+			}
+			if !tm.nextToWetGround(col, row) {
+				continue
+			}
+			if envRoll(col, row, tick) < chance {
+				spread = append(spread, row*tm.Cols+col)
+			}
+		}
+	}
+	// Apply after the scan so mud grows one ring per pass.
+	for _, i := range spread {
+		tm.Tiles[i].Ground = GroundMud
+	}
+}
+
+// mudCanSpreadTo reports whether rain can turn ground of type g into mud.
+func mudCanSpreadTo(g GroundType) bool {
+	switch g {
+	case GroundGrass, GroundGrassLong, GroundScrub, GroundDirt:
+		return true
+	}
+	return false
+}
+
+// nextToWetGround reports whether a 4-neighbour of (col, row) is mud or water.
+func (tm *TileMap) nextToWetGround(col, row int) bool {
+	for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		c, r := col+d[0], row+d[1]
+		if !tm.inBounds(c, r) {
+			continue
+		}
+		if g := tm.Ground(c, r); g == GroundMud || g == GroundWater {
+			return true
+		}
+	}
+	return false
+}
+
+// envRoll is a deterministic 0-1 roll for weather effects on a tile.
+func envRoll(col, row, tick int) float64 {
+	v := math.Sin(float64(col)*12.9898+float64(row)*78.233+float64(tick)*0.0137) * 43758.5453
+	return v - math.Floor(v)
+}
+
+// noticeMuzzleFlash lets s spot the flash of an enemy rifle at night. A seen
+// flash pins the shooter's position on the blackboard even when the shooter
+// is too far away or too dark to be seen directly.
+func (s *Soldier) noticeMuzzleFlash(ev GunfireEvent, tick int) {
+	if ev.Shooter == nil || s.state.IsIncapacitated() {
+		return
+	}
+	r := s.env.FlashRange(tick)
+	if r <= 0 || math.Hypot(ev.X-s.x, ev.Y-s.y) > r {
+		return
+	}
+	if !HasClearLineOfSight(s.x, s.y, ev.X, ev.Y, s.buildings, s.covers, s.smoke) {
+		return
+	}
+	s.blackboard.NoteMuzzleFlash(ev.Shooter, ev.X, ev.Y, tick)
+}
+
+// String summarises the conditions for reports, e.g. "23:30 rain:0.6 fog:0.2".
+func (e *Environment) String() string {
+	if e == nil {
+		e = DefaultEnvironment()
+	}
+	h := e.HourAt(0)
+	parts := []string{fmt.Sprintf("%02d:%02d", int(h), int(math.Mod(h, 1)*60))}
+	if e.Rain > 0 {
+		parts = append(parts, fmt.Sprintf("rain:%.1f", e.Rain))
+	}
+	if e.Fog > 0 {
+		parts = append(parts, fmt.Sprintf("fog:%.1f", e.Fog))
+	}
+	if e.WindSpeed > 0 {
+		parts = append(parts, fmt.Sprintf("wind:%.0fm/s@%.0f°", e.WindSpeed, e.WindDir))
+	}
+	if len(parts) == 1 {
+		parts = append(parts, "clear")
+	}
+	return strings.Join(parts, " ")
+}
+
+// DrawOverlay darkens the battlefield for night and greys it for fog and rain.
+func (e *Environment) DrawOverlay(screen *ebiten.Image, w, h float32, tick int) {
+	if e == nil {
+		return
+	}
+	if dark := 1 - e.Light(tick); dark > 0.01 {
+		vector.FillRect(screen, 0, 0, w, h, color.RGBA{R: 4, G: 8, B: 24, A: uint8(170 * dark)}, false)
+	}
+	if haze := clamp01(e.Fog*0.8 + e.Rain*0.25); haze > 0.01 {
+		vector.FillRect(screen, 0, 0, w, h, color.RGBA{R: 150, G: 155, B: 160, A: uint8(110 * haze)}, false)
+	}
+}
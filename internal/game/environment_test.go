@@ -0,0 +1,141 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvironment_NightAndFogShortenVision(t *testing.T) {
+	noon := DefaultEnvironment()
+	if got := noon.VisionMul(0); got != 1 {
+		t.Fatalf("clear noon vision mul %.3f, want 1", got)
+	}
+	midnight := NewEnvironment(0, 0, 0, 0, 0)
+	if got := midnight.VisionMul(0); got > 0.3 {
+		t.Fatalf("midnight vision mul %.3f, want well under a third", got)
+	}
+	foggy := NewEnvironment(12, 0, 0.8, 0, 0)
+	if got := foggy.VisionMul(0); got >= 0.5 {
+		t.Fatalf("thick fog vision mul %.3f, want under half", got)
+	}
+	if dusk := NewEnvironment(18, 0, 0, 0, 0).Light(0); dusk <= envNightLight || dusk >= 1 {
+		t.Fatalf("dusk light %.3f, want between night and day", dusk)
+	}
+	// The clock advances with the tick.
+	if h := midnight.HourAt(ticksPerSimHour * 6); h != 6 {
+		t.Fatalf("six sim hours after midnight is %.2f", h)
+	}
+
+	tick := 0
+	target := newTestSoldier(1, 100+0.6*NewVisionState(0).MaxRange, 200, TeamBlue, &tick)
+	v := NewVisionState(0)
+	v.PerformVisionScan(100, 200, []*Soldier{target}, nil, nil, nil)
+	if len(v.KnownContacts) != 1 {
+		t.Fatal("target should be seen in daylight")
+	}
+	v.EnvMul = midnight.VisionMul(0)
+	v.PerformVisionScan(100, 200, []*Soldier{target}, nil, nil, nil)
+	if len(v.KnownContacts) != 0 {
+		t.Fatal("same target should be out of sight at midnight")
+	}
+}
+
+func TestEnvironment_MuzzleFlashRevealsShooterAtNight(t *testing.T) {
+	tick := 0
+	shooter := newTestSoldier(1, 1000, 200, TeamBlue, &tick)
+	watcher := newTestSoldier(2, 100, 200, TeamRed, &tick)
+	ev := GunfireEvent{X: shooter.x, Y: shooter.y, Team: TeamBlue, Shooter: shooter}
+
+	watcher.env = DefaultEnvironment()
+	watcher.noticeMuzzleFlash(ev, tick)
+	if len(watcher.blackboard.Threats) != 0 {
+		t.Fatal("a muzzle flash should give nothing away in daylight")
+	}
+
+	watcher.env = NewEnvironment(1, 0, 0, 0, 0)
+	watcher.noticeMuzzleFlash(ev, tick)
+	if len(watcher.blackboard.Threats) != 1 {
+		t.Fatalf("expected one threat from the flash, got %d", len(watcher.blackboard.Threats))
+	}
+	th := watcher.blackboard.Threats[0]
+	if th.Source != shooter || th.IsVisible || th.X != shooter.x || th.Y != shooter.y {
+		t.Fatalf("flash threat %+v, want the shooter's position and not visible", th)
+	}
+}
+
+func TestEnvironment_RainMasksSoundAndWindCarriesIt(t *testing.T) {
+	calm := DefaultEnvironment()
+	if got := calm.HearingMul(0, 0, 0, 500, 0); got != 1 {
+		t.Fatalf("calm noon hearing mul %.3f, want 1", got)
+	}
+	rain := NewEnvironment(12, 1, 0, 0, 0)
+	if got := rain.HearingMul(0, 0, 0, 500, 0); got >= 1 {
+		t.Fatalf("heavy rain hearing mul %.3f, want < 1", got)
+	}
+	wind := NewEnvironment(12, 0, 0, 10, 0) // blowing toward +x
+	down := wind.HearingMul(0, 0, 0, 500, 0)
+	up := wind.HearingMul(0, 500, 0, 0, 0)
+	if down <= 1 || up >= 1 {
+		t.Fatalf("downwind %.3f upwind %.3f, want above and below 1", down, up)
+	}
+}
+
+func TestEnvironment_MudSpreadsAndSlowsWhenWet(t *testing.T) {
+	tm := NewTileMap(8, 8)
+	for i := range tm.Tiles {
+		tm.Tiles[i].Ground = GroundGrass
+	}
+	tm.SetGround(4, 4, GroundMud)
+	x, y := float64(4*cellSize+cellSize/2), float64(4*cellSize+cellSize/2)
+
+	dry := NewEnvironment(12, 0, 0, 0, 0)
+	if got := dry.MoveMul(0, tm, x, y); got != 1 {
+		t.Fatalf("dry mud move mul %.3f, want 1", got)
+	}
+	for tick := 1; tick <= envMudIntervalTick*4; tick++ {
+		dry.Update(tick, tm)
+	}
+	if tm.Ground(5, 4) != GroundGrass {
+		t.Fatal("mud should not spread on dry ground")
+	}
+
+	wet := NewEnvironment(12, 1, 0, 0, 0)
+	if got := wet.MoveMul(0, tm, x, y); got >= 0.8 {
+		t.Fatalf("saturated mud move mul %.3f, want a real slowdown", got)
+	}
+	for tick := 1; tick <= envMudIntervalTick*40; tick++ {
+		wet.Update(tick, tm)
+	}
+	mud := 0
+	for i := range tm.Tiles {
+		if tm.Tiles[i].Ground == GroundMud {
+			mud++
+		}
+	}
+	if mud <= 1 {
+		t.Fatal("mud should spread across a saturated field")
+	}
+}
+
+func TestParseScenario_Environment(t *testing.T) {
+	withEnv := strings.Replace(testScenarioJSON, `"stop":`, `"environment": {"hour": 23.5, "rain": 0.6, "wind": 4, "wind_dir": 90},
+  "stop":`, 1)
+	sc, err := ParseScenario([]byte(withEnv))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ts := NewTestSimFromScenario(sc, 1)
+	env := ts.Environment()
+	if env.Hour != 23.5 || env.Rain != 0.6 || env.WindSpeed != 4 {
+		t.Fatalf("environment not applied: %s", env)
+	}
+	if ts.Soldiers[0].env != env {
+		t.Fatal("soldiers should share the sim's environment")
+	}
+
+	bad := strings.Replace(testScenarioJSON, `"stop":`, `"environment": {"fog": 1.5},
+  "stop":`, 1)
+	if _, err := ParseScenario([]byte(bad)); err == nil || !strings.Contains(err.Error(), "fog") {
+		t.Fatalf("expected a fog range error, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package game
+
+import "math"
+
+// ---------------------------------------------------------------------------
+// Tactical Evacuation — casualty collection points and stretcher carries
+// ---------------------------------------------------------------------------
+
+const (
+	stretcherBearers  = 2     // a litter needs two soldiers
+	stretcherSpeedMul = 0.45  // bearers with a litter move at under half pace
+	ccpStandoff       = 260.0 // px from the squad leader back toward the rear
+	ccpArriveRadius   = 30.0  // px; close enough to set a casualty down at the CCP
+	evacArriveRadius  = 40.0  // px; close enough to hand over at the evacuation point
+	evacQuietTicks    = 300   // casualties wait at the CCP until the squad's fight has been quiet this long
+	bearerTrailOffset = 10.0  // px; the rear bearer walks this far behind the casualty
+)
+
+// EvacPoint is where a team's casualties leave the field, e.g. an ambulance
+// exchange point. A stabilised casualty carried here is evacuated.
+type EvacPoint struct {
+	Team Team
+	X, Y float64
+}
+
+// casualtyStabilized reports whether every wound has been treated.
+func casualtyStabilized(c *Soldier) bool {
+	return c.body.IsInjured() && !c.body.HasUntreatedWounds()
+}
+
+// updateCasualtyPhase moves a casualty into TACEVAC once they are stabilised
+// but cannot walk, and back to field care if they are hit again.
+func updateCasualtyPhase(c *Soldier, tick int) {
+	cs := &c.casualty
+	stable := casualtyStabilized(c)
+	if stable && cs.StabilizedTick == 0 {
+		cs.StabilizedTick = tick
+	}
+	switch {
+	case cs.Phase != PhaseTACEVAC && stable && c.state.IsIncapacitated():
+		cs.Phase = PhaseTACEVAC
+		cs.PhaseTick = tick
+		stopDraggingCasualty(c)
+		c.think("stabilised — awaiting evacuation")
+	case cs.Phase == PhaseTACEVAC && !stable:
+		cs.Phase = PhaseTFC
+		cs.PhaseTick = tick
+		cs.StabilizedTick = 0
+		releaseStretcher(c)
+	}
+}
+
+// evacDestination is where c's bearers should carry them next: the squad's
+// CCP, then the team's evacuation point once the squad's fight has gone
+// quiet. ok is false while c should stay where they are.
+func (c *Soldier) evacDestination() (x, y float64, ok bool) {
+	sq := c.squad
+	if sq == nil || c.casualty.Phase != PhaseTACEVAC || c.casualty.Evacuated {
+		return 0, 0, false
+	}
+	if !c.casualty.AtCCP {
+		return sq.ccpX, sq.ccpY, sq.ccpSet
+	}
+	if sq.evac == nil || c.tickVal()-sq.lastContactTick < evacQuietTicks {
+		return 0, 0, false
+	}
+	return sq.evac.X, sq.evac.Y, true
+}
+
+// needsStretcher reports whether c is a TACEVAC casualty with somewhere to
+// be carried.
+func (c *Soldier) needsStretcher() bool {
+	if c.state == SoldierStateDead {
+		return false
+	}
+	_, _, ok := c.evacDestination()
+	return ok
+}
+
+// joinStretcher takes one of the handles of c's litter.
+func (s *Soldier) joinStretcher(c *Soldier) {
+	if s.litter == c || len(c.casualty.Bearers) >= stretcherBearers {
+		return
+	}
+	if s.litter != nil {
+		s.leaveStretcher()
+	}
+	c.casualty.Bearers = append(c.casualty.Bearers, s)
+	s.litter = c
+	s.path = nil
+	s.think("taking a stretcher handle")
+}
+
+// leaveStretcher lets go of the litter s is carrying, if any.
+func (s *Soldier) leaveStretcher() {
+	c := s.litter
+	if c == nil {
+		return
+	}
+	s.litter = nil
+	for i, b := range c.casualty.Bearers {
+		if b == s {
+			c.casualty.Bearers = append(c.casualty.Bearers[:i], c.casualty.Bearers[i+1:]...)
+			break
+		}
+	}
+}
+
+// sameStretcher reports whether s and m are both part of one stretcher
+// party, who stay in contact instead of keeping personal space.
+func (s *Soldier) sameStretcher(m *Soldier) bool {
+	return (s.litter != nil && (s.litter == m || s.litter == m.litter)) || (m.litter != nil && m.litter == s)
+}
+
+// releaseStretcher sets c down and frees every bearer.
+func releaseStretcher(c *Soldier) {
+	for len(c.casualty.Bearers) > 0 {
+		c.casualty.Bearers[0].leaveStretcher()
+	}
+}
+
+// designateCCP picks the squad's casualty collection point the first time a
+// member is ready to be carried back: ccpStandoff behind the leader, toward
+// the team's evacuation point or, without one, the squad's start line.
+func (sq *Squad) designateCCP() {
+	if sq.ccpSet || sq.Leader == nil {
+		return
+	}
+	waiting := false
+	for _, m := range sq.Members {
+		waiting = waiting || (m.state != SoldierStateDead && m.casualty.Phase == PhaseTACEVAC)
+	}
+	if !waiting {
+		return
+	}
+	var rx, ry float64
+	if sq.evac != nil {
+		rx, ry = sq.evac.X, sq.evac.Y
+	} else {
+		for _, m := range sq.Members {
+			rx += m.startTarget[0]
+			ry += m.startTarget[1]
+		}
+		rx /= float64(len(sq.Members))
+		ry /= float64(len(sq.Members))
+	}
+	lx, ly := sq.Leader.x, sq.Leader.y
+	d := math.Hypot(rx-lx, ry-ly)
+	step := math.Min(ccpStandoff, d)
+	// Back off toward the leader until the point is walkable.
+	for ; step > 0; step -= cellSize {
+		x, y := lx+(rx-lx)/d*step, ly+(ry-ly)/d*step
+		if ng := sq.Leader.navGrid; ng != nil && ng.IsBlocked(int(x/cellSize), int(y/cellSize)) {
+			continue
+		}
+		sq.ccpX, sq.ccpY = x, y
+		break
+	}
+	if step <= 0 {
+		sq.ccpX, sq.ccpY = lx, ly
+	}
+	sq.ccpSet = true
+	sq.Leader.think("CCP designated to the rear")
+}
+
+// integrateEvacuation keeps c's stretcher party together and lands c at the
+// CCP or the evacuation point. Called from integrateBuddyAidTick.
+func integrateEvacuation(c *Soldier, tick int) {
+	cs := &c.casualty
+	for i := len(cs.Bearers) - 1; i >= 0; i-- {
+		b := cs.Bearers[i]
+		if b.state == SoldierStateDead || b.state.IsIncapacitated() || b.blackboard.CurrentGoal != GoalHelpCasualty {
+			b.leaveStretcher()
+		}
+	}
+	if cs.Phase != PhaseTACEVAC || c.squad == nil {
+		return
+	}
+	sq := c.squad
+	if !cs.AtCCP && sq.ccpSet && math.Hypot(c.x-sq.ccpX, c.y-sq.ccpY) < ccpArriveRadius {
+		cs.AtCCP = true
+		releaseStretcher(c)
+		c.think("at the CCP")
+	}
+	if cs.AtCCP && sq.evac != nil && math.Hypot(c.x-sq.evac.X, c.y-sq.evac.Y) < evacArriveRadius {
+		evacuate(c, tick)
+	}
+}
+
+// evacuate takes c off the field. An evacuated soldier is put in the dead
+// state so every system stops seeing them; casualty.Evacuated is what tells
+// them apart in the outcome, the AAR and the renderer. The leader knows the
+// casualty has gone back, so any status request to them is dropped rather
+// than left to time out.
+func evacuate(c *Soldier, tick int) {
+	releaseStretcher(c)
+	c.casualty.Evacuated = true
+	c.casualty.EvacuatedTick = tick
+	c.state = SoldierStateDead
+	if c.squad != nil {
+		c.squad.dropRadioMember(c.id)
+	}
+	c.think("evacuated")
+}
+
+// offField reports whether s has left play, killed or evacuated. Movement,
+// sensing, comms and squad bookkeeping only care that s is gone; anything
+// that counts losses or feeds morale must use killed instead.
+func (s *Soldier) offField() bool {
+	return s.state == SoldierStateDead
+}
+
+// killed reports whether s died, as opposed to being evacuated.
+func (s *Soldier) killed() bool {
+	return s.offField() && !s.casualty.Evacuated
+}
+
+// executeStretcherCarry moves s's litter toward its destination. The front
+// bearer paths at stretcher pace and the casualty and rear bearer follow.
+func (s *Soldier) executeStretcherCarry(dt float64) {
+	c := s.litter
+	bearers := c.casualty.Bearers
+	tx, ty, ok := c.evacDestination()
+	s.requestStance(StanceCrouching, false)
+	if !ok {
+		s.leaveStretcher()
+		s.state = SoldierStateIdle
+		return
+	}
+	if len(bearers) < stretcherBearers {
+		// Wait at the casualty for a second bearer.
+		s.state = SoldierStateIdle
+		s.vision.UpdateHeading(math.Atan2(c.y-s.y, c.x-s.x), turnRate)
+		return
+	}
+	if bearers[0] != s {
+		// Rear bearer: keep hold of the litter behind the casualty.
+		h := bearers[0].vision.Heading
+		s.x, s.y = c.x-math.Cos(h)*bearerTrailOffset, c.y-math.Sin(h)*bearerTrailOffset
+		s.vision.UpdateHeading(h, turnRate)
+		s.state = SoldierStateMoving
+		return
+	}
+	s.state = SoldierStateMoving
+	if s.navGrid != nil {
+		tick := s.tickVal()
+		if s.path == nil || s.pathIndex >= len(s.path) || tick%15 == 0 {
+			// Walk to where the casualty, not the front bearer, lands on the point.
+			s.path = s.navGrid.FindPath(s.x, s.y, tx+s.x-c.x, ty+s.y-c.y)
+			s.pathIndex = 0
+		}
+	}
+	px, py := s.x, s.y
+	s.moveAlongPath(dt)
+	c.x += s.x - px
+	c.y += s.y - py
+}
@@ -0,0 +1,158 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// makeStretcherCase gives s a treated leg wound they cannot walk on.
+func makeStretcherCase(s *Soldier, tick int) {
+	s.body.HP[RegionLegLeft] = 0
+	s.body.Wounds = append(s.body.Wounds, Wound{Region: RegionLegLeft, Severity: WoundCritical, Treated: true, TickInflicted: tick})
+	s.casualty = NewCasualtyState(tick)
+	s.state = SoldierStateWoundedNonAmbulatory
+}
+
+// newEvacSim is a four-man red squad advancing east with no enemy, and an
+// evacuation point back on its start line.
+func newEvacSim(extra ...SimOption) *TestSim {
+	opts := []SimOption{
+		WithSeed(9),
+		WithMapSize(1400, 600),
+		WithRedSoldier(0, 500, 300, 1300, 300),
+		WithRedSoldier(1, 490, 280, 1300, 280),
+		WithRedSoldier(2, 490, 320, 1300, 320),
+		WithRedSoldier(3, 480, 300, 1300, 300),
+		WithRedSquad(0, 1, 2, 3),
+	}
+	return NewTestSim(append(opts, extra...)...)
+}
+
+func TestEvacuation_StretcherCarriesToCCPThenEvacPoint(t *testing.T) {
+	ts := newEvacSim(WithEvacPoint(EvacPoint{Team: TeamRed, X: 80, Y: 300}))
+	sq := ts.squads[0]
+	casualty := ts.Soldiers[3]
+	ts.RunTicks(60)
+	makeStretcherCase(casualty, ts.tick)
+
+	if ts.RunUntil(func(*TestSim) bool { return sq.ccpSet }, 10) < 0 {
+		t.Fatalf("no CCP designated for a stabilised stretcher case")
+	}
+	if casualty.casualty.Phase != PhaseTACEVAC {
+		t.Fatalf("casualty phase = %s, want TACEVAC", casualty.casualty.Phase)
+	}
+	if sq.ccpX >= sq.Leader.x {
+		t.Errorf("CCP x=%.0f is not behind the leader at x=%.0f", sq.ccpX, sq.Leader.x)
+	}
+
+	// Once two bearers hold the litter it moves, but only at stretcher pace.
+	lastX, lastY := casualty.x, casualty.y
+	carried := false
+	ok := ts.RunUntil(func(*TestSim) bool {
+		moved := math.Hypot(casualty.x-lastX, casualty.y-lastY)
+		lastX, lastY = casualty.x, casualty.y
+		if len(casualty.casualty.Bearers) == stretcherBearers {
+			carried = carried || moved > 0
+			if moved > soldierSpeed*stretcherSpeedMul+1e-6 {
+				t.Fatalf("litter moved %.2f px in a tick, faster than stretcher pace", moved)
+			}
+		}
+		return casualty.casualty.AtCCP
+	}, 1500)
+	if ok < 0 || !carried {
+		t.Fatalf("casualty never reached the CCP: at (%.0f,%.0f), CCP (%.0f,%.0f), bearers=%d",
+			casualty.x, casualty.y, sq.ccpX, sq.ccpY, len(casualty.casualty.Bearers))
+	}
+
+	if ts.RunUntil(func(*TestSim) bool { return casualty.casualty.Evacuated }, 3000) < 0 {
+		t.Fatalf("casualty never evacuated: at (%.0f,%.0f)", casualty.x, casualty.y)
+	}
+	for _, m := range sq.Members {
+		if m.litter != nil {
+			t.Errorf("%s still holds a stretcher after the evacuation", m.label)
+		}
+	}
+	r := ts.Outcome()
+	if r.RedEvacuated != 1 || r.RedKilled != 0 || r.RedSurvivors != 4 {
+		t.Errorf("outcome evacuated=%d killed=%d survivors=%d, want 1, 0 and 4", r.RedEvacuated, r.RedKilled, r.RedSurvivors)
+	}
+	if sq.CasualtyCount() != 0 {
+		t.Errorf("an evacuation counted as a squad casualty")
+	}
+}
+
+func TestEvacuation_IsNotALossToSquadMorale(t *testing.T) {
+	ts := newEvacSim()
+	sq := ts.squads[0]
+	casualty, other := ts.Soldiers[3], ts.Soldiers[2]
+	ts.RunTicks(60)
+	makeStretcherCase(casualty, ts.tick)
+	ts.RunTicks(1)
+
+	sq.ensureRadioState()
+	sq.radioPendingStatus[casualty.id] = ts.tick + 5
+	shock := sq.cohesionShock
+	evacuate(casualty, ts.tick)
+	sq.SquadThink(nil)
+	if !casualty.offField() || casualty.killed() {
+		t.Fatalf("evacuee offField=%v killed=%v, want true and false", casualty.offField(), casualty.killed())
+	}
+	if sq.cohesionShock > shock {
+		t.Errorf("evacuation raised cohesion shock %.3f -> %.3f", shock, sq.cohesionShock)
+	}
+	if len(sq.Alive()) != 3 || sq.CasualtyCount() != 0 {
+		t.Errorf("alive=%d casualties=%d after an evacuation, want 3 and 0", len(sq.Alive()), sq.CasualtyCount())
+	}
+	ts.RunTicks(30)
+	if sq.RadioTimeouts != 0 || sq.radioUnresponsive[casualty.id] {
+		t.Errorf("leader timed out waiting on a status reply from an evacuee")
+	}
+
+	// A death, by contrast, is a shock.
+	shock = sq.cohesionShock
+	other.state = SoldierStateDead
+	sq.SquadThink(nil)
+	if sq.cohesionShock <= shock || sq.CasualtyCount() != 1 {
+		t.Errorf("a death left shock %.3f -> %.3f and casualties %d", shock, sq.cohesionShock, sq.CasualtyCount())
+	}
+}
+
+func TestEvacuation_WaitsAtCCPWithoutEvacPoint(t *testing.T) {
+	ts := newEvacSim()
+	casualty := ts.Soldiers[3]
+	ts.RunTicks(60)
+	makeStretcherCase(casualty, ts.tick)
+
+	if ts.RunUntil(func(*TestSim) bool { return casualty.casualty.AtCCP }, 1500) < 0 {
+		t.Fatalf("casualty never reached the CCP")
+	}
+	ts.RunTicks(evacQuietTicks + 60)
+	if casualty.casualty.Evacuated || casualty.needsStretcher() {
+		t.Fatalf("casualty left the CCP with no evacuation point to go to")
+	}
+	if r := ts.Outcome(); r.RedIncapacitated != 1 || r.RedEvacuated != 0 {
+		t.Errorf("outcome incapacitated=%d evacuated=%d, want 1 and 0", r.RedIncapacitated, r.RedEvacuated)
+	}
+}
+
+func TestEvacuation_FreshWoundReturnsCasualtyToFieldCare(t *testing.T) {
+	ts := newEvacSim()
+	casualty, bearer := ts.Soldiers[3], ts.Soldiers[1]
+	ts.RunTicks(1)
+	makeStretcherCase(casualty, ts.tick)
+	updateCasualtyPhase(casualty, ts.tick)
+	casualty.squad.designateCCP()
+	bearer.joinStretcher(casualty)
+
+	casualty.body.Wounds = append(casualty.body.Wounds, Wound{Region: RegionTorso, Severity: WoundSevere, BleedRate: 0.05})
+	updateCasualtyPhase(casualty, ts.tick)
+	if casualty.casualty.Phase != PhaseTFC {
+		t.Fatalf("phase = %s, want TFC after a fresh wound", casualty.casualty.Phase)
+	}
+	if bearer.litter != nil || len(casualty.casualty.Bearers) != 0 {
+		t.Errorf("the bearers kept carrying a casualty who needs treatment")
+	}
+	if !casualtyNeedsAid(casualty) {
+		t.Errorf("casualty with a fresh wound does not need aid")
+	}
+}
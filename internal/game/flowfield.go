@@ -61,6 +61,9 @@ type CostField struct {
 	// Friendly occupancy (slight penalty to encourage spacing)
 	occupancy []int
 
+	// Player-painted avoid (positive) and preferred-route (negative) cost; nil until painted
+	paintCost []float64
+
 	// Dirty flags for incremental updates
 	dirty []bool
 }
@@ -103,7 +106,12 @@ func (cf *CostField) GetCost(x, y int) float64 {
 	cover := cf.coverBonus[idx]
 	threat := cf.threatCost[idx]
 	occupancyCost := float64(cf.occupancy[idx]) * 0.2
-	return base - cover + threat + occupancyCost
+	cost := base - cover + threat + occupancyCost
+	if cf.paintCost != nil {
+		// Keep painted routes cheap but never free.
+		cost = math.Max(0.05, cost+cf.paintCost[idx])
+	}
+	return cost
 }
 
 func (cf *CostField) SetOccupancy(x, y int, count int) {
@@ -115,6 +123,38 @@ func (cf *CostField) SetOccupancy(x, y int, count int) {
 	cf.dirty[idx] = true
 }
 
+// layerInputs records the changing cost-field inputs a flow layer was last
+// computed from, so a save state can rebuild the layer.
+type layerInputs struct {
+	occupied []int     // cell index per occupant
+	paint    []float64 // paint cost slice in force; nil when unpainted
+}
+
+func (cf *CostField) inputs() layerInputs {
+	return layerInputs{occupied: cf.occupiedCells(), paint: cf.paintCost}
+}
+
+// occupiedCells lists the index of every occupied cell, once per occupant.
+func (cf *CostField) occupiedCells() []int {
+	var cells []int
+	for i, n := range cf.occupancy {
+		for ; n > 0; n-- {
+			cells = append(cells, i)
+		}
+	}
+	return cells
+}
+
+// setOccupiedCells replaces the occupancy with the given cells.
+func (cf *CostField) setOccupiedCells(cells []int) {
+	for i := range cf.occupancy {
+		cf.occupancy[i] = 0
+	}
+	for _, i := range cells {
+		cf.occupancy[i]++
+	}
+}
+
 func (cf *CostField) UpdateThreats(enemies []*Soldier, threatRadius int) {
 	// Clear previous threats
 	for i := range cf.threatCost {
@@ -42,6 +42,15 @@ type SquadFlowController struct {
 	// References
 	navGrid     *NavGrid
 	tacticalMap *TacticalMap
+
+	// Player command paint feeding the cost field and strategic goals.
+	paint         *PaintLayer
+	paintCostRev  int // paint revision last folded into the cost field
+	paintGoalsRev int // paint revision the strategic goals came from; -1 = not painted goals
+
+	// Cost-field inputs each layer was last computed from.
+	strategicInputs layerInputs
+	tacticalInputs  layerInputs
 }
 
 func NewSquadFlowController(squad *Squad, navGrid *NavGrid, tacticalMap *TacticalMap) *SquadFlowController {
@@ -55,6 +64,7 @@ func NewSquadFlowController(squad *Squad, navGrid *NavGrid, tacticalMap *Tactica
 		navGrid:           navGrid,
 		tacticalMap:       tacticalMap,
 		recomputeInterval: 60, // Recompute every 60 ticks (1 second)
+		paintGoalsRev:     -1,
 	}
 
 	// Initialize cost field
@@ -83,6 +93,14 @@ func (sfc *SquadFlowController) Update(enemies []*Soldier) {
 	// Update occupancy
 	sfc.updateOccupancy()
 
+	// Fold in player paint once it has changed.
+	if sfc.paint != nil && sfc.paint.Revision != sfc.paintCostRev {
+		sfc.costField.UpdatePaint(sfc.paint)
+		sfc.paintCostRev = sfc.paint.Revision
+		sfc.strategicDirty = true
+		sfc.tacticalDirty = true
+	}
+
 	// Recompute fields if dirty or interval elapsed
 	if sfc.strategicDirty || sfc.updateTicks >= sfc.recomputeInterval {
 		sfc.RecomputeStrategic()
@@ -107,6 +125,7 @@ func (sfc *SquadFlowController) SetStrategicGoal(worldX, worldY float64) {
 		abs(sfc.strategicGoals[0].Y-cellY) > 2 {
 		sfc.strategicGoals = []Vec2i{{cellX, cellY}}
 		sfc.strategicDirty = true
+		sfc.paintGoalsRev = -1
 	}
 }
 
@@ -116,6 +135,28 @@ func (sfc *SquadFlowController) SetStrategicGoals(goals []Vec2i) {
 	sfc.strategicDirty = true
 }
 
+// SetPaint attaches the team's command paint. Avoid and route strokes reshape
+// the cost field on the next Update.
+func (sfc *SquadFlowController) SetPaint(p *PaintLayer) {
+	sfc.paint = p
+}
+
+// SetPaintedGoals points the strategic layer at every painted objective cell,
+// so the squad flows toward whichever objective is cheapest to reach. It only
+// recomputes when the paint has changed. It reports false when no objective
+// is painted, leaving the current goal alone.
+func (sfc *SquadFlowController) SetPaintedGoals() bool {
+	if !sfc.paint.Has(PaintObjective) {
+		return false
+	}
+	if sfc.paintGoalsRev == sfc.paint.Revision {
+		return true
+	}
+	sfc.SetStrategicGoals(sfc.paint.Cells(PaintObjective))
+	sfc.paintGoalsRev = sfc.paint.Revision
+	return true
+}
+
 // SetFormationGoals sets tactical goals for formation positioning
 // Positions should be world coordinates for each soldier's formation position
 func (sfc *SquadFlowController) SetFormationGoals(positions []Vec2) {
@@ -148,6 +189,7 @@ func (sfc *SquadFlowController) RecomputeStrategic() {
 	sfc.strategicIntegration = NewIntegrationField(sfc.width, sfc.height, sfc.strategicGoals)
 	sfc.strategicIntegration.Compute(sfc.costField)
 	sfc.strategicFlow.Generate(sfc.strategicIntegration)
+	sfc.strategicInputs = sfc.costField.inputs()
 }
 
 // RecomputeTactical regenerates the tactical layer flow field
@@ -159,6 +201,7 @@ func (sfc *SquadFlowController) RecomputeTactical() {
 	sfc.tacticalIntegration = NewIntegrationField(sfc.width, sfc.height, sfc.tacticalGoals)
 	sfc.tacticalIntegration.Compute(sfc.costField)
 	sfc.tacticalFlow.Generate(sfc.tacticalIntegration)
+	sfc.tacticalInputs = sfc.costField.inputs()
 }
 
 // GetStrategicFlow returns the strategic layer flow vector at world position
@@ -207,27 +207,14 @@ func (g *Game) renderSquadPanel(buf *ebiten.Image, sq *Squad) {
 }
 
 type Game struct {
-	width              int
-	height             int
-	gameWidth          int               // playfield width (log panel takes the rest)
-	gameHeight         int               // playfield height (inside border)
-	offX               int               // pixel offset from window left to battlefield left
-	offY               int               // pixel offset from window top to battlefield top
-	buildings          []rect            // individual wall segments (1-cell wide), used for LOS/nav
-	windows            []rect            // window segments: block movement, transparent to LOS
-	buildingFootprints []rect            // overall floor area of each structure, used for rendering
-	buildingQualities  []BuildingQuality // pre-computed tactical metrics per footprint
-	covers             []*CoverObject
-	navGrid            *NavGrid
-	soldiers           []*Soldier // red friendlies
-	opfor              []*Soldier // blue OpFor
-	squads             []*Squad
-	thoughtLog         *ThoughtLog
-	combat             *CombatManager
-	intel              *IntelStore
-	tacticalMap        *TacticalMap
-	tick               int
-	nextID             int
+	// The simulation itself; Game adds input, rendering and speech on top.
+	*World
+
+	width  int
+	height int
+	offX   int // pixel offset from window left to battlefield left
+	offY   int // pixel offset from window top to battlefield top
+	nextID int
 
 	// Overlay toggle state.
 	// showOverlay[team][layer] = visible?
@@ -252,9 +239,6 @@ type Game struct {
 	// Deterministic terrain noise patches, generated once.
 	terrainPatches []terrainPatch
 
-	// Per-tile terrain map — authoritative ground/object data for every cell.
-	tileMap *TileMap
-
 	// Camera pan + zoom.
 	camX    float64 // world-space X of the camera centre
 	camY    float64 // world-space Y of the camera centre
@@ -263,6 +247,7 @@ type Game struct {
 	// Soldier speech bubbles.
 	speechBubbles []*SpeechBubble
 	speechRng     *rand.Rand
+	speechSrc     *countingSource // speechRng's source, for save states
 
 	// Soldier inspector (click-to-select panel).
 	inspector Inspector
@@ -273,6 +258,16 @@ type Game struct {
 	cachedChestSet    map[[2]int]bool
 	prevMouseLeft     bool // for edge-triggered click detection
 
+	// Command painting: the player brushes orders onto the overlay team's
+	// paint layer; troops act on each stroke after commandDelay ticks.
+	paintMode    bool
+	paintKind    PaintKind
+	paintRadius  float64
+	commandDelay int
+	lastDabX     float64
+	lastDabY     float64
+	dabbing      bool // a brush stroke is in progress
+
 	// Simulation speed control.
 	simSpeed  float64 // multiplier: 0=paused, 0.5, 1, 2, 4
 	tickAccum float64 // fractional tick accumulator for sub-1x speeds
@@ -292,15 +287,10 @@ type Game struct {
 	aarSelection int
 	aarReason    BattleOutcomeReason
 
-	// Analytics reporter — collects behaviour stats periodically.
-	reporter *SimReporter
-
-	// Master map seed — printed at startup so layouts can be reproduced.
+	// Master seed — every RNG in the game is derived from it (see seedOffset*).
 	mapSeed int64
-
-	// Spatial partitioning for performance optimization.
-	spatialHashRed  *SpatialHash
-	spatialHashBlue *SpatialHash
+	// Combat RNG reseeds applied via Branch, recorded in save states.
+	branches []SaveBranch
 }
 
 type rect struct {
@@ -345,78 +335,119 @@ func terrainHash(x, y int) uint32 {
 	return v
 }
 
+// Offsets applied to the master seed to derive each independent RNG stream.
+// Keeping the streams separate means extra draws in one system (say, speech)
+// never shift the rolls seen by another (say, combat). The map and cover
+// offsets are applied in newWorldMap, which the GUI and headless runs share,
+// so a seed produces the same terrain everywhere.
+const (
+	seedOffsetMap      = 0
+	seedOffsetRedSpawn = 101
+	seedOffsetOpFor    = 999
+	seedOffsetProfiles = 42
+	seedOffsetCombat   = 7777
+	seedOffsetSpeech   = 9999
+	seedOffsetCover    = 12345
+)
+
+// New creates a game with a fresh master seed taken from the wall clock.
+// The seed is printed so the session can be replayed with NewWithSeed.
 func New() *Game {
+	return NewWithSeed(time.Now().UnixNano())
+}
+
+// NewWithSeed creates a game whose every RNG is derived from seed, so two
+// games built from the same seed play out identically.
+func NewWithSeed(seed int64) *Game {
+	g := newSimGame(seed)
+	g.initRenderBuffers()
+	return g
+}
+
+// newSimGame builds all simulation state for a seeded game without touching
+// any Ebiten resources, so it can be driven headlessly via simTick.
+func newSimGame(seed int64) *Game {
 	// Battlefield is 3072x1728 — double the original size.
 	battleW := 3072
 	battleH := 1728
 
-	// Master map seed — random each game, printed to console for reproducibility.
-	mapSeed := time.Now().UnixNano()
-	fmt.Printf("MAP SEED: %d\n", mapSeed)
+	// Master seed — printed to console so a session can be reproduced with -seed.
+	fmt.Printf("SEED: %d (replay with -seed %d)\n", seed, seed)
 
 	g := &Game{
-		width:      borderWidth + battleW + borderWidth + logPanelWidth,
-		height:     borderWidth + battleH + borderWidth,
-		gameWidth:  battleW,
-		gameHeight: battleH,
-		offX:       borderWidth,
-		offY:       borderWidth,
-		thoughtLog: NewThoughtLog(),
-		showHUD:    true,
-		prevKeys:   make(map[ebiten.Key]bool),
-		mapSeed:    mapSeed,
-	}
-	mapRng := rand.New(rand.NewSource(mapSeed)) // #nosec G404 -- game only
-	// Create the TileMap first — grid roads and buildings write directly into it.
-	g.tileMap = NewTileMap(battleW/cellSize, battleH/cellSize)
-	generateGridRoads(g.tileMap, mapRng, defaultRoadConfig)
-	g.initBuildings(mapRng)
-	g.initCover()
-	g.initTileMap() // stamp buildings/cover into tileMap after generation
-	generateBiome(g.tileMap, mapRng, defaultBiomeConfig)
-	generateFortifications(g.tileMap, mapRng, defaultFortConfig)
-	g.navGrid = NewNavGrid(g.gameWidth, g.gameHeight, g.buildings, soldierRadius, g.covers, g.windows)
-	g.tacticalMap = NewTacticalMap(g.gameWidth, g.gameHeight, g.buildings, g.windows, g.buildingFootprints)
-	g.buildingQualities = ComputeBuildingQualities(g.buildingFootprints, g.buildings, g.windows, g.gameWidth, g.gameHeight, g.navGrid)
-	g.initSoldiers()
-	g.initOpFor()
+		World:    newWorldMap(seed, battleW, battleH),
+		width:    borderWidth + battleW + borderWidth + logPanelWidth,
+		height:   borderWidth + battleH + borderWidth,
+		offX:     borderWidth,
+		offY:     borderWidth,
+		showHUD:  true,
+		prevKeys: make(map[ebiten.Key]bool),
+		mapSeed:  seed,
+
+		paintRadius:  paintDefaultRadius,
+		commandDelay: defaultCommandDelay,
+	}
+	g.thoughtLog = NewThoughtLog()
+	g.initSoldiers(rand.New(rand.NewSource(seed + seedOffsetRedSpawn))) // #nosec G404 -- game only
+	g.initOpFor(rand.New(rand.NewSource(seed + seedOffsetOpFor)))       // #nosec G404 -- game only
 	g.initSquads()
-	g.randomiseProfiles()
-	g.combat = NewCombatManager(time.Now().UnixNano() + 7777)
-	g.intel = NewIntelStore(g.gameWidth, g.gameHeight)
-	g.intel.SetTileMap(g.tileMap)
-	for _, s := range g.soldiers {
-		s.setIntel(g.intel)
-	}
-	for _, s := range g.opfor {
-		s.setIntel(g.intel)
-	}
-	g.visionBuf = ebiten.NewImage(battleW, battleH)
-	g.worldBuf = ebiten.NewImage(battleW, battleH)
-	// HUD buffer: 1/hudScale of screen so it renders crisply when scaled up.
-	g.hudBuf = ebiten.NewImage(g.width/hudScale, g.height/hudScale)
-	// Log buffer: 1/logScale of the log panel area.
-	g.logBuf = ebiten.NewImage(logPanelWidth/logScale, g.height/logScale)
-	// Inspector buffer: 1/inspScale of the inspector panel area.
-	g.inspBuf = ebiten.NewImage(inspBufW, inspBufH)
-	// Squad status panel buffer: reused for each panel, blitted at logScale.
-	g.squadBuf = ebiten.NewImage(squadBufW, squadBufH)
+	g.randomiseProfiles(rand.New(rand.NewSource(seed + seedOffsetProfiles))) // #nosec G404 -- game only
+	g.startSim(seed+seedOffsetCombat, nil)
+	g.reporter = NewSimReporter(reportWindowTicks, false)
 	g.initTerrainPatches()
+	g.initViewState()
+	g.speechSrc = newCountingSource(seed + seedOffsetSpeech)
+	g.speechRng = rand.New(g.speechSrc) // #nosec G404 -- non-crypto RNG for local flavor text
+	return g
+}
+
+// initViewState resets the camera and sim speed and allocates the render caches.
+func (g *Game) initViewState() {
 	// Default camera: centred on battlefield, zoom 0.5 so the full map is visible.
-	g.camX = float64(battleW) / 2
-	g.camY = float64(battleH) / 2
+	g.camX = float64(g.gameWidth) / 2
+	g.camY = float64(g.gameHeight) / 2
 	g.camZoom = 0.5
 	g.simSpeed = 1.0
 	// Initialize cached maps for rendering.
 	g.cachedClaimedTeam = make(map[int]Team)
 	g.cachedSolidSet = make(map[[2]int]bool, len(g.buildings)+len(g.windows))
 	g.cachedChestSet = make(map[[2]int]bool)
-	g.speechRng = rand.New(rand.NewSource(time.Now().UnixNano() + 9999)) // #nosec G404 -- non-crypto RNG for local flavor text
-	g.reporter = NewSimReporter(reportWindowTicks, false)
-	// Initialize spatial hashes with cell size = max vision range for optimal performance.
-	g.spatialHashRed = NewSpatialHash(defaultViewDist)
-	g.spatialHashBlue = NewSpatialHash(defaultViewDist)
-	return g
+}
+
+// initRenderBuffers allocates the offscreen Ebiten images used by Draw.
+func (g *Game) initRenderBuffers() {
+	g.visionBuf = ebiten.NewImage(g.gameWidth, g.gameHeight)
+	g.worldBuf = ebiten.NewImage(g.gameWidth, g.gameHeight)
+	// HUD buffer: 1/hudScale of screen so it renders crisply when scaled up.
+	g.hudBuf = ebiten.NewImage(g.width/hudScale, g.height/hudScale)
+	// Log buffer: 1/logScale of the log panel area.
+	g.logBuf = ebiten.NewImage(logPanelWidth/logScale, g.height/logScale)
+	// Inspector buffer: 1/inspScale of the inspector panel area.
+	g.inspBuf = ebiten.NewImage(inspBufW, inspBufH)
+	// Squad status panel buffer: reused for each panel, blitted at logScale.
+	g.squadBuf = ebiten.NewImage(squadBufW, squadBufH)
+}
+
+// Seed returns the master seed this game was built from.
+func (g *Game) Seed() int64 {
+	return g.mapSeed
+}
+
+// StartRecording begins capturing every tick for later playback in a
+// ReplayViewer. The terrain is described by the master seed.
+func (g *Game) StartRecording() {
+	all := append(g.soldiers[:len(g.soldiers):len(g.soldiers)], g.opfor...)
+	m := ScenarioMap{Width: g.gameWidth, Height: g.gameHeight, Seed: g.mapSeed, Generate: true}
+	g.recorder = NewRecorder("game", "", g.mapSeed, m, all, g.squads)
+	g.recorder.Capture(g.tick, all, g.squads, nil)
+}
+
+// Recording returns the recording in progress, or nil if not recording.
+func (g *Game) Recording() *Recording {
+	if g.recorder == nil {
+		return nil
+	}
+	return g.recorder.Recording()
 }
 
 // initTerrainPatches generates deterministic subtle ground colour patches.
@@ -482,8 +513,7 @@ func (g *Game) initTileMap() {
 	}
 }
 
-func (g *Game) initCover() {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano() + 12345)) // #nosec G404 -- game only
+func (g *Game) initCover(rng *rand.Rand) {
 	var rubble []*CoverObject
 	g.covers, rubble = GenerateCover(g.gameWidth, g.gameHeight, g.buildingFootprints, g.buildings, rng, g.tileMap)
 	// Rubble replaces wall segments where explosions hit — remove those walls and add rubble.
@@ -992,8 +1022,7 @@ func (g *Game) spawnCluster(rng *rand.Rand, team Team, squadSize int, clusterCen
 	return out
 }
 
-func (g *Game) initSoldiers() {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec G404
+func (g *Game) initSoldiers(rng *rand.Rand) {
 	sqSz := 8
 	margin := 64.0
 	startX := margin
@@ -1004,8 +1033,7 @@ func (g *Game) initSoldiers() {
 	g.soldiers = append(g.soldiers, g.spawnCluster(rng, TeamRed, sqSz, float64(g.gameHeight)*0.80, startX, endX)...)
 }
 
-func (g *Game) initOpFor() {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano() + 999)) // #nosec G404
+func (g *Game) initOpFor(rng *rand.Rand) {
 	sqSz := 8
 	margin := 64.0
 	startX := float64(g.gameWidth) - margin
@@ -1018,16 +1046,19 @@ func (g *Game) initOpFor() {
 
 func (g *Game) initSquads() {
 	sqSz := 8
+	var red, blue []*Squad
 	for i := 0; i < len(g.soldiers); i += sqSz {
 		end := i + sqSz
 		if end > len(g.soldiers) {
 			end = len(g.soldiers)
 		}
 		sq := NewSquad(len(g.squads), TeamRed, g.soldiers[i:end])
+		sq.issueLoadout(standardSquadLoadout)
 		sq.buildingFootprints = g.buildingFootprints
 		sq.buildingQualities = g.buildingQualities
 		sq.InitializeFlowField(g.navGrid, g.tacticalMap)
 		g.squads = append(g.squads, sq)
+		red = append(red, sq)
 	}
 	for i := 0; i < len(g.opfor); i += sqSz {
 		end := i + sqSz
@@ -1035,10 +1066,19 @@ func (g *Game) initSquads() {
 			end = len(g.opfor)
 		}
 		sq := NewSquad(len(g.squads), TeamBlue, g.opfor[i:end])
+		sq.issueLoadout(standardSquadLoadout)
 		sq.buildingFootprints = g.buildingFootprints
 		sq.buildingQualities = g.buildingQualities
 		sq.InitializeFlowField(g.navGrid, g.tacticalMap)
 		g.squads = append(g.squads, sq)
+		blue = append(blue, sq)
+	}
+
+	// Each side's squads fight as one platoon.
+	for _, squads := range [][]*Squad{red, blue} {
+		if len(squads) > 1 {
+			g.platoons = append(g.platoons, NewPlatoon(len(g.platoons), squads[0].Team, squads))
+		}
 	}
 
 	// Initialize steering behaviors for all soldiers
@@ -1051,8 +1091,7 @@ func (g *Game) initSquads() {
 }
 
 // randomiseProfiles gives each soldier slightly different stats so behaviour varies.
-func (g *Game) randomiseProfiles() {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano() + 42)) // #nosec G404 -- game only, crypto/rand not needed
+func (g *Game) randomiseProfiles(rng *rand.Rand) {
 	all := append(g.soldiers[:len(g.soldiers):len(g.soldiers)], g.opfor...)
 	for _, s := range all {
 		p := &s.profile
@@ -1109,87 +1148,10 @@ func (g *Game) Update() error {
 
 // simTick runs one simulation tick.
 func (g *Game) simTick() {
-	g.tick++
-
-	// 0. SPATIAL HASH: populate spatial partitioning structures for efficient queries.
-	g.spatialHashRed.Clear()
-	g.spatialHashBlue.Clear()
-	for _, s := range g.soldiers {
-		if s.state != SoldierStateDead {
-			g.spatialHashRed.Insert(s)
-		}
-	}
-	for _, s := range g.opfor {
-		if s.state != SoldierStateDead {
-			g.spatialHashBlue.Insert(s)
-		}
-	}
-
-	// 1. SENSE: each soldier scans for enemies using spatial hash.
-	for _, s := range g.soldiers {
-		s.UpdateVisionSpatial(g.spatialHashBlue, g.buildings)
-	}
-	for _, s := range g.opfor {
-		s.UpdateVisionSpatial(g.spatialHashRed, g.buildings)
-	}
-
-	// 2. COMBAT: fire decisions and resolution.
-	all := append(g.soldiers[:len(g.soldiers):len(g.soldiers)], g.opfor...)
-	g.combat.ResetFireCounts(all)
-	g.combat.tick = g.tick
-	g.combat.ResolveCombat(g.soldiers, g.opfor, g.soldiers, g.buildings, all)
-	g.combat.ResolveCombat(g.opfor, g.soldiers, g.opfor, g.buildings, all)
-	g.combat.UpdateTracers()
-
-	// 2.1. SOUND: broadcast gunfire events using spatial hash for performance.
-	g.combat.BroadcastGunfireSpatial(g.spatialHashRed, g.spatialHashBlue, g.soldiers, g.opfor, g.tick)
-
-	// 2.5. INTEL: update all heatmap layers from current soldier state.
-	g.intel.Update(g.soldiers, g.opfor, g.buildings)
-
-	// 3. SQUAD THINK: leaders evaluate and set intent/orders.
-	for _, sq := range g.squads {
-		sq.SquadThink(g.intel)
-	}
-
-	// 3.5 + 3.6 COMMS PLAN/RESOLVE: phase-A squad radio messaging.
-	for _, sq := range g.squads {
-		sq.PlanComms(g.tick)
-		sq.ResolveComms(g.tick, g.thoughtLog)
-	}
-
-	// Formation pass: update slot targets before soldiers decide to move.
-	for _, sq := range g.squads {
-		sq.UpdateFormation()
-	}
-
-	// 4+5. INDIVIDUAL THINK + ACT.
-	for _, s := range g.soldiers {
-		s.Update()
-	}
-	for _, s := range g.opfor {
-		s.Update()
-	}
-
-	// 5.5. MEDICAL AID: advance treatment for wounded soldiers.
-	integrateBuddyAidTick(g.soldiers, g.tick)
-	integrateBuddyAidTick(g.opfor, g.tick)
+	g.Step()
 
-	// 6. SQUAD POLL: periodic summary to thought log (~every 5s).
-	if g.tick%squadPollInterval == 0 {
-		for _, sq := range g.squads {
-			g.thoughtLog.AddSquadPoll(g.tick, sq)
-		}
-	}
-
-	// 7. SPEECH: update soldier speech bubbles.
+	// Speech bubbles and the end-of-battle check are the GUI's own additions.
 	g.UpdateSpeech(g.speechRng)
-
-	// 8. ANALYTICS: collect behaviour report every ~1s.
-	if g.tick%60 == 0 && g.reporter != nil {
-		g.reporter.Collect(g.tick, g.soldiers, g.opfor, g.squads)
-	}
-
 	if !g.aarOpen {
 		g.checkCombatEnd()
 	}
@@ -1313,10 +1275,32 @@ func (g *Game) handleInput() {
 	for i, k := range layerKeys {
 		currentKeys[k] = ebiten.IsKeyPressed(k)
 		if currentKeys[k] && !g.prevKeys[k] {
+			if g.paintMode {
+				// In paint mode 1-4 pick the brush instead.
+				if i < int(paintKindCount) {
+					g.paintKind = PaintKind(i)
+				}
+				continue
+			}
 			g.showOverlay[g.overlayTeam][i] = !g.showOverlay[g.overlayTeam][i]
 		}
 	}
 
+	// B: toggle command paint mode; [ and ] resize the brush.
+	currentKeys[ebiten.KeyB] = ebiten.IsKeyPressed(ebiten.KeyB)
+	if currentKeys[ebiten.KeyB] && !g.prevKeys[ebiten.KeyB] {
+		g.paintMode = !g.paintMode
+		g.dabbing = false
+	}
+	currentKeys[ebiten.KeyBracketLeft] = ebiten.IsKeyPressed(ebiten.KeyBracketLeft)
+	if currentKeys[ebiten.KeyBracketLeft] && !g.prevKeys[ebiten.KeyBracketLeft] {
+		g.paintRadius = math.Max(paintMinRadius, g.paintRadius/1.25)
+	}
+	currentKeys[ebiten.KeyBracketRight] = ebiten.IsKeyPressed(ebiten.KeyBracketRight)
+	if currentKeys[ebiten.KeyBracketRight] && !g.prevKeys[ebiten.KeyBracketRight] {
+		g.paintRadius = math.Min(paintMaxRadius, g.paintRadius*1.25)
+	}
+
 	// Tab: switch which team's maps are displayed.
 	currentKeys[ebiten.KeyTab] = ebiten.IsKeyPressed(ebiten.KeyTab)
 	if currentKeys[ebiten.KeyTab] && !g.prevKeys[ebiten.KeyTab] {
@@ -1425,8 +1409,11 @@ func (g *Game) handleInput() {
 		}
 	}
 
-	// Left mouse click: try to select a soldier.
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+	// Left mouse click: try to select a soldier. In paint mode the left
+	// button paints and the right button erases instead.
+	if g.paintMode {
+		g.handlePaintInput()
+	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		if !g.prevMouseLeft {
 			mx, my := ebiten.CursorPosition()
 			g.handleInspectorClick(mx, my)
@@ -1434,6 +1421,29 @@ func (g *Game) handleInput() {
 	}
 	g.prevMouseLeft = ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
 
+	// C: copy the master seed so the session can be replayed with -seed.
+	currentKeys[ebiten.KeyC] = ebiten.IsKeyPressed(ebiten.KeyC)
+	if currentKeys[ebiten.KeyC] && !g.prevKeys[ebiten.KeyC] {
+		if err := setClipboardText(fmt.Sprintf("%d", g.mapSeed)); err == nil {
+			g.thoughtLog.Add(g.tick, "DBG", TeamRed, fmt.Sprintf("copied seed %d", g.mapSeed), LogCatThought)
+		}
+	}
+
+	// F9: write a save state for this tick to the working directory.
+	currentKeys[ebiten.KeyF9] = ebiten.IsKeyPressed(ebiten.KeyF9)
+	if currentKeys[ebiten.KeyF9] && !g.prevKeys[ebiten.KeyF9] {
+		path := fmt.Sprintf("soldier-sense-%d-t%d.save.json.gz", g.mapSeed, g.tick)
+		msg := "saved " + path
+		st, err := g.SaveState()
+		if err == nil {
+			err = st.Save(path)
+		}
+		if err != nil {
+			msg = fmt.Sprintf("save failed: %v", err)
+		}
+		g.thoughtLog.Add(g.tick, "DBG", TeamRed, msg, LogCatThought)
+	}
+
 	// I: toggle inspector raw/curated view.
 	currentKeys[ebiten.KeyI] = ebiten.IsKeyPressed(ebiten.KeyI)
 	if currentKeys[ebiten.KeyI] && !g.prevKeys[ebiten.KeyI] {
@@ -1606,7 +1616,10 @@ func (g *Game) drawAAR(screen *ebiten.Image) {
 	blueLoss := g.aarReason.BlueTotal - g.aarReason.BlueSurvivors
 	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("RED:  %d/%d losses  squads_broken=%d/%d", redLoss, g.aarReason.RedTotal, g.aarReason.RedSquadsBroken, g.aarReason.RedSquadsTotal), px+30, py+82)
 	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BLUE: %d/%d losses  squads_broken=%d/%d", blueLoss, g.aarReason.BlueTotal, g.aarReason.BlueSquadsBroken, g.aarReason.BlueSquadsTotal), px+30, py+98)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("reason: %s", g.aarReason.Description), px+30, py+126)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("      killed=%d/%d incapacitated=%d/%d evacuated=%d/%d  (red/blue)",
+		g.aarReason.RedKilled, g.aarReason.BlueKilled, g.aarReason.RedIncapacitated, g.aarReason.BlueIncapacitated,
+		g.aarReason.RedEvacuated, g.aarReason.BlueEvacuated), px+30, py+114)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("reason: %s", g.aarReason.Description), px+30, py+132)
 
 	ebitenutil.DebugPrintAt(screen, "W/S or Up/Down: select", px+30, py+156)
 	ebitenutil.DebugPrintAt(screen, "Enter: confirm", px+30, py+170)
@@ -1738,6 +1751,9 @@ func (g *Game) drawWorld(screen *ebiten.Image) {
 			}
 		}
 	}
+	if g.paintMode {
+		g.drawPaint(screen)
+	}
 
 	// Build a set of claimed building indices → team for tinting.
 	// Clear and reuse cached map to avoid per-frame allocation.
@@ -1852,6 +1868,9 @@ func (g *Game) drawWorld(screen *ebiten.Image) {
 		bh := float32(w.h)
 		cx := w.x / cellSize
 		cy := w.y / cellSize
+		if g.tileMap != nil && g.tileMap.ObjectAt(cx, cy) == ObjectWindowBroken {
+			continue // shot out; drawTileMapObjects draws the empty frame
+		}
 		hasN := solidSet[[2]int{cx, cy - 1}]
 		hasS := solidSet[[2]int{cx, cy + 1}]
 		hasW := solidSet[[2]int{cx - 1, cy}]
@@ -1929,12 +1948,17 @@ func (g *Game) drawWorld(screen *ebiten.Image) {
 		}
 	}
 
+	// Night and weather dim everything below the gunfire light.
+	g.env.DrawOverlay(screen, gw, gh, g.tick)
+
 	// Dynamic gunfire light blooms — drawn before muzzle flashes so they sit underneath.
 	g.drawGunfireLighting(screen, 0, 0)
 
 	// Muzzle flashes and tracers.
 	g.combat.DrawMuzzleFlashes(screen, 0, 0)
 	g.combat.DrawTracers(screen, 0, 0)
+	g.combat.DrawGrenades(screen, 0, 0)
+	g.combat.Smoke.Draw(screen, 0, 0)
 
 	// Speech bubbles above soldiers.
 	g.drawSpeechBubbles(screen, 0, 0)
@@ -2256,6 +2280,7 @@ func (g *Game) drawHUD(screen *ebiten.Image) {
 
 	lines := []string{
 		fmt.Sprintf("SIM: %s  tick:%d  P=pause  ,/. speed", speedStr, g.tick),
+		fmt.Sprintf("seed: %d  C=copy  F9=save", g.mapSeed),
 		fmt.Sprintf("Intel: [%s]  Tab=switch", teamLabel),
 	}
 	for k := IntelMapKind(0); k < intelMapCount; k++ {
@@ -2265,6 +2290,13 @@ func (g *Game) drawHUD(screen *ebiten.Image) {
 		}
 		lines = append(lines, fmt.Sprintf("  [%d]%s %s", k+1, on, IntelMapKindName(k)))
 	}
+	if g.paintMode {
+		lines = append(lines, fmt.Sprintf("PAINT [B]: %s r=%.0f  1-4 brush  [/] size", g.paintKind, g.paintRadius))
+		lines = append(lines, fmt.Sprintf("  LMB paint  RMB erase  delay %.1fs  queued %d",
+			float64(g.commandDelay)/60, len(g.intel.PaintFor(Team(g.overlayTeam)).Pending())))
+	} else {
+		lines = append(lines, "[B] paint orders")
+	}
 	lines = append(lines, "[H] toggle HUD")
 	lines = append(lines, "WASD/arrows=pan  scroll=zoom")
 	lines = append(lines, fmt.Sprintf("zoom: %.1fx  click=inspect", g.camZoom))
@@ -2396,7 +2428,7 @@ func (g *Game) drawRadioVisualEffects(screen *ebiten.Image) {
 				continue
 			}
 
-			// Console-green baseline, capped at ~25% alpha.
+			// Console-green baseline, capped at ~25% alpha; amber on the command net.
 			base := color.RGBA{R: 76, G: 255, B: 136, A: uint8(255 * baseOpacity * life)}
 			coreWidth := float32(1.5)
 			grain := 3.0
@@ -2441,14 +2473,14 @@ func (g *Game) drawRadioVisualEffects(screen *ebiten.Image) {
 				jx := arcX + nx*jitter
 				jy := arcY + ny*jitter
 
-				glowCol := color.RGBA{R: 76, G: 255, B: 136, A: uint8(float64(base.A) * 0.35)}
+				glowCol := radioNetTint(ev.Net, color.RGBA{R: 76, G: 255, B: 136, A: uint8(float64(base.A) * 0.35)})
 				vector.StrokeLine(screen, prevX, prevY, jx, jy, coreWidth+1.3, glowCol, false)
-				vector.StrokeLine(screen, prevX, prevY, jx, jy, coreWidth, base, false)
+				vector.StrokeLine(screen, prevX, prevY, jx, jy, coreWidth, radioNetTint(ev.Net, base), false)
 
 				// Grain speckle along the arc for analog/static feel.
 				if i%2 == 0 {
 					sparkA := uint8(float64(base.A) * 0.45)
-					vector.FillCircle(screen, jx+nx*0.6, jy+ny*0.6, 0.9, color.RGBA{R: 110, G: 255, B: 165, A: sparkA}, false)
+					vector.FillCircle(screen, jx+nx*0.6, jy+ny*0.6, 0.9, radioNetTint(ev.Net, color.RGBA{R: 110, G: 255, B: 165, A: sparkA}), false)
 				}
 
 				prevX, prevY = jx, jy
@@ -2456,12 +2488,22 @@ func (g *Game) drawRadioVisualEffects(screen *ebiten.Image) {
 
 			// Endpoint pings for readability.
 			pingAlpha := uint8(255 * baseOpacity * (0.7 + 0.3*life))
-			vector.FillCircle(screen, sx, sy, 2.0, color.RGBA{R: 120, G: 255, B: 170, A: pingAlpha}, false)
-			vector.FillCircle(screen, rx, ry, 1.8, color.RGBA{R: 120, G: 255, B: 170, A: pingAlpha}, false)
+			ping := radioNetTint(ev.Net, color.RGBA{R: 120, G: 255, B: 170, A: pingAlpha})
+			vector.FillCircle(screen, sx, sy, 2.0, ping, false)
+			vector.FillCircle(screen, rx, ry, 1.8, ping, false)
 		}
 	}
 }
 
+// radioNetTint turns a console-green radio colour amber for command-net
+// traffic, so platoon SITREPs and orders stand out from squad chatter.
+func radioNetTint(net RadioNetKind, c color.RGBA) color.RGBA {
+	if net != RadioNetCommand {
+		return c
+	}
+	return color.RGBA{R: c.G, G: uint8(int(c.G) * 3 / 4), B: c.B / 2, A: c.A}
+}
+
 // drawVisionConesBuffered renders all FOV fans for a team into an offscreen buffer,
 // then composites that buffer onto the main screen with a single controlled opacity.
 // This eliminates additive blowout from overlapping cones.
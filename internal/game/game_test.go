@@ -0,0 +1,78 @@
+package game
+
+import "testing"
+
+// TestNewSimGame_SameSeedReplaysIdentically checks that every RNG in Game is
+// derived from the master seed: two sessions built from one seed must stay
+// in lock-step tick for tick.
+func TestNewSimGame_SameSeedReplaysIdentically(t *testing.T) {
+	const seed = 20240601
+	const ticks = 180
+
+	a := newSimGame(seed)
+	b := newSimGame(seed)
+	if len(a.soldiers) != len(b.soldiers) || len(a.opfor) != len(b.opfor) {
+		t.Fatalf("spawn mismatch: red %d/%d blue %d/%d", len(a.soldiers), len(b.soldiers), len(a.opfor), len(b.opfor))
+	}
+
+	for i := 0; i < ticks; i++ {
+		a.simTick()
+		b.simTick()
+	}
+
+	all := func(g *Game) []*Soldier {
+		return append(g.soldiers[:len(g.soldiers):len(g.soldiers)], g.opfor...)
+	}
+	as, bs := all(a), all(b)
+	for i := range as {
+		sa, sb := as[i], bs[i]
+		if sa.x != sb.x || sa.y != sb.y || sa.state != sb.state ||
+			sa.blackboard.CurrentGoal != sb.blackboard.CurrentGoal ||
+			sa.profile.Psych.Fear != sb.profile.Psych.Fear {
+			t.Fatalf("tick %d: %s diverged: (%.2f,%.2f) %s %s vs (%.2f,%.2f) %s %s",
+				ticks, sa.label, sa.x, sa.y, sa.state, sa.blackboard.CurrentGoal,
+				sb.x, sb.y, sb.state, sb.blackboard.CurrentGoal)
+		}
+	}
+}
+
+// TestNewSimGame_MapMatchesHeadlessBattlefield checks that a GUI seed and a
+// headless battlefield seed generate the same terrain, so a battle seen on
+// screen can be rebuilt in a TestSim.
+func TestNewSimGame_MapMatchesHeadlessBattlefield(t *testing.T) {
+	const seed = 777
+	g := newSimGame(seed)
+	bf := NewHeadlessBattlefield(seed, g.gameWidth, g.gameHeight)
+	if len(g.buildings) != len(bf.Buildings) || len(g.covers) != len(bf.Covers) {
+		t.Fatalf("terrain mismatch: buildings %d/%d covers %d/%d",
+			len(g.buildings), len(bf.Buildings), len(g.covers), len(bf.Covers))
+	}
+	for i := range g.buildings {
+		if g.buildings[i] != bf.Buildings[i] {
+			t.Fatalf("building %d differs: %+v vs %+v", i, g.buildings[i], bf.Buildings[i])
+		}
+	}
+}
+
+// TestTreatmentRoll_Uniform checks treatment rolls spread evenly over [0,1),
+// so a success chance p succeeds about p of the time.
+func TestTreatmentRoll_Uniform(t *testing.T) {
+	provider, casualty := &Soldier{id: 3}, &Soldier{id: 7}
+	const n = 20000
+	var buckets [10]int
+	for tick := 0; tick < n; tick++ {
+		r := treatmentRoll(provider, casualty, tick)
+		if r < 0 || r >= 1 {
+			t.Fatalf("roll %v out of [0,1)", r)
+		}
+		buckets[int(r*10)]++
+	}
+	for i, c := range buckets {
+		if c < n/10*9/10 || c > n/10*11/10 {
+			t.Fatalf("bucket %d holds %d of %d rolls, want about %d: %v", i, c, n, n/10, buckets)
+		}
+	}
+	if treatmentRoll(provider, casualty, 5) == treatmentRoll(casualty, provider, 5) {
+		t.Error("provider and casualty should not be interchangeable")
+	}
+}
@@ -0,0 +1,507 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// --- Grenade constants ---
+
+const (
+	defaultGrenadeCount = 2 // fragmentation grenades carried at start
+
+	grenadeMinThrowRange = 56.0  // px, closer than this and the thrower is inside the casualty zone
+	grenadeMaxThrowRange = 240.0 // px, a hard overarm throw
+	grenadeWindupTicks   = 36    // pull pin, pick the spot, throw (~0.6s)
+	grenadeThrowSpeed    = 5.0   // px per tick of ground track while in flight
+	grenadeFuseTicks     = 210   // ticks from release to detonation (~3.5s)
+	grenadeCooldownTicks = 600   // ticks before the same soldier considers another throw
+
+	grenadeLethalRadius   = 40.0  // px, almost certain serious wounds
+	grenadeCasualtyRadius = 110.0 // px, fragments can still wound
+	grenadeBlastRadius    = 240.0 // px, shock, stress and suppression
+	grenadeFragmentHits   = 4.0   // expected fragment strikes on an exposed standing soldier at the burst point
+	grenadeFragmentDamage = 16.0  // base damage per fragment
+	grenadeBlastStress    = 0.35  // fear added at the burst point, falling off with distance
+	grenadeTileRadius     = 2     // cells around the burst that take structural damage
+	grenadeTileDamage     = 60    // durability removed at the burst cell
+
+	blastLifetime = 24 // ticks a blast flash persists on screen
+)
+
+// Grenade is a thrown grenade, either in flight or lying on the ground.
+type Grenade struct {
+	ThrowerID   int
+	Team        Team
+	Kind        GrenadeKind
+	FromX       float64 // release point
+	FromY       float64
+	ToX         float64 // landing point
+	ToY         float64
+	X, Y        float64 // current ground position
+	flightTick  int
+	flightTicks int
+	Fuse        int // ticks until detonation
+}
+
+// InFlight reports whether the grenade has not yet landed.
+func (gr *Grenade) InFlight() bool {
+	return gr.flightTick < gr.flightTicks
+}
+
+// blast is the short-lived visual left by a detonation.
+type blast struct {
+	x, y float64
+	age  int
+}
+
+// DetonationEvent records one grenade burst for the replay recorder and tests.
+type DetonationEvent struct {
+	ThrowerID int
+	X, Y      float64
+	Wounded   int // soldiers wounded by fragments
+	Killed    int // soldiers killed outright
+}
+
+// ResolveGrenades releases grenades from soldiers that finished their wind-up
+// and smoke their squad leader asked for, moves grenades in flight, detonates
+// any whose fuse has run out and advances the smoke field.
+// tm may be nil (TestSim has no tile map); terrain damage is skipped then.
+func (cm *CombatManager) ResolveGrenades(soldiers []*Soldier, buildings []rect, tm *TileMap) {
+	cm.Detonations = cm.Detonations[:0]
+
+	for _, s := range soldiers {
+		if !s.grenadeReady {
+			continue
+		}
+		s.grenadeReady = false
+		if s.state == SoldierStateDead || s.state.IsIncapacitated() || s.grenades <= 0 {
+			continue
+		}
+		cm.throwGrenade(s, GrenadeFrag, s.grenadeAimX, s.grenadeAimY, buildings)
+	}
+	for _, s := range soldiers {
+		if !s.smokeReady {
+			continue
+		}
+		s.smokeReady = false
+		if s.state == SoldierStateDead || s.state.IsIncapacitated() || s.smokeGrenades <= 0 {
+			continue
+		}
+		cm.throwGrenade(s, GrenadeSmoke, s.smokeAimX, s.smokeAimY, buildings)
+	}
+
+	live := cm.grenades[:0]
+	for _, gr := range cm.grenades {
+		if gr.InFlight() {
+			gr.flightTick++
+			t := float64(gr.flightTick) / float64(gr.flightTicks)
+			gr.X = gr.FromX + (gr.ToX-gr.FromX)*t
+			gr.Y = gr.FromY + (gr.ToY-gr.FromY)*t
+		}
+		gr.Fuse--
+		if gr.Fuse <= 0 {
+			if gr.Kind == GrenadeSmoke {
+				cm.Smoke.Deploy(gr.X, gr.Y)
+			} else {
+				cm.detonate(gr, soldiers, buildings, tm)
+			}
+			continue
+		}
+		live = append(live, gr)
+	}
+	cm.grenades = live
+
+	activeBlasts := cm.blasts[:0]
+	for _, b := range cm.blasts {
+		b.age++
+		if b.age < blastLifetime {
+			activeBlasts = append(activeBlasts, b)
+		}
+	}
+	cm.blasts = activeBlasts
+	cm.Smoke.Update()
+}
+
+// throwGrenade releases one of s's grenades of the given kind toward (aimX, aimY).
+// Throw accuracy degrades with range, inexperience, suppression and fear. A throw
+// that meets a wall drops at the foot of the wall on the thrower's side.
+func (cm *CombatManager) throwGrenade(s *Soldier, kind GrenadeKind, aimX, aimY float64, buildings []rect) {
+	dx := aimX - s.x
+	dy := aimY - s.y
+	dist := math.Hypot(dx, dy)
+
+	scatterFrac := 0.05 + (1.0-s.profile.Skills.Discipline)*0.10 +
+		s.blackboard.SuppressLevel*0.12 + s.profile.Psych.EffectiveFear()*0.08
+	scatter := dist * scatterFrac * cm.rng.Float64()
+	ang := cm.rng.Float64() * 2 * math.Pi
+	toX := aimX + math.Cos(ang)*scatter
+	toY := aimY + math.Sin(ang)*scatter
+
+	// Walls stop the throw short.
+	hitT := 1.0
+	for _, b := range buildings {
+		if t, ok := rayAABBHitT(s.x, s.y, toX, toY,
+			float64(b.x), float64(b.y), float64(b.x+b.w), float64(b.y+b.h)); ok && t < hitT {
+			hitT = t
+		}
+	}
+	if hitT < 1.0 {
+		legLen := math.Hypot(toX-s.x, toY-s.y)
+		back := 0.0
+		if legLen > 0 {
+			back = float64(soldierRadius) / legLen
+		}
+		hitT = math.Max(0, hitT-back)
+		toX = s.x + (toX-s.x)*hitT
+		toY = s.y + (toY-s.y)*hitT
+		s.think("grenade hit the wall — dropped short")
+	}
+
+	flight := int(math.Ceil(math.Hypot(toX-s.x, toY-s.y) / grenadeThrowSpeed))
+	if flight < 1 {
+		flight = 1
+	}
+	fuse := grenadeFuseTicks
+	if kind == GrenadeSmoke {
+		fuse = smokeFuseTicks
+	}
+	cm.grenades = append(cm.grenades, &Grenade{
+		ThrowerID:   s.id,
+		Team:        s.team,
+		Kind:        kind,
+		FromX:       s.x,
+		FromY:       s.y,
+		ToX:         toX,
+		ToY:         toY,
+		X:           s.x,
+		Y:           s.y,
+		flightTicks: flight,
+		Fuse:        fuse,
+	})
+	if kind == GrenadeSmoke {
+		s.smokeGrenades--
+		s.think(fmt.Sprintf("SMOKE OUT — %d left", s.smokeGrenades))
+		return
+	}
+	s.grenades--
+	s.blackboard.GrenadeCooldown = grenadeCooldownTicks
+	s.blackboard.ShatterEvent = true
+	s.think(fmt.Sprintf("FRAG OUT — %d left", s.grenades))
+}
+
+// detonate applies fragments, blast stress and terrain damage around gr.
+// Fragments need a clear line from the burst; building walls shield fully.
+// Cover and a low stance reduce the number of fragment strikes.
+func (cm *CombatManager) detonate(gr *Grenade, soldiers []*Soldier, buildings []rect, tm *TileMap) {
+	ev := DetonationEvent{ThrowerID: gr.ThrowerID, X: gr.X, Y: gr.Y}
+	cm.blasts = append(cm.blasts, &blast{x: gr.X, y: gr.Y})
+	cm.Gunfires = append(cm.Gunfires, GunfireEvent{X: gr.X, Y: gr.Y, Team: gr.Team})
+
+	for _, t := range soldiers {
+		if t.state == SoldierStateDead {
+			continue
+		}
+		d := math.Hypot(t.x-gr.X, t.y-gr.Y)
+		if d > grenadeBlastRadius {
+			continue
+		}
+		shielded := !HasLineOfSight(gr.X, gr.Y, t.x, t.y, buildings)
+
+		// Shock and noise reach everyone in range; walls muffle it.
+		stress := grenadeBlastStress * (1.0 - d/grenadeBlastRadius)
+		if shielded {
+			stress *= 0.4
+		}
+		t.profile.Psych.ApplyStress(stress)
+		t.blackboard.IncomingFireCount++
+		t.blackboard.AccumulateSuppression(d <= grenadeCasualtyRadius && !shielded, gr.X, gr.Y, t.x, t.y)
+
+		if shielded || d > grenadeCasualtyRadius {
+			continue
+		}
+		fall := 1.0 - d/grenadeCasualtyRadius
+		exposure := t.profile.Stance.Profile().ProfileMul
+		if tm != nil {
+			if inCover, defence := TileMapCoverBetween(tm, t.x, t.y, gr.X, gr.Y); inCover {
+				exposure *= 1.0 - defence*0.8
+			}
+		} else if inCover, defence := IsBehindCover(t.x, t.y, gr.X, gr.Y, t.covers); inCover {
+			exposure *= 1.0 - defence*0.8
+		}
+		expected := grenadeFragmentHits * exposure * fall * fall
+		if d <= grenadeLethalRadius {
+			expected += 2.0 * exposure
+		}
+		hits := int(expected)
+		if cm.rng.Float64() < expected-float64(hits) {
+			hits++
+		}
+		if hits == 0 {
+			continue
+		}
+		for i := 0; i < hits && t.state != SoldierStateDead; i++ {
+			cm.applyFragment(t, grenadeFragmentDamage*(0.6+0.4*fall))
+		}
+		if t.state == SoldierStateDead {
+			ev.Killed++
+		} else {
+			ev.Wounded++
+		}
+	}
+
+	if tm != nil {
+		cm.damageTilesAround(tm, gr.X, gr.Y)
+	}
+	cm.Detonations = append(cm.Detonations, ev)
+}
+
+// applyFragment resolves a single fragment strike through the body map.
+func (cm *CombatManager) applyFragment(t *Soldier, damage float64) {
+	var coverMask [regionCount]float64
+	wound, instantDeath := t.body.ApplyHit(damage, t.profile.Stance, coverMask, cm.tick, cm.rng)
+	t.profile.Psych.ApplyStress(cm.config().HitStress)
+	if !wound.Stopped && t.body.penetratingWounds() == 1 {
+		t.casualty = NewCasualtyState(cm.tick)
+	}
+	switch {
+	case wound.Stopped:
+		t.think(fmt.Sprintf("fragment %s — stopped by %s", wound.Region, wound.Armour))
+	case instantDeath:
+		t.state = SoldierStateDead
+		t.think(fmt.Sprintf("fragment %s (%s) — killed instantly", wound.Region, wound.Severity))
+	case t.body.HealthFraction() <= 0:
+		t.state = SoldierStateDead
+		t.think(fmt.Sprintf("fragment %s (%s) — incapacitated", wound.Region, wound.Severity))
+	default:
+		t.think(fmt.Sprintf("fragment %s (%s) — grenade!", wound.Region, wound.Severity))
+		cm.cryOut(t)
+	}
+}
+
+// damageTilesAround breaks breakable objects near a burst and marks the ground.
+func (cm *CombatManager) damageTilesAround(tm *TileMap, x, y float64) {
+	col := int(x) / cellSize
+	row := int(y) / cellSize
+	for dr := -grenadeTileRadius; dr <= grenadeTileRadius; dr++ {
+		for dc := -grenadeTileRadius; dc <= grenadeTileRadius; dc++ {
+			ring := dc
+			if ring < 0 {
+				ring = -ring
+			}
+			if a := abs(dr); a > ring {
+				ring = a
+			}
+			dmg := grenadeTileDamage / (1 + ring)
+			cm.damageTerrain(tm, col+dc, row+dr, dmg)
+		}
+	}
+	tm.AddFlag(col, row, TileFlagDamaged)
+}
+
+// Grenades returns the grenades currently in flight or on the ground.
+func (cm *CombatManager) Grenades() []*Grenade {
+	return cm.grenades
+}
+
+// DrawGrenades renders grenades in flight, lit grenades on the ground and blast flashes.
+func (cm *CombatManager) DrawGrenades(screen *ebiten.Image, offX, offY int) {
+	ox, oy := float32(offX), float32(offY)
+	for _, b := range cm.blasts {
+		t := float32(b.age) / float32(blastLifetime)
+		r := float32(grenadeLethalRadius) * (0.4 + t*1.2)
+		a := uint8(200 * (1 - t))
+		vector.FillCircle(screen, ox+float32(b.x), oy+float32(b.y), r, color.RGBA{R: 255, G: 190, B: 80, A: a / 2}, false)
+		vector.StrokeCircle(screen, ox+float32(b.x), oy+float32(b.y), float32(grenadeCasualtyRadius)*t, 1.5, color.RGBA{R: 255, G: 230, B: 180, A: a}, false)
+	}
+	for _, gr := range cm.grenades {
+		// Lift the grenade along a parabola while in flight.
+		lift := 0.0
+		if gr.InFlight() {
+			p := float64(gr.flightTick) / float64(gr.flightTicks)
+			lift = 4 * p * (1 - p) * math.Hypot(gr.ToX-gr.FromX, gr.ToY-gr.FromY) * 0.18
+		}
+		x := ox + float32(gr.X)
+		y := oy + float32(gr.Y-lift)
+		vector.FillCircle(screen, ox+float32(gr.X), oy+float32(gr.Y), 2, color.RGBA{A: 90}, false)
+		if gr.Kind == GrenadeSmoke {
+			vector.FillCircle(screen, x, y, 2.5, color.RGBA{R: 190, G: 190, B: 185, A: 255}, false)
+			continue
+		}
+		vector.FillCircle(screen, x, y, 2.5, color.RGBA{R: 60, G: 70, B: 40, A: 255}, false)
+		if !gr.InFlight() && (gr.Fuse/8)%2 == 0 {
+			vector.StrokeCircle(screen, x, y, 4.5, 1, color.RGBA{R: 255, G: 80, B: 40, A: 200}, false)
+		}
+	}
+}
+
+// --- Soldier side: choosing and executing a throw ---
+
+// updateGrenadeTarget picks the best spot this soldier could throw a grenade at
+// and writes it to the blackboard for SelectGoal. Good targets are enemies the
+// soldier cannot shoot effectively: unseen, behind cover, or inside buildings,
+// and anything inside the building an entry team is about to breach.
+func (s *Soldier) updateGrenadeTarget() {
+	bb := &s.blackboard
+	if bb.GrenadeCooldown > 0 {
+		bb.GrenadeCooldown--
+	}
+	bb.HasGrenadeTarget = false
+	bb.GrenadeTargetValue = 0
+	if s.grenades <= 0 || bb.GrenadeCooldown > 0 {
+		return
+	}
+
+	best := 0.0
+	consider := func(x, y, value float64) {
+		if value <= best {
+			return
+		}
+		d := math.Hypot(x-s.x, y-s.y)
+		if d < grenadeMinThrowRange || d > grenadeMaxThrowRange {
+			return
+		}
+		if !HasClearLineOfSight(s.x, s.y, x, y, s.buildings, nil, s.smoke) || s.friendlyNear(x, y, grenadeCasualtyRadius) {
+			return
+		}
+		best = value
+		bb.GrenadeTargetX = x
+		bb.GrenadeTargetY = y
+		bb.HasGrenadeTarget = true
+	}
+
+	for _, t := range bb.Threats {
+		if t.Confidence < 0.4 {
+			continue
+		}
+		value := 0.20
+		if !t.IsVisible {
+			value += 0.35 // can't be shot, but we know where they are
+		}
+		if s.tileMap != nil {
+			if inCover, defence := TileMapCoverBetween(s.tileMap, t.X, t.Y, s.x, s.y); inCover {
+				value += defence * 0.80
+			}
+		} else if inCover, defence := IsBehindCover(t.X, t.Y, s.x, s.y, s.covers); inCover {
+			value += defence * 0.80
+		}
+		if insideAnyFootprint(t.X, t.Y, s.buildingFootprints) {
+			value += 0.35
+		}
+		for _, o := range bb.Threats {
+			if o.Source != t.Source && o.Confidence >= 0.4 && math.Hypot(o.X-t.X, o.Y-t.Y) <= grenadeCasualtyRadius*0.6 {
+				value += 0.15 // several enemies inside one burst
+			}
+		}
+		consider(t.X, t.Y, value)
+	}
+
+	// Entry teams clear the room before going in.
+	if (bb.EntryStage == EntryStateStacking || bb.EntryStage == EntryStateBreaching) &&
+		bb.ClaimedBuildingIdx >= 0 && bb.ClaimedBuildingIdx < len(s.buildingFootprints) {
+		fp := s.buildingFootprints[bb.ClaimedBuildingIdx]
+		for _, t := range bb.Threats {
+			if t.Confidence >= 0.25 && pointInFootprint(t.X, t.Y, fp) {
+				consider(t.X, t.Y, 0.95)
+			}
+		}
+		consider(bb.ClaimedBuildingX, bb.ClaimedBuildingY, 0.70)
+	}
+	bb.GrenadeTargetValue = best
+}
+
+// friendlyNear reports whether any other living friendly, from any squad, is
+// within radius of (x, y). Outside a World only the squad is known.
+func (s *Soldier) friendlyNear(x, y, radius float64) bool {
+	var near []*Soldier
+	switch {
+	case s.allies != nil:
+		near = s.allies.QueryRadius(x, y, radius)
+	case s.squad != nil:
+		near = s.squad.Members
+	}
+	for _, m := range near {
+		if m == s || m.state == SoldierStateDead {
+			continue
+		}
+		if math.Hypot(m.x-x, m.y-y) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// insideAnyFootprint reports whether (x, y) lies inside one of the building footprints.
+func insideAnyFootprint(x, y float64, footprints []rect) bool {
+	for _, fp := range footprints {
+		if pointInFootprint(x, y, fp) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointInFootprint reports whether (x, y) lies inside fp.
+func pointInFootprint(x, y float64, fp rect) bool {
+	return x >= float64(fp.x) && x < float64(fp.x+fp.w) && y >= float64(fp.y) && y < float64(fp.y+fp.h)
+}
+
+// grenadeUtility scores GoalThrowGrenade. The blackboard target value carries
+// most of the weight; fear and suppression make soldiers reluctant to expose
+// themselves for the throw.
+func grenadeUtility(bb *Blackboard, profile *SoldierProfile) float64 {
+	if !bb.HasGrenadeTarget {
+		return 0
+	}
+	u := 0.30 + bb.GrenadeTargetValue*0.90 + profile.Skills.Discipline*0.10
+	if bb.EntryStage == EntryStateStacking || bb.EntryStage == EntryStateBreaching {
+		u += 0.35
+	}
+	u -= profile.Psych.EffectiveFear() * 0.30
+	u -= bb.SuppressLevel * 0.45
+	if bb.SquadIntent == IntentWithdraw {
+		u -= 0.30
+	}
+	return u
+}
+
+// executeThrowGrenade implements GoalThrowGrenade: stop, face the aim point,
+// wind up, then hand the throw to the combat manager.
+func (s *Soldier) executeThrowGrenade(dt float64) {
+	bb := &s.blackboard
+	s.state = SoldierStateIdle
+	s.path = nil
+	s.pathIndex = 0
+	s.profile.Physical.AccumulateFatigue(0, dt)
+
+	if s.grenadeWindup == 0 {
+		if !bb.HasGrenadeTarget {
+			bb.ShatterEvent = true
+			return
+		}
+		s.grenadeAimX = bb.GrenadeTargetX
+		s.grenadeAimY = bb.GrenadeTargetY
+		s.grenadeWindup = grenadeWindupTicks
+		s.think(fmt.Sprintf("preparing grenade → (%.0f,%.0f)", s.grenadeAimX, s.grenadeAimY))
+	}
+
+	// Abort if a friendly has moved into the danger area.
+	if s.friendlyNear(s.grenadeAimX, s.grenadeAimY, grenadeCasualtyRadius) {
+		s.grenadeWindup = 0
+		bb.GrenadeCooldown = grenadeWindupTicks * 2
+		bb.ShatterEvent = true
+		s.think("friendlies near the target — holding the grenade")
+		return
+	}
+
+	s.requestStance(StanceCrouching, false)
+	s.vision.UpdateHeading(math.Atan2(s.grenadeAimY-s.y, s.grenadeAimX-s.x), turnRate*2)
+	s.grenadeWindup--
+	if s.grenadeWindup == 0 {
+		s.grenadeReady = true
+	}
+}
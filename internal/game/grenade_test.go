@@ -0,0 +1,200 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// landGrenade puts a grenade on the ground at (x, y) about to detonate.
+func landGrenade(cm *CombatManager, x, y float64) {
+	cm.grenades = append(cm.grenades, &Grenade{ThrowerID: 99, Team: TeamRed, FromX: x, FromY: y, ToX: x, ToY: y, X: x, Y: y, Fuse: 1})
+}
+
+func TestGrenade_DetonationWoundsByDistance(t *testing.T) {
+	tick := 0
+	near := newTestSoldier(1, 210, 200, TeamBlue, &tick)
+	far := newTestSoldier(2, 200+grenadeCasualtyRadius+40, 200, TeamBlue, &tick)
+	cm := NewCombatManager(3)
+	landGrenade(cm, 200, 200)
+
+	cm.ResolveGrenades([]*Soldier{near, far}, nil, nil)
+
+	if len(cm.Detonations) != 1 || len(cm.Grenades()) != 0 {
+		t.Fatalf("detonations=%d live=%d, want 1 and 0", len(cm.Detonations), len(cm.Grenades()))
+	}
+	if near.body.WoundCount() == 0 {
+		t.Fatal("soldier 10px from the burst should take fragments")
+	}
+	if far.body.WoundCount() != 0 {
+		t.Fatalf("soldier outside the casualty radius took %d wounds", far.body.WoundCount())
+	}
+	if far.blackboard.SuppressLevel <= 0 || far.profile.Psych.Fear <= 0 {
+		t.Fatal("blast should still stress and suppress soldiers inside the blast radius")
+	}
+}
+
+func TestGrenade_WallShieldsFragments(t *testing.T) {
+	tick := 0
+	behind := newTestSoldier(1, 240, 200, TeamBlue, &tick)
+	cm := NewCombatManager(3)
+	landGrenade(cm, 200, 200)
+	wall := []rect{{x: 220, y: 150, w: 8, h: 100}}
+
+	cm.ResolveGrenades([]*Soldier{behind}, wall, nil)
+
+	if behind.body.WoundCount() != 0 {
+		t.Fatalf("wall should stop fragments, got %d wounds", behind.body.WoundCount())
+	}
+}
+
+func TestGrenade_DamagesSandbags(t *testing.T) {
+	tm := NewTileMap(20, 20)
+	col, row := 5, 5
+	tm.SetObject(col, row, ObjectSandbag)
+	cm := NewCombatManager(3)
+	landGrenade(cm, float64(col*cellSize+cellSize/2), float64(row*cellSize+cellSize/2))
+	landGrenade(cm, float64(col*cellSize+cellSize/2), float64(row*cellSize+cellSize/2))
+
+	cm.ResolveGrenades(nil, nil, tm)
+
+	if tm.ObjectAt(col, row) != ObjectRubblePile {
+		t.Fatalf("two bursts on a sandbag should leave rubble, got %d", tm.ObjectAt(col, row))
+	}
+}
+
+func TestGrenade_ThrowFliesToTargetAndStopsAtWalls(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	s.profile.Skills.Discipline = 1
+	s.grenadeAimX, s.grenadeAimY = 300, 200
+	s.grenadeReady = true
+	cm := NewCombatManager(3)
+
+	cm.ResolveGrenades([]*Soldier{s}, nil, nil)
+	if s.grenades != defaultGrenadeCount-1 || len(cm.Grenades()) != 1 {
+		t.Fatalf("grenades left=%d live=%d", s.grenades, len(cm.Grenades()))
+	}
+	for i := 0; i < 60; i++ {
+		cm.ResolveGrenades([]*Soldier{s}, nil, nil)
+	}
+	gr := cm.Grenades()[0]
+	if gr.InFlight() || math.Hypot(gr.X-300, gr.Y-200) > 20 {
+		t.Fatalf("grenade at (%.0f,%.0f) in flight=%v, want landed near (300,200)", gr.X, gr.Y, gr.InFlight())
+	}
+
+	s.grenadeReady = true
+	cm.ResolveGrenades([]*Soldier{s}, []rect{{x: 150, y: 100, w: 8, h: 200}}, nil)
+	short := cm.Grenades()[1]
+	if short.ToX > 150 {
+		t.Fatalf("throw through a wall landed at x=%.0f, want short of x=150", short.ToX)
+	}
+}
+
+func TestSelectGoal_ThrowsGrenadeAtEnemyInCover(t *testing.T) {
+	p := DefaultProfile()
+	p.Psych.Fear = 0.1
+	bb := &Blackboard{}
+	bb.SquadHasContact = true
+	bb.SquadIntent = IntentEngage
+	bb.VisibleAllyCount = 2
+	bb.HasGrenadeTarget = true
+	bb.GrenadeTargetValue = 1.0
+
+	if g := SelectGoal(bb, &p, false, true); g != GoalThrowGrenade {
+		t.Fatalf("expected %s with a hidden enemy in cover, got %s", GoalThrowGrenade, g)
+	}
+
+	bb.HasGrenadeTarget = false
+	if g := SelectGoal(bb, &p, false, true); g == GoalThrowGrenade {
+		t.Fatal("should not throw without a target")
+	}
+}
+
+func TestUpdateGrenadeTarget_SkipsTargetsNearFriendlies(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	buddy := newTestSoldier(2, 100, 260, TeamRed, &tick)
+	enemy := newTestSoldier(3, 250, 200, TeamBlue, &tick)
+	sq := &Squad{Members: []*Soldier{s, buddy}}
+	s.squad = sq
+	s.blackboard.UpdateThreats([]*Soldier{enemy}, 1)
+
+	s.updateGrenadeTarget()
+	if !s.blackboard.HasGrenadeTarget {
+		t.Fatal("expected a grenade target 150px away")
+	}
+
+	buddy.x, buddy.y = 240, 220
+	s.updateGrenadeTarget()
+	if s.blackboard.HasGrenadeTarget {
+		t.Fatal("must not pick a target next to a squadmate")
+	}
+}
+
+func TestUpdateGrenadeTarget_SkipsTargetsNearOtherSquads(t *testing.T) {
+	ts := NewTestSim(
+		WithSeed(1),
+		WithMapSize(800, 400),
+		WithRedSoldier(0, 100, 200, 700, 200),
+		WithRedSoldier(1, 100, 240, 700, 240),
+		WithRedSoldier(2, 100, 320, 700, 320),
+		WithBlueSoldier(3, 250, 200, 250, 200),
+		WithRedSquad(0, 1),
+		WithRedSquad(2),
+	)
+	ts.RunTicks(1)
+	s, other, enemy := ts.Soldiers[0], ts.Soldiers[2], ts.Soldiers[3]
+	s.blackboard.UpdateThreats([]*Soldier{enemy}, 1)
+	s.updateGrenadeTarget()
+	if !s.blackboard.HasGrenadeTarget {
+		t.Fatal("expected a grenade target 150px away")
+	}
+
+	other.x, other.y = 240, 220
+	ts.spatialHashRed.Clear()
+	for _, m := range ts.soldiers {
+		ts.spatialHashRed.Insert(m)
+	}
+	s.updateGrenadeTarget()
+	if s.blackboard.HasGrenadeTarget {
+		t.Fatal("must not pick a target next to a friendly from another squad")
+	}
+}
+
+func TestGrenade_SquadThrowsAtEnemyBehindSandbags(t *testing.T) {
+	ts := NewTestSim(
+		WithSeed(1),
+		WithMapSize(800, 400),
+		WithRedSoldier(0, 100, 200, 700, 200),
+		WithRedSoldier(1, 100, 240, 700, 240),
+		WithBlueSoldier(2, 300, 200, 300, 200),
+		WithBlueSoldier(3, 300, 240, 300, 240),
+		WithRedSquad(0, 1),
+		WithBlueSquad(2, 3),
+	)
+	// TestSim has no tile map; give everyone a sandbag line in front of blue.
+	tm := NewTileMap(50, 25)
+	for r := 10; r <= 16; r++ {
+		tm.SetObject(17, r, ObjectSandbag)
+	}
+	for _, s := range ts.Soldiers {
+		s.tileMap = tm
+	}
+
+	bursts := 0
+	for i := 0; i < 600 && bursts == 0; i++ {
+		ts.RunTicks(1)
+		for _, d := range ts.combat.Detonations {
+			bursts++
+			if ts.Soldiers[d.ThrowerID].team != TeamRed {
+				t.Fatalf("unexpected thrower %d", d.ThrowerID)
+			}
+		}
+	}
+	if bursts == 0 {
+		t.Fatal("red never used a grenade on blue behind sandbags within 600 ticks")
+	}
+	if ts.Soldiers[2].body.WoundCount()+ts.Soldiers[3].body.WoundCount() == 0 {
+		t.Fatal("expected the burst to wound a blue soldier")
+	}
+}
@@ -1,9 +1,5 @@
 package game
 
-import (
-	"math/rand"
-)
-
 type HeadlessBattlefield struct {
 	Width  int
 	Height int
@@ -19,41 +15,20 @@ type HeadlessBattlefield struct {
 	MapSeed     int64
 }
 
+// NewHeadlessBattlefield generates the World terrain for mapSeed — the same
+// map the GUI builds from that seed — for use without a full World.
 func NewHeadlessBattlefield(mapSeed int64, battleW, battleH int) *HeadlessBattlefield {
-	g := &Game{
-		gameWidth:  battleW,
-		gameHeight: battleH,
-		mapSeed:    mapSeed,
-	}
-
-	mapRng := rand.New(rand.NewSource(mapSeed)) // #nosec G404 -- deterministic sim
-	g.tileMap = NewTileMap(battleW/cellSize, battleH/cellSize)
-	generateGridRoads(g.tileMap, mapRng, defaultRoadConfig)
-	g.initBuildings(mapRng)
-
-	coverRng := rand.New(rand.NewSource(mapSeed + 12345)) // #nosec G404 -- deterministic sim
-	var rubble []*CoverObject
-	g.covers, rubble = GenerateCover(g.gameWidth, g.gameHeight, g.buildingFootprints, g.buildings, coverRng, g.tileMap)
-	g.applyBuildingDamage(rubble)
-
-	g.initTileMap()
-	generateBiome(g.tileMap, mapRng, defaultBiomeConfig)
-	generateFortifications(g.tileMap, mapRng, defaultFortConfig)
-
-	g.navGrid = NewNavGrid(g.gameWidth, g.gameHeight, g.buildings, soldierRadius, g.covers, g.windows)
-	g.tacticalMap = NewTacticalMap(g.gameWidth, g.gameHeight, g.buildings, g.windows, g.buildingFootprints)
-	g.buildingQualities = ComputeBuildingQualities(g.buildingFootprints, g.buildings, g.windows, g.gameWidth, g.gameHeight, g.navGrid)
-
+	w := newWorldMap(mapSeed, battleW, battleH)
 	return &HeadlessBattlefield{
 		Width:              battleW,
 		Height:             battleH,
-		TileMap:            g.tileMap,
-		Buildings:          append([]rect(nil), g.buildings...),
-		BuildingFootprints: append([]rect(nil), g.buildingFootprints...),
-		Windows:            append([]rect(nil), g.windows...),
-		Covers:             append([]*CoverObject(nil), g.covers...),
-		NavGrid:            g.navGrid,
-		TacticalMap:        g.tacticalMap,
+		TileMap:            w.tileMap,
+		Buildings:          append([]rect(nil), w.buildings...),
+		BuildingFootprints: append([]rect(nil), w.buildingFootprints...),
+		Windows:            append([]rect(nil), w.windows...),
+		Covers:             append([]*CoverObject(nil), w.covers...),
+		NavGrid:            w.navGrid,
+		TacticalMap:        w.tacticalMap,
 		MapSeed:            mapSeed,
 	}
 }
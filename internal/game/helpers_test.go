@@ -0,0 +1,8 @@
+package game
+
+// newTestSoldier is a lone soldier on an open 800x600 nav grid, facing east
+// with no squad, for tests that drive one system directly.
+func newTestSoldier(id int, x, y float64, team Team, tick *int) *Soldier {
+	ng := NewNavGrid(800, 600, nil, 6, nil, nil)
+	return NewSoldier(id, x, y, team, [2]float64{x, y}, [2]float64{x + 100, y}, ng, nil, nil, NewThoughtLog(), tick)
+}
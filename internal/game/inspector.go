@@ -252,6 +252,10 @@ func (g *Game) drawInspectorCurated(buf *ebiten.Image, s *Soldier, lx, ly int) {
 				line(fmt.Sprintf("  +%d more", s.body.WoundCount()-3))
 				break
 			}
+			if w.Stopped {
+				line(fmt.Sprintf("  [A]%s stopped by %s", w.Region, w.Armour))
+				continue
+			}
 			treated := " "
 			if w.Treated {
 				treated = "T"
@@ -259,6 +263,9 @@ func (g *Game) drawInspectorCurated(buf *ebiten.Image, s *Soldier, lx, ly int) {
 			line(fmt.Sprintf("  [%s]%s %s bleed:%.1f", treated, w.Region, w.Severity, w.BleedRate))
 		}
 	}
+	if len(s.body.Armour) > 0 {
+		line(fmt.Sprintf("armour: %s (%d stopped)", s.body.armourSummary(), s.body.StoppedCount()))
+	}
 
 	// Functional degradation.
 	mobMul := s.body.MobilityMul()
@@ -412,6 +419,8 @@ func (g *Game) drawInspectorRaw(buf *ebiten.Image, s *Soldier, lx, ly int) {
 		vis := " "
 		if t.IsVisible {
 			vis = "V"
+		} else if t.Uncertain {
+			vis = "?"
 		}
 		line(fmt.Sprintf("  t%d[%s] (%.0f,%.0f) c=%.2f", i, vis, t.X, t.Y, t.Confidence))
 	}
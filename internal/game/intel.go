@@ -1,6 +1,9 @@
 package game
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // IntelMapKind identifies a specific heat layer within an IntelMap.
 type IntelMapKind int
@@ -249,10 +252,12 @@ func (l *HeatLayer) Fill(v float32) {
 type IntelMap struct {
 	Team   Team
 	layers [intelMapCount]*HeatLayer
+	// Paint holds the player's command layers for this team.
+	Paint *PaintLayer
 }
 
 func newIntelMap(team Team, rows, cols int) *IntelMap {
-	m := &IntelMap{Team: team}
+	m := &IntelMap{Team: team, Paint: newPaintLayer(rows, cols)}
 	for k := IntelMapKind(0); k < intelMapCount; k++ {
 		m.layers[k] = newHeatLayer(rows, cols, heatDecayRates[k])
 	}
@@ -304,6 +309,15 @@ func (m *IntelMap) ClearSeen(wx, wy float64) {
 	m.layers[IntelUnexplored].Set(row, col, 0)
 }
 
+// MergeReport stamps an enemy sighting heard over the radio. confidence
+// scales the heat, so a garbled report counts for less. Nothing refreshes it,
+// so it goes stale at the layers' decay rates.
+func (m *IntelMap) MergeReport(wx, wy, confidence float64) {
+	col, row := WorldToCell(wx, wy)
+	m.layers[IntelRecentContact].Add(row, col, float32(confidence*0.8))
+	m.layers[IntelThreatDensity].Add(row, col, float32(confidence*0.5))
+}
+
 // AccumulateThreatDensity bleeds ContactHeat into ThreatDensity each tick.
 func (m *IntelMap) AccumulateThreatDensity() {
 	contact := m.layers[IntelContact]
@@ -321,14 +335,50 @@ func (m *IntelMap) AccumulateThreatDensity() {
 
 // --- IntelStore ---
 
-// IntelStore owns all intelligence maps for all teams.
+// IntelMode decides how far a soldier's observations travel.
+type IntelMode int
+
+const (
+	// IntelShared writes every soldier's observations into one team-wide
+	// map, so each squad knows at once what any other squad sees.
+	IntelShared IntelMode = iota
+	// IntelRadio gives each squad its own map: its leader's picture. The
+	// squad's own positions, fire and casualties go in directly. Enemy
+	// sightings come only from the leader's own eyes, contact reports that
+	// reach them on the squad net and SITREPs on the platoon net.
+	IntelRadio
+)
+
+func (m IntelMode) String() string {
+	if m == IntelRadio {
+		return "radio"
+	}
+	return "shared"
+}
+
+// ParseIntelMode accepts "shared" or "radio"; "" is shared.
+func ParseIntelMode(s string) (IntelMode, error) {
+	switch s {
+	case "", "shared":
+		return IntelShared, nil
+	case "radio":
+		return IntelRadio, nil
+	}
+	return IntelShared, fmt.Errorf("unknown intel mode %q (want shared or radio)", s)
+}
+
+// IntelStore owns all intelligence maps for all teams. The team maps always
+// see everything their soldiers see; in IntelRadio mode the squads act on
+// their own maps instead (see NewSquadMap).
 type IntelStore struct {
-	maps    map[Team]*IntelMap
-	rows    int
-	cols    int
-	mapW    int // playfield width in pixels
-	mapH    int // playfield height in pixels
-	tileMap *TileMap
+	maps      map[Team]*IntelMap
+	squadMaps []*IntelMap
+	mode      IntelMode
+	rows      int
+	cols      int
+	mapW      int // playfield width in pixels
+	mapH      int // playfield height in pixels
+	tileMap   *TileMap
 }
 
 // NewIntelStore creates maps for TeamRed and TeamBlue sized to the given
@@ -348,6 +398,28 @@ func NewIntelStore(mapW, mapH int) *IntelStore {
 	return s
 }
 
+// SetMode switches between shared and radio-limited intel. Set it before
+// any squad maps are made.
+func (s *IntelStore) SetMode(m IntelMode) {
+	s.mode = m
+}
+
+// Mode reports how intel is shared.
+func (s *IntelStore) Mode() IntelMode {
+	return s.mode
+}
+
+// NewSquadMap makes a map for one squad of team. It starts from the team
+// map's terrain layers and is updated with the rest each tick.
+func (s *IntelStore) NewSquadMap(team Team) *IntelMap {
+	m := newIntelMap(team, s.rows, s.cols)
+	if tm := s.maps[team]; tm != nil {
+		copy(m.layers[IntelOpenGround].cells, tm.layers[IntelOpenGround].cells)
+	}
+	s.squadMaps = append(s.squadMaps, m)
+	return m
+}
+
 // SetTileMap provides the authoritative terrain map. When set, IntelOpenGround
 // is (re)computed for all teams.
 func (s *IntelStore) SetTileMap(tm *TileMap) {
@@ -359,8 +431,8 @@ func (s *IntelStore) recomputeOpenGround() {
 	if s.tileMap == nil {
 		return
 	}
-	// OpenGround is structural and identical for both teams.
-	for _, im := range s.maps {
+	// OpenGround is structural and identical for every map.
+	for _, im := range s.allMaps() {
 		layer := im.Layer(IntelOpenGround)
 		for row := 0; row < s.rows; row++ {
 			for col := 0; col < s.cols; col++ {
@@ -379,9 +451,20 @@ func (s *IntelStore) For(team Team) *IntelMap {
 	return s.maps[team]
 }
 
-// Decay applies per-tick decay to all team maps.
+// allMaps returns the team maps, red then blue, then the squad maps.
+func (s *IntelStore) allMaps() []*IntelMap {
+	out := make([]*IntelMap, 0, 2+len(s.squadMaps))
+	for _, team := range []Team{TeamRed, TeamBlue} {
+		if m := s.maps[team]; m != nil {
+			out = append(out, m)
+		}
+	}
+	return append(out, s.squadMaps...)
+}
+
+// Decay applies per-tick decay to all maps.
 func (s *IntelStore) Decay() {
-	for _, m := range s.maps {
+	for _, m := range s.allMaps() {
 		m.Decay()
 	}
 }
@@ -401,7 +484,7 @@ func (s *IntelStore) Update(redSoldiers, blueSoldiers []*Soldier, buildings []re
 	s.writeSoldiers(blueSoldiers, redSoldiers, buildings)
 
 	// Accumulate derived ThreatDensity from contact heat.
-	for _, m := range s.maps {
+	for _, m := range s.allMaps() {
 		m.AccumulateThreatDensity()
 		s.computeSafeTerritory(m)
 	}
@@ -453,22 +536,32 @@ func (s *IntelStore) writeSoldiers(soldiers, _ []*Soldier, _ []rect) {
 		if sol.state == SoldierStateDead {
 			continue
 		}
+		s.writeSoldier(m, sol, true)
+		if sq := sol.squad; s.mode == IntelRadio && sq != nil && sq.intel != nil {
+			s.writeSoldier(sq.intel, sol, sol == sq.Leader)
+		}
+	}
+}
 
-		// Friendly presence — stamp soldier's own position.
-		m.WriteFriendlyPresence(sol.x, sol.y)
+// writeSoldier writes one soldier's intel into m. Enemy sightings are
+// written only when sightings is set.
+func (s *IntelStore) writeSoldier(m *IntelMap, sol *Soldier, sightings bool) {
+	// Friendly presence — stamp soldier's own position.
+	m.WriteFriendlyPresence(sol.x, sol.y)
 
-		// Danger zone — soldier is being shot at.
-		if sol.blackboard.IncomingFireCount > 0 {
-			m.WriteDangerZone(sol.x, sol.y, sol.blackboard.IncomingFireCount)
-		}
+	// Danger zone — soldier is being shot at.
+	if sol.blackboard.IncomingFireCount > 0 {
+		m.WriteDangerZone(sol.x, sol.y, sol.blackboard.IncomingFireCount)
+	}
 
-		// Casualty danger — injured/incapacitated friendlies mark hazardous areas
-		// that should be approached cautiously (and later searched).
-		if sol.state == SoldierStateWoundedAmbulatory || sol.state == SoldierStateWoundedNonAmbulatory || sol.state == SoldierStateUnconscious {
-			col, row := WorldToCell(sol.x, sol.y)
-			m.Layer(IntelCasualtyDanger).Add(row, col, 0.9)
-		}
+	// Casualty danger — injured/incapacitated friendlies mark hazardous areas
+	// that should be approached cautiously (and later searched).
+	if sol.state == SoldierStateWoundedAmbulatory || sol.state == SoldierStateWoundedNonAmbulatory || sol.state == SoldierStateUnconscious {
+		col, row := WorldToCell(sol.x, sol.y)
+		m.Layer(IntelCasualtyDanger).Add(row, col, 0.9)
+	}
 
+	if sightings {
 		// Contact heat — from live vision contacts.
 		for _, c := range sol.vision.KnownContacts {
 			m.WriteContact(c.x, c.y)
@@ -483,11 +576,11 @@ func (s *IntelStore) writeSoldiers(soldiers, _ []*Soldier, _ []rect) {
 				m.WriteRecentContact(t.X, t.Y, float64(t.Confidence))
 			}
 		}
-
-		// Unexplored: clear cells within the soldier's approximate vision cone.
-		// We sample a grid of points inside the cone and clear each one.
-		s.clearVisibleCells(m, sol)
 	}
+
+	// Unexplored: clear cells within the soldier's approximate vision cone.
+	// We sample a grid of points inside the cone and clear each one.
+	s.clearVisibleCells(m, sol)
 }
 
 // clearVisibleCells clears IntelUnexplored for cells visible to a soldier.
@@ -45,3 +45,152 @@ func TestIntelStore_CasualtyDanger_StampedFromWounded(t *testing.T) {
 		t.Fatalf("expected casualty danger > 0 at wounded location, got %v", v)
 	}
 }
+
+// newSplitLaneSim puts two red pairs either side of a long wall. Only the
+// north pair can see the blue rifleman.
+func newSplitLaneSim(mode IntelMode, extra ...SimOption) *TestSim {
+	opts := []SimOption{
+		WithSeed(3),
+		WithMapSize(1600, 900),
+		WithBuilding(100, 440, 1400, 32),
+		WithIntelMode(mode),
+		WithRedSoldier(0, 200, 200, 260, 200),
+		WithRedSoldier(1, 200, 240, 260, 240),
+		WithRedSoldier(2, 200, 700, 260, 700),
+		WithRedSoldier(3, 200, 740, 260, 740),
+		WithBlueSoldier(10, 700, 220, 700, 220),
+		WithRedSquad(0, 1),
+		WithRedSquad(2, 3),
+		WithBlueSquad(10),
+	}
+	return NewTestSim(append(opts, extra...)...)
+}
+
+// contactHeat is how strongly sq's intel places an enemy near (x,y).
+func contactHeat(sq *Squad, intel *IntelStore, x, y float64) float32 {
+	return sq.intelMap(intel).Layer(IntelRecentContact).SumInRadius(x, y, 80)
+}
+
+func TestIntel_RadioModeKeepsSquadPicturesApart(t *testing.T) {
+	shared := newSplitLaneSim(IntelShared)
+	radio := newSplitLaneSim(IntelRadio)
+	shared.RunTicks(30)
+	radio.RunTicks(30)
+
+	if h := contactHeat(shared.squads[1], shared.intel, 700, 220); h <= 0 {
+		t.Fatalf("shared intel: the south squad should know of the rifleman, heat=%v", h)
+	}
+	if h := contactHeat(radio.squads[0], radio.intel, 700, 220); h <= 0 {
+		t.Fatalf("radio intel: the north squad's leader sees the rifleman, heat=%v", h)
+	}
+	if h := contactHeat(radio.squads[1], radio.intel, 700, 220); h != 0 {
+		t.Errorf("radio intel: the south squad knows of a rifleman nobody told it about, heat=%v", h)
+	}
+	// The team map still sees everything, for the overlay.
+	if h := radio.intel.For(TeamRed).Layer(IntelRecentContact).SumInRadius(700, 220, 80); h <= 0 {
+		t.Errorf("radio intel: team map lost the sighting, heat=%v", h)
+	}
+
+}
+
+func TestIntel_ContactReportsFeedTheSquadPicture(t *testing.T) {
+	// The leader is south of the wall; only their rifleman sees the enemy.
+	newSim := func(extra ...SimOption) *TestSim {
+		opts := []SimOption{
+			WithSeed(5),
+			WithMapSize(1600, 900),
+			WithBuilding(100, 440, 1400, 32),
+			WithIntelMode(IntelRadio),
+			WithRedSoldier(0, 200, 700, 260, 700),
+			WithRedSoldier(1, 200, 200, 260, 200),
+			WithBlueSoldier(10, 700, 220, 700, 220),
+			WithRedSquad(0, 1),
+			WithBlueSquad(10),
+		}
+		ts := NewTestSim(append(opts, extra...)...)
+		ts.Soldiers[1].radioLastContactReportTick = -radioContactReportCooldown // report on first sight
+		return ts
+	}
+
+	ts := newSim()
+	heard := ts.RunUntil(func(ts *TestSim) bool { return ts.squads[0].Leader.blackboard.RadioHasContact }, 120)
+	if heard < 0 {
+		t.Fatalf("the rifleman's contact report never reached the leader")
+	}
+	if h := contactHeat(ts.squads[0], ts.intel, 700, 220); h <= 0 {
+		t.Fatalf("delivered contact report left no heat in the squad's picture")
+	}
+
+	// Jam the rifleman and the same report never arrives.
+	jammed := newSim(WithJammer(Jammer{Team: TeamBlue, X: 200, Y: 200, Radius: 200, Strength: 1}))
+	jammed.RunTicks(heard + 120)
+	if jammed.squads[0].RadioDropped == 0 {
+		t.Fatalf("expected the jammer to drop the rifleman's reports")
+	}
+	if h := contactHeat(jammed.squads[0], jammed.intel, 700, 220); h != 0 {
+		t.Errorf("squad picture has the enemy although every report was jammed, heat=%v", h)
+	}
+}
+
+// newMeetingEngagement sends two red and two blue four-man squads at each
+// other across a generated battlefield.
+func newMeetingEngagement(seed int64, mode IntelMode) *TestSim {
+	opts := []SimOption{WithSeed(seed), WithHeadlessBattlefield(NewHeadlessBattlefield(seed, 1600, 900)), WithIntelMode(mode)}
+	for i := 0; i < 4; i++ {
+		y := 180 + float64(i)*40
+		opts = append(opts,
+			WithRedSoldier(i, 80, y, 1500, y),
+			WithRedSoldier(4+i, 80, y+400, 1500, y+400),
+			WithBlueSoldier(10+i, 1520, y, 100, y),
+			WithBlueSoldier(14+i, 1520, y+400, 100, y+400))
+	}
+	opts = append(opts, WithRedSquad(0, 1, 2, 3), WithRedSquad(4, 5, 6, 7), WithBlueSquad(10, 11, 12, 13), WithBlueSquad(14, 15, 16, 17))
+	return NewTestSim(opts...)
+}
+
+// squadAwareTicks runs ts for ticks and counts, tick by tick, how many squads
+// carry any recent contact in their own intel picture.
+func squadAwareTicks(ts *TestSim, ticks int) int {
+	aware := 0
+	for i := 0; i < ticks; i++ {
+		ts.RunTicks(1)
+		for _, sq := range ts.squads {
+			if sq.intelMap(ts.intel).Layer(IntelRecentContact).SumInRadius(800, 450, 2000) > 0 {
+				aware++
+			}
+		}
+	}
+	return aware
+}
+
+func TestIntel_OmniscientAndRadioOutcomes(t *testing.T) {
+	const ticks = 1200
+	for _, seed := range []int64{11, 12, 13} {
+		aware := map[IntelMode]int{}
+		for _, mode := range []IntelMode{IntelShared, IntelRadio} {
+			ts := newMeetingEngagement(seed, mode)
+			aware[mode] = squadAwareTicks(ts, ticks)
+			r := ts.Outcome()
+			t.Logf("seed %d, %s intel: %s, red %d / blue %d survivors, %d squad-ticks aware of contact", seed, mode, r.Outcome, r.RedSurvivors, r.BlueSurvivors, aware[mode])
+			if r.RedSurvivors > 8 || r.BlueSurvivors > 8 {
+				t.Fatalf("%s intel: survivor counts %d/%d exceed the starting strength", mode, r.RedSurvivors, r.BlueSurvivors)
+			}
+			if mode != IntelRadio {
+				continue
+			}
+			// Each squad's picture is a subset of what its team as a whole saw.
+			for _, sq := range ts.squads {
+				team := ts.intel.For(sq.Team).Layer(IntelRecentContact).SumInRadius(800, 450, 2000)
+				if own := sq.intelMap(ts.intel).Layer(IntelRecentContact).SumInRadius(800, 450, 2000); own > team+1e-3 {
+					t.Errorf("squad %d pictures more contact (%.2f) than its team saw (%.2f)", sq.ID, own, team)
+				}
+			}
+		}
+		// Shared intel hands every squad the team picture the moment anyone
+		// sees the enemy; over radio a squad only knows what it saw or was told.
+		if aware[IntelRadio] >= aware[IntelShared] {
+			t.Errorf("seed %d: radio squads were aware of contact for %d squad-ticks, shared for %d; radio should know less",
+				seed, aware[IntelRadio], aware[IntelShared])
+		}
+	}
+}
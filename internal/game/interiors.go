@@ -196,11 +196,14 @@ func placeDoorInDoorway(tm *TileMap, rng *rand.Rand, doorX, doorY, unit int, isE
 	}
 
 	if isExterior {
-		// Exterior doors: 70% closed, 30% open.
+		// Exterior doors: 70% closed, 30% open. Some closed ones are locked.
 		if rng.Float64() < 0.30 {
 			tm.SetObject(dc, dr, ObjectDoorOpen)
 		} else {
 			tm.SetObject(dc, dr, ObjectDoor)
+			if doorStartsLocked(dc, dr) {
+				tm.AddFlag(dc, dr, TileFlagLocked)
+			}
 		}
 	} else {
 		// Interior doors: 80% closed, 20% open.
@@ -35,6 +35,25 @@ func HasLineOfSightWithCover(ax, ay, bx, by float64, buildings []rect, covers []
 	return true
 }
 
+// LineOfSightClarity returns how clear the view from (ax,ay) to (bx,by) is:
+// 0 when buildings, tall walls or smoke block it entirely, otherwise the
+// share of the view the smoke along the ray leaves. covers and smoke may be
+// nil.
+func LineOfSightClarity(ax, ay, bx, by float64, buildings []rect, covers []*CoverObject, smoke *SmokeField) float64 {
+	if !HasLineOfSightWithCover(ax, ay, bx, by, buildings, covers) {
+		return 0
+	}
+	return 1 - smoke.Obscuration(ax, ay, bx, by)
+}
+
+// HasClearLineOfSight reports whether anything at all can be seen from
+// (ax,ay) to (bx,by) past buildings, tall walls and smoke. Every sight check
+// goes through this or LineOfSightClarity; the smoke-blind versions above are
+// for movement, blast and sound.
+func HasClearLineOfSight(ax, ay, bx, by float64, buildings []rect, covers []*CoverObject, smoke *SmokeField) bool {
+	return HasLineOfSightWithCover(ax, ay, bx, by, buildings, covers) && !smoke.Blocks(ax, ay, bx, by)
+}
+
 // rayAABBHitT returns the first segment parameter t in [0,1] where the line
 // from (ox,oy)->(ex,ey) enters the AABB. The bool is false when no hit exists.
 func rayAABBHitT(ox, oy, ex, ey, minX, minY, maxX, maxY float64) (float64, bool) {
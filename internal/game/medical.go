@@ -1,9 +1,6 @@
 package game
 
-import (
-	"math"
-	"math/rand"
-)
+import "math"
 
 // ---------------------------------------------------------------------------
 // Medical Aid System — TCCC-Informed Casualty Response
@@ -82,6 +79,14 @@ type CasualtyState struct {
 	DragTargetY    float64
 	ReportSent     bool // casualty report transmitted to leader
 	StabilizedTick int  // tick when all critical bleeds controlled (0 = not yet)
+
+	// TACEVAC: stretcher bearers (at most stretcherBearers), whether the
+	// casualty has reached the squad's CCP, and whether they have left the
+	// field through the evacuation point.
+	Bearers       []*Soldier
+	AtCCP         bool
+	Evacuated     bool
+	EvacuatedTick int
 }
 
 // NewCasualtyState initializes casualty state in Care Under Fire phase.
@@ -138,6 +143,26 @@ func treatmentSuccessChance(provider *Soldier, targetPain float64) float64 {
 	return clamp01(base - painPenalty - fearPenalty)
 }
 
+// treatmentRoll returns a deterministic [0,1) roll for a treatment attempt,
+// keyed on provider, casualty and tick so seeded runs replay exactly. The
+// key is run through a 64-bit mixer so the roll is uniform and every success
+// chance means what it says.
+func treatmentRoll(provider, casualty *Soldier, tick int) float64 {
+	h := mix64(uint64(int64(tick)))               // #nosec G115 -- bit pattern only
+	h = mix64(h ^ uint64(int64(provider.id)))     // #nosec G115 -- bit pattern only
+	h = mix64(h ^ uint64(int64(casualty.id))<<32) // #nosec G115 -- bit pattern only
+	return float64(h>>11) / (1 << 53)
+}
+
+// mix64 is the splitmix64 finaliser: it scrambles x so that nearby inputs
+// give unrelated, evenly spread outputs.
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
 // ---------------------------------------------------------------------------
 // Self-Aid
 // ---------------------------------------------------------------------------
@@ -153,7 +178,7 @@ func (s *Soldier) attemptSelfAid(tick int) bool {
 	var worstWound *Wound
 	for i := range s.body.Wounds {
 		w := &s.body.Wounds[i]
-		if w.Treated {
+		if w.Treated || w.Stopped {
 			continue
 		}
 		// Only limbs can have tourniquets.
@@ -197,7 +222,7 @@ func (s *Soldier) tickSelfAid(tick int) {
 		pain := s.body.TotalPain()
 		successChance := treatmentSuccessChance(s, pain)
 
-		if rand.Float64() < successChance {
+		if treatmentRoll(s, s, tick) < successChance {
 			// Success: mark wound as treated.
 			treat.TargetWound.Treated = true
 			treat.TargetWound.TreatedTick = tick
@@ -241,12 +266,11 @@ func (s *Soldier) findNearestCasualty() *Soldier {
 		if !m.body.IsInjured() {
 			continue
 		}
-		// Skip if already being treated by 2 providers.
-		if len(m.casualty.Providers) >= 2 {
-			continue
-		}
-		// Skip if all wounds are treated (unless unconscious - they need monitoring).
-		if !m.body.HasUntreatedWounds() && m.state != SoldierStateUnconscious {
+		// Skip unless they need treatment from fewer than 2 providers or
+		// a stretcher with a free handle.
+		needsAid := casualtyNeedsAid(m) && len(m.casualty.Providers) < 2
+		needsCarry := m.needsStretcher() && len(m.casualty.Bearers) < stretcherBearers
+		if !needsAid && !needsCarry {
 			continue
 		}
 
@@ -333,7 +357,7 @@ func tickProvidedAid(casualty *Soldier, tick int) {
 			successChance = clamp01(successChance + 0.2)
 		}
 
-		if rand.Float64() < successChance {
+		if treatmentRoll(treat.Provider, casualty, tick) < successChance {
 			// Success: apply treatment effect.
 			switch treat.Action {
 			case TreatApplyTourniquet:
@@ -385,9 +409,10 @@ func casualtyNeedsAid(c *Soldier) bool {
 	if !c.body.IsInjured() {
 		return false
 	}
-	// Unconscious soldiers always need aid (monitoring / airway management).
+	// Unconscious soldiers need aid (monitoring / airway management) until
+	// they are stabilised and handed over for evacuation.
 	if c.state == SoldierStateUnconscious {
-		return true
+		return c.casualty.Phase != PhaseTACEVAC
 	}
 	// Otherwise, only need aid if there are untreated wounds.
 	return c.body.HasUntreatedWounds()
@@ -10,6 +10,11 @@ func (s *Soldier) executeHelpCasualty(dt float64) {
 		return
 	}
 
+	if s.litter != nil {
+		s.executeStretcherCarry(dt)
+		return
+	}
+
 	// Find nearest casualty needing aid.
 	casualty := s.findNearestCasualty()
 	if casualty == nil {
@@ -45,7 +50,7 @@ func (s *Soldier) executeHelpCasualty(dt float64) {
 
 	// If the casualty is incapacitated and the helper is under threat, drag first.
 	bb := &s.blackboard
-	if s.isMedic && !casualty.casualty.BeingDragged && casualty.state.IsIncapacitated() && (bb.IncomingFireCount > 0 || bb.VisibleThreatCount() > 0) {
+	if s.isMedic && !casualty.casualty.BeingDragged && casualty.casualty.Phase != PhaseTACEVAC && casualty.state.IsIncapacitated() && (bb.IncomingFireCount > 0 || bb.VisibleThreatCount() > 0) {
 		// Pick a drag destination away from the closest visible threat.
 		best := math.MaxFloat64
 		var tx, ty float64
@@ -79,6 +84,13 @@ func (s *Soldier) executeHelpCasualty(dt float64) {
 	dy := casualty.y - s.y
 	dist := math.Sqrt(dx*dx + dy*dy)
 
+	// A stabilised stretcher case is carried, not treated.
+	if dist < 35.0 && casualty.needsStretcher() {
+		s.joinStretcher(casualty)
+		s.executeStretcherCarry(dt)
+		return
+	}
+
 	// If close enough, provide aid.
 	if dist < 35.0 {
 		s.state = SoldierStateIdle
@@ -152,6 +164,7 @@ func (s *Soldier) integrateWoundedSelfAid() {
 func integrateBuddyAidTick(soldiers []*Soldier, tick int) {
 	for _, s := range soldiers {
 		if s.state == SoldierStateDead {
+			releaseStretcher(s)
 			continue
 		}
 		if !s.body.IsInjured() {
@@ -160,6 +173,8 @@ func integrateBuddyAidTick(soldiers []*Soldier, tick int) {
 
 		// Tick any active treatment.
 		tickProvidedAid(s, tick)
+		updateCasualtyPhase(s, tick)
+		integrateEvacuation(s, tick)
 		// If the casualty no longer needs aid, release all providers.
 		stopAllProvidersIfNoAidNeeded(s)
 
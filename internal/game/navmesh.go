@@ -12,6 +12,9 @@ type NavGrid struct {
 	cols    int
 	rows    int
 	blocked []bool
+	// extra is the added path cost of crossing a cell, in cells, e.g. the
+	// time to open a closed door. Sparse; nil when nothing costs extra.
+	extra map[int]float64
 }
 
 // NewNavGrid builds a walkability grid from the map dimensions and buildings.
@@ -78,6 +81,27 @@ func (ng *NavGrid) IsBlocked(cx, cy int) bool {
 	return ng.blocked[cy*ng.cols+cx]
 }
 
+// setBlocked marks one cell walkable or not, e.g. when fire breaks open a
+// window.
+func (ng *NavGrid) setBlocked(cx, cy int, blocked bool) {
+	if cx < 0 || cy < 0 || cx >= ng.cols || cy >= ng.rows {
+		return
+	}
+	ng.blocked[cy*ng.cols+cx] = blocked
+}
+
+// setExtraCost sets the added path cost of cell index k; 0 clears it.
+func (ng *NavGrid) setExtraCost(k int, c float64) {
+	if c == 0 {
+		delete(ng.extra, k)
+		return
+	}
+	if ng.extra == nil {
+		ng.extra = make(map[int]float64)
+	}
+	ng.extra[k] = c
+}
+
 // WorldToCell converts world pixel coordinates to grid cell coordinates.
 func WorldToCell(wx, wy float64) (int, int) {
 	return int(wx) / cellSize, int(wy) / cellSize
@@ -171,6 +195,9 @@ func (ng *NavGrid) FindPath(sx, sy, gx, gy float64) [][2]float64 {
 			if d[0] != 0 && d[1] != 0 {
 				cost = math.Sqrt2
 			}
+			if ng.extra != nil {
+				cost += ng.extra[nk]
+			}
 			ng := cur.g + cost
 			if prev, ok := best[nk]; ok && ng >= prev.g {
 				continue
@@ -37,9 +37,55 @@ type BattleOutcomeReason struct {
 	RedFled          int
 	BlueFled         int
 	Description      string
+
+	// Where each team's losses went. Evacuated soldiers left the field alive
+	// through an evacuation point and count as survivors.
+	RedKilled         int
+	RedIncapacitated  int
+	RedEvacuated      int
+	BlueKilled        int
+	BlueIncapacitated int
+	BlueEvacuated     int
+
+	// Rounds and fragments each team's armour stopped.
+	RedStopped  int
+	BlueStopped int
+}
+
+// casualtyCounts splits a team's losses into killed, incapacitated (still on
+// the field) and evacuated.
+func casualtyCounts(soldiers []*Soldier) (killed, incapacitated, evacuated int) {
+	for _, s := range soldiers {
+		switch {
+		case s.casualty.Evacuated:
+			evacuated++
+		case s.state == SoldierStateDead:
+			killed++
+		case s.state.IsIncapacitated():
+			incapacitated++
+		}
+	}
+	return killed, incapacitated, evacuated
+}
+
+// roundsStopped counts the hits armour stopped across soldiers.
+func roundsStopped(soldiers []*Soldier) int {
+	n := 0
+	for _, s := range soldiers {
+		n += s.body.StoppedCount()
+	}
+	return n
 }
 
 func DetermineBattleOutcome(redSoldiers, blueSoldiers []*Soldier, redSquads, blueSquads []*Squad) BattleOutcomeReason {
+	r := decideBattleOutcome(redSoldiers, blueSoldiers, redSquads, blueSquads)
+	r.RedKilled, r.RedIncapacitated, r.RedEvacuated = casualtyCounts(redSoldiers)
+	r.BlueKilled, r.BlueIncapacitated, r.BlueEvacuated = casualtyCounts(blueSoldiers)
+	r.RedStopped, r.BlueStopped = roundsStopped(redSoldiers), roundsStopped(blueSoldiers)
+	return r
+}
+
+func decideBattleOutcome(redSoldiers, blueSoldiers []*Soldier, redSquads, blueSquads []*Squad) BattleOutcomeReason {
 	redTotal := len(redSoldiers)
 	blueTotal := len(blueSoldiers)
 	redSurvivors := 0
@@ -48,12 +94,12 @@ func DetermineBattleOutcome(redSoldiers, blueSoldiers []*Soldier, redSquads, blu
 	blueFled := 0
 
 	for _, s := range redSoldiers {
-		if s.state != SoldierStateDead {
+		if !s.killed() {
 			redSurvivors++
 		}
 	}
 	for _, s := range blueSoldiers {
-		if s.state != SoldierStateDead {
+		if !s.killed() {
 			blueSurvivors++
 		}
 	}
@@ -0,0 +1,259 @@
+package game
+
+import "math"
+
+// --- Command paint constants ---
+
+const (
+	defaultCommandDelay = 180 // ticks (~3s) from a brush stroke to troops acting on it
+
+	paintDefaultRadius = 48.0  // px, brush radius
+	paintMinRadius     = 16.0  // px
+	paintMaxRadius     = 192.0 // px
+	paintDabStrength   = 0.45  // heat added at the brush centre per dab
+
+	paintMinHeat       = 0.25  // heat at which a cell counts as painted
+	paintAreaRadius    = 160.0 // px, cells this close to the picked cell form one painted area
+	paintAvoidCost     = 6.0   // CostField cost added at full avoid heat
+	paintRouteDiscount = 0.6   // CostField cost removed at full route heat
+)
+
+// PaintKind is one of the player's command layers.
+type PaintKind int
+
+const (
+	PaintObjective PaintKind = iota // attracts advance orders
+	PaintAvoid                      // steers movement away
+	PaintRoute                      // cheap ground for movement
+	PaintHold                       // squads that reach it stop and hold
+	paintKindCount                  // sentinel
+)
+
+func (k PaintKind) String() string {
+	switch k {
+	case PaintObjective:
+		return "objective"
+	case PaintAvoid:
+		return "avoid"
+	case PaintRoute:
+		return "route"
+	case PaintHold:
+		return "hold"
+	default:
+		return "unknown"
+	}
+}
+
+// PaintStroke is one dab of the player's brush. It is painted at Tick and
+// reaches the troops at Due.
+type PaintStroke struct {
+	Team   Team      `json:"team"`
+	Kind   PaintKind `json:"kind"`
+	X      float64   `json:"x"`
+	Y      float64   `json:"y"`
+	Radius float64   `json:"radius"`
+	Erase  bool      `json:"erase,omitempty"`
+	Tick   int       `json:"tick"`
+	Due    int       `json:"due"`
+}
+
+// PaintLayer holds one team's painted commands. Strokes wait in a queue
+// until they are due, so the AI only ever reads orders that have made it
+// down the chain of command. A nil *PaintLayer has nothing painted.
+type PaintLayer struct {
+	layers  [paintKindCount]*HeatLayer
+	painted [paintKindCount]int // cells at or above paintMinHeat, per kind
+	pending []PaintStroke
+	// Revision counts changes to the active layers so consumers can cache.
+	Revision int
+}
+
+func newPaintLayer(rows, cols int) *PaintLayer {
+	p := &PaintLayer{}
+	for k := PaintKind(0); k < paintKindCount; k++ {
+		p.layers[k] = newHeatLayer(rows, cols, 0)
+	}
+	return p
+}
+
+// Layer returns the active heat for kind k.
+func (p *PaintLayer) Layer(k PaintKind) *HeatLayer {
+	return p.layers[k]
+}
+
+// Pending returns strokes that have been painted but not yet acted on.
+func (p *PaintLayer) Pending() []PaintStroke {
+	if p == nil {
+		return nil
+	}
+	return p.pending
+}
+
+// Queue adds a stroke to wait out its command delay.
+func (p *PaintLayer) Queue(st PaintStroke) {
+	if p == nil {
+		return
+	}
+	p.pending = append(p.pending, st)
+}
+
+// Update applies every queued stroke that is due by tick, in the order painted.
+func (p *PaintLayer) Update(tick int) {
+	if p == nil || len(p.pending) == 0 {
+		return
+	}
+	waiting := p.pending[:0]
+	for _, st := range p.pending {
+		if st.Due > tick {
+			waiting = append(waiting, st)
+			continue
+		}
+		p.apply(st)
+	}
+	p.pending = waiting
+}
+
+// apply dabs a stroke into its layer with a soft falloff toward the rim.
+func (p *PaintLayer) apply(st PaintStroke) {
+	if st.Kind < 0 || st.Kind >= paintKindCount {
+		return
+	}
+	l := p.layers[st.Kind]
+	c0, r0 := WorldToCell(st.X-st.Radius, st.Y-st.Radius)
+	c1, r1 := WorldToCell(st.X+st.Radius, st.Y+st.Radius)
+	for row := r0; row <= r1; row++ {
+		for col := c0; col <= c1; col++ {
+			wx, wy := CellToWorld(col, row)
+			d := math.Hypot(wx-st.X, wy-st.Y)
+			if d > st.Radius {
+				continue
+			}
+			if st.Erase {
+				l.Set(row, col, 0)
+				continue
+			}
+			l.Add(row, col, float32(paintDabStrength*(1-0.5*d/st.Radius)))
+		}
+	}
+	n := 0
+	for _, v := range l.cells {
+		if v >= paintMinHeat {
+			n++
+		}
+	}
+	p.painted[st.Kind] = n
+	p.Revision++
+}
+
+// Has reports whether any cell of kind k is painted.
+func (p *PaintLayer) Has(k PaintKind) bool {
+	return p != nil && p.painted[k] > 0
+}
+
+// In reports whether (x, y) lies inside painted area of kind k.
+func (p *PaintLayer) In(k PaintKind, x, y float64) bool {
+	return p != nil && p.layers[k].SampleAt(x, y) >= paintMinHeat
+}
+
+// AreaNear returns the heat-weighted centre of the painted area of kind k
+// closest to (x, y). ok is false when nothing of that kind is painted.
+func (p *PaintLayer) AreaNear(k PaintKind, x, y float64) (float64, float64, bool) {
+	if !p.Has(k) {
+		return 0, 0, false
+	}
+	l := p.layers[k]
+	bestD := math.MaxFloat64
+	var ax, ay float64
+	for row := 0; row < l.rows; row++ {
+		for col := 0; col < l.cols; col++ {
+			if l.cells[row*l.cols+col] < paintMinHeat {
+				continue
+			}
+			wx, wy := CellToWorld(col, row)
+			if d := math.Hypot(wx-x, wy-y); d < bestD {
+				bestD, ax, ay = d, wx, wy
+			}
+		}
+	}
+	if bestD == math.MaxFloat64 {
+		return 0, 0, false
+	}
+	var sumW, sumX, sumY float64
+	for row := 0; row < l.rows; row++ {
+		for col := 0; col < l.cols; col++ {
+			v := float64(l.cells[row*l.cols+col])
+			if v < paintMinHeat {
+				continue
+			}
+			wx, wy := CellToWorld(col, row)
+			if math.Hypot(wx-ax, wy-ay) > paintAreaRadius {
+				continue
+			}
+			sumW += v
+			sumX += wx * v
+			sumY += wy * v
+		}
+	}
+	return sumX / sumW, sumY / sumW, true
+}
+
+// Cells returns every painted cell of kind k, for use as flow-field goals.
+func (p *PaintLayer) Cells(k PaintKind) []Vec2i {
+	if !p.Has(k) {
+		return nil
+	}
+	l := p.layers[k]
+	var out []Vec2i
+	for row := 0; row < l.rows; row++ {
+		for col := 0; col < l.cols; col++ {
+			if l.cells[row*l.cols+col] >= paintMinHeat {
+				out = append(out, Vec2i{col, row})
+			}
+		}
+	}
+	return out
+}
+
+// --- IntelStore side ---
+
+// PaintFor returns the command paint for team, or nil for unknown teams.
+func (s *IntelStore) PaintFor(team Team) *PaintLayer {
+	if s == nil {
+		return nil
+	}
+	if m := s.maps[team]; m != nil {
+		return m.Paint
+	}
+	return nil
+}
+
+// QueuePaint routes a stroke to its team's paint layer.
+func (s *IntelStore) QueuePaint(st PaintStroke) {
+	s.PaintFor(st.Team).Queue(st)
+}
+
+// UpdatePaint applies every team's strokes that are due by tick.
+func (s *IntelStore) UpdatePaint(tick int) {
+	for _, team := range []Team{TeamRed, TeamBlue} {
+		s.PaintFor(team).Update(tick)
+	}
+}
+
+// --- CostField side ---
+
+// UpdatePaint rebuilds the paint component of the cost field: avoid heat
+// makes cells expensive and route heat makes them cheap.
+func (cf *CostField) UpdatePaint(p *PaintLayer) {
+	// A fresh slice each time: flow layers keep the one they were computed from.
+	cf.paintCost = make([]float64, cf.width*cf.height)
+	if p == nil {
+		return
+	}
+	avoid, route := p.layers[PaintAvoid], p.layers[PaintRoute]
+	for y := 0; y < cf.height && y < avoid.rows; y++ {
+		for x := 0; x < cf.width && x < avoid.cols; x++ {
+			i := y*avoid.cols + x
+			cf.paintCost[y*cf.width+x] = float64(avoid.cells[i])*paintAvoidCost - float64(route.cells[i])*paintRouteDiscount
+		}
+	}
+}
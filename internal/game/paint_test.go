@@ -0,0 +1,128 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPaintLayer_StrokeWaitsForCommandDelay(t *testing.T) {
+	intel := NewIntelStore(1600, 800)
+	intel.QueuePaint(PaintStroke{Team: TeamRed, Kind: PaintObjective, X: 1000, Y: 400, Radius: 64, Tick: 0, Due: 120})
+	p := intel.PaintFor(TeamRed)
+
+	intel.UpdatePaint(119)
+	if p.Has(PaintObjective) || len(p.Pending()) != 1 {
+		t.Fatal("stroke should wait until it is due")
+	}
+	intel.UpdatePaint(120)
+	if !p.Has(PaintObjective) || len(p.Pending()) != 0 {
+		t.Fatal("stroke should apply once due")
+	}
+	if intel.PaintFor(TeamBlue).Has(PaintObjective) {
+		t.Fatal("red paint must not reach blue")
+	}
+
+	if !p.In(PaintObjective, 1000, 400) || p.In(PaintObjective, 1200, 400) {
+		t.Fatal("painted area should cover the brush centre only")
+	}
+	x, y, ok := p.AreaNear(PaintObjective, 100, 100)
+	if !ok || math.Hypot(x-1000, y-400) > cellSize {
+		t.Fatalf("area centre (%.0f,%.0f), want near (1000,400)", x, y)
+	}
+	if len(p.Cells(PaintObjective)) == 0 {
+		t.Fatal("painted cells should be usable as flow goals")
+	}
+
+	rev := p.Revision
+	intel.QueuePaint(PaintStroke{Team: TeamRed, Kind: PaintObjective, X: 1000, Y: 400, Radius: 96, Erase: true, Due: 121})
+	intel.UpdatePaint(121)
+	if p.Has(PaintObjective) || p.Revision == rev {
+		t.Fatal("erase should clear the area and bump the revision")
+	}
+	if _, _, ok := p.AreaNear(PaintObjective, 0, 0); ok {
+		t.Fatal("nothing painted should give no area")
+	}
+}
+
+func TestCostField_PaintAvoidAndRoute(t *testing.T) {
+	p := newPaintLayer(10, 10)
+	p.Queue(PaintStroke{Kind: PaintAvoid, X: 2 * cellSize, Y: 2 * cellSize, Radius: cellSize})
+	p.Queue(PaintStroke{Kind: PaintRoute, X: 7 * cellSize, Y: 7 * cellSize, Radius: cellSize})
+	p.Queue(PaintStroke{Kind: PaintRoute, X: 7 * cellSize, Y: 7 * cellSize, Radius: cellSize})
+	p.Update(0)
+
+	cf := NewCostField(10, 10)
+	cf.InitializeFromNavGrid(NewNavGrid(10*cellSize, 10*cellSize, nil, 6, nil, nil))
+	base := cf.GetCost(5, 5)
+	cf.UpdatePaint(p)
+	if got := cf.GetCost(2, 2); got <= base+1 {
+		t.Fatalf("avoid cell cost %.2f, want well above %.2f", got, base)
+	}
+	if got := cf.GetCost(7, 7); got >= base || got < 0.05 {
+		t.Fatalf("route cell cost %.2f, want cheaper than %.2f but positive", got, base)
+	}
+	if got := cf.GetCost(5, 5); got != base {
+		t.Fatalf("unpainted cell cost %.2f, want %.2f", got, base)
+	}
+}
+
+func TestSquad_OfficerFollowsPaint(t *testing.T) {
+	tick := 0
+	var members []*Soldier
+	for i := 0; i < 4; i++ {
+		members = append(members, newTestSoldier(i+1, 200, 200+float64(i)*20, TeamRed, &tick))
+	}
+	sq := NewSquad(1, TeamRed, members)
+	sq.Intent = IntentAdvance
+	sq.paint = newPaintLayer(60, 120)
+	for i := 0; i < 2; i++ {
+		sq.paint.Queue(PaintStroke{Kind: PaintObjective, X: 1400, Y: 600, Radius: 80})
+	}
+	sq.paint.Update(0)
+
+	sq.syncOfficerOrder(1, false, 0, 0, false, false)
+	o := sq.ActiveOrder
+	if o.Kind != CmdMoveTo || math.Hypot(o.TargetX-1400, o.TargetY-600) > cellSize {
+		t.Fatalf("order %s to (%.0f,%.0f), want a move to the painted objective", o.Kind, o.TargetX, o.TargetY)
+	}
+
+	for i := 0; i < 2; i++ {
+		sq.paint.Queue(PaintStroke{Kind: PaintHold, X: sq.Leader.x, Y: sq.Leader.y, Radius: 80})
+	}
+	sq.paint.Update(0)
+	sq.syncOfficerOrder(200, false, 0, 0, false, false)
+	if sq.ActiveOrder.Kind != CmdHold {
+		t.Fatalf("leader inside a hold zone got %s, want hold", sq.ActiveOrder.Kind)
+	}
+}
+
+func TestSaveState_GameRestoresPaint(t *testing.T) {
+	g := newSimGame(5150)
+	g.SetCommandDelay(20)
+	for i := 0; i < 40; i++ {
+		if i == 10 || i == 25 {
+			for j := 0; j < 3; j++ {
+				g.paint(PaintStroke{Team: TeamRed, Kind: PaintObjective, X: 1500, Y: 800 + float64(i), Radius: 96})
+			}
+		}
+		g.simTick()
+	}
+	if !g.intel.PaintFor(TeamRed).Has(PaintObjective) || len(g.intel.PaintFor(TeamRed).Pending()) != 3 {
+		t.Fatal("first strokes should be active and the last still pending")
+	}
+	st, err := g.SaveState()
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	restored, err := restoreSimGame(st)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		g.simTick()
+		restored.simTick()
+	}
+	if g.stateDigest() != restored.stateDigest() {
+		t.Fatal("restored game diverged from the painted original")
+	}
+}
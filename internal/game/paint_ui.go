@@ -0,0 +1,92 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// paintColors gives each command layer its own wash.
+var paintColors = [paintKindCount]color.RGBA{
+	PaintObjective: {R: 60, G: 230, B: 90, A: 110},
+	PaintAvoid:     {R: 240, G: 50, B: 40, A: 110},
+	PaintRoute:     {R: 60, G: 140, B: 255, A: 110},
+	PaintHold:      {R: 255, G: 200, B: 60, A: 110},
+}
+
+// SetCommandDelay sets how many ticks a painted order takes to reach the troops.
+func (g *Game) SetCommandDelay(ticks int) {
+	if ticks < 0 {
+		ticks = 0
+	}
+	g.commandDelay = ticks
+}
+
+// cursorWorld converts the mouse position to world coordinates.
+func (g *Game) cursorWorld() (float64, float64) {
+	mx, my := ebiten.CursorPosition()
+	wx := (float64(mx)-float64(g.offX)-float64(g.gameWidth)/2)/g.camZoom + g.camX
+	wy := (float64(my)-float64(g.offY)-float64(g.gameHeight)/2)/g.camZoom + g.camY
+	return wx, wy
+}
+
+// handlePaintInput paints with the left button and erases with the right.
+// Dabs are spaced along the drag so a stroke covers ground evenly.
+func (g *Game) handlePaintInput() {
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	right := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		g.dabbing = false
+		return
+	}
+	wx, wy := g.cursorWorld()
+	if wx < 0 || wy < 0 || wx >= float64(g.gameWidth) || wy >= float64(g.gameHeight) {
+		return
+	}
+	if g.dabbing && math.Hypot(wx-g.lastDabX, wy-g.lastDabY) < g.paintRadius*0.5 {
+		return
+	}
+	g.dabbing = true
+	g.lastDabX, g.lastDabY = wx, wy
+	g.paint(PaintStroke{
+		Team:   Team(g.overlayTeam),
+		Kind:   g.paintKind,
+		X:      wx,
+		Y:      wy,
+		Radius: g.paintRadius,
+		Erase:  right && !left,
+	})
+}
+
+// paint stamps the current tick and command delay on a stroke and queues it.
+func (g *Game) paint(st PaintStroke) {
+	st.Tick = g.tick
+	st.Due = g.tick + g.commandDelay
+	g.intel.QueuePaint(st)
+}
+
+// drawPaint shows the overlay team's active paint, strokes still waiting
+// out the command delay, and the brush.
+func (g *Game) drawPaint(screen *ebiten.Image) {
+	p := g.intel.PaintFor(Team(g.overlayTeam))
+	if p == nil {
+		return
+	}
+	for k := PaintKind(0); k < paintKindCount; k++ {
+		g.drawHeatLayer(screen, p.Layer(k), paintColors[k])
+	}
+	for _, st := range p.Pending() {
+		c := paintColors[st.Kind]
+		c.A = 200
+		if st.Erase {
+			c = color.RGBA{R: 200, G: 200, B: 200, A: 160}
+		}
+		vector.StrokeCircle(screen, float32(st.X), float32(st.Y), float32(st.Radius), 1, c, false)
+	}
+	wx, wy := g.cursorWorld()
+	brush := paintColors[g.paintKind]
+	brush.A = 255
+	vector.StrokeCircle(screen, float32(wx), float32(wy), float32(g.paintRadius), 1.5, brush, false)
+}
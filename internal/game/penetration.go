@@ -0,0 +1,142 @@
+package game
+
+import "math"
+
+// ---------------------------------------------------------------------------
+// Penetration — rounds through soft cover, and cover shot to pieces
+// ---------------------------------------------------------------------------
+//
+// Each landed round is traced through the TileMap from muzzle to where it
+// ends up. Objects it strikes lose durability and may break (a window to an
+// empty frame, a door to splinters, a sandbag wall to rubble); soft objects
+// let the round through with less energy, hard ones stop it. When a break
+// opens a wall or window cell, the NavGrid and TacticalMap are updated so
+// soldiers can use the new gap.
+
+const (
+	bulletTileDamage   = 6    // durability a full-energy round takes off what it strikes
+	bulletMinEnergy    = 0.15 // a round left with less energy than this is spent
+	lowCoverReachCells = 2    // low cover this close to the target is what a cover strike hits
+	bulletTraceStep    = 4.0  // px between trace samples; well under a cell so corners are not skipped
+)
+
+// objectPenetration returns the share of a round's energy left after it
+// passes through o: 0 stops it, 1 means o does not get in the way.
+func objectPenetration(o ObjectType) float64 {
+	switch o {
+	case ObjectWall, ObjectWallDamaged, ObjectPillar, ObjectTallWall, ObjectChestWall,
+		ObjectSandbag, ObjectRubblePile, ObjectATBarrier, ObjectSlitTrench,
+		ObjectVehicleWreck, ObjectTreeTrunk:
+		return 0
+	case ObjectCrate:
+		return 0.3
+	case ObjectHedgerow:
+		return 0.5
+	case ObjectDoor, ObjectTable:
+		return 0.6
+	case ObjectWindow:
+		return 0.8
+	case ObjectChair, ObjectBush:
+		return 0.85
+	case ObjectFence:
+		return 0.9
+	default:
+		return 1
+	}
+}
+
+// objectIsLow reports whether o is below chest height, so a round aimed at
+// a standing man passes over it unless he is sheltering right behind it.
+func objectIsLow(o ObjectType) bool {
+	switch o {
+	case ObjectTable, ObjectChair, ObjectCrate, ObjectSandbag, ObjectChestWall,
+		ObjectRubblePile, ObjectATBarrier, ObjectSlitTrench, ObjectBush:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetTerrain gives cm the grids that fire can change. Any may be nil.
+func (cm *CombatManager) SetTerrain(tm *TileMap, ng *NavGrid, tac *TacticalMap) {
+	cm.tileMap, cm.navGrid, cm.tactical = tm, ng, tac
+}
+
+// traceRound walks a round from (fromX, fromY) to (toX, toY), damaging
+// every object it strikes. Low objects are flown over unless strikeLow is
+// set and they lie within lowCoverReachCells of the end, i.e. the round was
+// aimed at a target crouched behind them. It returns the energy left (0 =
+// stopped) and how many objects were struck.
+func (cm *CombatManager) traceRound(fromX, fromY, toX, toY float64, strikeLow bool) (energy float64, struck int) {
+	tm := cm.tileMap
+	energy = 1
+	dist := math.Hypot(toX-fromX, toY-fromY)
+	if tm == nil || dist < 1 {
+		return energy, 0
+	}
+	sc, sr := WorldToCell(fromX, fromY)
+	ec, er := WorldToCell(toX, toY)
+	lc, lr := sc, sr
+	steps := int(dist / bulletTraceStep)
+	for i := 1; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		c, r := WorldToCell(fromX+(toX-fromX)*f, fromY+(toY-fromY)*f)
+		if c == lc && r == lr {
+			continue
+		}
+		lc, lr = c, r
+		o := tm.ObjectAt(c, r)
+		pen := objectPenetration(o)
+		if pen >= 1 {
+			continue
+		}
+		if objectIsLow(o) && (!strikeLow || max(absInt(ec-c), absInt(er-r)) > lowCoverReachCells) {
+			continue
+		}
+		struck++
+		cm.damageTerrain(tm, c, r, max(1, int(math.Round(bulletTileDamage*energy))))
+		energy *= pen
+		if energy < bulletMinEnergy {
+			return 0, struck
+		}
+	}
+	return energy, struck
+}
+
+// penetrate traces sh through the tile map and reports whether it still
+// wounds its target and with how much damage. A cover strike only becomes a
+// wound if it went through something soft on the way.
+func (cm *CombatManager) penetrate(sh firedShot) (bool, float64) {
+	energy, struck := cm.traceRound(sh.fromX, sh.fromY, sh.toX, sh.toY, sh.coverStrike)
+	switch {
+	case energy <= 0:
+		return false, 0
+	case sh.hit, sh.coverStrike && struck > 0:
+		return true, sh.damage * energy
+	default:
+		return false, 0
+	}
+}
+
+// damageTerrain wears down the tile at (col, row) and, if that breaks its
+// object, brings the navigation and tactical grids up to date.
+func (cm *CombatManager) damageTerrain(tm *TileMap, col, row, dmg int) {
+	before := tm.ObjectAt(col, row)
+	tm.DamageTile(col, row, dmg)
+	after := tm.ObjectAt(col, row)
+	if after == before {
+		return
+	}
+	if cm.navGrid != nil && objectBlocksMovement(before) != objectBlocksMovement(after) {
+		cm.navGrid.setBlocked(col, row, objectBlocksMovement(after))
+	}
+	if before == ObjectDoor {
+		cm.navGrid.syncDoorCell(tm, col, row)
+	}
+	if cm.tactical != nil {
+		switch before {
+		case ObjectWall, ObjectWallDamaged, ObjectWindow:
+			cm.tactical.openCell(col, row)
+		}
+	}
+}
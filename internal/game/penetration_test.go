@@ -0,0 +1,110 @@
+package game
+
+import "testing"
+
+// newPenetrationRange is a 40x20-cell tile map with a shooter at cell
+// (2, 10) and a target at cell (30, 10), both facing along row 10.
+func newPenetrationRange(tick *int) (*CombatManager, *TileMap, *Soldier, *Soldier) {
+	tm := NewTileMap(40, 20)
+	cm := NewCombatManager(5)
+	cm.SetTerrain(tm, nil, nil)
+	sx, sy := CellToWorld(2, 10)
+	tx, ty := CellToWorld(30, 10)
+	shooter := newTestSoldier(0, sx, sy, TeamRed, tick)
+	target := newTestSoldier(1, tx, ty, TeamBlue, tick)
+	return cm, tm, shooter, target
+}
+
+func shotAt(shooter, target *Soldier, hit, coverStrike bool) firedShot {
+	return firedShot{
+		shooter: shooter, target: target, hit: hit, coverStrike: coverStrike,
+		fromX: shooter.x, fromY: shooter.y, toX: target.x, toY: target.y,
+		damage: baseDamage,
+	}
+}
+
+func TestPenetration_SoftCoverPassesReducedRound(t *testing.T) {
+	tick := 0
+	cm, tm, shooter, target := newPenetrationRange(&tick)
+	tm.SetObject(29, 10, ObjectTable)
+
+	hit, dmg := cm.penetrate(shotAt(shooter, target, false, true))
+	if !hit {
+		t.Fatal("a round into a table should carry through to the man behind it")
+	}
+	if dmg >= baseDamage || dmg <= 0 {
+		t.Errorf("damage through the table %.1f, want less than the full %.1f", dmg, float64(baseDamage))
+	}
+	if tm.At(29, 10).Durability >= objectDefaultDurability(ObjectTable) {
+		t.Error("the table took no damage")
+	}
+}
+
+func TestPenetration_HardCoverStopsRoundAndLowCoverIsFlownOver(t *testing.T) {
+	tick := 0
+	cm, tm, shooter, target := newPenetrationRange(&tick)
+	tm.SetObject(29, 10, ObjectChestWall)
+	if hit, _ := cm.penetrate(shotAt(shooter, target, false, true)); hit {
+		t.Fatal("a round into a chest wall went through it")
+	}
+	if tm.ObjectAt(29, 10) != ObjectChestWall {
+		t.Fatal("small-arms fire broke a masonry wall")
+	}
+
+	// The same wall far from the target does not stop a round aimed at his
+	// exposed body.
+	tm.SetObject(29, 10, ObjectNone)
+	tm.SetObject(10, 10, ObjectChestWall)
+	if hit, dmg := cm.penetrate(shotAt(shooter, target, true, false)); !hit || dmg != baseDamage {
+		t.Errorf("direct hit over a distant low wall: hit=%v dmg=%.1f, want a full-damage hit", hit, dmg)
+	}
+}
+
+func TestPenetration_FireBreaksWindowAndOpensGap(t *testing.T) {
+	tick := 0
+	cm, tm, shooter, target := newPenetrationRange(&tick)
+	wx, wy := 15*cellSize, 10*cellSize
+	windows := []rect{{x: wx, y: wy, w: cellSize, h: cellSize}}
+	walls := []rect{{x: wx, y: wy - cellSize, w: cellSize, h: cellSize}, {x: wx, y: wy + cellSize, w: cellSize, h: cellSize}}
+	ng := NewNavGrid(40*cellSize, 20*cellSize, nil, 0, nil, windows)
+	tac := NewTacticalMap(40*cellSize, 20*cellSize, walls, windows, nil)
+	cm.SetTerrain(tm, ng, tac)
+	tm.SetObject(15, 10, ObjectWindow)
+	if !ng.IsBlocked(15, 10) {
+		t.Fatal("intact window should block movement")
+	}
+
+	shots := 0
+	for tm.ObjectAt(15, 10) == ObjectWindow {
+		cm.landBullet(shotAt(shooter, target, false, false), nil, nil)
+		if shots++; shots > 50 {
+			t.Fatal("window survived 50 rounds")
+		}
+	}
+	if tm.ObjectAt(15, 10) != ObjectWindowBroken {
+		t.Fatalf("window became %d, want a broken window", tm.ObjectAt(15, 10))
+	}
+	if ng.IsBlocked(15, 10) {
+		t.Error("broken window still blocks the nav grid")
+	}
+	if tr := tac.traits[10*tac.cols+15]; tr&CellTraitWindow != 0 || tr&CellTraitDoorway == 0 {
+		t.Errorf("broken window traits %b, want a doorway and no window", tr)
+	}
+}
+
+func TestPenetration_SandbagsShotToRubble(t *testing.T) {
+	tick := 0
+	cm, tm, shooter, target := newPenetrationRange(&tick)
+	tm.SetObject(29, 10, ObjectSandbag)
+	for i := 0; tm.ObjectAt(29, 10) == ObjectSandbag; i++ {
+		if hit, _ := cm.penetrate(shotAt(shooter, target, false, true)); hit {
+			t.Fatal("a round went through sandbags")
+		}
+		if i > 100 {
+			t.Fatal("sandbags survived 100 rounds")
+		}
+	}
+	if tm.ObjectAt(29, 10) != ObjectRubblePile {
+		t.Errorf("sandbags became %d, want a rubble pile", tm.ObjectAt(29, 10))
+	}
+}
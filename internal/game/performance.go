@@ -258,7 +258,7 @@ func (pt *PerfTracker) Update(s *Soldier) {
 
 // Finalize snapshots end-of-run state.
 func (pt *PerfTracker) Finalize(s *Soldier) {
-	pt.Survived = s.state != SoldierStateDead
+	pt.Survived = !s.killed()
 	pt.HealthAtEnd = s.health()
 }
 
@@ -0,0 +1,864 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	platoonNetIDBase       = 1000 // platoon nets sit above the squad nets (squad ID + 1)
+	platoonRadioRangeScale = 2.0  // platoon sets carry further than squad radios
+
+	platoonSitrepInterval     = 240
+	platoonSitrepMinGap       = 60  // earliest repeat when a squad's contact picture changes
+	platoonSightingFreshTicks = 300 // a sighting older than this is not reported as contact
+	platoonContactMemoryTicks = 600 // the platoon forgets a contact nobody has reported for this long
+
+	// A task lapses unless refreshed, so a platoon that loses its net hands
+	// its squads back to their own leaders.
+	platoonOrderTTL           = 600
+	platoonOrderRefreshTicks  = 300
+	platoonOrderMoveThreshold = 60.0 // px a task point must move before it is re-sent early
+
+	platoonDevelopTicks         = 180
+	platoonManeuverTimeoutTicks = 1200
+	platoonAssaultTicks         = 1200
+	platoonConsolidateTicks     = 300
+	platoonSuccessionDelayTicks = 240
+
+	platoonFrontage           = 220.0 // px between squads advancing abreast
+	platoonFlankOffset        = 280.0 // px off the support squad's line of fire
+	platoonFlankStandoff      = 120.0 // px short of the contact
+	platoonFlankArrivalRadius = 140.0
+	platoonReserveStandoff    = 150.0 // px behind the support squad
+)
+
+// PlatoonPhase is the platoon leader's plan for the fight, one level above
+// SquadPhase.
+type PlatoonPhase int
+
+const (
+	PlatoonPhaseAdvance     PlatoonPhase = iota // no contact: squads move abreast on the objective
+	PlatoonPhaseDevelop                         // contact: a support squad fixes it while roles are set
+	PlatoonPhaseManeuver                        // the manoeuvre squad moves to a flank under covering fire
+	PlatoonPhaseAssault                         // the manoeuvre squad assaults; support keeps firing
+	PlatoonPhaseConsolidate                     // contact lost: squads hold and reorganise
+)
+
+func (pp PlatoonPhase) String() string {
+	switch pp {
+	case PlatoonPhaseAdvance:
+		return "advance"
+	case PlatoonPhaseDevelop:
+		return "develop"
+	case PlatoonPhaseManeuver:
+		return "maneuver"
+	case PlatoonPhaseAssault:
+		return "assault"
+	case PlatoonPhaseConsolidate:
+		return "consolidate"
+	default:
+		return "unknown"
+	}
+}
+
+// PlatoonRole is a squad's job within its platoon.
+type PlatoonRole int
+
+const (
+	PlatoonRoleNone PlatoonRole = iota
+	PlatoonRoleAdvance
+	PlatoonRoleSupport // support-by-fire: hold and suppress the contact
+	PlatoonRoleManeuver
+	PlatoonRoleReserve
+)
+
+func (pr PlatoonRole) String() string {
+	switch pr {
+	case PlatoonRoleAdvance:
+		return "advance"
+	case PlatoonRoleSupport:
+		return "support"
+	case PlatoonRoleManeuver:
+		return "maneuver"
+	case PlatoonRoleReserve:
+		return "reserve"
+	default:
+		return "none"
+	}
+}
+
+// PlatoonTask is one squad's tasking from the platoon leader. The squad folds
+// it into its ActiveOrder while it is current.
+type PlatoonTask struct {
+	Role        PlatoonRole
+	Kind        OfficerCommandKind
+	X, Y        float64
+	IssuedTick  int
+	ExpiresTick int
+}
+
+func (t PlatoonTask) activeAt(tick int) bool {
+	return t.Kind != CmdNone && tick < t.ExpiresTick
+}
+
+// platoonSitrep is a squad's situation as the platoon leader last heard it.
+type platoonSitrep struct {
+	Tick         int // when it was heard; 0 before the first report
+	PosX, PosY   float64
+	Alive        int
+	Broken       bool
+	Contact      bool
+	ContactX     float64
+	ContactY     float64
+	ContactCount int
+}
+
+// platoonSquadState is what the platoon tracks for one of its squads.
+type platoonSquadState struct {
+	report        platoonSitrep
+	sent          PlatoonTask // last task sent
+	sitrepTick    int         // when the squad last sent a SITREP
+	sitrepContact bool        // whether that SITREP reported contact
+	lane          int         // left-to-right slot when advancing abreast
+}
+
+// Platoon coordinates several squads of one team. Its leader is a soldier who
+// also leads the first squad; when they go down, command passes to the next
+// squad leader after a delay. The platoon net is the team's command net: the
+// platoon leader hears other squads only through SITREPs on it and tasks them
+// over it. Squad leaders bridge the two nets, so a SITREP carries only what
+// its sender has seen or heard on their own squad net.
+type Platoon struct {
+	ID     int
+	Team   Team
+	Leader *Soldier
+	Squads []*Squad
+	Phase  PlatoonPhase
+	Roles  []PlatoonRole // parallel to Squads
+
+	state            []platoonSquadState
+	phaseEnteredTick int
+
+	// The contact the platoon is fighting and the manoeuvre plan against it.
+	contactX, contactY float64
+	lastContactTick    int
+	supportX, supportY float64
+	flankX, flankY     float64
+
+	// Axis of advance, fixed from the squads' start positions to the objective.
+	axisX, axisY float64
+	axisSet      bool
+
+	successor            *Soldier // next platoon leader, while command passes
+	leaderSuccessionTick int
+
+	// Platoon net: squad leaders and the platoon leader, one talker at a time.
+	radioNet              radioNet
+	radio                 *radioMedium
+	radioInFlight         *radioTransmission
+	radioChannelBusyUntil int
+
+	// Radio telemetry counters.
+	RadioSent     int
+	RadioReceived int
+	RadioDropped  int
+	RadioGarbled  int
+}
+
+// NewPlatoon groups squads under the first squad's leader. The squads keep
+// their own IDs and nets; the platoon net is platoonNetIDBase + id.
+func NewPlatoon(id int, team Team, squads []*Squad) *Platoon {
+	p := &Platoon{
+		ID:     id,
+		Team:   team,
+		Squads: squads,
+		Roles:  make([]PlatoonRole, len(squads)),
+		state:  make([]platoonSquadState, len(squads)),
+	}
+	p.radioNet.netID = platoonNetIDBase + id
+	p.radioNet.kind = RadioNetCommand
+	p.radioNet.name = fmt.Sprintf("CMD-%d", id)
+	if len(squads) > 0 {
+		p.Leader = squads[0].Leader
+	}
+	// The leader knows their order of battle before the first SITREP.
+	for i, sq := range squads {
+		p.Roles[i] = PlatoonRoleAdvance
+		p.state[i].report.Alive = len(sq.Members)
+		if sq.Leader != nil {
+			p.state[i].report.PosX, p.state[i].report.PosY = sq.Leader.x, sq.Leader.y
+		}
+	}
+	return p
+}
+
+// canCommand reports whether s is conscious and able to lead.
+func canCommand(s *Soldier) bool {
+	return s != nil && !s.offField() && s.state != SoldierStateUnconscious
+}
+
+// Think runs the platoon leader's decision loop: SITREPs up, then the phase
+// machine, then tasks down to the squads. Call it after SquadThink.
+func (p *Platoon) Think(tick int) {
+	if !p.updateCommand(tick) {
+		return
+	}
+	p.initAxis()
+	p.planSitreps(tick)
+	p.updateContact()
+	p.updatePhase(tick)
+	p.issueTasks(tick)
+}
+
+// updateCommand handles platoon succession and reports whether the platoon
+// has a leader this tick. Squads keep their last tasks during the gap.
+func (p *Platoon) updateCommand(tick int) bool {
+	if canCommand(p.Leader) {
+		return true
+	}
+	if !canCommand(p.successor) {
+		// The next squad leader in line starts the clock; if they fall
+		// too, the one after starts it again.
+		p.successor = nil
+		for _, sq := range p.Squads {
+			if canCommand(sq.Leader) {
+				p.successor = sq.Leader
+				break
+			}
+		}
+		if p.successor == nil {
+			return false
+		}
+		p.leaderSuccessionTick = tick + platoonSuccessionDelayTicks
+		p.successor.think("platoon leader down — taking the platoon")
+	}
+	if tick < p.leaderSuccessionTick {
+		return false
+	}
+	p.Leader, p.successor = p.successor, nil
+	// Traffic addressed to the old leader dies with them.
+	p.radioNet.pending = nil
+	p.Leader.think("platoon command established")
+	return true
+}
+
+// initAxis fixes the axis of advance and each squad's lane the first time
+// the platoon thinks, so squads keep their left-to-right order.
+func (p *Platoon) initAxis() {
+	if p.axisSet {
+		return
+	}
+	p.axisSet = true
+	cx, cy := p.reportCentroid()
+	ax, ay := p.Leader.endTarget[0]-cx, p.Leader.endTarget[1]-cy
+	if d := math.Hypot(ax, ay); d > 1 {
+		p.axisX, p.axisY = ax/d, ay/d
+	} else {
+		p.axisX, p.axisY = 1, 0
+	}
+	order := make([]int, len(p.Squads))
+	for i := range order {
+		order[i] = i
+	}
+	nx, ny := -p.axisY, p.axisX
+	lateral := func(i int) float64 {
+		r := p.state[i].report
+		return (r.PosX-cx)*nx + (r.PosY-cy)*ny
+	}
+	sort.SliceStable(order, func(a, b int) bool { return lateral(order[a]) < lateral(order[b]) })
+	for lane, i := range order {
+		p.state[i].lane = lane
+	}
+}
+
+func (p *Platoon) reportCentroid() (float64, float64) {
+	var sx, sy float64
+	for _, st := range p.state {
+		sx += st.report.PosX
+		sy += st.report.PosY
+	}
+	n := float64(max(1, len(p.state)))
+	return sx / n, sy / n
+}
+
+// hqIndex is the index of the platoon leader's own squad, or -1.
+func (p *Platoon) hqIndex() int {
+	for i, sq := range p.Squads {
+		if sq.Leader == p.Leader {
+			return i
+		}
+	}
+	return -1
+}
+
+// squadSitrep is sq's situation as its leader would report it. Contact is
+// what the leader sees, or failing that the freshest contact report that
+// reached them on the squad net: a member's sighting lost on the squad net
+// never reaches the platoon.
+func squadSitrep(sq *Squad, tick int) platoonSitrep {
+	r := platoonSitrep{Tick: tick, Alive: len(sq.Alive()), Broken: sq.Broken}
+	r.PosX, r.PosY = sq.LeaderPosition()
+	l := sq.Leader
+	bb := &l.blackboard
+	closest := math.MaxFloat64
+	for _, t := range bb.Threats {
+		if !t.IsVisible {
+			continue
+		}
+		r.ContactCount++
+		if d := math.Hypot(t.X-l.x, t.Y-l.y); d < closest {
+			closest = d
+			r.ContactX, r.ContactY = t.X, t.Y
+		}
+	}
+	if r.ContactCount == 0 && bb.RadioHasContact && tick-bb.RadioContactTick < platoonSightingFreshTicks {
+		r.ContactX, r.ContactY = bb.RadioContactX, bb.RadioContactY
+		r.ContactCount = 1
+	}
+	r.Contact = r.ContactCount > 0
+	return r
+}
+
+// planSitreps queues a SITREP from each squad leader that is due one. The
+// platoon leader's own squad needs no radio.
+func (p *Platoon) planSitreps(tick int) {
+	hq := p.hqIndex()
+	for i, sq := range p.Squads {
+		if !canCommand(sq.Leader) {
+			continue
+		}
+		r := squadSitrep(sq, tick)
+		st := &p.state[i]
+		if i == hq {
+			st.report = r
+			continue
+		}
+		due := tick-st.sitrepTick >= platoonSitrepInterval
+		changed := r.Contact != st.sitrepContact && tick-st.sitrepTick >= platoonSitrepMinGap
+		if !due && !changed {
+			continue
+		}
+		st.sitrepTick = tick
+		st.sitrepContact = r.Contact
+		p.radioNet.enqueue(p.sitrepMessage(sq.Leader, r, tick))
+	}
+}
+
+func (p *Platoon) sitrepMessage(sender *Soldier, r platoonSitrep, tick int) RadioMessage {
+	msg := RadioMessage{
+		TickCreated:   tick,
+		SenderID:      sender.id,
+		SenderLabel:   sender.label,
+		ReceiverID:    p.Leader.id,
+		ReceiverLabel: p.Leader.label,
+		Type:          RadioMsgSitrep,
+		Priority:      RadioPriRoutine,
+		PosX:          r.PosX,
+		PosY:          r.PosY,
+		Alive:         r.Alive,
+		Broken:        r.Broken,
+		Summary:       fmt.Sprintf("SITREP %d UP, NO CONTACT", r.Alive),
+	}
+	if r.Contact {
+		msg.Priority = RadioPriUrgent
+		msg.ContactX, msg.ContactY = r.ContactX, r.ContactY
+		msg.ContactCount = r.ContactCount
+		msg.Summary = fmt.Sprintf("SITREP %d UP, CONTACT %d", r.Alive, r.ContactCount)
+	}
+	if r.Broken {
+		msg.Summary += ", BROKEN"
+	}
+	return msg
+}
+
+// updateContact takes the freshest reported contact as the platoon's.
+func (p *Platoon) updateContact() {
+	for _, st := range p.state {
+		r := st.report
+		if r.Contact && r.Tick > p.lastContactTick {
+			p.lastContactTick = r.Tick
+			p.contactX, p.contactY = r.ContactX, r.ContactY
+		}
+	}
+}
+
+func (p *Platoon) hasContact(tick int) bool {
+	return p.lastContactTick > 0 && tick-p.lastContactTick < platoonContactMemoryTicks
+}
+
+// effective reports whether squad i can still take a role, going by its
+// last SITREP: not broken and at least half strength.
+func (p *Platoon) effective(i int) bool {
+	r := p.state[i].report
+	return canCommand(p.Squads[i].Leader) && !r.Broken && r.Alive*2 >= len(p.Squads[i].Members)
+}
+
+func (p *Platoon) roleIndex(role PlatoonRole) int {
+	for i, r := range p.Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Platoon) setPhase(tick int, next PlatoonPhase) {
+	if next == p.Phase {
+		return
+	}
+	old := p.Phase
+	p.Phase = next
+	p.phaseEnteredTick = tick
+	p.Leader.think(fmt.Sprintf("platoon: %s -> %s", old, next))
+}
+
+func (p *Platoon) updatePhase(tick int) {
+	contact := p.hasContact(tick)
+	elapsed := tick - p.phaseEnteredTick
+
+	// Casualties: a support or manoeuvre squad that can no longer do its job
+	// is replaced, and the attack is re-planned from the top.
+	if p.Phase == PlatoonPhaseDevelop || p.Phase == PlatoonPhaseManeuver || p.Phase == PlatoonPhaseAssault {
+		support, maneuver := p.roleIndex(PlatoonRoleSupport), p.roleIndex(PlatoonRoleManeuver)
+		if (support >= 0 && !p.effective(support)) || (maneuver >= 0 && !p.effective(maneuver)) {
+			p.Leader.think("platoon: re-tasking after casualties")
+			p.assignRoles()
+			if p.Phase != PlatoonPhaseDevelop {
+				p.setPhase(tick, PlatoonPhaseDevelop)
+			}
+			return
+		}
+	}
+
+	switch p.Phase {
+	case PlatoonPhaseAdvance:
+		p.assignAdvanceRoles()
+		if contact {
+			p.assignRoles()
+			p.setPhase(tick, PlatoonPhaseDevelop)
+		}
+	case PlatoonPhaseDevelop:
+		switch {
+		case !contact:
+			p.setPhase(tick, PlatoonPhaseConsolidate)
+		case p.roleIndex(PlatoonRoleManeuver) < 0:
+			// Nobody free to manoeuvre: keep looking as squads recover.
+			p.assignRoles()
+		case elapsed >= platoonDevelopTicks:
+			p.setPhase(tick, PlatoonPhaseManeuver)
+		}
+	case PlatoonPhaseManeuver:
+		m := p.roleIndex(PlatoonRoleManeuver)
+		if !contact {
+			p.setPhase(tick, PlatoonPhaseConsolidate)
+			break
+		}
+		if m < 0 {
+			p.setPhase(tick, PlatoonPhaseDevelop)
+			break
+		}
+		r := p.state[m].report
+		arrived := math.Hypot(r.PosX-p.flankX, r.PosY-p.flankY) < platoonFlankArrivalRadius
+		if arrived || elapsed >= platoonManeuverTimeoutTicks {
+			p.setPhase(tick, PlatoonPhaseAssault)
+		}
+	case PlatoonPhaseAssault:
+		if !contact {
+			p.setPhase(tick, PlatoonPhaseConsolidate)
+		} else if elapsed >= platoonAssaultTicks {
+			p.assignRoles()
+			p.setPhase(tick, PlatoonPhaseDevelop)
+		}
+	case PlatoonPhaseConsolidate:
+		if contact {
+			p.assignRoles()
+			p.setPhase(tick, PlatoonPhaseDevelop)
+		} else if elapsed >= platoonConsolidateTicks {
+			p.setPhase(tick, PlatoonPhaseAdvance)
+		}
+	}
+}
+
+// assignAdvanceRoles puts every effective squad on the advance. With three
+// or more, the last one follows as the reserve.
+func (p *Platoon) assignAdvanceRoles() {
+	n := 0
+	for i := range p.Squads {
+		if p.effective(i) {
+			n++
+		}
+	}
+	seen := 0
+	for i := range p.Squads {
+		p.Roles[i] = PlatoonRoleReserve
+		if p.effective(i) {
+			seen++
+			if n < 3 || seen < n {
+				p.Roles[i] = PlatoonRoleAdvance
+			}
+		}
+	}
+}
+
+// assignRoles sets up a fire-and-manoeuvre attack on the current contact:
+// the squad in contact supports by fire, the strongest other squad
+// manoeuvres to a flank, and everyone else is reserve.
+func (p *Platoon) assignRoles() {
+	support, bestTick := -1, 0
+	for i, st := range p.state {
+		if p.effective(i) && st.report.Contact && st.report.Tick > bestTick {
+			support, bestTick = i, st.report.Tick
+		}
+	}
+	if support < 0 {
+		best := math.MaxFloat64
+		for i, st := range p.state {
+			if !p.effective(i) {
+				continue
+			}
+			if d := math.Hypot(st.report.PosX-p.contactX, st.report.PosY-p.contactY); d < best {
+				support, best = i, d
+			}
+		}
+	}
+	maneuver := -1
+	for i, st := range p.state {
+		if i == support || !p.effective(i) {
+			continue
+		}
+		if maneuver < 0 || st.report.Alive > p.state[maneuver].report.Alive {
+			maneuver = i
+		}
+	}
+	for i := range p.Roles {
+		switch i {
+		case support:
+			p.Roles[i] = PlatoonRoleSupport
+		case maneuver:
+			p.Roles[i] = PlatoonRoleManeuver
+		default:
+			p.Roles[i] = PlatoonRoleReserve
+		}
+	}
+	if support >= 0 {
+		p.supportX, p.supportY = p.state[support].report.PosX, p.state[support].report.PosY
+	}
+	if support >= 0 && maneuver >= 0 {
+		p.flankX, p.flankY = p.flankPoint(maneuver)
+	}
+}
+
+// flankPoint is a position beside the contact, off the support squad's line
+// of fire, on whichever side the manoeuvre squad already is.
+func (p *Platoon) flankPoint(maneuver int) (float64, float64) {
+	vx, vy := p.fireLine()
+	nx, ny := -vy, vx
+	m := p.state[maneuver].report
+	side := 1.0
+	if (m.PosX-p.supportX)*nx+(m.PosY-p.supportY)*ny < 0 {
+		side = -1
+	}
+	return p.clampToMap(
+		p.contactX+nx*side*platoonFlankOffset-vx*platoonFlankStandoff,
+		p.contactY+ny*side*platoonFlankOffset-vy*platoonFlankStandoff)
+}
+
+// fireLine is the unit vector from the support position to the contact.
+func (p *Platoon) fireLine() (float64, float64) {
+	vx, vy := p.contactX-p.supportX, p.contactY-p.supportY
+	d := math.Hypot(vx, vy)
+	if d < 1 {
+		return p.axisX, p.axisY
+	}
+	return vx / d, vy / d
+}
+
+// laneObjective spreads the advancing squads abreast across the objective,
+// one frontage apart, keeping their left-to-right order.
+func (p *Platoon) laneObjective(i int) (float64, float64) {
+	n, k := 0, 0
+	for j, role := range p.Roles {
+		if role != PlatoonRoleAdvance {
+			continue
+		}
+		n++
+		if p.state[j].lane < p.state[i].lane {
+			k++
+		}
+	}
+	off := (float64(k) - float64(n-1)/2) * platoonFrontage
+	ox, oy := p.Leader.endTarget[0], p.Leader.endTarget[1]
+	return p.clampToMap(ox-p.axisY*off, oy+p.axisX*off)
+}
+
+func (p *Platoon) clampToMap(x, y float64) (float64, float64) {
+	ng := p.Leader.navGrid
+	if ng == nil {
+		return x, y
+	}
+	const margin = 2 * cellSize
+	w, h := float64(ng.cols*cellSize-margin), float64(ng.rows*cellSize-margin)
+	return math.Max(margin, math.Min(w, x)), math.Max(margin, math.Min(h, y))
+}
+
+// taskFor is what squad i should be doing in the current phase.
+func (p *Platoon) taskFor(i int) PlatoonTask {
+	r := p.state[i].report
+	t := PlatoonTask{Role: p.Roles[i], Kind: CmdHold, X: r.PosX, Y: r.PosY}
+	switch p.Phase {
+	case PlatoonPhaseAdvance:
+		if t.Role == PlatoonRoleAdvance {
+			t.Kind = CmdMoveTo
+			t.X, t.Y = p.laneObjective(i)
+		} else if p.effective(i) {
+			// The reserve follows the platoon leader's objective.
+			t.Kind = CmdMoveTo
+			t.X, t.Y = p.Leader.endTarget[0], p.Leader.endTarget[1]
+		}
+	case PlatoonPhaseDevelop, PlatoonPhaseManeuver, PlatoonPhaseAssault:
+		switch t.Role {
+		case PlatoonRoleSupport:
+			t.X, t.Y = p.supportX, p.supportY
+		case PlatoonRoleManeuver:
+			if p.Phase == PlatoonPhaseManeuver {
+				t.Kind = CmdMoveTo
+				t.X, t.Y = p.flankX, p.flankY
+			} else if p.Phase == PlatoonPhaseAssault {
+				t.Kind = CmdAssault
+				t.X, t.Y = p.contactX, p.contactY
+			}
+		case PlatoonRoleReserve:
+			if p.effective(i) {
+				vx, vy := p.fireLine()
+				t.Kind = CmdMoveTo
+				t.X, t.Y = p.clampToMap(p.supportX-vx*platoonReserveStandoff, p.supportY-vy*platoonReserveStandoff)
+			}
+		}
+	}
+	return t
+}
+
+// issueTasks sends each squad its task when it changes, and again before
+// the last one lapses.
+func (p *Platoon) issueTasks(tick int) {
+	hq := p.hqIndex()
+	for i, sq := range p.Squads {
+		if !canCommand(sq.Leader) {
+			continue
+		}
+		t := p.taskFor(i)
+		st := &p.state[i]
+		same := st.sent.Kind == t.Kind && st.sent.Role == t.Role &&
+			math.Hypot(st.sent.X-t.X, st.sent.Y-t.Y) < platoonOrderMoveThreshold
+		if same && tick-st.sent.IssuedTick < platoonOrderRefreshTicks {
+			continue
+		}
+		t.IssuedTick = tick
+		t.ExpiresTick = tick + platoonOrderTTL
+		st.sent = t
+		if i == hq {
+			sq.platoonTask = t
+			continue
+		}
+		p.queueOrder(sq.Leader, t, tick)
+	}
+}
+
+// queueOrder puts a task on the net, replacing any older one still waiting
+// for the same squad leader.
+func (p *Platoon) queueOrder(receiver *Soldier, t PlatoonTask, tick int) {
+	kept := p.radioNet.pending[:0]
+	for _, m := range p.radioNet.pending {
+		if m.Type != RadioMsgPlatoonOrder || m.ReceiverID != receiver.id {
+			kept = append(kept, m)
+		}
+	}
+	p.radioNet.pending = kept
+	p.radioNet.enqueue(RadioMessage{
+		TickCreated:   tick,
+		SenderID:      p.Leader.id,
+		SenderLabel:   p.Leader.label,
+		ReceiverID:    receiver.id,
+		ReceiverLabel: receiver.label,
+		Type:          RadioMsgPlatoonOrder,
+		Priority:      RadioPriUrgent,
+		Summary:       fmt.Sprintf("%s, %s %.0f,%.0f", strings.ToUpper(t.Role.String()), strings.ToUpper(t.Kind.String()), t.X, t.Y),
+		ContactX:      t.X,
+		ContactY:      t.Y,
+		Role:          t.Role,
+		Command:       t.Kind,
+	})
+}
+
+// ResolveComms runs the platoon net for one tick, like Squad.ResolveComms:
+// one transmission at a time, delivered, garbled or dropped by range and fear.
+func (p *Platoon) ResolveComms(tick int, tl *ThoughtLog) {
+	p.resolveInFlightTransmission(tick, tl)
+	if p.radioInFlight != nil || tick < p.radioChannelBusyUntil {
+		return
+	}
+	msg, ok := p.radioNet.dequeue()
+	if !ok {
+		return
+	}
+	arrivalTick := tick + radioTransmitDurationTicks(msg, p.soldierByID(msg.SenderID))
+	resolvedMsg, outcome := p.resolveDelivery(msg, tick)
+	p.radioInFlight = &radioTransmission{
+		msg:          msg,
+		dispatchTick: tick,
+		arrivalTick:  arrivalTick,
+		resolvedMsg:  resolvedMsg,
+		outcome:      outcome,
+	}
+	p.radioChannelBusyUntil = arrivalTick + radioChannelTurnaroundTicks
+	p.RadioSent++
+}
+
+func (p *Platoon) resolveInFlightTransmission(tick int, tl *ThoughtLog) {
+	if p.radioInFlight == nil || tick < p.radioInFlight.arrivalTick {
+		return
+	}
+	tx := p.radioInFlight
+	p.radioInFlight = nil
+
+	// The sender's squad carries the traffic to the radio log and the map.
+	if i := p.squadIndexOf(tx.msg.SenderID); i >= 0 {
+		sq := p.Squads[i]
+		sender, receiver := p.soldierByID(tx.msg.SenderID), p.soldierByID(tx.msg.ReceiverID)
+		sq.addRadioVisualEvent(sender, receiver, p.radioNet.kind, tx.resolvedMsg, tx.outcome, tx.dispatchTick, tx.arrivalTick-tx.dispatchTick)
+		sq.pushRadioChatLine(p.radioNet.name, tx.resolvedMsg, tx.outcome, tick)
+	}
+	switch tx.outcome {
+	case radioDeliveryDrop:
+		p.RadioDropped++
+		if tl != nil {
+			tl.Add(tick, tx.msg.SenderLabel, p.Team, fmt.Sprintf("radio %s %s->%s DROP %s", p.radioNet.name, tx.msg.SenderLabel, tx.msg.ReceiverLabel, tx.msg.Summary), LogCatRadio)
+		}
+		return
+	case radioDeliveryGarbled:
+		p.RadioGarbled++
+	}
+	p.RadioReceived++
+	p.applyRadioMessage(tx.resolvedMsg, tick)
+	if tl != nil {
+		tl.Add(tick, tx.resolvedMsg.SenderLabel, p.Team, fmt.Sprintf("radio %s %s->%s %s (%s)", p.radioNet.name, tx.resolvedMsg.SenderLabel, tx.resolvedMsg.ReceiverLabel, tx.resolvedMsg.Summary, radioQualityLabel(tx.outcome, tx.resolvedMsg.Relay)), LogCatRadio)
+	}
+}
+
+func (p *Platoon) applyRadioMessage(msg RadioMessage, tick int) {
+	switch msg.Type {
+	case RadioMsgSitrep:
+		if p.Leader == nil || msg.ReceiverID != p.Leader.id {
+			return
+		}
+		if i := p.squadIndexByLeader(msg.SenderID); i >= 0 {
+			p.state[i].report = platoonSitrep{
+				Tick:         tick,
+				PosX:         msg.PosX,
+				PosY:         msg.PosY,
+				Alive:        msg.Alive,
+				Broken:       msg.Broken,
+				Contact:      msg.ContactCount > 0,
+				ContactX:     msg.ContactX,
+				ContactY:     msg.ContactY,
+				ContactCount: msg.ContactCount,
+			}
+			// The platoon leader's squad acts on what they hear.
+			if hq := p.hqIndex(); hq >= 0 && p.Squads[hq].intel != nil && msg.ContactCount > 0 {
+				p.Squads[hq].intel.MergeReport(msg.ContactX, msg.ContactY, radioReportConfidence(msg))
+			}
+		}
+	case RadioMsgPlatoonOrder:
+		if i := p.squadIndexByLeader(msg.ReceiverID); i >= 0 {
+			sq := p.Squads[i]
+			sq.platoonTask = PlatoonTask{
+				Role:        msg.Role,
+				Kind:        msg.Command,
+				X:           msg.ContactX,
+				Y:           msg.ContactY,
+				IssuedTick:  tick,
+				ExpiresTick: tick + platoonOrderTTL,
+			}
+			sq.Leader.think(fmt.Sprintf("platoon task: %s, %s", msg.Role, msg.Command))
+		}
+	}
+}
+
+func (p *Platoon) resolveDelivery(msg RadioMessage, tick int) (RadioMessage, radioDeliveryOutcome) {
+	sender := p.soldierByID(msg.SenderID)
+	receiver := p.soldierByID(msg.ReceiverID)
+	if sender == nil || receiver == nil || sender.offField() || receiver.offField() {
+		return msg, radioDeliveryDrop
+	}
+	noise := p.radioDeterministicNoise(msg, tick)
+	return deliverRadioMessage(p.radio, msg, sender, receiver, sender.config().RadioMaxRange*platoonRadioRangeScale, noise)
+}
+
+func (p *Platoon) radioDeterministicNoise(msg RadioMessage, tick int) float64 {
+	phase := float64(msg.ID*17+uint64(msg.SenderID*31)+uint64(msg.ReceiverID*13)+uint64(tick*7)+uint64(p.radioNet.netID*19)) * 0.071 // #nosec G115 -- intentional bit-mixing for deterministic noise
+	return (math.Sin(phase) + 1.0) * 0.5
+}
+
+func (p *Platoon) soldierByID(id int) *Soldier {
+	if i := p.squadIndexOf(id); i >= 0 {
+		return p.Squads[i].memberByID(id)
+	}
+	return nil
+}
+
+// squadIndexOf finds the squad soldier id belongs to.
+func (p *Platoon) squadIndexOf(id int) int {
+	for i, sq := range p.Squads {
+		if sq.memberByID(id) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// squadIndexByLeader finds the squad currently led by soldier id.
+func (p *Platoon) squadIndexByLeader(id int) int {
+	for i, sq := range p.Squads {
+		if sq.Leader != nil && sq.Leader.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// followPlatoonTask issues the squad's platoon task as its ActiveOrder and
+// reports whether it did. A broken, regrouping or withdrawing squad looks
+// after itself, a squad already in a fight only takes hold and assault
+// tasks, and a stalemate push outranks a move.
+func (sq *Squad) followPlatoonTask(tick int, forceProactive bool) bool {
+	t := sq.platoonTask
+	if !t.activeAt(tick) || sq.Broken || sq.Intent == IntentRegroup || sq.Intent == IntentWithdraw {
+		return false
+	}
+	switch t.Kind {
+	case CmdMoveTo:
+		if sq.Intent != IntentAdvance || forceProactive {
+			return false
+		}
+		sq.Formation = FormationWedge
+		if math.Hypot(t.X-sq.Leader.x, t.Y-sq.Leader.y) > 650 {
+			sq.Formation = FormationColumn
+		}
+		sq.issueOfficerOrder(tick, CmdMoveTo, t.X, t.Y, 140, sq.Formation, 0.78, 0.90, 360)
+	case CmdHold:
+		if sq.Phase == SquadPhaseAssault {
+			return false
+		}
+		sq.Formation = FormationLine
+		sq.issueOfficerOrder(tick, CmdHold, t.X, t.Y, 170, sq.Formation, 0.80, 0.90, 240)
+	case CmdAssault:
+		sq.Formation = FormationLine
+		sq.issueOfficerOrder(tick, CmdAssault, t.X, t.Y, 230, sq.Formation, 0.90, 0.98, 220)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// newPlatoonSim puts two four-man red squads in one platoon, advancing
+// across an empty field. extra adds the enemy.
+func newPlatoonSim(extra ...SimOption) *TestSim {
+	opts := []SimOption{
+		WithSeed(7),
+		WithRedSoldier(0, 80, 260, 1180, 360),
+		WithRedSoldier(1, 70, 240, 1180, 360),
+		WithRedSoldier(2, 70, 280, 1180, 360),
+		WithRedSoldier(3, 60, 260, 1180, 360),
+		WithRedSoldier(4, 80, 460, 1180, 360),
+		WithRedSoldier(5, 70, 440, 1180, 360),
+		WithRedSoldier(6, 70, 480, 1180, 360),
+		WithRedSoldier(7, 60, 460, 1180, 360),
+		WithRedSquad(0, 1, 2, 3),
+		WithRedSquad(4, 5, 6, 7),
+		WithPlatoon(0, 1),
+	}
+	return NewTestSim(append(opts, extra...)...)
+}
+
+// withBlueFireTeam holds the middle of the field, in view of the platoon.
+func withBlueFireTeam() []SimOption {
+	return []SimOption{
+		WithBlueSoldier(10, 760, 340, 760, 340),
+		WithBlueSoldier(11, 760, 380, 760, 380),
+		WithBlueSoldier(12, 780, 360, 780, 360),
+		WithBlueSquad(10, 11, 12),
+	}
+}
+
+func TestPlatoon_AdvancesSquadsAbreastOverTheNet(t *testing.T) {
+	ts := newPlatoonSim()
+	p := ts.platoons[0]
+	if p.Leader != ts.squads[0].Leader {
+		t.Fatalf("platoon leader should be squad 0's leader")
+	}
+	if p.radioNet.netID == ts.squads[0].radioNet.netID || p.radioNet.netID == ts.squads[1].radioNet.netID {
+		t.Fatalf("platoon net %d shares an ID with a squad net", p.radioNet.netID)
+	}
+
+	ts.RunTicks(5)
+	hq, other := ts.squads[0].platoonTask, ts.squads[1].platoonTask
+	if hq.Kind != CmdMoveTo || hq.Role != PlatoonRoleAdvance {
+		t.Fatalf("HQ squad should be tasked to advance at once, got %s %s", hq.Role, hq.Kind)
+	}
+	if other.Kind != CmdNone {
+		t.Fatalf("second squad has a task before it could arrive over the radio: %+v", other)
+	}
+
+	ts.RunUntil(func(*TestSim) bool { return ts.squads[1].platoonTask.Kind != CmdNone }, 120)
+	other = ts.squads[1].platoonTask
+	if other.Kind != CmdMoveTo || p.RadioReceived == 0 {
+		t.Fatalf("second squad's advance order never arrived: task=%+v received=%d", other, p.RadioReceived)
+	}
+	// The order shows in the radio log on the command net, under its sender's squad.
+	onCmd := false
+	for _, line := range ts.squads[0].radioChatLines {
+		onCmd = onCmd || line.Net == p.radioNet.name
+	}
+	if !onCmd {
+		t.Errorf("no %s line in the radio log: %+v", p.radioNet.name, ts.squads[0].radioChatLines)
+	}
+	// Lanes keep the squads' left-to-right order, one frontage apart.
+	if other.Y <= hq.Y {
+		t.Errorf("lanes crossed: squad 0 lane y=%.0f, squad 1 lane y=%.0f", hq.Y, other.Y)
+	}
+	if gap := math.Hypot(other.X-hq.X, other.Y-hq.Y); math.Abs(gap-platoonFrontage) > 70 {
+		t.Errorf("lane gap = %.0f, want about %.0f", gap, platoonFrontage)
+	}
+	ts.RunTicks(1) // the squad leader acts on it at their next think
+	if o := ts.squads[1].ActiveOrder; o.Kind != CmdMoveTo || math.Hypot(o.TargetX-other.X, o.TargetY-other.Y) > 1 {
+		t.Errorf("squad 1 order %s at (%.0f,%.0f) does not follow its task at (%.0f,%.0f)", o.Kind, o.TargetX, o.TargetY, other.X, other.Y)
+	}
+}
+
+func TestPlatoon_ContactSetsSupportAndManeuver(t *testing.T) {
+	ts := newPlatoonSim(withBlueFireTeam()...)
+	p := ts.platoons[0]
+	if ts.RunUntil(func(*TestSim) bool { return p.Phase == PlatoonPhaseDevelop }, 1500) < 0 {
+		t.Fatalf("platoon never developed the contact; phase=%s", p.Phase)
+	}
+	support, maneuver := p.roleIndex(PlatoonRoleSupport), p.roleIndex(PlatoonRoleManeuver)
+	if support < 0 || maneuver < 0 || support == maneuver {
+		t.Fatalf("roles = %v, want one support and one maneuver squad", p.Roles)
+	}
+
+	if ts.RunUntil(func(*TestSim) bool { return p.Phase == PlatoonPhaseManeuver }, 600) < 0 {
+		t.Fatalf("platoon stuck in %s", p.Phase)
+	}
+	// The flank point sits off the support squad's line of fire.
+	vx, vy := p.fireLine()
+	off := math.Abs((p.flankX-p.supportX)*-vy + (p.flankY-p.supportY)*vx)
+	if off < platoonFlankOffset/2 {
+		t.Errorf("flank point (%.0f,%.0f) is only %.0f px off the line of fire", p.flankX, p.flankY, off)
+	}
+	ok := ts.RunUntil(func(*TestSim) bool {
+		task := ts.squads[maneuver].platoonTask
+		return task.Role == PlatoonRoleManeuver && task.Kind == CmdMoveTo
+	}, 300)
+	if ok < 0 {
+		t.Fatalf("maneuver squad never received its flank task: %+v", ts.squads[maneuver].platoonTask)
+	}
+}
+
+func TestPlatoon_SitrepCarriesOnlyWhatTheLeaderKnows(t *testing.T) {
+	ts := newPlatoonSim()
+	sq := ts.squads[1]
+	sq.Members[1].blackboard.Threats = append(sq.Members[1].blackboard.Threats,
+		ThreatFact{X: 700, Y: 450, Confidence: 1, IsVisible: true})
+	if r := squadSitrep(sq, 1); r.Contact {
+		t.Fatalf("SITREP reports a contact that never reached the squad leader")
+	}
+
+	// The member's contact report gets through on the squad net.
+	bb := &sq.Leader.blackboard
+	bb.RadioHasContact, bb.RadioContactX, bb.RadioContactY, bb.RadioContactTick = true, 700, 450, 1
+	if r := squadSitrep(sq, 2); !r.Contact || r.ContactX != 700 || r.ContactY != 450 {
+		t.Fatalf("SITREP = %+v, want the relayed contact at (700,450)", r)
+	}
+	if r := squadSitrep(sq, 1+platoonSightingFreshTicks); r.Contact {
+		t.Errorf("SITREP still reports a contact heard %d ticks ago", platoonSightingFreshTicks)
+	}
+}
+
+func TestPlatoon_ReassignsRolesAfterCasualties(t *testing.T) {
+	ts := newPlatoonSim()
+	p := ts.platoons[0]
+	ts.RunTicks(1)
+	p.lastContactTick = 1
+	p.contactX, p.contactY = 760, 360
+	p.state[1].report.Contact = true
+	p.state[1].report.Tick = 1
+	p.assignRoles()
+	p.setPhase(1, PlatoonPhaseManeuver)
+	if p.Roles[1] != PlatoonRoleSupport || p.Roles[0] != PlatoonRoleManeuver {
+		t.Fatalf("roles = %v, want squad 1 support and squad 0 maneuver", p.Roles)
+	}
+
+	// Squad 1 reports it is down to one man.
+	p.state[1].report.Alive = 1
+	p.updatePhase(2)
+	if p.Phase != PlatoonPhaseDevelop {
+		t.Errorf("phase = %s, want the attack re-planned from develop", p.Phase)
+	}
+	if p.Roles[0] != PlatoonRoleSupport || p.Roles[1] != PlatoonRoleReserve {
+		t.Errorf("roles = %v, want squad 0 support and squad 1 in reserve", p.Roles)
+	}
+}
+
+func TestPlatoon_CommandPassesToNextSquadLeader(t *testing.T) {
+	ts := newPlatoonSim()
+	p := ts.platoons[0]
+	ts.RunTicks(1)
+	old, next := p.Leader, ts.squads[1].Leader
+	old.state = SoldierStateDead
+
+	ts.RunTicks(platoonSuccessionDelayTicks - 10)
+	if p.Leader != old || p.successor != next {
+		t.Fatalf("during the delay: leader=%s successor=%v, want %s and %s", p.Leader.label, p.successor != nil, old.label, next.label)
+	}
+	ts.RunTicks(20)
+	if p.Leader != next {
+		t.Fatalf("platoon leader = %s, want %s", p.Leader.label, next.label)
+	}
+	if p.hqIndex() != 1 {
+		t.Errorf("hq squad = %d, want 1", p.hqIndex())
+	}
+}
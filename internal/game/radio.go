@@ -36,6 +36,9 @@ const (
 	RadioMsgStatusReport
 	RadioMsgFearReport
 	RadioMsgStatusRequest
+	RadioMsgAmmoReport
+	RadioMsgSitrep       // squad leader -> platoon leader, platoon net
+	RadioMsgPlatoonOrder // platoon leader -> squad leader, platoon net
 )
 
 func (t RadioMessageType) String() string {
@@ -48,12 +51,20 @@ func (t RadioMessageType) String() string {
 		return "fear"
 	case RadioMsgStatusRequest:
 		return "status_request"
+	case RadioMsgAmmoReport:
+		return "ammo"
+	case RadioMsgSitrep:
+		return "sitrep"
+	case RadioMsgPlatoonOrder:
+		return "platoon_order"
 	default:
 		return "unknown"
 	}
 }
 
-func (sq *Squad) radioTransmitDurationTicks(msg RadioMessage, sender *Soldier) int {
+// radioTransmitDurationTicks is how long sender keys the radio to send msg:
+// longer messages take longer, and fear makes operators rush or ramble.
+func radioTransmitDurationTicks(msg RadioMessage, sender *Soldier) int {
 	chars := len(msg.Summary)
 	if chars < 1 {
 		chars = 1
@@ -108,16 +119,37 @@ type RadioMessage struct {
 	Priority RadioPriority
 	Summary  string
 
-	ContactX     float64
+	ContactX     float64 // contact position; the task point for platoon orders
 	ContactY     float64
 	ContactCount int
 	Distance     float64
 	Fear         float64
 	Injured      bool
+	Rounds       int // rounds carried, for ammo reports
+
+	// Platoon net fields.
+	PosX, PosY float64            // sender's squad position, for SITREPs
+	Alive      int                // squad members still up, for SITREPs
+	Broken     bool               // squad cohesion has collapsed, for SITREPs
+	Role       PlatoonRole        // for platoon orders
+	Command    OfficerCommandKind // for platoon orders
+
+	Relay   string // label of the relay operator who re-sent it; empty if direct
+	Garbled bool   // arrived garbled; positions and counts are off
 }
 
+// RadioNetKind tells a squad net from the command net its leaders share.
+type RadioNetKind uint8
+
+const (
+	RadioNetSquad RadioNetKind = iota
+	RadioNetCommand
+)
+
 type radioNet struct {
 	netID   int
+	kind    RadioNetKind
+	name    string // "SQ-0", "CMD-0": shown on every radio log line
 	nextID  uint64
 	pending []RadioMessage
 }
@@ -133,10 +165,13 @@ type radioVisualEvent struct {
 	MsgType    RadioMessageType
 	Delivery   radioDeliveryOutcome
 	SenderTeam Team
+	Net        RadioNetKind
 }
 
 type radioChatLine struct {
 	Tick     int
+	Net      string
+	SenderID int
 	Sender   string
 	Message  string
 	Receiver string
@@ -184,6 +219,9 @@ func (sq *Squad) ensureRadioState() {
 	if sq.radioNet.netID == 0 {
 		sq.radioNet.netID = sq.ID + 1
 	}
+	if sq.radioNet.name == "" {
+		sq.radioNet.name = squadNetName(sq.ID)
+	}
 	if sq.radioPendingStatus == nil {
 		sq.radioPendingStatus = make(map[int]int)
 	}
@@ -203,7 +241,7 @@ func (sq *Squad) queueRadio(msg RadioMessage) {
 
 // PlanComms creates candidate member/leader transmissions for this tick.
 func (sq *Squad) PlanComms(tick int) {
-	if sq.Leader == nil || sq.Leader.state == SoldierStateDead {
+	if sq.Leader == nil || sq.Leader.offField() {
 		return
 	}
 	sq.ensureRadioState()
@@ -213,7 +251,7 @@ func (sq *Squad) PlanComms(tick int) {
 	}
 
 	for _, m := range sq.Members {
-		if m == sq.Leader || m.state == SoldierStateDead {
+		if m == sq.Leader || m.offField() {
 			continue
 		}
 
@@ -230,6 +268,10 @@ func (sq *Squad) PlanComms(tick int) {
 			sq.queueRadio(msg)
 			continue
 		}
+		if msg, ok := m.buildAmmoReportMessage(sq.Leader, tick); ok {
+			sq.queueRadio(msg)
+			continue
+		}
 		if msg, ok := m.buildInjuryStatusMessage(sq.Leader, tick); ok {
 			sq.queueRadio(msg)
 			continue
@@ -249,7 +291,7 @@ func (sq *Squad) planLeaderStatusRequest(tick int) {
 	for i := 0; i < len(sq.Members); i++ {
 		idx := (start + i) % len(sq.Members)
 		m := sq.Members[idx]
-		if m == sq.Leader || m.state == SoldierStateDead {
+		if m == sq.Leader || m.offField() {
 			continue
 		}
 		if _, pending := sq.radioPendingStatus[m.id]; pending {
@@ -277,7 +319,7 @@ func (sq *Squad) planLeaderStatusRequest(tick int) {
 
 // ResolveComms resolves one transmission slot for this tick and applies effects.
 func (sq *Squad) ResolveComms(tick int, tl *ThoughtLog) {
-	if sq.Leader == nil || sq.Leader.state == SoldierStateDead {
+	if sq.Leader == nil || sq.Leader.offField() {
 		return
 	}
 	sq.ensureRadioState()
@@ -298,7 +340,7 @@ func (sq *Squad) ResolveComms(tick int, tl *ThoughtLog) {
 	}
 
 	sender := sq.memberByID(msg.SenderID)
-	transmitTicks := sq.radioTransmitDurationTicks(msg, sender)
+	transmitTicks := radioTransmitDurationTicks(msg, sender)
 	arrivalTick := tick + transmitTicks
 	resolvedMsg, outcome := sq.resolveDelivery(msg, tick)
 
@@ -322,12 +364,12 @@ func (sq *Squad) resolveInFlightTransmission(tick int, tl *ThoughtLog) {
 	sq.radioInFlight = nil
 
 	sq.pushRadioVisualEvent(tx.resolvedMsg, tx.outcome, tx.dispatchTick, tx.arrivalTick-tx.dispatchTick)
-	sq.pushRadioChatLine(tx.resolvedMsg, tx.outcome, tick)
+	sq.pushRadioChatLine(sq.radioNet.name, tx.resolvedMsg, tx.outcome, tick)
 	switch tx.outcome {
 	case radioDeliveryDrop:
 		sq.RadioDropped++
 		if tl != nil {
-			tl.Add(tick, tx.msg.SenderLabel, sq.Team, fmt.Sprintf("radio %s->%s DROP %s", tx.msg.SenderLabel, tx.msg.ReceiverLabel, tx.msg.Summary), LogCatRadio)
+			tl.Add(tick, tx.msg.SenderLabel, sq.Team, fmt.Sprintf("radio %s %s->%s DROP %s", sq.radioNet.name, tx.msg.SenderLabel, tx.msg.ReceiverLabel, tx.msg.Summary), LogCatRadio)
 		}
 		return
 	case radioDeliveryGarbled:
@@ -350,11 +392,7 @@ func (sq *Squad) resolveInFlightTransmission(tick int, tl *ThoughtLog) {
 		sq.radioChannelBusyUntil = tick + radioResponsePauseTicks
 	}
 	if tl != nil {
-		quality := "CLEAR"
-		if tx.outcome == radioDeliveryGarbled {
-			quality = "GARBLED"
-		}
-		tl.Add(tick, tx.resolvedMsg.SenderLabel, sq.Team, fmt.Sprintf("radio %s->%s %s (%s)", tx.resolvedMsg.SenderLabel, tx.resolvedMsg.ReceiverLabel, tx.resolvedMsg.Summary, quality), LogCatRadio)
+		tl.Add(tick, tx.resolvedMsg.SenderLabel, sq.Team, fmt.Sprintf("radio %s %s->%s %s (%s)", sq.radioNet.name, tx.resolvedMsg.SenderLabel, tx.resolvedMsg.ReceiverLabel, tx.resolvedMsg.Summary, radioQualityLabel(tx.outcome, tx.resolvedMsg.Relay)), LogCatRadio)
 	}
 }
 
@@ -385,6 +423,14 @@ func (sq *Squad) resolveStatusTimeouts(tick int, tl *ThoughtLog) {
 	}
 }
 
+// dropRadioMember forgets any outstanding status request to member id, and
+// any mark against them for not answering one.
+func (sq *Squad) dropRadioMember(id int) {
+	delete(sq.radioPendingStatus, id)
+	delete(sq.radioStatusReplyQueued, id)
+	delete(sq.radioUnresponsive, id)
+}
+
 func (sq *Squad) applyRadioMessage(msg RadioMessage, tick int) {
 	if sq.Leader == nil {
 		return
@@ -420,6 +466,9 @@ func (sq *Squad) applyRadioMessage(msg RadioMessage, tick int) {
 		bb.SquadHasContact = true
 		bb.SquadContactX = msg.ContactX
 		bb.SquadContactY = msg.ContactY
+		if sq.intel != nil {
+			sq.intel.MergeReport(msg.ContactX, msg.ContactY, radioReportConfidence(msg))
+		}
 	case RadioMsgStatusReport:
 		delete(sq.radioPendingStatus, msg.SenderID)
 		delete(sq.radioStatusReplyQueued, msg.SenderID)
@@ -429,6 +478,11 @@ func (sq *Squad) applyRadioMessage(msg RadioMessage, tick int) {
 		}
 	case RadioMsgFearReport:
 		sq.Leader.profile.Psych.ApplyStress(0.01 + 0.03*msg.Fear)
+	case RadioMsgAmmoReport:
+		sq.noteAmmoRequest(msg.SenderID)
+		if msg.Rounds == 0 {
+			sq.Leader.profile.Psych.ApplyStress(0.01)
+		}
 	}
 
 	bb.UnresponsiveMembers = len(sq.radioUnresponsive)
@@ -445,25 +499,78 @@ const (
 func (sq *Squad) resolveDelivery(msg RadioMessage, tick int) (RadioMessage, radioDeliveryOutcome) {
 	sender := sq.memberByID(msg.SenderID)
 	receiver := sq.memberByID(msg.ReceiverID)
-	if sender == nil || receiver == nil || sender.state == SoldierStateDead || receiver.state == SoldierStateDead {
+	if sender == nil || receiver == nil || sender.offField() || receiver.offField() {
 		return msg, radioDeliveryDrop
 	}
 
-	dist := math.Hypot(sender.x-receiver.x, sender.y-receiver.y)
-	distancePenalty := clamp01(dist / radioMaxReliableRange)
+	noise := sq.radioDeterministicNoise(msg, tick)
+	return deliverRadioMessage(sq.radio, msg, sender, receiver, sender.config().RadioMaxRange, noise)
+}
+
+// deliverRadioMessage is msg as receiver hears it: clear, garbled or lost,
+// and tagged with the relay that carried it if one did.
+func deliverRadioMessage(m *radioMedium, msg RadioMessage, sender, receiver *Soldier, maxRange, noise float64) (RadioMessage, radioDeliveryOutcome) {
+	outcome, relay := radioLinkOutcome(m, sender, receiver, maxRange, noise)
+	if relay != nil {
+		msg.Relay = relay.label
+	}
+	switch outcome {
+	case radioDeliveryDrop:
+		return msg, radioDeliveryDrop
+	case radioDeliveryGarbled:
+		return garbleRadioMessage(msg, noise), radioDeliveryGarbled
+	}
+	return msg, radioDeliveryClear
+}
+
+// radioLinkOutcome rates one transmission through m: range against
+// maxRange, terrain and jamming on the best route, both operators' fear and
+// a deterministic noise term in [0,1]. relay is nil for a direct link.
+func radioLinkOutcome(m *radioMedium, sender, receiver *Soldier, maxRange, noise float64) (outcome radioDeliveryOutcome, relay *Soldier) {
+	cfg := sender.config()
+	pathPenalty, relay := m.route(sender, receiver, maxRange)
 	senderFear := sender.profile.Psych.EffectiveFear()
 	receiverFear := receiver.profile.Psych.EffectiveFear()
-	noisePenalty := sq.radioDeterministicNoise(msg, tick) * 0.12
+	noisePenalty := noise * 0.12
 
-	quality := 1.0 - (0.55 * distancePenalty) - (0.20 * senderFear) - (0.13 * receiverFear) - noisePenalty
+	quality := 1.0 - pathPenalty - (0.20 * senderFear) - (0.13 * receiverFear) - noisePenalty
 	quality = clamp01(quality)
-	if quality < radioDropThreshold {
-		return msg, radioDeliveryDrop
+	if quality < cfg.RadioDropThreshold {
+		return radioDeliveryDrop, relay
 	}
-	if quality < radioGarbleThreshold {
-		return sq.garbledMessage(msg, tick), radioDeliveryGarbled
+	if quality < cfg.RadioGarbleThreshold {
+		return radioDeliveryGarbled, relay
 	}
-	return msg, radioDeliveryClear
+	return radioDeliveryClear, relay
+}
+
+// radioReportConfidence is how much a leader trusts a radioed sighting.
+func radioReportConfidence(msg RadioMessage) float64 {
+	if msg.Garbled {
+		return 0.5
+	}
+	return 1.0
+}
+
+// radioQualityLabel is how a transmission's outcome reads in the radio log.
+func radioQualityLabel(outcome radioDeliveryOutcome, relay string) string {
+	switch outcome {
+	case radioDeliveryDrop:
+		return "DROP"
+	case radioDeliveryGarbled:
+		if relay != "" {
+			return "GARBLED via " + relay
+		}
+		return "GARBLED"
+	}
+	if relay != "" {
+		return "CLEAR via " + relay
+	}
+	return "CLEAR"
+}
+
+func squadNetName(squadID int) string {
+	return fmt.Sprintf("SQ-%d", squadID)
 }
 
 func (sq *Squad) radioDeterministicNoise(msg RadioMessage, tick int) float64 {
@@ -472,13 +579,15 @@ func (sq *Squad) radioDeterministicNoise(msg RadioMessage, tick int) float64 {
 	return (v + 1.0) * 0.5
 }
 
-func (sq *Squad) garbledMessage(msg RadioMessage, tick int) RadioMessage {
+// garbleRadioMessage degrades msg the way a bad link does: positions drift
+// and counts and fear readings come through wrong. jitter is in [0,1].
+func garbleRadioMessage(msg RadioMessage, jitter float64) RadioMessage {
 	garbled := msg
-	jitter := sq.radioDeterministicNoise(msg, tick)
+	garbled.Garbled = true
 	garbled.Summary = "GARBLED " + msg.Summary
 
 	switch msg.Type {
-	case RadioMsgContactReport:
+	case RadioMsgContactReport, RadioMsgSitrep, RadioMsgPlatoonOrder:
 		offsetX := (jitter - 0.5) * 120.0
 		offsetY := (0.5 - jitter) * 120.0
 		garbled.ContactX = msg.ContactX + offsetX
@@ -503,8 +612,12 @@ func (sq *Squad) memberByID(id int) *Soldier {
 }
 
 func (sq *Squad) pushRadioVisualEvent(msg RadioMessage, outcome radioDeliveryOutcome, tick int, transmitTicks int) {
-	sender := sq.memberByID(msg.SenderID)
-	receiver := sq.memberByID(msg.ReceiverID)
+	sq.addRadioVisualEvent(sq.memberByID(msg.SenderID), sq.memberByID(msg.ReceiverID), sq.radioNet.kind, msg, outcome, tick, transmitTicks)
+}
+
+// addRadioVisualEvent draws a transmission between two soldiers who need not
+// both be in sq; the platoon net borrows the sender's squad to show its traffic.
+func (sq *Squad) addRadioVisualEvent(sender, receiver *Soldier, net RadioNetKind, msg RadioMessage, outcome radioDeliveryOutcome, tick int, transmitTicks int) {
 	if sender == nil || receiver == nil {
 		return
 	}
@@ -528,6 +641,7 @@ func (sq *Squad) pushRadioVisualEvent(msg RadioMessage, outcome radioDeliveryOut
 		MsgType:    msg.Type,
 		Delivery:   outcome,
 		SenderTeam: sender.team,
+		Net:        net,
 	}
 	sq.radioVisualEvents = append(sq.radioVisualEvents, event)
 }
@@ -545,21 +659,17 @@ func (sq *Squad) pruneRadioVisualEvents(tick int) {
 	sq.radioVisualEvents = kept
 }
 
-func (sq *Squad) pushRadioChatLine(msg RadioMessage, outcome radioDeliveryOutcome, tick int) {
-	quality := "CLEAR"
-	switch outcome {
-	case radioDeliveryDrop:
-		quality = "DROP"
-	case radioDeliveryGarbled:
-		quality = "GARBLED"
-	}
-
+// pushRadioChatLine records a transmission on the named net for the radio
+// log. Platoon-net traffic lands in the sending squad leader's squad.
+func (sq *Squad) pushRadioChatLine(net string, msg RadioMessage, outcome radioDeliveryOutcome, tick int) {
 	line := radioChatLine{
 		Tick:     tick,
+		Net:      net,
+		SenderID: msg.SenderID,
 		Sender:   msg.SenderLabel,
 		Message:  msg.Summary,
 		Receiver: msg.ReceiverLabel,
-		Quality:  quality,
+		Quality:  radioQualityLabel(outcome, msg.Relay),
 		Duration: 360,
 	}
 	sq.radioChatLines = append(sq.radioChatLines, line)
@@ -641,7 +751,7 @@ func (s *Soldier) buildStatusReportMessage(leader *Soldier, tick int, pri RadioP
 	if injured {
 		status = "INJURED"
 	}
-	if s.state == SoldierStateDead {
+	if s.offField() {
 		status = "DOWN"
 	}
 
@@ -676,7 +786,7 @@ func (s *Soldier) buildFearReportMessage(leader *Soldier, tick int) (RadioMessag
 		return RadioMessage{}, false
 	}
 	fear := s.profile.Psych.EffectiveFear()
-	if fear < radioFearReportThreshold {
+	if fear < s.config().RadioFearReportThreshold {
 		return RadioMessage{}, false
 	}
 	if tick-s.radioLastFearReportTick < radioFearReportCooldown {
@@ -0,0 +1,112 @@
+package game
+
+import "math"
+
+const (
+	radioWallLoss        = 0.12 // quality lost per wall cell between sender and receiver
+	radioTerrainLossCap  = 0.60 // terrain alone never blocks a link outright
+	radioRelayHopPenalty = 0.05 // a relay re-sends with a little loss of its own
+	radioRangeWeight     = 0.55 // share of link quality that range can take away
+)
+
+// Jammer is an emitter that degrades radio links near it. It belongs to Team
+// and only affects the other team's nets.
+type Jammer struct {
+	Team     Team
+	X, Y     float64
+	Radius   float64 // px; no effect beyond this
+	Strength float64 // quality lost at the centre, fading to 0 at Radius
+}
+
+// radioMedium is what a transmission passes through: buildings and terrain on
+// the tile map, enemy jammers, and relay operators who can re-send traffic
+// around an obstacle. A nil medium is open ground with no relays, which is
+// what unit tests that build squads by hand get.
+type radioMedium struct {
+	tileMap *TileMap // nil on open-field maps
+	jammers []Jammer
+	relays  []*Soldier
+}
+
+// newRadioMedium collects the relay operators among soldiers.
+func newRadioMedium(tm *TileMap, jammers []Jammer, soldiers []*Soldier) *radioMedium {
+	m := &radioMedium{tileMap: tm, jammers: jammers}
+	for _, s := range soldiers {
+		if s.radioRelay {
+			m.relays = append(m.relays, s)
+		}
+	}
+	return m
+}
+
+// linkPenalty is the quality lost between a and b before fear and noise:
+// range against maxRange, the terrain along the line and enemy jamming at
+// either end.
+func (m *radioMedium) linkPenalty(a, b *Soldier, maxRange float64) float64 {
+	p := radioRangeWeight * clamp01(math.Hypot(b.x-a.x, b.y-a.y)/maxRange)
+	if m == nil {
+		return p
+	}
+	return p + m.terrainLoss(a.x, a.y, b.x, b.y, a.config().RadioWallLoss) + m.jamLoss(a.team, a.x, a.y, b.x, b.y)
+}
+
+// route picks the better of the direct link and one relay hop. A relayed
+// message is as good as its worse hop. relay is nil for a direct link.
+func (m *radioMedium) route(sender, receiver *Soldier, maxRange float64) (penalty float64, relay *Soldier) {
+	penalty = m.linkPenalty(sender, receiver, maxRange)
+	if m == nil {
+		return penalty, nil
+	}
+	for _, r := range m.relays {
+		if r == sender || r == receiver || r.team != sender.team || !canCommand(r) {
+			continue
+		}
+		hop := math.Max(m.linkPenalty(sender, r, maxRange), m.linkPenalty(r, receiver, maxRange)) + radioRelayHopPenalty
+		if hop < penalty {
+			penalty, relay = hop, r
+		}
+	}
+	return penalty, relay
+}
+
+// terrainLoss sums objectRadioLoss over the tiles the line crosses, each
+// tile counted once, up to radioTerrainLossCap.
+func (m *radioMedium) terrainLoss(x0, y0, x1, y1, wallLoss float64) float64 {
+	tm := m.tileMap
+	if tm == nil || wallLoss <= 0 {
+		return 0
+	}
+	dist := math.Hypot(x1-x0, y1-y0)
+	steps := int(dist/(cellSize/2)) + 1
+	lastCol, lastRow := int(x0/cellSize), int(y0/cellSize)
+	loss := 0.0
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		col, row := int((x0+(x1-x0)*t)/cellSize), int((y0+(y1-y0)*t)/cellSize)
+		if col == lastCol && row == lastRow {
+			continue
+		}
+		lastCol, lastRow = col, row
+		loss += wallLoss * tm.RadioLoss(col, row)
+		if loss >= radioTerrainLossCap {
+			return radioTerrainLossCap
+		}
+	}
+	return loss
+}
+
+// jamLoss is the quality lost to jammers not on team. Each jammer hits the
+// link at whichever end is closer to it.
+func (m *radioMedium) jamLoss(team Team, x0, y0, x1, y1 float64) float64 {
+	loss := 0.0
+	for _, j := range m.jammers {
+		if j.Team == team || j.Radius <= 0 {
+			continue
+		}
+		d := math.Min(math.Hypot(x0-j.X, y0-j.Y), math.Hypot(x1-j.X, y1-j.Y))
+		if d < j.Radius {
+			loss += j.Strength * (1 - d/j.Radius)
+		}
+	}
+	return loss
+}
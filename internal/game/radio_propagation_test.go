@@ -0,0 +1,70 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// wallBetween puts a three-cell-thick wall across y=200 between x=240 and
+// x=288, squarely between makeRadioSquadForTest's soldiers at x=100 and x=400.
+func wallBetween() *TileMap {
+	tm := NewTileMap(2400/cellSize, 800/cellSize)
+	for col := 15; col <= 17; col++ {
+		for row := 10; row <= 14; row++ {
+			tm.SetObject(col, row, ObjectWall)
+		}
+	}
+	return tm
+}
+
+func TestRadioLink_WallsAttenuate(t *testing.T) {
+	_, leader, member, _ := makeRadioSquadForTest(t, 100, 400)
+	open := (*radioMedium)(nil).linkPenalty(member, leader, radioMaxReliableRange)
+	walled := (&radioMedium{tileMap: wallBetween()}).linkPenalty(member, leader, radioMaxReliableRange)
+	if want := open + 3*radioWallLoss; math.Abs(walled-want) > 1e-9 {
+		t.Fatalf("penalty through three walls = %.3f, want %.3f", walled, want)
+	}
+
+	clear, _ := radioLinkOutcome(nil, member, leader, radioMaxReliableRange, 0)
+	blocked, _ := radioLinkOutcome(&radioMedium{tileMap: wallBetween()}, member, leader, radioMaxReliableRange, 0)
+	if clear != radioDeliveryClear || blocked == radioDeliveryClear {
+		t.Errorf("outcome open=%d walled=%d, want the wall to spoil a clear link", clear, blocked)
+	}
+}
+
+func TestRadioLink_RelayRoutesAroundWall(t *testing.T) {
+	sq, leader, member, tick := makeRadioSquadForTest(t, 100, 400)
+	relay := NewSoldier(2, 250, 60, TeamRed, [2]float64{250, 60}, [2]float64{250, 60}, leader.navGrid, nil, nil, NewThoughtLog(), tick)
+	relay.radioRelay = true
+	sq.radio = newRadioMedium(wallBetween(), nil, []*Soldier{leader, member, relay})
+
+	msg, outcome := sq.resolveDelivery(member.buildStatusReportMessage(leader, *tick, RadioPriUrgent, "STATUS"), *tick)
+	if outcome != radioDeliveryClear || msg.Relay != relay.label {
+		t.Fatalf("outcome=%d relay=%q, want a clear message via %s", outcome, msg.Relay, relay.label)
+	}
+	if got := radioQualityLabel(outcome, msg.Relay); got != "CLEAR via "+relay.label {
+		t.Errorf("quality label = %q", got)
+	}
+
+	relay.state = SoldierStateDead
+	if _, via := sq.radio.route(member, leader, radioMaxReliableRange); via != nil {
+		t.Errorf("a dead relay still carries traffic")
+	}
+}
+
+func TestRadioLink_JammerDegradesOnlyTheEnemy(t *testing.T) {
+	_, leader, member, _ := makeRadioSquadForTest(t, 100, 400)
+	open := (*radioMedium)(nil).linkPenalty(member, leader, radioMaxReliableRange)
+	blue := &radioMedium{jammers: []Jammer{{Team: TeamBlue, X: 100, Y: 200, Radius: 300, Strength: 0.4}}}
+	red := &radioMedium{jammers: []Jammer{{Team: TeamRed, X: 100, Y: 200, Radius: 300, Strength: 0.4}}}
+
+	if got := blue.linkPenalty(member, leader, radioMaxReliableRange); math.Abs(got-(open+0.4)) > 1e-9 {
+		t.Errorf("penalty beside a blue jammer = %.3f, want %.3f", got, open+0.4)
+	}
+	if got := red.linkPenalty(member, leader, radioMaxReliableRange); got != open {
+		t.Errorf("own jammer changed the penalty from %.3f to %.3f", open, got)
+	}
+	if outcome, _ := radioLinkOutcome(blue, member, leader, radioMaxReliableRange, 0); outcome != radioDeliveryGarbled {
+		t.Errorf("outcome beside a blue jammer = %d, want garbled", outcome)
+	}
+}
@@ -0,0 +1,388 @@
+package game
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// replayFormatVersion is bumped whenever the Recording layout changes.
+const replayFormatVersion = 1
+
+// Recording is a compact per-tick record of a whole battle: every soldier's
+// position and visible state each tick, plus a stream of discrete events
+// (goal changes, shots, wounds, deaths, radio traffic, orders). It carries
+// enough map information to rebuild the terrain, so a ReplayViewer can play
+// the battle back without re-running the simulation.
+type Recording struct {
+	Version  int             `json:"version"`
+	Source   string          `json:"source"` // "game" or "headless"
+	Name     string          `json:"name,omitempty"`
+	Seed     int64           `json:"seed"`
+	Map      ScenarioMap     `json:"map"`
+	Soldiers []ReplaySoldier `json:"soldiers"`
+	Squads   []ReplaySquad   `json:"squads,omitempty"`
+	Frames   []ReplayFrame   `json:"frames"`
+	Events   []ReplayEvent   `json:"events,omitempty"`
+}
+
+// ReplaySoldier is the static roster entry for one recorded soldier.
+type ReplaySoldier struct {
+	ID        int        `json:"id"`
+	Label     string     `json:"label"`
+	Team      string     `json:"team"`
+	Start     [2]float64 `json:"start"`
+	Objective [2]float64 `json:"objective"`
+}
+
+// ReplaySquad records squad membership; the first member is the leader.
+type ReplaySquad struct {
+	ID      int    `json:"id"`
+	Team    string `json:"team"`
+	Members []int  `json:"members"`
+}
+
+// ReplayFrame is the state of every soldier at the end of one tick.
+// Soldiers is index-aligned with Recording.Soldiers.
+type ReplayFrame struct {
+	Tick     int           `json:"t"`
+	Soldiers []ReplayState `json:"s"`
+}
+
+// ReplayState is the per-tick state needed to draw one soldier. Positions are
+// rounded to 0.1px and headings to 0.01rad to keep recordings small.
+type ReplayState struct {
+	X       float32      `json:"x"`
+	Y       float32      `json:"y"`
+	Heading float32      `json:"h"`
+	State   SoldierState `json:"st"`
+	Stance  Stance       `json:"sn"`
+	Goal    GoalKind     `json:"g"`
+	Health  float32      `json:"hp"` // body.HealthFraction
+}
+
+// ReplayEventKind names a discrete recorded event.
+type ReplayEventKind string
+
+const (
+	ReplayEventGoal  ReplayEventKind = "goal"
+	ReplayEventShot  ReplayEventKind = "shot"
+	ReplayEventWound ReplayEventKind = "wound"
+	ReplayEventDeath ReplayEventKind = "death"
+	ReplayEventEvac  ReplayEventKind = "evac"
+	ReplayEventRadio ReplayEventKind = "radio"
+	ReplayEventOrder ReplayEventKind = "order"
+)
+
+// ReplayEvent is one discrete event. Soldier is the acting soldier (shooter,
+// casualty, radio sender, issuing leader). Target is the shot target for
+// shots, the squad ID for orders and -1 otherwise. Code carries the GoalKind
+// for goal events and the OfficerCommandKind for orders.
+type ReplayEvent struct {
+	Tick    int             `json:"t"`
+	Kind    ReplayEventKind `json:"k"`
+	Soldier int             `json:"id"`
+	Target  int             `json:"target"`
+	Code    int             `json:"code,omitempty"`
+	X       float32         `json:"x,omitempty"`
+	Y       float32         `json:"y,omitempty"`
+	ToX     float32         `json:"tx,omitempty"`
+	ToY     float32         `json:"ty,omitempty"`
+	Hit     bool            `json:"hit,omitempty"`
+	Text    string          `json:"text,omitempty"`
+}
+
+// Recorder builds a Recording by sampling the sim at the end of each tick.
+// It diffs against the previous tick to emit goal, wound, death, evacuation
+// and order events; shots and radio lines are taken from the per-tick buffers.
+type Recorder struct {
+	rec        *Recording
+	slot       map[int]int // soldier ID -> index into rec.Soldiers
+	prevGoal   []GoalKind
+	prevWounds []int
+	prevDead   []bool
+	prevOrder  map[int]int // squad ID -> last recorded order ID
+}
+
+// NewRecorder starts a recording of the given soldiers and squads. source is
+// "game" or "headless"; a zero m.Seed is replaced by seed so the viewer can
+// regenerate the same terrain. Call Capture once straight away to record the
+// starting positions.
+func NewRecorder(source, name string, seed int64, m ScenarioMap, soldiers []*Soldier, squads []*Squad) *Recorder {
+	if m.Seed == 0 {
+		m.Seed = seed
+	}
+	r := &Recorder{
+		rec: &Recording{
+			Version: replayFormatVersion,
+			Source:  source,
+			Name:    name,
+			Seed:    seed,
+			Map:     m,
+		},
+		slot:       make(map[int]int, len(soldiers)),
+		prevGoal:   make([]GoalKind, len(soldiers)),
+		prevWounds: make([]int, len(soldiers)),
+		prevDead:   make([]bool, len(soldiers)),
+		prevOrder:  make(map[int]int, len(squads)),
+	}
+	for i, s := range soldiers {
+		r.slot[s.id] = i
+		r.rec.Soldiers = append(r.rec.Soldiers, ReplaySoldier{
+			ID:        s.id,
+			Label:     s.label,
+			Team:      teamLabel(s.team),
+			Start:     s.startTarget,
+			Objective: s.endTarget,
+		})
+		r.prevGoal[i] = s.blackboard.CurrentGoal
+		r.prevWounds[i] = s.body.WoundCount()
+		r.prevDead[i] = s.state == SoldierStateDead
+	}
+	for _, sq := range squads {
+		rs := ReplaySquad{ID: sq.ID, Team: teamLabel(sq.Team)}
+		if sq.Leader != nil {
+			rs.Members = append(rs.Members, sq.Leader.id)
+		}
+		for _, m := range sq.Members {
+			if m != sq.Leader {
+				rs.Members = append(rs.Members, m.id)
+			}
+		}
+		r.rec.Squads = append(r.rec.Squads, rs)
+		r.prevOrder[sq.ID] = sq.ActiveOrder.ID
+	}
+	return r
+}
+
+// Capture records the end-of-tick state. shots is CombatManager.Shots for
+// the same tick.
+func (r *Recorder) Capture(tick int, soldiers []*Soldier, squads []*Squad, shots []ShotEvent) {
+	for _, sh := range shots {
+		r.event(ReplayEvent{
+			Tick: tick, Kind: ReplayEventShot, Soldier: sh.ShooterID, Target: sh.TargetID,
+			X: round1(sh.FromX), Y: round1(sh.FromY), ToX: round1(sh.ToX), ToY: round1(sh.ToY),
+			Hit: sh.Hit,
+		})
+	}
+	for _, s := range soldiers {
+		i, ok := r.slot[s.id]
+		if !ok {
+			continue
+		}
+		if n := s.body.WoundCount(); n > r.prevWounds[i] {
+			for _, w := range s.body.Wounds[r.prevWounds[i]:n] {
+				text := fmt.Sprintf("%s (%s)", w.Region, w.Severity)
+				if w.Stopped {
+					text = fmt.Sprintf("%s (stopped by %s)", w.Region, w.Armour)
+				}
+				r.event(ReplayEvent{
+					Tick: tick, Kind: ReplayEventWound, Soldier: s.id, Target: -1,
+					X: round1(s.x), Y: round1(s.y),
+					Text: text,
+				})
+			}
+			r.prevWounds[i] = n
+		}
+		if dead := s.state == SoldierStateDead; dead && !r.prevDead[i] {
+			kind := ReplayEventDeath
+			if s.casualty.Evacuated {
+				kind = ReplayEventEvac
+			}
+			r.event(ReplayEvent{Tick: tick, Kind: kind, Soldier: s.id, Target: -1, X: round1(s.x), Y: round1(s.y)})
+			r.prevDead[i] = true
+		}
+		if g := s.blackboard.CurrentGoal; g != r.prevGoal[i] {
+			r.event(ReplayEvent{
+				Tick: tick, Kind: ReplayEventGoal, Soldier: s.id, Target: -1,
+				Code: int(g), Text: fmt.Sprintf("%s -> %s", r.prevGoal[i], g),
+			})
+			r.prevGoal[i] = g
+		}
+	}
+	for _, sq := range squads {
+		for _, line := range sq.radioChatLines {
+			if line.Tick != tick {
+				continue
+			}
+			r.event(ReplayEvent{
+				Tick: tick, Kind: ReplayEventRadio, Soldier: line.SenderID, Target: -1,
+				Text: fmt.Sprintf("%s %s->%s %s (%s)", line.Net, line.Sender, line.Receiver, line.Message, line.Quality),
+			})
+		}
+		o := sq.ActiveOrder
+		if o.State == OfficerOrderActive && o.ID != r.prevOrder[sq.ID] {
+			leader := -1
+			if sq.Leader != nil {
+				leader = sq.Leader.id
+			}
+			r.event(ReplayEvent{
+				Tick: tick, Kind: ReplayEventOrder, Soldier: leader, Target: sq.ID,
+				Code: int(o.Kind), X: round1(o.TargetX), Y: round1(o.TargetY),
+				Text: fmt.Sprintf("SQ-%d %s", sq.ID, o.Kind),
+			})
+			r.prevOrder[sq.ID] = o.ID
+		}
+	}
+	r.rec.Frames = append(r.rec.Frames, r.frame(tick, soldiers))
+}
+
+// Recording returns the recording built so far.
+func (r *Recorder) Recording() *Recording {
+	return r.rec
+}
+
+func (r *Recorder) event(ev ReplayEvent) {
+	r.rec.Events = append(r.rec.Events, ev)
+}
+
+func (r *Recorder) frame(tick int, soldiers []*Soldier) ReplayFrame {
+	f := ReplayFrame{Tick: tick, Soldiers: make([]ReplayState, len(r.rec.Soldiers))}
+	for _, s := range soldiers {
+		i, ok := r.slot[s.id]
+		if !ok {
+			continue
+		}
+		f.Soldiers[i] = ReplayState{
+			X:       round1(s.x),
+			Y:       round1(s.y),
+			Heading: float32(math.Round(s.vision.Heading*100) / 100),
+			State:   s.state,
+			Stance:  s.profile.Stance,
+			Goal:    s.blackboard.CurrentGoal,
+			Health:  float32(math.Round(s.body.HealthFraction()*1000) / 1000),
+		}
+	}
+	return f
+}
+
+func round1(v float64) float32 {
+	return float32(math.Round(v*10) / 10)
+}
+
+// FirstTick returns the tick of the first recorded frame.
+func (rec *Recording) FirstTick() int {
+	if len(rec.Frames) == 0 {
+		return 0
+	}
+	return rec.Frames[0].Tick
+}
+
+// LastTick returns the tick of the last recorded frame.
+func (rec *Recording) LastTick() int {
+	if len(rec.Frames) == 0 {
+		return 0
+	}
+	return rec.Frames[len(rec.Frames)-1].Tick
+}
+
+// FrameAt returns the last frame at or before tick, clamped to the recording.
+func (rec *Recording) FrameAt(tick int) *ReplayFrame {
+	if len(rec.Frames) == 0 {
+		return nil
+	}
+	i := sort.Search(len(rec.Frames), func(i int) bool { return rec.Frames[i].Tick > tick })
+	if i > 0 {
+		i--
+	}
+	return &rec.Frames[i]
+}
+
+// EventsBetween returns the events with from <= Tick <= to, in order.
+func (rec *Recording) EventsBetween(from, to int) []ReplayEvent {
+	lo := sort.Search(len(rec.Events), func(i int) bool { return rec.Events[i].Tick >= from })
+	hi := sort.Search(len(rec.Events), func(i int) bool { return rec.Events[i].Tick > to })
+	if lo >= hi {
+		return nil
+	}
+	return rec.Events[lo:hi]
+}
+
+// Validate checks that the recording is internally consistent.
+func (rec *Recording) Validate() error {
+	if rec.Version != replayFormatVersion {
+		return fmt.Errorf("unsupported recording version %d (want %d)", rec.Version, replayFormatVersion)
+	}
+	if rec.Map.Width <= 0 || rec.Map.Height <= 0 {
+		return fmt.Errorf("map size must be positive, got %dx%d", rec.Map.Width, rec.Map.Height)
+	}
+	for _, rs := range rec.Soldiers {
+		if _, err := parseTeam(rs.Team); err != nil {
+			return fmt.Errorf("soldier %d: %w", rs.ID, err)
+		}
+	}
+	prev := math.MinInt
+	for i, f := range rec.Frames {
+		if len(f.Soldiers) != len(rec.Soldiers) {
+			return fmt.Errorf("frame %d: %d soldier states, roster has %d", i, len(f.Soldiers), len(rec.Soldiers))
+		}
+		if f.Tick <= prev {
+			return fmt.Errorf("frame %d: tick %d is not after %d", i, f.Tick, prev)
+		}
+		prev = f.Tick
+	}
+	return nil
+}
+
+// Write encodes the recording as gzip-compressed JSON.
+func (rec *Recording) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(rec); err != nil {
+		return fmt.Errorf("encode recording: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress recording: %w", err)
+	}
+	return nil
+}
+
+// Save writes the recording to path.
+func (rec *Recording) Save(path string) error {
+	f, err := os.Create(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return fmt.Errorf("create recording %s: %w", path, err)
+	}
+	if err := rec.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("recording %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close recording %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadRecording decodes and validates a gzip-compressed JSON recording.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	var rec Recording
+	if err := json.NewDecoder(zr).Decode(&rec); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if err := rec.Validate(); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// LoadRecording reads and validates a recording from disk.
+func LoadRecording(path string) (*Recording, error) {
+	f, err := os.Open(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("open recording %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	rec, err := ReadRecording(f)
+	if err != nil {
+		return nil, fmt.Errorf("recording %s: %w", path, err)
+	}
+	return rec, nil
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+// recordedSkirmish runs a short close-range fight with recording enabled.
+func recordedSkirmish(t *testing.T, ticks int) (*TestSim, *Recording) {
+	t.Helper()
+	ts := NewTestSim(
+		WithSeed(7),
+		WithMapSize(800, 400),
+		WithBuilding(380, 40, 40, 80),
+		WithRedSoldier(0, 100, 200, 700, 200),
+		WithRedSoldier(1, 100, 240, 700, 240),
+		WithBlueSoldier(2, 500, 200, 100, 200),
+		WithBlueSoldier(3, 500, 240, 100, 240),
+		WithRedSquad(0, 1),
+		WithBlueSquad(2, 3),
+	)
+	ts.StartRecording("skirmish", 7, ScenarioMap{})
+	ts.RunTicks(ticks)
+	return ts, ts.Recording()
+}
+
+func TestRecorder_CapturesEveryTick(t *testing.T) {
+	const ticks = 600
+	ts, rec := recordedSkirmish(t, ticks)
+
+	if err := rec.Validate(); err != nil {
+		t.Fatalf("recording invalid: %v", err)
+	}
+	if got := len(rec.Frames); got != ticks+1 {
+		t.Fatalf("frames = %d, want %d (start + one per tick)", got, ticks+1)
+	}
+	if rec.FirstTick() != 0 || rec.LastTick() != ticks {
+		t.Fatalf("tick range = %d..%d, want 0..%d", rec.FirstTick(), rec.LastTick(), ticks)
+	}
+	if rec.Map.Width != 800 || rec.Map.Seed != 7 || len(rec.Map.Buildings) != 1 {
+		t.Fatalf("map not captured from sim: %+v", rec.Map)
+	}
+
+	last := rec.Frames[len(rec.Frames)-1]
+	for i, s := range ts.Soldiers {
+		st := last.Soldiers[i]
+		if st.State != s.state || st.Goal != s.blackboard.CurrentGoal {
+			t.Errorf("%s: final frame state=%s goal=%s, sim state=%s goal=%s",
+				s.label, st.State, st.Goal, s.state, s.blackboard.CurrentGoal)
+		}
+		if dx, dy := float64(st.X)-s.x, float64(st.Y)-s.y; dx*dx+dy*dy > 0.1 {
+			t.Errorf("%s: final frame at (%.1f,%.1f), sim at (%.1f,%.1f)", s.label, st.X, st.Y, s.x, s.y)
+		}
+	}
+
+	counts := map[ReplayEventKind]int{}
+	for _, ev := range rec.Events {
+		counts[ev.Kind]++
+	}
+	if counts[ReplayEventShot] == 0 || counts[ReplayEventGoal] == 0 {
+		t.Fatalf("expected shots and goal changes at 400px, got %v", counts)
+	}
+	t.Logf("events: %v", counts)
+}
+
+func TestRecording_RoundTrip(t *testing.T) {
+	_, rec := recordedSkirmish(t, 300)
+
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	t.Logf("300 ticks x %d soldiers = %d bytes compressed", len(rec.Soldiers), buf.Len())
+
+	got, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(got.Frames) != len(rec.Frames) || len(got.Events) != len(rec.Events) {
+		t.Fatalf("round trip lost data: frames %d/%d events %d/%d",
+			len(got.Frames), len(rec.Frames), len(got.Events), len(rec.Events))
+	}
+	if got.Frames[150].Soldiers[2] != rec.Frames[150].Soldiers[2] {
+		t.Fatalf("frame 150 differs after round trip")
+	}
+}
+
+func TestRecording_FrameAtAndEventsBetween(t *testing.T) {
+	_, rec := recordedSkirmish(t, 120)
+
+	if f := rec.FrameAt(-5); f.Tick != 0 {
+		t.Errorf("FrameAt(-5).Tick = %d, want 0", f.Tick)
+	}
+	if f := rec.FrameAt(60); f.Tick != 60 {
+		t.Errorf("FrameAt(60).Tick = %d, want 60", f.Tick)
+	}
+	if f := rec.FrameAt(10000); f.Tick != 120 {
+		t.Errorf("FrameAt(10000).Tick = %d, want 120", f.Tick)
+	}
+	for _, ev := range rec.EventsBetween(30, 60) {
+		if ev.Tick < 30 || ev.Tick > 60 {
+			t.Fatalf("EventsBetween(30,60) returned tick %d", ev.Tick)
+		}
+	}
+}
+
+func TestReplayViewer_SeekRestoresRecordedState(t *testing.T) {
+	_, rec := recordedSkirmish(t, 400)
+	v := newReplayViewer(rec)
+
+	for _, tick := range []int{400, 37, 250, 0} {
+		v.Seek(tick)
+		f := rec.FrameAt(tick)
+		for i, s := range v.order {
+			st := f.Soldiers[i]
+			if s.x != float64(st.X) || s.y != float64(st.Y) || s.state != st.State {
+				t.Fatalf("tick %d: %s at (%.1f,%.1f) %s, recorded (%.1f,%.1f) %s",
+					tick, s.label, s.x, s.y, s.state, st.X, st.Y, st.State)
+			}
+		}
+		if v.g.tick != tick {
+			t.Fatalf("game tick = %d after Seek(%d)", v.g.tick, tick)
+		}
+	}
+
+	v.Seek(-100)
+	if v.Tick() != 0 {
+		t.Fatalf("Seek(-100) cursor = %d, want 0", v.Tick())
+	}
+	v.Seek(1 << 20)
+	if v.Tick() != 400 {
+		t.Fatalf("Seek past end cursor = %d, want 400", v.Tick())
+	}
+}
+
+func TestRecording_RejectsMismatchedFrames(t *testing.T) {
+	_, rec := recordedSkirmish(t, 10)
+	rec.Frames[3].Soldiers = rec.Frames[3].Soldiers[:1]
+	if err := rec.Validate(); err == nil {
+		t.Fatal("expected error for short frame")
+	}
+}
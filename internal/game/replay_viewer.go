@@ -0,0 +1,490 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	replayLogWindowTicks = 600 // thought log shows events from the last ~10s
+	replayBigStepTicks   = 60  // Shift + [ / ] step size
+	replayBarHeight      = 10  // scrubber bar height in the bottom border
+)
+
+// Scrubber marker bits, one byte per bar pixel column.
+const (
+	replayMarkWound uint8 = 1 << iota
+	replayMarkDeath
+	replayMarkOrder
+)
+
+// ReplayViewer plays a Recording back through the normal Game renderer. The
+// sim never runs: each tick the recorded frame is written into the soldiers
+// and Game.Draw does the rest. Controls (in addition to the usual camera,
+// overlay and P/,/. speed keys):
+//
+//	[ / ]        step one tick back / forward (Shift = 60 ticks)
+//	Home / End   jump to the first / last tick
+//	PgUp / PgDn  jump to the previous / next wound, death or order
+//	Backspace    toggle reverse playback
+//	click bar    jump to tick (drag to scrub)
+type ReplayViewer struct {
+	g   *Game
+	rec *Recording
+
+	order  []*Soldier             // index-aligned with rec.Soldiers
+	byID   map[int]*Soldier       // soldier ID -> soldier
+	orders map[int][]*ReplayEvent // squad ID -> order events, by tick
+	evacAt map[int]int            // soldier ID -> tick they were evacuated
+
+	cursor  int // tick currently shown
+	reverse bool
+	accum   float64
+	markers []uint8 // per-column scrubber markers
+
+	prevKeys  map[ebiten.Key]bool
+	scrubbing bool
+}
+
+// NewReplayViewer builds a viewer for rec, positioned at its first tick.
+func NewReplayViewer(rec *Recording) *ReplayViewer {
+	v := newReplayViewer(rec)
+	v.g.initRenderBuffers()
+	return v
+}
+
+// newReplayViewer builds the viewer state without any Ebiten resources.
+func newReplayViewer(rec *Recording) *ReplayViewer {
+	v := &ReplayViewer{
+		g:        newReplayGame(rec),
+		rec:      rec,
+		byID:     make(map[int]*Soldier, len(rec.Soldiers)),
+		orders:   make(map[int][]*ReplayEvent),
+		evacAt:   make(map[int]int),
+		prevKeys: make(map[ebiten.Key]bool),
+	}
+	g := v.g
+	for _, rs := range rec.Soldiers {
+		team, _ := parseTeam(rs.Team)
+		s := NewSoldier(rs.ID, rs.Start[0], rs.Start[1], team, rs.Start, rs.Objective,
+			g.navGrid, g.covers, g.buildings, g.thoughtLog, &g.tick, g.tacticalMap)
+		s.label = rs.Label
+		s.tileMap = g.tileMap
+		s.setIntel(g.intel)
+		if team == TeamRed {
+			g.soldiers = append(g.soldiers, s)
+		} else {
+			g.opfor = append(g.opfor, s)
+		}
+		v.order = append(v.order, s)
+		v.byID[rs.ID] = s
+	}
+	for _, rsq := range rec.Squads {
+		team, _ := parseTeam(rsq.Team)
+		var members []*Soldier
+		for _, id := range rsq.Members {
+			if s, ok := v.byID[id]; ok {
+				members = append(members, s)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		sq := NewSquad(rsq.ID, team, members)
+		sq.buildingFootprints = g.buildingFootprints
+		g.squads = append(g.squads, sq)
+	}
+	for i := range rec.Events {
+		ev := &rec.Events[i]
+		switch ev.Kind {
+		case ReplayEventOrder:
+			v.orders[ev.Target] = append(v.orders[ev.Target], ev)
+		case ReplayEventEvac:
+			v.evacAt[ev.Soldier] = ev.Tick
+		}
+	}
+	v.buildMarkers()
+	g.simSpeed = 0
+	v.Seek(rec.FirstTick())
+	return v
+}
+
+// newReplayGame builds a Game with the recording's terrain and no soldiers.
+func newReplayGame(rec *Recording) *Game {
+	m := rec.Map
+	g := &Game{
+		World:    &World{gameWidth: m.Width, gameHeight: m.Height},
+		width:    borderWidth + m.Width + borderWidth + logPanelWidth,
+		height:   borderWidth + m.Height + borderWidth,
+		offX:     borderWidth,
+		offY:     borderWidth,
+		showHUD:  true,
+		prevKeys: make(map[ebiten.Key]bool),
+		mapSeed:  m.Seed,
+	}
+	if m.Generate {
+		g.World = newWorldMap(m.Seed, m.Width, m.Height)
+	} else {
+		g.tileMap = NewTileMap(m.Width/cellSize, m.Height/cellSize)
+		// Scenario buildings are solid blocks; the renderer expects 1-cell walls.
+		for _, b := range m.Buildings {
+			for y := b.Y; y < b.Y+b.H; y += cellSize {
+				for x := b.X; x < b.X+b.W; x += cellSize {
+					g.buildings = append(g.buildings, rect{x: x, y: y, w: cellSize, h: cellSize})
+				}
+			}
+		}
+		g.initTileMap()
+		g.navGrid = NewNavGrid(g.gameWidth, g.gameHeight, g.buildings, soldierRadius, nil, nil)
+		g.tacticalMap = NewTacticalMap(g.gameWidth, g.gameHeight, g.buildings, nil, nil)
+	}
+	g.thoughtLog = NewThoughtLog()
+	g.combat = NewCombatManager(m.Seed)
+	g.intel = NewIntelStore(g.gameWidth, g.gameHeight)
+	g.intel.SetTileMap(g.tileMap)
+	g.initTerrainPatches()
+	g.initViewState()
+	return g
+}
+
+// Tick returns the tick currently shown.
+func (v *ReplayViewer) Tick() int {
+	return v.cursor
+}
+
+// Seek shows the recorded state at tick, clamped to the recording.
+func (v *ReplayViewer) Seek(tick int) {
+	first, last := v.rec.FirstTick(), v.rec.LastTick()
+	if tick < first {
+		tick = first
+	}
+	if tick > last {
+		tick = last
+	}
+	v.cursor = tick
+	f := v.rec.FrameAt(tick)
+	if f == nil {
+		return
+	}
+	g := v.g
+	g.tick = f.Tick
+	for i, st := range f.Soldiers {
+		s := v.order[i]
+		s.x, s.y = float64(st.X), float64(st.Y)
+		s.vision.Heading = float64(st.Heading)
+		s.state = st.State
+		evacTick, evacuated := v.evacAt[s.id]
+		s.casualty.Evacuated = evacuated && f.Tick >= evacTick
+		s.profile.Stance = st.Stance
+		s.blackboard.CurrentGoal = st.Goal
+		for r := range s.body.HP {
+			s.body.HP[r] = s.body.MaxHP[r] * float64(st.Health)
+		}
+	}
+	v.applyTracers(f.Tick)
+	v.applyOrders(f.Tick)
+	v.applyLog(f.Tick)
+}
+
+// applyTracers recreates the tracers and muzzle flashes still alive at tick.
+func (v *ReplayViewer) applyTracers(tick int) {
+	cm := v.g.combat
+	cm.tracers = cm.tracers[:0]
+	cm.flashes = cm.flashes[:0]
+	for _, ev := range v.rec.EventsBetween(tick-tracerLifetime+1, tick) {
+		if ev.Kind != ReplayEventShot {
+			continue
+		}
+		shooter := v.byID[ev.Soldier]
+		if shooter == nil {
+			continue
+		}
+		age := tick - ev.Tick + 1
+		if age < tracerLifetime {
+			cm.tracers = append(cm.tracers, &Tracer{
+				fromX: float64(ev.X), fromY: float64(ev.Y),
+				toX: float64(ev.ToX), toY: float64(ev.ToY),
+				hit: ev.Hit, team: shooter.team,
+				age: age, fractionalAge: float64(age),
+			})
+		}
+		if age < flashLifetime {
+			cm.flashes = append(cm.flashes, &MuzzleFlash{
+				x: float64(ev.X), y: float64(ev.Y),
+				angle: math.Atan2(float64(ev.ToY-ev.Y), float64(ev.ToX-ev.X)),
+				team:  shooter.team, age: age,
+			})
+		}
+	}
+}
+
+// applyOrders sets each squad's ActiveOrder to the last order issued at or
+// before tick. An order stays drawn until the squad's next order.
+func (v *ReplayViewer) applyOrders(tick int) {
+	for _, sq := range v.g.squads {
+		evs := v.orders[sq.ID]
+		i := sort.Search(len(evs), func(i int) bool { return evs[i].Tick > tick })
+		if i == 0 {
+			sq.ActiveOrder = OfficerOrder{Kind: CmdNone, State: OfficerOrderInactive}
+			continue
+		}
+		ev := evs[i-1]
+		expires := v.rec.LastTick() + 1
+		if i < len(evs) {
+			expires = evs[i].Tick
+		}
+		sq.ActiveOrder = OfficerOrder{
+			Kind:        OfficerCommandKind(ev.Code),
+			IssuedTick:  ev.Tick,
+			ExpiresTick: expires,
+			TargetX:     float64(ev.X),
+			TargetY:     float64(ev.Y),
+			State:       OfficerOrderActive,
+		}
+	}
+}
+
+// applyLog refills the thought log with the recent non-shot events.
+func (v *ReplayViewer) applyLog(tick int) {
+	tl := v.g.thoughtLog
+	tl.Clear()
+	for _, ev := range v.rec.EventsBetween(tick-replayLogWindowTicks, tick) {
+		s := v.byID[ev.Soldier]
+		label, team := "--", TeamRed
+		if s != nil {
+			label, team = s.label, s.team
+		}
+		switch ev.Kind {
+		case ReplayEventGoal:
+			tl.Add(ev.Tick, label, team, "goal "+ev.Text, LogCatThought)
+		case ReplayEventWound:
+			tl.Add(ev.Tick, label, team, "hit "+ev.Text, LogCatThought)
+		case ReplayEventDeath:
+			tl.Add(ev.Tick, label, team, "killed", LogCatThought)
+		case ReplayEventEvac:
+			tl.Add(ev.Tick, label, team, "evacuated", LogCatThought)
+		case ReplayEventRadio:
+			tl.Add(ev.Tick, label, team, "radio "+ev.Text, LogCatRadio)
+		case ReplayEventOrder:
+			tl.Add(ev.Tick, label, team, "order "+ev.Text, LogCatSquad)
+		}
+	}
+}
+
+// buildMarkers bins wounds, deaths and orders into scrubber pixel columns.
+func (v *ReplayViewer) buildMarkers() {
+	v.markers = make([]uint8, v.g.gameWidth)
+	for _, ev := range v.rec.Events {
+		var bit uint8
+		switch ev.Kind {
+		case ReplayEventWound:
+			bit = replayMarkWound
+		case ReplayEventDeath:
+			bit = replayMarkDeath
+		case ReplayEventOrder:
+			bit = replayMarkOrder
+		default:
+			continue
+		}
+		v.markers[v.columnForTick(ev.Tick)] |= bit
+	}
+}
+
+func (v *ReplayViewer) columnForTick(tick int) int {
+	first, last := v.rec.FirstTick(), v.rec.LastTick()
+	if last <= first {
+		return 0
+	}
+	col := (tick - first) * (len(v.markers) - 1) / (last - first)
+	return max(0, min(len(v.markers)-1, col))
+}
+
+func (v *ReplayViewer) tickForColumn(col int) int {
+	first, last := v.rec.FirstTick(), v.rec.LastTick()
+	if len(v.markers) <= 1 {
+		return first
+	}
+	return first + col*(last-first)/(len(v.markers)-1)
+}
+
+// barRect returns the scrubber bar in screen space (in the bottom border).
+func (v *ReplayViewer) barRect() rectI {
+	g := v.g
+	return rectI{
+		x: g.offX,
+		y: g.offY + g.gameHeight + (borderWidth-replayBarHeight)/2,
+		w: g.gameWidth,
+		h: replayBarHeight,
+	}
+}
+
+// notableEvent reports whether ev is a PgUp/PgDn jump target.
+func notableEvent(ev *ReplayEvent) bool {
+	return ev.Kind == ReplayEventWound || ev.Kind == ReplayEventDeath || ev.Kind == ReplayEventOrder
+}
+
+// nextNotableTick returns the tick of the next (dir > 0) or previous notable
+// event relative to the cursor, or the cursor itself if there is none.
+func (v *ReplayViewer) nextNotableTick(dir int) int {
+	evs := v.rec.Events
+	if dir > 0 {
+		i := sort.Search(len(evs), func(i int) bool { return evs[i].Tick > v.cursor })
+		for ; i < len(evs); i++ {
+			if notableEvent(&evs[i]) {
+				return evs[i].Tick
+			}
+		}
+		return v.cursor
+	}
+	i := sort.Search(len(evs), func(i int) bool { return evs[i].Tick >= v.cursor }) - 1
+	for ; i >= 0; i-- {
+		if notableEvent(&evs[i]) {
+			return evs[i].Tick
+		}
+	}
+	return v.cursor
+}
+
+// edge reports a fresh key press and records the key for next frame.
+func (v *ReplayViewer) edge(cur map[ebiten.Key]bool, k ebiten.Key) bool {
+	cur[k] = ebiten.IsKeyPressed(k)
+	return cur[k] && !v.prevKeys[k]
+}
+
+// Update handles input and advances the playback cursor.
+func (v *ReplayViewer) Update() error {
+	g := v.g
+	mx, my := ebiten.CursorPosition()
+	bar := v.barRect()
+	mouseDown := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	onBar := bar.contains(mx, my)
+	if onBar || v.scrubbing {
+		// Keep scrubber clicks away from the soldier inspector.
+		g.prevMouseLeft = true
+	}
+
+	g.handleInput()
+	if g.pendingExit == ErrRestart {
+		// "Restart" rewinds the replay instead of starting a new battle.
+		g.pendingExit = nil
+		g.menuOpen = false
+		g.aarOpen = false
+		g.simSpeed = 0
+		v.reverse = false
+		v.Seek(v.rec.FirstTick())
+		return nil
+	}
+	if g.pendingExit != nil {
+		return g.pendingExit
+	}
+	if g.menuOpen {
+		return nil
+	}
+
+	switch {
+	case mouseDown && (onBar || v.scrubbing):
+		v.scrubbing = true
+		v.Seek(v.tickForColumn(mx - bar.x))
+	case !mouseDown:
+		v.scrubbing = false
+	}
+
+	cur := map[ebiten.Key]bool{}
+	step := 1
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		step = replayBigStepTicks
+	}
+	if v.edge(cur, ebiten.KeyBracketLeft) {
+		g.simSpeed = 0
+		v.Seek(v.cursor - step)
+	}
+	if v.edge(cur, ebiten.KeyBracketRight) {
+		g.simSpeed = 0
+		v.Seek(v.cursor + step)
+	}
+	if v.edge(cur, ebiten.KeyHome) {
+		v.Seek(v.rec.FirstTick())
+	}
+	if v.edge(cur, ebiten.KeyEnd) {
+		v.Seek(v.rec.LastTick())
+	}
+	if v.edge(cur, ebiten.KeyPageUp) {
+		v.Seek(v.nextNotableTick(-1))
+	}
+	if v.edge(cur, ebiten.KeyPageDown) {
+		v.Seek(v.nextNotableTick(1))
+	}
+	if v.edge(cur, ebiten.KeyBackspace) {
+		v.reverse = !v.reverse
+	}
+	v.prevKeys = cur
+
+	if g.simSpeed > 0 && !v.scrubbing {
+		v.accum += g.simSpeed
+		if n := int(v.accum); n > 0 {
+			v.accum -= float64(n)
+			if v.reverse {
+				n = -n
+			}
+			v.Seek(v.cursor + n)
+			if v.cursor == v.rec.FirstTick() || v.cursor == v.rec.LastTick() {
+				g.simSpeed = 0
+			}
+		}
+	}
+	return nil
+}
+
+// Draw renders the battlefield via Game.Draw and overlays the scrubber.
+func (v *ReplayViewer) Draw(screen *ebiten.Image) {
+	v.g.Draw(screen)
+	v.drawScrubber(screen)
+}
+
+// Layout matches the underlying game's fixed resolution.
+func (v *ReplayViewer) Layout(w, h int) (int, int) {
+	return v.g.Layout(w, h)
+}
+
+func (v *ReplayViewer) drawScrubber(screen *ebiten.Image) {
+	bar := v.barRect()
+	bx, by := float32(bar.x), float32(bar.y)
+	bw, bh := float32(bar.w), float32(bar.h)
+	vector.FillRect(screen, bx, by, bw, bh, color.RGBA{R: 20, G: 26, B: 20, A: 255}, false)
+	played := float32(v.columnForTick(v.cursor))
+	vector.FillRect(screen, bx, by, played, bh, color.RGBA{R: 55, G: 85, B: 60, A: 255}, false)
+
+	for col, m := range v.markers {
+		if m == 0 {
+			continue
+		}
+		x := bx + float32(col)
+		switch {
+		case m&replayMarkDeath != 0:
+			vector.StrokeLine(screen, x, by-3, x, by+bh+3, 1, color.RGBA{R: 240, G: 240, B: 240, A: 230}, false)
+		case m&replayMarkWound != 0:
+			vector.StrokeLine(screen, x, by, x, by+bh, 1, color.RGBA{R: 230, G: 60, B: 50, A: 220}, false)
+		case m&replayMarkOrder != 0:
+			vector.StrokeLine(screen, x, by+bh/2, x, by+bh, 1, color.RGBA{R: 240, G: 210, B: 60, A: 200}, false)
+		}
+	}
+	vector.StrokeLine(screen, bx+played, by-4, bx+played, by+bh+4, 2, color.RGBA{R: 255, G: 255, B: 255, A: 255}, false)
+	vector.StrokeRect(screen, bx, by, bw, bh, 1, color.RGBA{R: 75, G: 110, B: 75, A: 255}, false)
+
+	dir := ">"
+	if v.reverse {
+		dir = "<"
+	}
+	if v.g.simSpeed <= 0 {
+		dir = "||"
+	}
+	label := fmt.Sprintf("REPLAY %s  tick %d/%d  %s %.1fx   [ ] step  Home/End  PgUp/PgDn events  Bksp reverse",
+		v.rec.Name, v.cursor, v.rec.LastTick(), dir, v.g.simSpeed)
+	ebitenutil.DebugPrintAt(screen, label, v.g.offX, 4)
+}
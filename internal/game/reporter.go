@@ -16,7 +16,8 @@ type SquadReport struct {
 	Team               Team
 	SquadID            int
 	Alive              int
-	Dead               int
+	Dead               int // killed; evacuated members are counted in Evacuated
+	Evacuated          int
 	Intent             SquadIntentKind
 	Broken             bool
 	Stress             float64
@@ -62,7 +63,9 @@ type SimReport struct {
 
 	// Per-team aggregate stats.
 	RedAlive, BlueAlive     int
-	RedDead, BlueDead       int
+	RedDead, BlueDead       int // killed
+	RedEvacuated            int // left the field through an evacuation point
+	BlueEvacuated           int
 	RedInjured, BlueInjured int // health < max but > 0
 
 	// Visibility ratios.
@@ -147,7 +150,9 @@ func (r *SimReporter) Collect(tick int, soldiers []*Soldier, opfor []*Soldier, s
 			CasualtyRate: sq.CasualtyRate,
 		}
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.casualty.Evacuated {
+				sr.Evacuated++
+			} else if m.state == SoldierStateDead {
 				sr.Dead++
 			} else {
 				sr.Alive++
@@ -216,7 +221,15 @@ func (r *SimReporter) tallySoldier(s *Soldier, report *SimReport, team Team) {
 		goals = report.BlueGoals
 	}
 
-	if s.state == SoldierStateDead {
+	if s.casualty.Evacuated {
+		if team == TeamRed {
+			report.RedEvacuated++
+		} else {
+			report.BlueEvacuated++
+		}
+		return
+	}
+	if s.killed() {
 		if team == TeamRed {
 			report.RedDead++
 		} else {
@@ -592,14 +605,14 @@ func (r *SimReporter) FormatLatest() string {
 	}
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "--- Snapshot T=%d ---\n", rpt.Tick)
-	fmt.Fprintf(&sb, "Red:  alive=%d dead=%d injured=%d  contact=%d enemies_seen=%d  posture=%+.2f\n",
-		rpt.RedAlive, rpt.RedDead, rpt.RedInjured,
+	fmt.Fprintf(&sb, "Red:  alive=%d dead=%d evacuated=%d injured=%d  contact=%d enemies_seen=%d  posture=%+.2f\n",
+		rpt.RedAlive, rpt.RedDead, rpt.RedEvacuated, rpt.RedInjured,
 		rpt.RedMembersWithContact, rpt.RedTotalEnemiesSeen, rpt.RedAvgPosture)
 	fmt.Fprintf(&sb, "      stalled_in_combat=%d detached=%d\n", rpt.RedStalledInCombat, rpt.RedDetached)
 	fmt.Fprintf(&sb, "      disobeying=%d panic_retreat=%d surrendered=%d broken_members=%d stress=%.2f casualty_rate=%.2f\n",
 		rpt.RedDisobeying, rpt.RedPanicRetreat, rpt.RedSurrendered, rpt.RedSquadBrokenMembers, rpt.RedAvgSquadStress, rpt.RedAvgCasualtyRate)
-	fmt.Fprintf(&sb, "Blue: alive=%d dead=%d injured=%d  contact=%d enemies_seen=%d  posture=%+.2f\n",
-		rpt.BlueAlive, rpt.BlueDead, rpt.BlueInjured,
+	fmt.Fprintf(&sb, "Blue: alive=%d dead=%d evacuated=%d injured=%d  contact=%d enemies_seen=%d  posture=%+.2f\n",
+		rpt.BlueAlive, rpt.BlueDead, rpt.BlueEvacuated, rpt.BlueInjured,
 		rpt.BlueMembersWithContact, rpt.BlueTotalEnemiesSeen, rpt.BlueAvgPosture)
 	fmt.Fprintf(&sb, "      stalled_in_combat=%d detached=%d\n", rpt.BlueStalledInCombat, rpt.BlueDetached)
 	fmt.Fprintf(&sb, "      disobeying=%d panic_retreat=%d surrendered=%d broken_members=%d stress=%.2f casualty_rate=%.2f\n",
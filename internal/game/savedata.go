@@ -0,0 +1,1887 @@
+package game
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// The sim state inside a SaveState is copied field by field into the saved*
+// types below: plain values, with every pointer between sim objects written
+// as an index. Soldiers are numbered red then blue; squads, platoons and
+// cover objects in World order; -1 is nil. Whatever a World derives (spatial
+// hashes, the radio medium, flow fields) is rebuilt on restore, and whatever
+// is only drawn or logged (debug rings, radio chatter, thought logs) starts
+// fresh.
+//
+// A field added to Soldier, Squad or Platoon must be added here as well, or
+// a restored battle drifts from the one saved. TestSaveState_RestoresEveryField
+// catches a missing field once its test battles give it a non-zero value.
+
+type savedWorld struct {
+	Width      int          `json:"width"`
+	Height     int          `json:"height"`
+	Tick       int          `json:"tick"`
+	Terrain    savedTerrain `json:"terrain"`
+	Jammers    []Jammer     `json:"jammers,omitempty"`
+	EvacPoints []EvacPoint  `json:"evac_points,omitempty"`
+	Config     SimConfig    `json:"config"`
+	IntelMode  IntelMode    `json:"intel_mode"`
+	Env        Environment  `json:"env"`
+	Wetness    float64      `json:"wetness"`
+
+	Soldiers []savedSoldier `json:"soldiers"` // red then blue
+	Red      int            `json:"red"`      // how many of Soldiers are red
+	Squads   []savedSquad   `json:"squads"`
+	Platoons []savedPlatoon `json:"platoons,omitempty"`
+	Combat   savedCombat    `json:"combat"`
+	Intel    savedIntel     `json:"intel"`
+}
+
+// savedTerrain is the battlefield as it stands, damage included.
+type savedTerrain struct {
+	TileCols   int               `json:"tile_cols,omitempty"` // 0 = no tile map
+	TileRows   int               `json:"tile_rows,omitempty"`
+	Tiles      []byte            `json:"tiles,omitempty"` // see packTiles
+	Buildings  [][4]int          `json:"buildings,omitempty"`
+	Windows    [][4]int          `json:"windows,omitempty"`
+	Footprints [][4]int          `json:"footprints,omitempty"`
+	Covers     []savedCover      `json:"covers,omitempty"`
+	Qualities  []BuildingQuality `json:"qualities,omitempty"`
+
+	NavCols    int             `json:"nav_cols"`
+	NavRows    int             `json:"nav_rows"`
+	NavBlocked []byte          `json:"nav_blocked"` // one bit a cell, see packBits
+	NavExtra   map[int]float64 `json:"nav_extra,omitempty"`
+
+	// Tactical is set when the world has a tactical map; it is rebuilt from
+	// the walls and then has Opened, the cells fire broke open, reapplied.
+	Tactical bool  `json:"tactical,omitempty"`
+	Opened   []int `json:"opened,omitempty"`
+}
+
+type savedCover struct {
+	X    int       `json:"x"`
+	Y    int       `json:"y"`
+	Kind CoverKind `json:"kind"`
+}
+
+type savedSoldier struct {
+	ID    int     `json:"id"`
+	Label string  `json:"label"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Team  Team    `json:"team"`
+
+	Path        [][2]float64 `json:"path,omitempty"`
+	PathIndex   int          `json:"path_index"`
+	StartTarget [2]float64   `json:"start_target"`
+	EndTarget   [2]float64   `json:"end_target"`
+
+	State    SoldierState   `json:"state"`
+	Profile  SoldierProfile `json:"profile"`
+	Vision   savedVision    `json:"vision"`
+	IsLeader bool           `json:"is_leader,omitempty"`
+	Squad    int            `json:"squad"`
+
+	Body          BodyMap       `json:"body"`
+	Casualty      savedCasualty `json:"casualty"`
+	IsMedic       bool          `json:"is_medic,omitempty"`
+	Litter        int           `json:"litter"`
+	FireCooldown  int           `json:"fire_cooldown"`
+	LastFiredTick int           `json:"last_fired_tick"`
+
+	BurstShotsRemaining int     `json:"burst_shots_remaining"`
+	BurstShotIndex      int     `json:"burst_shot_index"`
+	BurstTargetID       int     `json:"burst_target_id"`
+	BurstAnyHit         bool    `json:"burst_any_hit,omitempty"`
+	BurstHitChance      float64 `json:"burst_hit_chance"`
+	BurstDist           float64 `json:"burst_dist"`
+	BurstBaseSpread     float64 `json:"burst_base_spread"`
+	AimingTargetID      int     `json:"aiming_target_id"`
+	AimingTicks         int     `json:"aiming_ticks"`
+
+	CurrentFireMode FireMode `json:"current_fire_mode"`
+	DesiredFireMode FireMode `json:"desired_fire_mode"`
+	ModeSwitchTimer int      `json:"mode_switch_timer"`
+
+	// Blackboard is saved with Threats cleared; they follow in Threats.
+	Blackboard     Blackboard      `json:"blackboard"`
+	Threats        []savedThreat   `json:"threats,omitempty"`
+	SuppressSpiked bool            `json:"suppress_spiked,omitempty"`
+	Suppressed     bool            `json:"suppressed,omitempty"`
+	OwnThresholds  *GoalThresholds `json:"own_thresholds,omitempty"`
+	PrevGoal       GoalKind        `json:"prev_goal"`
+
+	FormationMember bool    `json:"formation_member,omitempty"`
+	SlotIndex       int     `json:"slot_index"`
+	SlotTargetX     float64 `json:"slot_target_x"`
+	SlotTargetY     float64 `json:"slot_target_y"`
+	CoverTarget     int     `json:"cover_target"`
+
+	LastSightlineTick          int  `json:"last_sightline_tick"`
+	LastSpeechTick             int  `json:"last_speech_tick"`
+	RadioLastContactReportTick int  `json:"radio_last_contact_report_tick"`
+	RadioLastStatusReportTick  int  `json:"radio_last_status_report_tick"`
+	RadioLastFearReportTick    int  `json:"radio_last_fear_report_tick"`
+	RadioLastAmmoReportTick    int  `json:"radio_last_ammo_report_tick"`
+	RadioRelay                 bool `json:"radio_relay,omitempty"`
+
+	AimSpread             float64        `json:"aim_spread"`
+	DashOverwatchTimer    int            `json:"dash_overwatch_timer"`
+	BoundHoldTicks        int            `json:"bound_hold_ticks"`
+	Steering              *savedSteering `json:"steering,omitempty"`
+	BoundDestX            float64        `json:"bound_dest_x"`
+	BoundDestY            float64        `json:"bound_dest_y"`
+	BoundDestSet          bool           `json:"bound_dest_set,omitempty"`
+	SuppressionAbort      bool           `json:"suppression_abort,omitempty"`
+	PostArrivalTimer      int            `json:"post_arrival_timer"`
+	PeekTarget            [2]float64     `json:"peek_target"`
+	PeekTimer             int            `json:"peek_timer"`
+	GoalPauseTimer        int            `json:"goal_pause_timer"`
+	CognitionPauseTimer   int            `json:"cognition_pause_timer"`
+	NextCognitionTick     int            `json:"next_cognition_tick"`
+	PendingStance         Stance         `json:"pending_stance"`
+	StanceTransitionTimer int            `json:"stance_transition_timer"`
+	MobilityStallTicks    int            `json:"mobility_stall_ticks"`
+
+	Weapon      *WeaponKind `json:"weapon,omitempty"` // nil = none issued, i.e. the rifle
+	MagCapacity int         `json:"mag_capacity"`
+	MagRounds   int         `json:"mag_rounds"`
+	ReloadTimer int         `json:"reload_timer"`
+	SpareMags   int         `json:"spare_mags"`
+	DryNoted    bool        `json:"dry_noted,omitempty"`
+
+	Grenades      int     `json:"grenades"`
+	GrenadeWindup int     `json:"grenade_windup"`
+	GrenadeReady  bool    `json:"grenade_ready,omitempty"`
+	GrenadeAimX   float64 `json:"grenade_aim_x"`
+	GrenadeAimY   float64 `json:"grenade_aim_y"`
+	SmokeGrenades int     `json:"smoke_grenades"`
+	SmokeReady    bool    `json:"smoke_ready,omitempty"`
+	SmokeAimX     float64 `json:"smoke_aim_x"`
+	SmokeAimY     float64 `json:"smoke_aim_y"`
+
+	StepX      float64    `json:"step_x"`
+	StepY      float64    `json:"step_y"`
+	SoundCell  int        `json:"sound_cell"`
+	DoorNoise  float64    `json:"door_noise"`
+	DoorTimer  int        `json:"door_timer"`
+	DoorAction doorAction `json:"door_action"`
+	DoorCol    int        `json:"door_col"`
+	DoorRow    int        `json:"door_row"`
+	InDoorway  bool       `json:"in_doorway,omitempty"`
+	DoorwayCol int        `json:"doorway_col"`
+	DoorwayRow int        `json:"doorway_row"`
+
+	RecoveryNoPathStreak        int            `json:"recovery_no_path_streak"`
+	RecoveryRouteFailEMA        float64        `json:"recovery_route_fail_ema"`
+	RecoveryStallEMA            float64        `json:"recovery_stall_ema"`
+	RecoveryCommitTicks         int            `json:"recovery_commit_ticks"`
+	RecoveryAction              RecoveryAction `json:"recovery_action"`
+	RecoveryAttempts            int            `json:"recovery_attempts"`
+	RecoverySuccesses           int            `json:"recovery_successes"`
+	RecoveryActionCounts        [4]int         `json:"recovery_action_counts"`
+	RecoveryActionSuccessCounts [4]int         `json:"recovery_action_success_counts"`
+	RecoveryExploreTriggers     int            `json:"recovery_explore_triggers"`
+	RecoveryExploreSuccesses    int            `json:"recovery_explore_successes"`
+	RecoveryExploreThisTick     bool           `json:"recovery_explore_this_tick,omitempty"`
+}
+
+type savedThreat struct {
+	Source     int     `json:"source"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Confidence float64 `json:"confidence"`
+	LastTick   int     `json:"last_tick"`
+	IsVisible  bool    `json:"is_visible,omitempty"`
+	Uncertain  bool    `json:"uncertain,omitempty"`
+}
+
+type savedVision struct {
+	Heading       float64      `json:"heading"`
+	FOV           float64      `json:"fov"`
+	MaxRange      float64      `json:"max_range"`
+	EnvMul        float64      `json:"env_mul"`
+	KnownContacts []int        `json:"known_contacts,omitempty"`
+	Suspected     []int        `json:"suspected,omitempty"`
+	Tracks        []savedTrack `json:"tracks,omitempty"`
+}
+
+type savedTrack struct {
+	Target int     `json:"target"`
+	Level  float64 `json:"level"`
+	Seen   bool    `json:"seen,omitempty"`
+}
+
+type savedCasualty struct {
+	Phase            TCCCPhase       `json:"phase"`
+	PhaseTick        int             `json:"phase_tick"`
+	SelfAidActive    bool            `json:"self_aid_active,omitempty"`
+	Treat            *savedTreatment `json:"treat,omitempty"`
+	Providers        []int           `json:"providers,omitempty"`
+	BuddyAidAttempts int             `json:"buddy_aid_attempts"`
+	BuddyAidSuccess  int             `json:"buddy_aid_success"`
+	MedicAidAttempts int             `json:"medic_aid_attempts"`
+	MedicAidSuccess  int             `json:"medic_aid_success"`
+	BeingDragged     bool            `json:"being_dragged,omitempty"`
+	Dragger          int             `json:"dragger"`
+	DragTargetX      float64         `json:"drag_target_x"`
+	DragTargetY      float64         `json:"drag_target_y"`
+	ReportSent       bool            `json:"report_sent,omitempty"`
+	StabilizedTick   int             `json:"stabilized_tick"`
+	Bearers          []int           `json:"bearers,omitempty"`
+	AtCCP            bool            `json:"at_ccp,omitempty"`
+	Evacuated        bool            `json:"evacuated,omitempty"`
+	EvacuatedTick    int             `json:"evacuated_tick"`
+}
+
+// savedTreatment is a TreatmentAttempt. Its target is an index into the
+// casualty's wounds, or Detached when the pointer no longer points into them.
+type savedTreatment struct {
+	Action      TreatmentAction `json:"action"`
+	Wound       int             `json:"wound"`
+	Detached    *Wound          `json:"detached,omitempty"`
+	Provider    int             `json:"provider"`
+	TicksLeft   int             `json:"ticks_left"`
+	Interrupted bool            `json:"interrupted,omitempty"`
+	SkillLevel  float64         `json:"skill_level"`
+}
+
+// savedSteering holds a SteeringBehavior's changing state; its weights are
+// the NewSteeringBehavior defaults.
+type savedSteering struct {
+	MaxSpeed   float64 `json:"max_speed"`
+	StuckTicks int     `json:"stuck_ticks"`
+	LastX      float64 `json:"last_x"`
+	LastY      float64 `json:"last_y"`
+}
+
+type savedSquad struct {
+	ID        int             `json:"id"`
+	Team      Team            `json:"team"`
+	Leader    int             `json:"leader"`
+	Members   []int           `json:"members"`
+	Formation FormationType   `json:"formation"`
+	Intent    SquadIntentKind `json:"intent"`
+	Phase     SquadPhase      `json:"phase"`
+
+	SupportTargetX     float64      `json:"support_target_x"`
+	SupportTargetY     float64      `json:"support_target_y"`
+	SupportActiveUntil int          `json:"support_active_until"`
+	PlatoonTask        PlatoonTask  `json:"platoon_task"`
+	ActiveOrder        OfficerOrder `json:"active_order"`
+	NextOrderID        int          `json:"next_order_id"`
+	SmoothedHeading    float64      `json:"smoothed_heading"`
+	HeadingInit        bool         `json:"heading_init,omitempty"`
+	EnemyBearing       float64      `json:"enemy_bearing"`
+
+	LeaderDeadTick       int  `json:"leader_dead_tick"`
+	LeaderSuccessionTick int  `json:"leader_succession_tick"`
+	LeaderSucceeding     bool `json:"leader_succeeding,omitempty"`
+
+	ClaimedBuildingIdx         int                   `json:"claimed_building_idx"`
+	ClaimEvalTick              int                   `json:"claim_eval_tick"`
+	ClaimedNoContactTicks      int                   `json:"claimed_no_contact_ticks"`
+	ClaimedOccupiedTicks       int                   `json:"claimed_occupied_ticks"`
+	BuildingClaimCooldownUntil int                   `json:"building_claim_cooldown_until"`
+	BuildingState              *BuildingState        `json:"building_state,omitempty"`
+	BuildingIntel              map[int]BuildingIntel `json:"building_intel,omitempty"`
+	HasBuildingIntel           bool                  `json:"has_building_intel,omitempty"`
+	EntryPlan                  *savedEntryPlan       `json:"entry_plan,omitempty"`
+	SmokeCooldownUntil         int                   `json:"smoke_cooldown_until"`
+	AmmoRequests               []int                 `json:"ammo_requests,omitempty"`
+	AmmoShareNextTick          int                   `json:"ammo_share_next_tick"`
+	CCPX                       float64               `json:"ccp_x"`
+	CCPY                       float64               `json:"ccp_y"`
+	CCPSet                     bool                  `json:"ccp_set,omitempty"`
+
+	IntentLockUntil      int           `json:"intent_lock_until"`
+	LastIntentChangeTick int           `json:"last_intent_change_tick"`
+	LastFormationLeaderX float64       `json:"last_formation_leader_x"`
+	LastFormationLeaderY float64       `json:"last_formation_leader_y"`
+	LastFormationUpdate  int           `json:"last_formation_update"`
+	LastFormation        FormationType `json:"last_formation"`
+	StalemateTicks       int           `json:"stalemate_ticks"`
+	ProactivePushUntil   int           `json:"proactive_push_until"`
+	LastStalledOrderTick int           `json:"last_stalled_order_tick"`
+	LastStalledOrderID   int           `json:"last_stalled_order_id"`
+	PhaseInit            bool          `json:"phase_init,omitempty"`
+	PhaseEnteredTick     int           `json:"phase_entered_tick"`
+	LastProgressTick     int           `json:"last_progress_tick"`
+	LastProgressMetric   float64       `json:"last_progress_metric"`
+	BoundMovingGroup     int           `json:"bound_moving_group"`
+	BoundCycleTick       int           `json:"bound_cycle_tick"`
+	BoundCycleActive     bool          `json:"bound_cycle_active,omitempty"`
+	LastContactTick      int           `json:"last_contact_tick"`
+
+	CasualtyRate          float64 `json:"casualty_rate"`
+	Stress                float64 `json:"stress"`
+	StressDelta           float64 `json:"stress_delta"`
+	AvgMorale             float64 `json:"avg_morale"`
+	MoraleDelta           float64 `json:"morale_delta"`
+	Cohesion              float64 `json:"cohesion"`
+	CohesionDelta         float64 `json:"cohesion_delta"`
+	Broken                bool    `json:"broken,omitempty"`
+	BreakLockEnd          int     `json:"break_lock_end"`
+	BreakPressureTicks    int     `json:"break_pressure_ticks"`
+	CohesionShock         float64 `json:"cohesion_shock"`
+	PrevInjuredAliveCount int     `json:"prev_injured_alive_count"`
+	PrevCasualtyCount     int     `json:"prev_casualty_count"`
+
+	RadioNet                   savedRadioNet      `json:"radio_net"`
+	RadioPendingStatus         map[int]int        `json:"radio_pending_status,omitempty"`
+	RadioStatusReplyQueued     map[int]bool       `json:"radio_status_reply_queued,omitempty"`
+	RadioUnresponsive          map[int]bool       `json:"radio_unresponsive,omitempty"`
+	RadioStatusRequestCursor   int                `json:"radio_status_request_cursor"`
+	RadioLastStatusRequestTick int                `json:"radio_last_status_request_tick"`
+	RadioChannelBusyUntil      int                `json:"radio_channel_busy_until"`
+	RadioInFlight              *savedTransmission `json:"radio_in_flight,omitempty"`
+	RadioQueued                int                `json:"radio_queued"`
+	RadioSent                  int                `json:"radio_sent"`
+	RadioReceived              int                `json:"radio_received"`
+	RadioDropped               int                `json:"radio_dropped"`
+	RadioGarbled               int                `json:"radio_garbled"`
+	RadioTimeouts              int                `json:"radio_timeouts"`
+
+	Flow     *savedFlow `json:"flow,omitempty"`
+	Paint    bool       `json:"paint,omitempty"` // the team's paint layer is attached
+	IntelMap int        `json:"intel_map"`       // index into the squad maps, -1 = the team's
+}
+
+type savedEntryPlan struct {
+	BuildingEntryPlan
+	EntryTeam     []int `json:"entry_team_refs,omitempty"`
+	OverwatchTeam []int `json:"overwatch_team_refs,omitempty"`
+}
+
+type savedRadioNet struct {
+	NetID   int            `json:"net_id"`
+	Kind    RadioNetKind   `json:"kind"`
+	Name    string         `json:"name"`
+	NextID  uint64         `json:"next_id"`
+	Pending []RadioMessage `json:"pending,omitempty"`
+}
+
+type savedTransmission struct {
+	Msg          RadioMessage         `json:"msg"`
+	DispatchTick int                  `json:"dispatch_tick"`
+	ArrivalTick  int                  `json:"arrival_tick"`
+	ResolvedMsg  RadioMessage         `json:"resolved_msg"`
+	Outcome      radioDeliveryOutcome `json:"outcome"`
+}
+
+// savedFlow is a squad's flow controller. The flow fields are not stored:
+// each layer is recomputed from its goals and the cost-field inputs it was
+// last computed from, which gives the same field.
+type savedFlow struct {
+	BaseBlocked   []byte         `json:"base_blocked"` // cells the cost field treats as impassable
+	Threat        sparseCosts    `json:"threat,omitempty"`
+	Occupied      []int          `json:"occupied,omitempty"`
+	Paints        []sparseCosts  `json:"paints,omitempty"` // distinct paint cost slices
+	Paint         int            `json:"paint"`            // index into Paints, -1 = unpainted
+	Goals         []Vec2i        `json:"goals,omitempty"`
+	TacticalGoals []Vec2i        `json:"tactical_goals,omitempty"`
+	Strategic     savedFlowLayer `json:"strategic"`
+	Tactical      savedFlowLayer `json:"tactical"`
+
+	UpdateTicks    int  `json:"update_ticks"`
+	StrategicDirty bool `json:"strategic_dirty,omitempty"`
+	TacticalDirty  bool `json:"tactical_dirty,omitempty"`
+	PaintCostRev   int  `json:"paint_cost_rev"`
+	PaintGoalsRev  int  `json:"paint_goals_rev"`
+}
+
+type savedFlowLayer struct {
+	Computed bool    `json:"computed,omitempty"`
+	Goals    []Vec2i `json:"goals,omitempty"`
+	Occupied []int   `json:"occupied,omitempty"`
+	Paint    int     `json:"paint"` // index into savedFlow.Paints, -1 = unpainted
+}
+
+// sparseCosts holds the non-zero cells of a per-cell cost slice.
+type sparseCosts map[int]float64
+
+type savedPlatoon struct {
+	ID     int           `json:"id"`
+	Team   Team          `json:"team"`
+	Leader int           `json:"leader"`
+	Squads []int         `json:"squads"`
+	Phase  PlatoonPhase  `json:"phase"`
+	Roles  []PlatoonRole `json:"roles"`
+
+	State                []savedPlatoonSquad `json:"state"`
+	PhaseEnteredTick     int                 `json:"phase_entered_tick"`
+	ContactX             float64             `json:"contact_x"`
+	ContactY             float64             `json:"contact_y"`
+	LastContactTick      int                 `json:"last_contact_tick"`
+	SupportX             float64             `json:"support_x"`
+	SupportY             float64             `json:"support_y"`
+	FlankX               float64             `json:"flank_x"`
+	FlankY               float64             `json:"flank_y"`
+	AxisX                float64             `json:"axis_x"`
+	AxisY                float64             `json:"axis_y"`
+	AxisSet              bool                `json:"axis_set,omitempty"`
+	Successor            int                 `json:"successor"`
+	LeaderSuccessionTick int                 `json:"leader_succession_tick"`
+
+	RadioNet              savedRadioNet      `json:"radio_net"`
+	RadioInFlight         *savedTransmission `json:"radio_in_flight,omitempty"`
+	RadioChannelBusyUntil int                `json:"radio_channel_busy_until"`
+	RadioSent             int                `json:"radio_sent"`
+	RadioReceived         int                `json:"radio_received"`
+	RadioDropped          int                `json:"radio_dropped"`
+	RadioGarbled          int                `json:"radio_garbled"`
+}
+
+type savedPlatoonSquad struct {
+	Report        platoonSitrep `json:"report"`
+	Sent          PlatoonTask   `json:"sent"`
+	SitrepTick    int           `json:"sitrep_tick"`
+	SitrepContact bool          `json:"sitrep_contact,omitempty"`
+	Lane          int           `json:"lane"`
+}
+
+// savedCombat is the CombatManager between ticks. The per-tick event lists
+// (Gunfires, Shots, Detonations) are consumed within the tick and not kept.
+type savedCombat struct {
+	RNG      RNGState       `json:"rng"`
+	Tick     int            `json:"tick"`
+	Tracers  []savedTracer  `json:"tracers,omitempty"`
+	Flashes  []savedFlash   `json:"flashes,omitempty"`
+	Grenades []savedGrenade `json:"grenades,omitempty"`
+	Blasts   []savedBlast   `json:"blasts,omitempty"`
+	Smoke    SmokeField     `json:"smoke"`
+	Emitted  []savedSound   `json:"emitted,omitempty"`
+	Pending  []savedPending `json:"pending,omitempty"`
+}
+
+type savedTracer struct {
+	FromX         float64 `json:"from_x"`
+	FromY         float64 `json:"from_y"`
+	ToX           float64 `json:"to_x"`
+	ToY           float64 `json:"to_y"`
+	Hit           bool    `json:"hit,omitempty"`
+	Team          Team    `json:"team"`
+	Age           int     `json:"age"`
+	FractionalAge float64 `json:"fractional_age"`
+}
+
+type savedFlash struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+	Team  Team    `json:"team"`
+	Age   int     `json:"age"`
+}
+
+type savedGrenade struct {
+	Grenade
+	FlightTick  int `json:"flight_tick"`
+	FlightTicks int `json:"flight_ticks"`
+}
+
+type savedBlast struct {
+	X   float64 `json:"x"`
+	Y   float64 `json:"y"`
+	Age int     `json:"age"`
+}
+
+type savedSound struct {
+	Kind   SoundKind `json:"kind"`
+	X      float64   `json:"x"`
+	Y      float64   `json:"y"`
+	Team   Team      `json:"team"`
+	Source int       `json:"source"`
+	Tick   int       `json:"tick"`
+	Range  float64   `json:"range"`
+}
+
+type savedPending struct {
+	Event    savedSound `json:"event"`
+	Listener int        `json:"listener"`
+	Strength float64    `json:"strength"`
+	Arrival  int        `json:"arrival"`
+}
+
+// savedIntel holds every intel map: the red and blue team maps, then the
+// squad maps in the order they were made.
+type savedIntel struct {
+	Maps []savedIntelMap `json:"maps"`
+}
+
+type savedIntelMap struct {
+	Team     Team                `json:"team"`
+	Layers   [][]byte            `json:"layers"` // one per IntelMapKind, see packCells
+	Paint    [][]byte            `json:"paint"`  // one per PaintKind
+	Painted  [paintKindCount]int `json:"painted"`
+	Pending  []PaintStroke       `json:"pending,omitempty"`
+	Revision int                 `json:"revision"`
+}
+
+// --- saving ---
+
+// saveRefs numbers the objects one World's pointers can point at.
+type saveRefs struct {
+	soldiers map[*Soldier]int
+	squads   map[*Squad]int
+	covers   map[*CoverObject]int
+}
+
+func newSaveRefs(w *World) *saveRefs {
+	r := &saveRefs{
+		soldiers: make(map[*Soldier]int),
+		squads:   make(map[*Squad]int),
+		covers:   make(map[*CoverObject]int),
+	}
+	for i, s := range w.allSoldiers() {
+		r.soldiers[s] = i
+	}
+	for i, sq := range w.squads {
+		r.squads[sq] = i
+	}
+	for i, c := range w.covers {
+		r.covers[c] = i
+	}
+	return r
+}
+
+func (r *saveRefs) soldier(s *Soldier) int {
+	if i, ok := r.soldiers[s]; ok {
+		return i
+	}
+	return -1
+}
+
+func (r *saveRefs) soldierList(ss []*Soldier) []int {
+	if ss == nil {
+		return nil
+	}
+	out := make([]int, len(ss))
+	for i, s := range ss {
+		out[i] = r.soldier(s)
+	}
+	return out
+}
+
+func (r *saveRefs) squad(sq *Squad) int {
+	if i, ok := r.squads[sq]; ok {
+		return i
+	}
+	return -1
+}
+
+func (r *saveRefs) cover(c *CoverObject) int {
+	if i, ok := r.covers[c]; ok {
+		return i
+	}
+	return -1
+}
+
+// save copies w into its saved form.
+func (w *World) save() *savedWorld {
+	r := newSaveRefs(w)
+	sw := &savedWorld{
+		Width:      w.gameWidth,
+		Height:     w.gameHeight,
+		Tick:       w.tick,
+		Terrain:    w.saveTerrain(),
+		Jammers:    w.jammers,
+		EvacPoints: w.evacPoints,
+		Config:     *w.config,
+		IntelMode:  w.intelMode,
+		Env:        *w.env,
+		Wetness:    w.env.wetness,
+		Red:        len(w.soldiers),
+		Combat:     w.combat.save(r),
+		Intel:      w.intel.save(),
+	}
+	for _, s := range w.allSoldiers() {
+		sw.Soldiers = append(sw.Soldiers, s.save(r))
+	}
+	for _, sq := range w.squads {
+		sw.Squads = append(sw.Squads, sq.save(r, w.intel))
+	}
+	for _, p := range w.platoons {
+		sw.Platoons = append(sw.Platoons, p.save(r))
+	}
+	return sw
+}
+
+func (w *World) saveTerrain() savedTerrain {
+	t := savedTerrain{
+		Buildings:  saveRects(w.buildings),
+		Windows:    saveRects(w.windows),
+		Footprints: saveRects(w.buildingFootprints),
+		Qualities:  w.buildingQualities,
+		NavCols:    w.navGrid.cols,
+		NavRows:    w.navGrid.rows,
+		NavBlocked: packBits(w.navGrid.blocked),
+		NavExtra:   w.navGrid.extra,
+	}
+	if tm := w.tileMap; tm != nil {
+		t.TileCols, t.TileRows = tm.Cols, tm.Rows
+		t.Tiles = packTiles(tm.Tiles)
+	}
+	for _, c := range w.covers {
+		t.Covers = append(t.Covers, savedCover{X: c.x, Y: c.y, Kind: c.kind})
+	}
+	if w.tacticalMap != nil {
+		t.Tactical = true
+		t.Opened = w.tacticalMap.opened
+	}
+	return t
+}
+
+func (s *Soldier) save(r *saveRefs) savedSoldier {
+	bb := s.blackboard
+	bb.Threats = nil
+	ss := savedSoldier{
+		ID:          s.id,
+		Label:       s.label,
+		X:           s.x,
+		Y:           s.y,
+		Team:        s.team,
+		Path:        s.path,
+		PathIndex:   s.pathIndex,
+		StartTarget: s.startTarget,
+		EndTarget:   s.endTarget,
+
+		State:   s.state,
+		Profile: s.profile,
+		Vision: savedVision{
+			Heading:       s.vision.Heading,
+			FOV:           s.vision.FOV,
+			MaxRange:      s.vision.MaxRange,
+			EnvMul:        s.vision.EnvMul,
+			KnownContacts: r.soldierList(s.vision.KnownContacts),
+			Suspected:     r.soldierList(s.vision.Suspected),
+		},
+		IsLeader: s.isLeader,
+		Squad:    r.squad(s.squad),
+
+		Body:          s.body,
+		Casualty:      s.casualty.save(r, s.body.Wounds),
+		IsMedic:       s.isMedic,
+		Litter:        r.soldier(s.litter),
+		FireCooldown:  s.fireCooldown,
+		LastFiredTick: s.lastFiredTick,
+
+		BurstShotsRemaining: s.burstShotsRemaining,
+		BurstShotIndex:      s.burstShotIndex,
+		BurstTargetID:       s.burstTargetID,
+		BurstAnyHit:         s.burstAnyHit,
+		BurstHitChance:      s.burstHitChance,
+		BurstDist:           s.burstDist,
+		BurstBaseSpread:     s.burstBaseSpread,
+		AimingTargetID:      s.aimingTargetID,
+		AimingTicks:         s.aimingTicks,
+
+		CurrentFireMode: s.currentFireMode,
+		DesiredFireMode: s.desiredFireMode,
+		ModeSwitchTimer: s.modeSwitchTimer,
+
+		Blackboard:     bb,
+		SuppressSpiked: s.blackboard.suppressSpiked,
+		Suppressed:     s.blackboard.suppressed,
+		OwnThresholds:  s.blackboard.ownThresholds,
+		PrevGoal:       s.prevGoal,
+
+		FormationMember: s.formationMember,
+		SlotIndex:       s.slotIndex,
+		SlotTargetX:     s.slotTargetX,
+		SlotTargetY:     s.slotTargetY,
+		CoverTarget:     r.cover(s.coverTarget),
+
+		LastSightlineTick:          s.lastSightlineTick,
+		LastSpeechTick:             s.lastSpeechTick,
+		RadioLastContactReportTick: s.radioLastContactReportTick,
+		RadioLastStatusReportTick:  s.radioLastStatusReportTick,
+		RadioLastFearReportTick:    s.radioLastFearReportTick,
+		RadioLastAmmoReportTick:    s.radioLastAmmoReportTick,
+		RadioRelay:                 s.radioRelay,
+
+		AimSpread:             s.aimSpread,
+		DashOverwatchTimer:    s.dashOverwatchTimer,
+		BoundHoldTicks:        s.boundHoldTicks,
+		BoundDestX:            s.boundDestX,
+		BoundDestY:            s.boundDestY,
+		BoundDestSet:          s.boundDestSet,
+		SuppressionAbort:      s.suppressionAbort,
+		PostArrivalTimer:      s.postArrivalTimer,
+		PeekTarget:            s.peekTarget,
+		PeekTimer:             s.peekTimer,
+		GoalPauseTimer:        s.goalPauseTimer,
+		CognitionPauseTimer:   s.cognitionPauseTimer,
+		NextCognitionTick:     s.nextCognitionTick,
+		PendingStance:         s.pendingStance,
+		StanceTransitionTimer: s.stanceTransitionTimer,
+		MobilityStallTicks:    s.mobilityStallTicks,
+
+		MagCapacity: s.magCapacity,
+		MagRounds:   s.magRounds,
+		ReloadTimer: s.reloadTimer,
+		SpareMags:   s.spareMags,
+		DryNoted:    s.dryNoted,
+
+		Grenades:      s.grenades,
+		GrenadeWindup: s.grenadeWindup,
+		GrenadeReady:  s.grenadeReady,
+		GrenadeAimX:   s.grenadeAimX,
+		GrenadeAimY:   s.grenadeAimY,
+		SmokeGrenades: s.smokeGrenades,
+		SmokeReady:    s.smokeReady,
+		SmokeAimX:     s.smokeAimX,
+		SmokeAimY:     s.smokeAimY,
+
+		StepX:      s.stepX,
+		StepY:      s.stepY,
+		SoundCell:  s.soundCell,
+		DoorNoise:  s.doorNoise,
+		DoorTimer:  s.doorTimer,
+		DoorAction: s.doorAction,
+		DoorCol:    s.doorCol,
+		DoorRow:    s.doorRow,
+		InDoorway:  s.inDoorway,
+		DoorwayCol: s.doorwayCol,
+		DoorwayRow: s.doorwayRow,
+
+		RecoveryNoPathStreak:        s.recoveryNoPathStreak,
+		RecoveryRouteFailEMA:        s.recoveryRouteFailEMA,
+		RecoveryStallEMA:            s.recoveryStallEMA,
+		RecoveryCommitTicks:         s.recoveryCommitTicks,
+		RecoveryAction:              s.recoveryAction,
+		RecoveryAttempts:            s.recoveryAttempts,
+		RecoverySuccesses:           s.recoverySuccesses,
+		RecoveryActionCounts:        s.recoveryActionCounts,
+		RecoveryActionSuccessCounts: s.recoveryActionSuccessCounts,
+		RecoveryExploreTriggers:     s.recoveryExploreTriggers,
+		RecoveryExploreSuccesses:    s.recoveryExploreSuccesses,
+		RecoveryExploreThisTick:     s.recoveryExploreThisTick,
+	}
+	for _, t := range s.blackboard.Threats {
+		ss.Threats = append(ss.Threats, savedThreat{
+			Source: r.soldier(t.Source), X: t.X, Y: t.Y, Confidence: t.Confidence,
+			LastTick: t.LastTick, IsVisible: t.IsVisible, Uncertain: t.Uncertain,
+		})
+	}
+	for _, tr := range s.vision.tracks {
+		ss.Vision.Tracks = append(ss.Vision.Tracks, savedTrack{Target: r.soldier(tr.target), Level: tr.level, Seen: tr.seen})
+	}
+	if sb := s.steeringBehavior; sb != nil {
+		ss.Steering = &savedSteering{MaxSpeed: sb.maxSpeed, StuckTicks: sb.stuckTicks, LastX: sb.lastX, LastY: sb.lastY}
+	}
+	if s.weapon != nil {
+		k := s.weapon.Kind
+		ss.Weapon = &k
+	}
+	return ss
+}
+
+func (c *CasualtyState) save(r *saveRefs, wounds []Wound) savedCasualty {
+	sc := savedCasualty{
+		Phase:            c.Phase,
+		PhaseTick:        c.PhaseTick,
+		SelfAidActive:    c.SelfAidActive,
+		Providers:        r.soldierList(c.Providers),
+		BuddyAidAttempts: c.BuddyAidAttempts,
+		BuddyAidSuccess:  c.BuddyAidSuccess,
+		MedicAidAttempts: c.MedicAidAttempts,
+		MedicAidSuccess:  c.MedicAidSuccess,
+		BeingDragged:     c.BeingDragged,
+		Dragger:          r.soldier(c.Dragger),
+		DragTargetX:      c.DragTargetX,
+		DragTargetY:      c.DragTargetY,
+		ReportSent:       c.ReportSent,
+		StabilizedTick:   c.StabilizedTick,
+		Bearers:          r.soldierList(c.Bearers),
+		AtCCP:            c.AtCCP,
+		Evacuated:        c.Evacuated,
+		EvacuatedTick:    c.EvacuatedTick,
+	}
+	if t := c.CurrentTreat; t != nil {
+		st := &savedTreatment{
+			Action:      t.Action,
+			Wound:       -1,
+			Provider:    r.soldier(t.Provider),
+			TicksLeft:   t.TicksLeft,
+			Interrupted: t.Interrupted,
+			SkillLevel:  t.SkillLevel,
+		}
+		if t.TargetWound != nil {
+			for i := range wounds {
+				if &wounds[i] == t.TargetWound {
+					st.Wound = i
+					break
+				}
+			}
+			if st.Wound < 0 {
+				w := *t.TargetWound
+				st.Detached = &w
+			}
+		}
+		sc.Treat = st
+	}
+	return sc
+}
+
+func (sq *Squad) save(r *saveRefs, intel *IntelStore) savedSquad {
+	ss := savedSquad{
+		ID:        sq.ID,
+		Team:      sq.Team,
+		Leader:    r.soldier(sq.Leader),
+		Members:   r.soldierList(sq.Members),
+		Formation: sq.Formation,
+		Intent:    sq.Intent,
+		Phase:     sq.Phase,
+
+		SupportTargetX:     sq.supportTargetX,
+		SupportTargetY:     sq.supportTargetY,
+		SupportActiveUntil: sq.supportActiveUntil,
+		PlatoonTask:        sq.platoonTask,
+		ActiveOrder:        sq.ActiveOrder,
+		NextOrderID:        sq.nextOrderID,
+		SmoothedHeading:    sq.smoothedHeading,
+		HeadingInit:        sq.headingInit,
+		EnemyBearing:       sq.EnemyBearing,
+
+		LeaderDeadTick:       sq.leaderDeadTick,
+		LeaderSuccessionTick: sq.leaderSuccessionTick,
+		LeaderSucceeding:     sq.leaderSucceeding,
+
+		ClaimedBuildingIdx:         sq.ClaimedBuildingIdx,
+		ClaimEvalTick:              sq.claimEvalTick,
+		ClaimedNoContactTicks:      sq.claimedNoContactTicks,
+		ClaimedOccupiedTicks:       sq.claimedOccupiedTicks,
+		BuildingClaimCooldownUntil: sq.buildingClaimCooldownUntil,
+		BuildingState:              sq.buildingState,
+		SmokeCooldownUntil:         sq.smokeCooldownUntil,
+		AmmoRequests:               sq.ammoRequests,
+		AmmoShareNextTick:          sq.ammoShareNextTick,
+		CCPX:                       sq.ccpX,
+		CCPY:                       sq.ccpY,
+		CCPSet:                     sq.ccpSet,
+
+		IntentLockUntil:      sq.intentLockUntil,
+		LastIntentChangeTick: sq.lastIntentChangeTick,
+		LastFormationLeaderX: sq.lastFormationLeaderX,
+		LastFormationLeaderY: sq.lastFormationLeaderY,
+		LastFormationUpdate:  sq.lastFormationUpdate,
+		LastFormation:        sq.lastFormation,
+		StalemateTicks:       sq.stalemateTicks,
+		ProactivePushUntil:   sq.proactivePushUntil,
+		LastStalledOrderTick: sq.lastStalledOrderTick,
+		LastStalledOrderID:   sq.lastStalledOrderID,
+		PhaseInit:            sq.phaseInit,
+		PhaseEnteredTick:     sq.phaseEnteredTick,
+		LastProgressTick:     sq.lastProgressTick,
+		LastProgressMetric:   sq.lastProgressMetric,
+		BoundMovingGroup:     sq.BoundMovingGroup,
+		BoundCycleTick:       sq.boundCycleTick,
+		BoundCycleActive:     sq.boundCycleActive,
+		LastContactTick:      sq.lastContactTick,
+
+		CasualtyRate:          sq.CasualtyRate,
+		Stress:                sq.Stress,
+		StressDelta:           sq.StressDelta,
+		AvgMorale:             sq.AvgMorale,
+		MoraleDelta:           sq.MoraleDelta,
+		Cohesion:              sq.Cohesion,
+		CohesionDelta:         sq.CohesionDelta,
+		Broken:                sq.Broken,
+		BreakLockEnd:          sq.breakLockEnd,
+		BreakPressureTicks:    sq.breakPressureTicks,
+		CohesionShock:         sq.cohesionShock,
+		PrevInjuredAliveCount: sq.prevInjuredAliveCount,
+		PrevCasualtyCount:     sq.prevCasualtyCount,
+
+		RadioNet:                   sq.radioNet.save(),
+		RadioPendingStatus:         sq.radioPendingStatus,
+		RadioStatusReplyQueued:     sq.radioStatusReplyQueued,
+		RadioUnresponsive:          sq.radioUnresponsive,
+		RadioStatusRequestCursor:   sq.radioStatusRequestCursor,
+		RadioLastStatusRequestTick: sq.radioLastStatusRequestTick,
+		RadioChannelBusyUntil:      sq.radioChannelBusyUntil,
+		RadioInFlight:              sq.radioInFlight.save(),
+		RadioQueued:                sq.RadioQueued,
+		RadioSent:                  sq.RadioSent,
+		RadioReceived:              sq.RadioReceived,
+		RadioDropped:               sq.RadioDropped,
+		RadioGarbled:               sq.RadioGarbled,
+		RadioTimeouts:              sq.RadioTimeouts,
+
+		Flow:     sq.flowController.save(),
+		Paint:    sq.paint != nil,
+		IntelMap: -1,
+	}
+	if sq.buildingIntel != nil {
+		ss.HasBuildingIntel = true
+		ss.BuildingIntel = make(map[int]BuildingIntel, len(sq.buildingIntel.buildings))
+		for k, bi := range sq.buildingIntel.buildings {
+			ss.BuildingIntel[k] = *bi
+		}
+	}
+	if p := sq.entryPlan; p != nil {
+		ss.EntryPlan = &savedEntryPlan{
+			BuildingEntryPlan: *p,
+			EntryTeam:         r.soldierList(p.EntryTeam),
+			OverwatchTeam:     r.soldierList(p.OverwatchTeam),
+		}
+		ss.EntryPlan.BuildingEntryPlan.EntryTeam = nil
+		ss.EntryPlan.BuildingEntryPlan.OverwatchTeam = nil
+	}
+	if intel != nil && sq.intel != nil {
+		for i, m := range intel.squadMaps {
+			if m == sq.intel {
+				ss.IntelMap = i
+				break
+			}
+		}
+	}
+	return ss
+}
+
+func (n *radioNet) save() savedRadioNet {
+	return savedRadioNet{NetID: n.netID, Kind: n.kind, Name: n.name, NextID: n.nextID, Pending: n.pending}
+}
+
+func (t *radioTransmission) save() *savedTransmission {
+	if t == nil {
+		return nil
+	}
+	return &savedTransmission{Msg: t.msg, DispatchTick: t.dispatchTick, ArrivalTick: t.arrivalTick, ResolvedMsg: t.resolvedMsg, Outcome: t.outcome}
+}
+
+func (sfc *SquadFlowController) save() *savedFlow {
+	if sfc == nil {
+		return nil
+	}
+	cf := sfc.costField
+	blocked := make([]bool, len(cf.baseCost))
+	for i, c := range cf.baseCost {
+		blocked[i] = math.IsInf(c, 1)
+	}
+	sf := &savedFlow{
+		BaseBlocked:    packBits(blocked),
+		Threat:         newSparseCosts(cf.threatCost),
+		Occupied:       cf.occupiedCells(),
+		Goals:          sfc.strategicGoals,
+		TacticalGoals:  sfc.tacticalGoals,
+		UpdateTicks:    sfc.updateTicks,
+		StrategicDirty: sfc.strategicDirty,
+		TacticalDirty:  sfc.tacticalDirty,
+		PaintCostRev:   sfc.paintCostRev,
+		PaintGoalsRev:  sfc.paintGoalsRev,
+	}
+	// Layers and the cost field usually share one paint slice; keep one copy.
+	var paints [][]float64
+	paintIndex := func(p []float64) int {
+		if p == nil {
+			return -1
+		}
+		for i, q := range paints {
+			if &q[0] == &p[0] {
+				return i
+			}
+		}
+		paints = append(paints, p)
+		sf.Paints = append(sf.Paints, newSparseCosts(p))
+		return len(paints) - 1
+	}
+	sf.Paint = paintIndex(cf.paintCost)
+	sf.Strategic = savedFlowLayer{Paint: -1}
+	if sfc.strategicIntegration != nil {
+		sf.Strategic = savedFlowLayer{
+			Computed: true,
+			Goals:    sfc.strategicIntegration.goals,
+			Occupied: sfc.strategicInputs.occupied,
+			Paint:    paintIndex(sfc.strategicInputs.paint),
+		}
+	}
+	sf.Tactical = savedFlowLayer{Paint: -1}
+	if sfc.tacticalIntegration != nil {
+		sf.Tactical = savedFlowLayer{
+			Computed: true,
+			Goals:    sfc.tacticalIntegration.goals,
+			Occupied: sfc.tacticalInputs.occupied,
+			Paint:    paintIndex(sfc.tacticalInputs.paint),
+		}
+	}
+	return sf
+}
+
+func newSparseCosts(costs []float64) sparseCosts {
+	sc := sparseCosts{}
+	for i, c := range costs {
+		if c != 0 {
+			sc[i] = c
+		}
+	}
+	return sc
+}
+
+func (sc sparseCosts) dense(n int) []float64 {
+	costs := make([]float64, n)
+	for i, c := range sc {
+		if i >= 0 && i < n {
+			costs[i] = c
+		}
+	}
+	return costs
+}
+
+func (p *Platoon) save(r *saveRefs) savedPlatoon {
+	sp := savedPlatoon{
+		ID:     p.ID,
+		Team:   p.Team,
+		Leader: r.soldier(p.Leader),
+		Phase:  p.Phase,
+		Roles:  p.Roles,
+
+		PhaseEnteredTick:     p.phaseEnteredTick,
+		ContactX:             p.contactX,
+		ContactY:             p.contactY,
+		LastContactTick:      p.lastContactTick,
+		SupportX:             p.supportX,
+		SupportY:             p.supportY,
+		FlankX:               p.flankX,
+		FlankY:               p.flankY,
+		AxisX:                p.axisX,
+		AxisY:                p.axisY,
+		AxisSet:              p.axisSet,
+		Successor:            r.soldier(p.successor),
+		LeaderSuccessionTick: p.leaderSuccessionTick,
+
+		RadioNet:              p.radioNet.save(),
+		RadioInFlight:         p.radioInFlight.save(),
+		RadioChannelBusyUntil: p.radioChannelBusyUntil,
+		RadioSent:             p.RadioSent,
+		RadioReceived:         p.RadioReceived,
+		RadioDropped:          p.RadioDropped,
+		RadioGarbled:          p.RadioGarbled,
+	}
+	for _, sq := range p.Squads {
+		sp.Squads = append(sp.Squads, r.squad(sq))
+	}
+	for _, st := range p.state {
+		sp.State = append(sp.State, savedPlatoonSquad{
+			Report: st.report, Sent: st.sent, SitrepTick: st.sitrepTick, SitrepContact: st.sitrepContact, Lane: st.lane,
+		})
+	}
+	return sp
+}
+
+func (cm *CombatManager) save(r *saveRefs) savedCombat {
+	sc := savedCombat{RNG: cm.rngSrc.state(), Tick: cm.tick}
+	for _, t := range cm.tracers {
+		sc.Tracers = append(sc.Tracers, savedTracer{
+			FromX: t.fromX, FromY: t.fromY, ToX: t.toX, ToY: t.toY,
+			Hit: t.hit, Team: t.team, Age: t.age, FractionalAge: t.fractionalAge,
+		})
+	}
+	for _, f := range cm.flashes {
+		sc.Flashes = append(sc.Flashes, savedFlash{X: f.x, Y: f.y, Angle: f.angle, Team: f.team, Age: f.age})
+	}
+	for _, gr := range cm.grenades {
+		sc.Grenades = append(sc.Grenades, savedGrenade{Grenade: *gr, FlightTick: gr.flightTick, FlightTicks: gr.flightTicks})
+	}
+	for _, b := range cm.blasts {
+		sc.Blasts = append(sc.Blasts, savedBlast{X: b.x, Y: b.y, Age: b.age})
+	}
+	if cm.Smoke != nil {
+		sc.Smoke = *cm.Smoke
+	}
+	if sf := cm.Sound; sf != nil {
+		for _, ev := range sf.emitted {
+			sc.Emitted = append(sc.Emitted, saveSound(r, ev))
+		}
+		for _, p := range sf.pending {
+			sc.Pending = append(sc.Pending, savedPending{
+				Event: saveSound(r, p.ev), Listener: r.soldier(p.listener), Strength: p.strength, Arrival: p.arrival,
+			})
+		}
+	}
+	return sc
+}
+
+func saveSound(r *saveRefs, ev SoundEvent) savedSound {
+	return savedSound{Kind: ev.Kind, X: ev.X, Y: ev.Y, Team: ev.Team, Source: r.soldier(ev.Source), Tick: ev.Tick, Range: ev.Range}
+}
+
+func (s *IntelStore) save() savedIntel {
+	var si savedIntel
+	for _, m := range s.allMaps() {
+		sm := savedIntelMap{
+			Team:     m.Team,
+			Painted:  m.Paint.painted,
+			Pending:  m.Paint.pending,
+			Revision: m.Paint.Revision,
+		}
+		for _, l := range m.layers {
+			sm.Layers = append(sm.Layers, packCells(l.cells))
+		}
+		for _, l := range m.Paint.layers {
+			sm.Paint = append(sm.Paint, packCells(l.cells))
+		}
+		si.Maps = append(si.Maps, sm)
+	}
+	return si
+}
+
+// --- restoring ---
+
+// loadRefs resolves the indices in a savedWorld.
+type loadRefs struct {
+	soldiers []*Soldier
+	squads   []*Squad
+	covers   []*CoverObject
+}
+
+func (r *loadRefs) soldier(i int) *Soldier {
+	if i < 0 || i >= len(r.soldiers) {
+		return nil
+	}
+	return r.soldiers[i]
+}
+
+func (r *loadRefs) soldierList(is []int) []*Soldier {
+	if is == nil {
+		return nil
+	}
+	out := make([]*Soldier, len(is))
+	for i, j := range is {
+		out[i] = r.soldier(j)
+	}
+	return out
+}
+
+func (r *loadRefs) squad(i int) *Squad {
+	if i < 0 || i >= len(r.squads) {
+		return nil
+	}
+	return r.squads[i]
+}
+
+// restore rebuilds the World sw was saved from and wires it up as startSim
+// would.
+func (sw *savedWorld) restore() (*World, error) {
+	if sw.Red < 0 || sw.Red > len(sw.Soldiers) {
+		return nil, fmt.Errorf("save has %d red soldiers out of %d", sw.Red, len(sw.Soldiers))
+	}
+	cfg := sw.Config
+	env := sw.Env
+	env.wetness = sw.Wetness
+	w := &World{
+		gameWidth:  sw.Width,
+		gameHeight: sw.Height,
+		tick:       sw.Tick,
+		jammers:    sw.Jammers,
+		evacPoints: sw.EvacPoints,
+		config:     &cfg,
+		intelMode:  sw.IntelMode,
+		env:        &env,
+		thoughtLog: NewThoughtLog(),
+	}
+	if err := w.restoreTerrain(&sw.Terrain); err != nil {
+		return nil, err
+	}
+
+	r := &loadRefs{covers: w.covers}
+	for range sw.Soldiers {
+		r.soldiers = append(r.soldiers, &Soldier{})
+	}
+	for range sw.Squads {
+		r.squads = append(r.squads, &Squad{})
+	}
+	w.soldiers = r.soldiers[:sw.Red:sw.Red]
+	w.opfor = r.soldiers[sw.Red:]
+	w.squads = r.squads
+
+	w.combat = NewCombatManager(sw.Combat.RNG.Seed)
+	w.combat.restore(&sw.Combat, r)
+	w.intel = NewIntelStore(w.gameWidth, w.gameHeight)
+	w.intel.SetTileMap(w.tileMap)
+	w.intel.SetMode(w.intelMode)
+	if err := w.intel.restore(&sw.Intel); err != nil {
+		return nil, err
+	}
+
+	for i := range sw.Soldiers {
+		sw.Soldiers[i].restore(r.soldiers[i], w, r)
+	}
+	for i := range sw.Squads {
+		if err := sw.Squads[i].restore(r.squads[i], w, r); err != nil {
+			return nil, err
+		}
+	}
+	for i := range sw.Platoons {
+		w.platoons = append(w.platoons, sw.Platoons[i].restore(r))
+	}
+	w.connect()
+	return w, nil
+}
+
+func (w *World) restoreTerrain(t *savedTerrain) error {
+	if t.TileCols > 0 {
+		tiles, err := unpackTiles(t.Tiles, t.TileCols*t.TileRows)
+		if err != nil {
+			return err
+		}
+		w.tileMap = &TileMap{Cols: t.TileCols, Rows: t.TileRows, Tiles: tiles}
+	}
+	w.buildings = restoreRects(t.Buildings)
+	w.windows = restoreRects(t.Windows)
+	w.buildingFootprints = restoreRects(t.Footprints)
+	w.buildingQualities = t.Qualities
+	for _, c := range t.Covers {
+		w.covers = append(w.covers, &CoverObject{x: c.X, y: c.Y, kind: c.Kind})
+	}
+	blocked, err := unpackBits(t.NavBlocked, t.NavCols*t.NavRows)
+	if err != nil {
+		return fmt.Errorf("nav grid: %w", err)
+	}
+	w.navGrid = &NavGrid{cols: t.NavCols, rows: t.NavRows, blocked: blocked, extra: t.NavExtra}
+	if t.Tactical {
+		w.tacticalMap = NewTacticalMap(w.gameWidth, w.gameHeight, w.buildings, w.windows, w.buildingFootprints)
+		for _, i := range t.Opened {
+			w.tacticalMap.openCell(i%w.tacticalMap.cols, i/w.tacticalMap.cols)
+		}
+	}
+	return nil
+}
+
+func (ss *savedSoldier) restore(s *Soldier, w *World, r *loadRefs) {
+	*s = Soldier{
+		id:          ss.ID,
+		label:       ss.Label,
+		x:           ss.X,
+		y:           ss.Y,
+		team:        ss.Team,
+		path:        ss.Path,
+		pathIndex:   ss.PathIndex,
+		startTarget: ss.StartTarget,
+		endTarget:   ss.EndTarget,
+		navGrid:     w.navGrid,
+
+		state:   ss.State,
+		profile: ss.Profile,
+		vision: VisionState{
+			Heading:       ss.Vision.Heading,
+			FOV:           ss.Vision.FOV,
+			MaxRange:      ss.Vision.MaxRange,
+			EnvMul:        ss.Vision.EnvMul,
+			KnownContacts: r.soldierList(ss.Vision.KnownContacts),
+			Suspected:     r.soldierList(ss.Vision.Suspected),
+		},
+		isLeader: ss.IsLeader,
+		squad:    r.squad(ss.Squad),
+
+		body:          ss.Body,
+		isMedic:       ss.IsMedic,
+		litter:        r.soldier(ss.Litter),
+		fireCooldown:  ss.FireCooldown,
+		lastFiredTick: ss.LastFiredTick,
+
+		burstShotsRemaining: ss.BurstShotsRemaining,
+		burstShotIndex:      ss.BurstShotIndex,
+		burstTargetID:       ss.BurstTargetID,
+		burstAnyHit:         ss.BurstAnyHit,
+		burstHitChance:      ss.BurstHitChance,
+		burstDist:           ss.BurstDist,
+		burstBaseSpread:     ss.BurstBaseSpread,
+		aimingTargetID:      ss.AimingTargetID,
+		aimingTicks:         ss.AimingTicks,
+
+		currentFireMode: ss.CurrentFireMode,
+		desiredFireMode: ss.DesiredFireMode,
+		modeSwitchTimer: ss.ModeSwitchTimer,
+
+		blackboard:  ss.Blackboard,
+		prevGoal:    ss.PrevGoal,
+		thoughtLog:  w.thoughtLog,
+		currentTick: &w.tick,
+		debugRing:   make([]SoldierDebugSnapshot, 720),
+
+		formationMember: ss.FormationMember,
+		slotIndex:       ss.SlotIndex,
+		slotTargetX:     ss.SlotTargetX,
+		slotTargetY:     ss.SlotTargetY,
+
+		covers:      w.covers,
+		buildings:   w.buildings,
+		tacticalMap: w.tacticalMap,
+
+		lastSightlineTick:          ss.LastSightlineTick,
+		lastSpeechTick:             ss.LastSpeechTick,
+		radioLastContactReportTick: ss.RadioLastContactReportTick,
+		radioLastStatusReportTick:  ss.RadioLastStatusReportTick,
+		radioLastFearReportTick:    ss.RadioLastFearReportTick,
+		radioLastAmmoReportTick:    ss.RadioLastAmmoReportTick,
+		radioRelay:                 ss.RadioRelay,
+
+		aimSpread:             ss.AimSpread,
+		dashOverwatchTimer:    ss.DashOverwatchTimer,
+		boundHoldTicks:        ss.BoundHoldTicks,
+		boundDestX:            ss.BoundDestX,
+		boundDestY:            ss.BoundDestY,
+		boundDestSet:          ss.BoundDestSet,
+		suppressionAbort:      ss.SuppressionAbort,
+		postArrivalTimer:      ss.PostArrivalTimer,
+		peekTarget:            ss.PeekTarget,
+		peekTimer:             ss.PeekTimer,
+		goalPauseTimer:        ss.GoalPauseTimer,
+		cognitionPauseTimer:   ss.CognitionPauseTimer,
+		nextCognitionTick:     ss.NextCognitionTick,
+		pendingStance:         ss.PendingStance,
+		stanceTransitionTimer: ss.StanceTransitionTimer,
+		mobilityStallTicks:    ss.MobilityStallTicks,
+
+		magCapacity: ss.MagCapacity,
+		magRounds:   ss.MagRounds,
+		reloadTimer: ss.ReloadTimer,
+		spareMags:   ss.SpareMags,
+		dryNoted:    ss.DryNoted,
+
+		grenades:      ss.Grenades,
+		grenadeWindup: ss.GrenadeWindup,
+		grenadeReady:  ss.GrenadeReady,
+		grenadeAimX:   ss.GrenadeAimX,
+		grenadeAimY:   ss.GrenadeAimY,
+		smokeGrenades: ss.SmokeGrenades,
+		smokeReady:    ss.SmokeReady,
+		smokeAimX:     ss.SmokeAimX,
+		smokeAimY:     ss.SmokeAimY,
+
+		stepX:      ss.StepX,
+		stepY:      ss.StepY,
+		soundCell:  ss.SoundCell,
+		doorNoise:  ss.DoorNoise,
+		doorTimer:  ss.DoorTimer,
+		doorAction: ss.DoorAction,
+		doorCol:    ss.DoorCol,
+		doorRow:    ss.DoorRow,
+		inDoorway:  ss.InDoorway,
+		doorwayCol: ss.DoorwayCol,
+		doorwayRow: ss.DoorwayRow,
+
+		recoveryNoPathStreak:        ss.RecoveryNoPathStreak,
+		recoveryRouteFailEMA:        ss.RecoveryRouteFailEMA,
+		recoveryStallEMA:            ss.RecoveryStallEMA,
+		recoveryCommitTicks:         ss.RecoveryCommitTicks,
+		recoveryAction:              ss.RecoveryAction,
+		recoveryAttempts:            ss.RecoveryAttempts,
+		recoverySuccesses:           ss.RecoverySuccesses,
+		recoveryActionCounts:        ss.RecoveryActionCounts,
+		recoveryActionSuccessCounts: ss.RecoveryActionSuccessCounts,
+		recoveryExploreTriggers:     ss.RecoveryExploreTriggers,
+		recoveryExploreSuccesses:    ss.RecoveryExploreSuccesses,
+		recoveryExploreThisTick:     ss.RecoveryExploreThisTick,
+	}
+	bb := &s.blackboard
+	bb.suppressSpiked = ss.SuppressSpiked
+	bb.suppressed = ss.Suppressed
+	bb.ownThresholds = ss.OwnThresholds
+	for _, t := range ss.Threats {
+		bb.Threats = append(bb.Threats, ThreatFact{
+			Source: r.soldier(t.Source), X: t.X, Y: t.Y, Confidence: t.Confidence,
+			LastTick: t.LastTick, IsVisible: t.IsVisible, Uncertain: t.Uncertain,
+		})
+	}
+	for _, t := range ss.Vision.Tracks {
+		s.vision.tracks = append(s.vision.tracks, detectionTrack{target: r.soldier(t.Target), level: t.Level, seen: t.Seen})
+	}
+	if ss.CoverTarget >= 0 && ss.CoverTarget < len(r.covers) {
+		s.coverTarget = r.covers[ss.CoverTarget]
+	}
+	if st := ss.Steering; st != nil {
+		sb := NewSteeringBehavior(s)
+		sb.maxSpeed, sb.stuckTicks, sb.lastX, sb.lastY = st.MaxSpeed, st.StuckTicks, st.LastX, st.LastY
+		s.steeringBehavior = sb
+	}
+	if ss.Weapon != nil {
+		s.weapon = weaponFor(*ss.Weapon)
+		bb.weapon = s.weapon
+	}
+	ss.Casualty.restore(&s.casualty, s.body.Wounds, r)
+}
+
+func (sc *savedCasualty) restore(c *CasualtyState, wounds []Wound, r *loadRefs) {
+	*c = CasualtyState{
+		Phase:            sc.Phase,
+		PhaseTick:        sc.PhaseTick,
+		SelfAidActive:    sc.SelfAidActive,
+		Providers:        r.soldierList(sc.Providers),
+		BuddyAidAttempts: sc.BuddyAidAttempts,
+		BuddyAidSuccess:  sc.BuddyAidSuccess,
+		MedicAidAttempts: sc.MedicAidAttempts,
+		MedicAidSuccess:  sc.MedicAidSuccess,
+		BeingDragged:     sc.BeingDragged,
+		Dragger:          r.soldier(sc.Dragger),
+		DragTargetX:      sc.DragTargetX,
+		DragTargetY:      sc.DragTargetY,
+		ReportSent:       sc.ReportSent,
+		StabilizedTick:   sc.StabilizedTick,
+		Bearers:          r.soldierList(sc.Bearers),
+		AtCCP:            sc.AtCCP,
+		Evacuated:        sc.Evacuated,
+		EvacuatedTick:    sc.EvacuatedTick,
+	}
+	if st := sc.Treat; st != nil {
+		t := &TreatmentAttempt{
+			Action:      st.Action,
+			Provider:    r.soldier(st.Provider),
+			TicksLeft:   st.TicksLeft,
+			Interrupted: st.Interrupted,
+			SkillLevel:  st.SkillLevel,
+		}
+		switch {
+		case st.Wound >= 0 && st.Wound < len(wounds):
+			t.TargetWound = &wounds[st.Wound]
+		case st.Detached != nil:
+			w := *st.Detached
+			t.TargetWound = &w
+		}
+		c.CurrentTreat = t
+	}
+}
+
+func (ss *savedSquad) restore(sq *Squad, w *World, r *loadRefs) error {
+	*sq = Squad{
+		ID:        ss.ID,
+		Team:      ss.Team,
+		Leader:    r.soldier(ss.Leader),
+		Members:   r.soldierList(ss.Members),
+		Formation: ss.Formation,
+		Intent:    ss.Intent,
+		Phase:     ss.Phase,
+
+		supportTargetX:     ss.SupportTargetX,
+		supportTargetY:     ss.SupportTargetY,
+		supportActiveUntil: ss.SupportActiveUntil,
+		platoonTask:        ss.PlatoonTask,
+		ActiveOrder:        ss.ActiveOrder,
+		nextOrderID:        ss.NextOrderID,
+		smoothedHeading:    ss.SmoothedHeading,
+		headingInit:        ss.HeadingInit,
+		EnemyBearing:       ss.EnemyBearing,
+
+		leaderDeadTick:       ss.LeaderDeadTick,
+		leaderSuccessionTick: ss.LeaderSuccessionTick,
+		leaderSucceeding:     ss.LeaderSucceeding,
+
+		ClaimedBuildingIdx:         ss.ClaimedBuildingIdx,
+		claimEvalTick:              ss.ClaimEvalTick,
+		claimedNoContactTicks:      ss.ClaimedNoContactTicks,
+		claimedOccupiedTicks:       ss.ClaimedOccupiedTicks,
+		buildingClaimCooldownUntil: ss.BuildingClaimCooldownUntil,
+		buildingState:              ss.BuildingState,
+		smokeCooldownUntil:         ss.SmokeCooldownUntil,
+		ammoRequests:               ss.AmmoRequests,
+		ammoShareNextTick:          ss.AmmoShareNextTick,
+		ccpX:                       ss.CCPX,
+		ccpY:                       ss.CCPY,
+		ccpSet:                     ss.CCPSet,
+
+		intentLockUntil:      ss.IntentLockUntil,
+		lastIntentChangeTick: ss.LastIntentChangeTick,
+		lastFormationLeaderX: ss.LastFormationLeaderX,
+		lastFormationLeaderY: ss.LastFormationLeaderY,
+		lastFormationUpdate:  ss.LastFormationUpdate,
+		lastFormation:        ss.LastFormation,
+		stalemateTicks:       ss.StalemateTicks,
+		proactivePushUntil:   ss.ProactivePushUntil,
+		lastStalledOrderTick: ss.LastStalledOrderTick,
+		lastStalledOrderID:   ss.LastStalledOrderID,
+		phaseInit:            ss.PhaseInit,
+		phaseEnteredTick:     ss.PhaseEnteredTick,
+		lastProgressTick:     ss.LastProgressTick,
+		lastProgressMetric:   ss.LastProgressMetric,
+		BoundMovingGroup:     ss.BoundMovingGroup,
+		boundCycleTick:       ss.BoundCycleTick,
+		boundCycleActive:     ss.BoundCycleActive,
+		lastContactTick:      ss.LastContactTick,
+
+		CasualtyRate:          ss.CasualtyRate,
+		Stress:                ss.Stress,
+		StressDelta:           ss.StressDelta,
+		AvgMorale:             ss.AvgMorale,
+		MoraleDelta:           ss.MoraleDelta,
+		Cohesion:              ss.Cohesion,
+		CohesionDelta:         ss.CohesionDelta,
+		Broken:                ss.Broken,
+		breakLockEnd:          ss.BreakLockEnd,
+		breakPressureTicks:    ss.BreakPressureTicks,
+		cohesionShock:         ss.CohesionShock,
+		prevInjuredAliveCount: ss.PrevInjuredAliveCount,
+		prevCasualtyCount:     ss.PrevCasualtyCount,
+
+		radioNet:                   ss.RadioNet.restore(),
+		radioPendingStatus:         make(map[int]int, len(ss.RadioPendingStatus)),
+		radioStatusReplyQueued:     make(map[int]bool, len(ss.RadioStatusReplyQueued)),
+		radioUnresponsive:          make(map[int]bool, len(ss.RadioUnresponsive)),
+		radioStatusRequestCursor:   ss.RadioStatusRequestCursor,
+		radioLastStatusRequestTick: ss.RadioLastStatusRequestTick,
+		radioChannelBusyUntil:      ss.RadioChannelBusyUntil,
+		radioInFlight:              ss.RadioInFlight.restore(),
+		RadioQueued:                ss.RadioQueued,
+		RadioSent:                  ss.RadioSent,
+		RadioReceived:              ss.RadioReceived,
+		RadioDropped:               ss.RadioDropped,
+		RadioGarbled:               ss.RadioGarbled,
+		RadioTimeouts:              ss.RadioTimeouts,
+	}
+	for k, v := range ss.RadioPendingStatus {
+		sq.radioPendingStatus[k] = v
+	}
+	for k, v := range ss.RadioStatusReplyQueued {
+		sq.radioStatusReplyQueued[k] = v
+	}
+	for k, v := range ss.RadioUnresponsive {
+		sq.radioUnresponsive[k] = v
+	}
+	if ss.HasBuildingIntel {
+		sq.buildingIntel = NewBuildingIntelMap()
+		for k, bi := range ss.BuildingIntel {
+			bi := bi
+			sq.buildingIntel.buildings[k] = &bi
+		}
+	}
+	if ss.EntryPlan != nil {
+		p := ss.EntryPlan.BuildingEntryPlan
+		p.EntryTeam = r.soldierList(ss.EntryPlan.EntryTeam)
+		p.OverwatchTeam = r.soldierList(ss.EntryPlan.OverwatchTeam)
+		sq.entryPlan = &p
+	}
+	if ss.Paint {
+		sq.paint = w.intel.PaintFor(sq.Team)
+	}
+	if ss.IntelMap >= 0 {
+		if ss.IntelMap >= len(w.intel.squadMaps) {
+			return fmt.Errorf("squad %d: no intel map %d", ss.ID, ss.IntelMap)
+		}
+		sq.intel = w.intel.squadMaps[ss.IntelMap]
+	}
+	if ss.Flow != nil {
+		if err := ss.Flow.restore(sq, w); err != nil {
+			return fmt.Errorf("squad %d flow field: %w", ss.ID, err)
+		}
+	}
+	return nil
+}
+
+func (sn *savedRadioNet) restore() radioNet {
+	return radioNet{netID: sn.NetID, kind: sn.Kind, name: sn.Name, nextID: sn.NextID, pending: sn.Pending}
+}
+
+func (st *savedTransmission) restore() *radioTransmission {
+	if st == nil {
+		return nil
+	}
+	return &radioTransmission{msg: st.Msg, dispatchTick: st.DispatchTick, arrivalTick: st.ArrivalTick, resolvedMsg: st.ResolvedMsg, outcome: st.Outcome}
+}
+
+// restore gives sq a flow controller like the saved one. Each computed layer
+// is recomputed from the occupancy, paint and goals it was computed from
+// before the cost field gets its current inputs back.
+func (sf *savedFlow) restore(sq *Squad, w *World) error {
+	sfc := NewSquadFlowController(sq, w.navGrid, w.tacticalMap)
+	cf := sfc.costField
+	n := len(cf.baseCost)
+	blocked, err := unpackBits(sf.BaseBlocked, n)
+	if err != nil {
+		return err
+	}
+	for i, b := range blocked {
+		cf.baseCost[i] = 1
+		if b {
+			cf.baseCost[i] = math.Inf(1)
+		}
+	}
+	cf.threatCost = sf.Threat.dense(n)
+	paints := make([][]float64, len(sf.Paints))
+	for i, p := range sf.Paints {
+		paints[i] = p.dense(n)
+	}
+	paint := func(i int) ([]float64, error) {
+		if i < 0 {
+			return nil, nil
+		}
+		if i >= len(paints) {
+			return nil, fmt.Errorf("no paint costs %d", i)
+		}
+		return paints[i], nil
+	}
+	occupied := func(cells []int) error {
+		for _, i := range cells {
+			if i < 0 || i >= n {
+				return fmt.Errorf("occupied cell %d out of range", i)
+			}
+		}
+		cf.setOccupiedCells(cells)
+		return nil
+	}
+	layer := func(sl *savedFlowLayer, flow *FlowField) (*IntegrationField, layerInputs, error) {
+		if !sl.Computed {
+			return nil, layerInputs{}, nil
+		}
+		p, err := paint(sl.Paint)
+		if err != nil {
+			return nil, layerInputs{}, err
+		}
+		if err := occupied(sl.Occupied); err != nil {
+			return nil, layerInputs{}, err
+		}
+		cf.paintCost = p
+		in := NewIntegrationField(sfc.width, sfc.height, sl.Goals)
+		in.Compute(cf)
+		flow.Generate(in)
+		return in, layerInputs{occupied: sl.Occupied, paint: p}, nil
+	}
+	if sfc.strategicIntegration, sfc.strategicInputs, err = layer(&sf.Strategic, sfc.strategicFlow); err != nil {
+		return err
+	}
+	if sfc.tacticalIntegration, sfc.tacticalInputs, err = layer(&sf.Tactical, sfc.tacticalFlow); err != nil {
+		return err
+	}
+	if cf.paintCost, err = paint(sf.Paint); err != nil {
+		return err
+	}
+	if err := occupied(sf.Occupied); err != nil {
+		return err
+	}
+
+	sfc.strategicGoals = sf.Goals
+	sfc.tacticalGoals = sf.TacticalGoals
+	sfc.updateTicks = sf.UpdateTicks
+	sfc.strategicDirty = sf.StrategicDirty
+	sfc.tacticalDirty = sf.TacticalDirty
+	sfc.paint = sq.paint
+	sfc.paintCostRev = sf.PaintCostRev
+	sfc.paintGoalsRev = sf.PaintGoalsRev
+	sq.flowController = sfc
+	return nil
+}
+
+func (sp *savedPlatoon) restore(r *loadRefs) *Platoon {
+	p := &Platoon{
+		ID:     sp.ID,
+		Team:   sp.Team,
+		Leader: r.soldier(sp.Leader),
+		Phase:  sp.Phase,
+		Roles:  sp.Roles,
+
+		phaseEnteredTick:     sp.PhaseEnteredTick,
+		contactX:             sp.ContactX,
+		contactY:             sp.ContactY,
+		lastContactTick:      sp.LastContactTick,
+		supportX:             sp.SupportX,
+		supportY:             sp.SupportY,
+		flankX:               sp.FlankX,
+		flankY:               sp.FlankY,
+		axisX:                sp.AxisX,
+		axisY:                sp.AxisY,
+		axisSet:              sp.AxisSet,
+		successor:            r.soldier(sp.Successor),
+		leaderSuccessionTick: sp.LeaderSuccessionTick,
+
+		radioNet:              sp.RadioNet.restore(),
+		radioInFlight:         sp.RadioInFlight.restore(),
+		radioChannelBusyUntil: sp.RadioChannelBusyUntil,
+		RadioSent:             sp.RadioSent,
+		RadioReceived:         sp.RadioReceived,
+		RadioDropped:          sp.RadioDropped,
+		RadioGarbled:          sp.RadioGarbled,
+	}
+	for _, i := range sp.Squads {
+		p.Squads = append(p.Squads, r.squad(i))
+	}
+	for _, st := range sp.State {
+		p.state = append(p.state, platoonSquadState{
+			report: st.Report, sent: st.Sent, sitrepTick: st.SitrepTick, sitrepContact: st.SitrepContact, lane: st.Lane,
+		})
+	}
+	return p
+}
+
+func (cm *CombatManager) restore(sc *savedCombat, r *loadRefs) {
+	cm.rngSrc.restore(sc.RNG)
+	cm.tick = sc.Tick
+	for _, t := range sc.Tracers {
+		cm.tracers = append(cm.tracers, &Tracer{
+			fromX: t.FromX, fromY: t.FromY, toX: t.ToX, toY: t.ToY,
+			hit: t.Hit, team: t.Team, age: t.Age, fractionalAge: t.FractionalAge,
+		})
+	}
+	for _, f := range sc.Flashes {
+		cm.flashes = append(cm.flashes, &MuzzleFlash{x: f.X, y: f.Y, angle: f.Angle, team: f.Team, age: f.Age})
+	}
+	for _, g := range sc.Grenades {
+		gr := g.Grenade
+		gr.flightTick, gr.flightTicks = g.FlightTick, g.FlightTicks
+		cm.grenades = append(cm.grenades, &gr)
+	}
+	for _, b := range sc.Blasts {
+		cm.blasts = append(cm.blasts, &blast{x: b.X, y: b.Y, age: b.Age})
+	}
+	smoke := sc.Smoke
+	cm.Smoke = &smoke
+	for _, ev := range sc.Emitted {
+		cm.Sound.emitted = append(cm.Sound.emitted, ev.restore(r))
+	}
+	for _, p := range sc.Pending {
+		cm.Sound.pending = append(cm.Sound.pending, pendingSound{
+			ev: p.Event.restore(r), listener: r.soldier(p.Listener), strength: p.Strength, arrival: p.Arrival,
+		})
+	}
+}
+
+func (ss *savedSound) restore(r *loadRefs) SoundEvent {
+	return SoundEvent{Kind: ss.Kind, X: ss.X, Y: ss.Y, Team: ss.Team, Source: r.soldier(ss.Source), Tick: ss.Tick, Range: ss.Range}
+}
+
+// restore loads si into a store that already has its team maps, making the
+// squad maps as it goes.
+func (s *IntelStore) restore(si *savedIntel) error {
+	if len(si.Maps) < 2 {
+		return fmt.Errorf("intel: %d maps, want the two team maps at least", len(si.Maps))
+	}
+	maps := []*IntelMap{s.maps[TeamRed], s.maps[TeamBlue]}
+	for _, sm := range si.Maps[2:] {
+		maps = append(maps, s.NewSquadMap(sm.Team))
+	}
+	for i, m := range maps {
+		sm := &si.Maps[i]
+		if len(sm.Layers) != len(m.layers) || len(sm.Paint) != len(m.Paint.layers) {
+			return fmt.Errorf("intel map %d: %d heat and %d paint layers, want %d and %d",
+				i, len(sm.Layers), len(sm.Paint), len(m.layers), len(m.Paint.layers))
+		}
+		for k, l := range m.layers {
+			if err := unpackCells(l.cells, sm.Layers[k]); err != nil {
+				return fmt.Errorf("intel map %d layer %d: %w", i, k, err)
+			}
+		}
+		for k, l := range m.Paint.layers {
+			if err := unpackCells(l.cells, sm.Paint[k]); err != nil {
+				return fmt.Errorf("intel map %d paint %d: %w", i, k, err)
+			}
+		}
+		m.Paint.painted = sm.Painted
+		m.Paint.pending = sm.Pending
+		m.Paint.Revision = sm.Revision
+	}
+	return nil
+}
+
+// --- packing ---
+
+func saveRects(rs []rect) [][4]int {
+	if rs == nil {
+		return nil
+	}
+	out := make([][4]int, len(rs))
+	for i, r := range rs {
+		out[i] = [4]int{r.x, r.y, r.w, r.h}
+	}
+	return out
+}
+
+func restoreRects(rs [][4]int) []rect {
+	if rs == nil {
+		return nil
+	}
+	out := make([]rect, len(rs))
+	for i, r := range rs {
+		out[i] = rect{x: r[0], y: r[1], w: r[2], h: r[3]}
+	}
+	return out
+}
+
+// tileBytes is the packed size of one Tile.
+const tileBytes = 6
+
+// packTiles writes each tile as ground, object, flags, elevation and
+// little-endian durability.
+func packTiles(tiles []Tile) []byte {
+	b := make([]byte, len(tiles)*tileBytes)
+	for i, t := range tiles {
+		p := b[i*tileBytes:]
+		p[0], p[1], p[2], p[3] = byte(t.Ground), byte(t.Object), byte(t.Flags), byte(t.Elevation) // #nosec G115 -- bit pattern only
+		binary.LittleEndian.PutUint16(p[4:], uint16(t.Durability))                                // #nosec G115 -- bit pattern only
+	}
+	return b
+}
+
+func unpackTiles(b []byte, n int) ([]Tile, error) {
+	if len(b) != n*tileBytes {
+		return nil, fmt.Errorf("tile map: %d bytes for %d tiles", len(b), n)
+	}
+	tiles := make([]Tile, n)
+	for i := range tiles {
+		p := b[i*tileBytes:]
+		tiles[i] = Tile{
+			Ground:     GroundType(p[0]),
+			Object:     ObjectType(p[1]),
+			Flags:      TileFlags(p[2]),
+			Elevation:  int8(p[3]),                               // #nosec G115 -- bit pattern only
+			Durability: int16(binary.LittleEndian.Uint16(p[4:])), // #nosec G115 -- bit pattern only
+		}
+	}
+	return tiles, nil
+}
+
+func packBits(bs []bool) []byte {
+	b := make([]byte, (len(bs)+7)/8)
+	for i, v := range bs {
+		if v {
+			b[i/8] |= 1 << (i % 8)
+		}
+	}
+	return b
+}
+
+func unpackBits(b []byte, n int) ([]bool, error) {
+	if len(b) != (n+7)/8 {
+		return nil, fmt.Errorf("%d bytes for %d bits", len(b), n)
+	}
+	bs := make([]bool, n)
+	for i := range bs {
+		bs[i] = b[i/8]&(1<<(i%8)) != 0
+	}
+	return bs, nil
+}
+
+// packCells writes heat cells as little-endian float32 bits, or nil when
+// every cell is zero.
+func packCells(cells []float32) []byte {
+	empty := true
+	for _, c := range cells {
+		if c != 0 {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		return nil
+	}
+	b := make([]byte, len(cells)*4)
+	for i, c := range cells {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(c))
+	}
+	return b
+}
+
+func unpackCells(cells []float32, b []byte) error {
+	if b == nil {
+		for i := range cells {
+			cells[i] = 0
+		}
+		return nil
+	}
+	if len(b) != len(cells)*4 {
+		return fmt.Errorf("%d bytes for %d cells", len(b), len(cells))
+	}
+	for i := range cells {
+		cells[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package game
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// saveStateVersion is bumped whenever the saved state layout changes. A save
+// only loads into the version that wrote it.
+const saveStateVersion = 3
+
+// ErrSaveMismatch is returned when a restored sim does not match the digest
+// taken when it was saved, i.e. the save file is damaged or was edited.
+var ErrSaveMismatch = errors.New("restored state does not match save")
+
+// SaveState is a resumable checkpoint of a running simulation.
+//
+// State is an explicit snapshot of the sim (see savedata.go): soldiers with
+// their blackboards and wounds, squads with their orders and radio queues,
+// intel heat and paint, terrain damage, and every RNG as a seed plus a draw
+// count. Restoring rebuilds the sim from it directly, so it costs the same
+// at any tick. Render, log and analytics state is not saved and starts fresh.
+// Digest hashes the state at save time and is checked after restoring.
+type SaveState struct {
+	Version  int          `json:"version"`
+	Source   string       `json:"source"` // "game" or "headless"
+	Seed     int64        `json:"seed"`
+	Tick     int          `json:"tick"`
+	Branches []SaveBranch `json:"branches,omitempty"`
+	State    *savedSim    `json:"state"`
+	Digest   string       `json:"digest"`
+}
+
+// savedSim is the World plus whatever the TestSim or Game around it keeps.
+type savedSim struct {
+	World *savedWorld `json:"world"`
+
+	// TestSim only: soldiers in the order they were added (indices into
+	// World.Soldiers), the map size and the harness RNG.
+	Soldiers []int    `json:"soldiers,omitempty"`
+	Width    int      `json:"width,omitempty"`
+	Height   int      `json:"height,omitempty"`
+	RNG      RNGState `json:"rng"`
+
+	// Game only.
+	CommandDelay int                 `json:"command_delay,omitempty"`
+	AAROpen      bool                `json:"aar_open,omitempty"`
+	AARReason    BattleOutcomeReason `json:"aar_reason"`
+	Speech       RNGState            `json:"speech"`
+
+	NextID int `json:"next_id"`
+}
+
+// SaveBranch records a reseed of the combat RNG after Tick, forking the
+// battle from that point onward.
+type SaveBranch struct {
+	Tick int   `json:"tick"`
+	Seed int64 `json:"seed"`
+}
+
+// RNGState is the position of a seeded RNG in its stream.
+type RNGState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+// countingSource wraps a math/rand source and counts draws, so an RNG's
+// position in its stream can be saved and restored.
+type countingSource struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64), seed: seed} // #nosec G404 -- game only
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.seed = seed
+	c.draws = 0
+}
+
+func (c *countingSource) state() RNGState {
+	return RNGState{Seed: c.seed, Draws: c.draws}
+}
+
+// restore reseeds the source and replays its stream up to st. Every draw
+// advances the underlying source by one step, so this lands exactly where
+// the saved source was.
+func (c *countingSource) restore(st RNGState) {
+	c.Seed(st.Seed)
+	for c.draws < st.Draws {
+		c.Uint64()
+	}
+}
+
+// --- TestSim ---
+
+// SaveState checkpoints the sim at the current tick. The SimLog, reporter
+// and performance trackers are not saved; a restored sim starts fresh ones.
+func (ts *TestSim) SaveState() (*SaveState, error) {
+	ss := &savedSim{
+		World:  ts.save(),
+		Width:  ts.Width,
+		Height: ts.Height,
+		RNG:    ts.rngSrc.state(),
+		NextID: ts.nextID,
+	}
+	refs := newSaveRefs(ts.World)
+	ss.Soldiers = refs.soldierList(ts.Soldiers)
+	return &SaveState{
+		Version:  saveStateVersion,
+		Source:   "headless",
+		Seed:     ts.seed,
+		Tick:     ts.tick,
+		Branches: append([]SaveBranch(nil), ts.branches...),
+		State:    ss,
+		Digest:   ts.stateDigest(),
+	}, nil
+}
+
+// Branch reseeds the combat RNG so the battle diverges from here. Saves
+// taken afterwards record the branch.
+func (ts *TestSim) Branch(seed int64) {
+	ts.combat.rng.Seed(seed)
+	ts.branches = append(ts.branches, SaveBranch{Tick: ts.tick, Seed: seed})
+}
+
+// RestoreTestSim rebuilds a headless save.
+func RestoreTestSim(st *SaveState) (*TestSim, error) {
+	if err := st.validate("headless"); err != nil {
+		return nil, err
+	}
+	w, err := st.State.World.restore()
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	all := w.allSoldiers()
+	src := newCountingSource(st.State.RNG.Seed)
+	src.restore(st.State.RNG)
+	ts := &TestSim{
+		World:        w,
+		Width:        st.State.Width,
+		Height:       st.State.Height,
+		Squads:       w.squads,
+		SimLog:       NewSimLog(false),
+		rng:          rand.New(src), // #nosec G404 -- test harness
+		rngSrc:       src,
+		effProbes:    make(map[int]*effectivenessProbe),
+		PerfTrackers: make(map[int]*PerfTracker),
+		seed:         st.Seed,
+		branches:     append([]SaveBranch(nil), st.Branches...),
+		nextID:       st.State.NextID,
+	}
+	for _, i := range st.State.Soldiers {
+		if i < 0 || i >= len(all) {
+			return nil, fmt.Errorf("restore: no soldier %d", i)
+		}
+		ts.Soldiers = append(ts.Soldiers, all[i])
+	}
+	ts.Reporter = NewSimReporter(reportWindowTicks, true)
+	ts.reporter = ts.Reporter
+	hasBuildings := len(ts.buildings) > 0
+	for _, s := range ts.Soldiers {
+		ts.effProbes[s.id] = &effectivenessProbe{lastX: s.x, lastY: s.y}
+		ts.PerfTrackers[s.id] = NewPerfTracker(s, hasBuildings)
+	}
+	if err := st.check(ts.tick, ts.stateDigest()); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (ts *TestSim) stateDigest() string {
+	return digestState(ts.tick, ts.Soldiers, ts.Squads, ts.intel, ts.tileMap, ts.combat.rngSrc.state())
+}
+
+// --- Game ---
+
+// SaveState checkpoints the game at the current tick. Render buffers, the
+// camera and the thought log are not saved.
+func (g *Game) SaveState() (*SaveState, error) {
+	return &SaveState{
+		Version:  saveStateVersion,
+		Source:   "game",
+		Seed:     g.mapSeed,
+		Tick:     g.tick,
+		Branches: append([]SaveBranch(nil), g.branches...),
+		State: &savedSim{
+			World:        g.save(),
+			CommandDelay: g.commandDelay,
+			AAROpen:      g.aarOpen,
+			AARReason:    g.aarReason,
+			Speech:       g.speechSrc.state(),
+			NextID:       g.nextID,
+		},
+		Digest: g.stateDigest(),
+	}, nil
+}
+
+// Branch reseeds the combat RNG so the battle diverges from here.
+func (g *Game) Branch(seed int64) {
+	g.combat.rng.Seed(seed)
+	g.branches = append(g.branches, SaveBranch{Tick: g.tick, Seed: seed})
+}
+
+// RestoreGame rebuilds an interactive save. The restored game starts
+// paused.
+func RestoreGame(st *SaveState) (*Game, error) {
+	g, err := restoreSimGame(st)
+	if err != nil {
+		return nil, err
+	}
+	g.initRenderBuffers()
+	return g, nil
+}
+
+// restoreSimGame is RestoreGame without any Ebiten resources.
+func restoreSimGame(st *SaveState) (*Game, error) {
+	if err := st.validate("game"); err != nil {
+		return nil, err
+	}
+	w, err := st.State.World.restore()
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	g := &Game{
+		World:    w,
+		width:    borderWidth + w.gameWidth + borderWidth + logPanelWidth,
+		height:   borderWidth + w.gameHeight + borderWidth,
+		offX:     borderWidth,
+		offY:     borderWidth,
+		nextID:   st.State.NextID,
+		showHUD:  true,
+		prevKeys: make(map[ebiten.Key]bool),
+		mapSeed:  st.Seed,
+		branches: append([]SaveBranch(nil), st.Branches...),
+
+		paintRadius:  paintDefaultRadius,
+		commandDelay: st.State.CommandDelay,
+		aarOpen:      st.State.AAROpen,
+		aarReason:    st.State.AARReason,
+	}
+	g.reporter = NewSimReporter(reportWindowTicks, false)
+	g.initTerrainPatches()
+	g.initViewState()
+	g.speechSrc = newCountingSource(st.State.Speech.Seed)
+	g.speechSrc.restore(st.State.Speech)
+	g.speechRng = rand.New(g.speechSrc) // #nosec G404 -- non-crypto RNG for local flavor text
+	if err := st.check(g.tick, g.stateDigest()); err != nil {
+		return nil, err
+	}
+	if !g.aarOpen {
+		g.simSpeed = 0
+	}
+	return g, nil
+}
+
+func (g *Game) stateDigest() string {
+	return digestState(g.tick, g.allSoldiers(), g.squads, g.intel, g.tileMap, g.combat.rngSrc.state())
+}
+
+// --- file I/O ---
+
+func (st *SaveState) validate(source string) error {
+	if st.Version != saveStateVersion {
+		return fmt.Errorf("unsupported save version %d (want %d)", st.Version, saveStateVersion)
+	}
+	if st.Source != source {
+		return fmt.Errorf("save is from %q, not %q", st.Source, source)
+	}
+	if st.State == nil || st.State.World == nil {
+		return fmt.Errorf("save has no state")
+	}
+	return nil
+}
+
+func (st *SaveState) check(tick int, digest string) error {
+	if tick != st.Tick {
+		return fmt.Errorf("%w: state is at tick %d, save says %d", ErrSaveMismatch, tick, st.Tick)
+	}
+	if digest != st.Digest {
+		return fmt.Errorf("%w at tick %d: digest %s, saved %s", ErrSaveMismatch, st.Tick, digest, st.Digest)
+	}
+	return nil
+}
+
+// Write encodes the checkpoint as gzip-compressed JSON.
+func (st *SaveState) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(st); err != nil {
+		return fmt.Errorf("encode save: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress save: %w", err)
+	}
+	return nil
+}
+
+// Save writes the checkpoint to path.
+func (st *SaveState) Save(path string) error {
+	f, err := os.Create(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return fmt.Errorf("create save %s: %w", path, err)
+	}
+	if err := st.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close save %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadSaveState decodes a checkpoint written by SaveState.Write.
+func ReadSaveState(r io.Reader) (*SaveState, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	var st SaveState
+	if err := json.NewDecoder(zr).Decode(&st); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return &st, nil
+}
+
+// LoadSaveState reads a checkpoint written by SaveState.Save.
+func LoadSaveState(path string) (*SaveState, error) {
+	f, err := os.Open(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("open save %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	st, err := ReadSaveState(f)
+	if err != nil {
+		return nil, fmt.Errorf("save %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// --- digest ---
+
+// stateHasher folds sim values into an FNV-64a hash.
+type stateHasher struct {
+	h   hash.Hash64
+	buf [8]byte
+}
+
+func (sh *stateHasher) u(v uint64) {
+	binary.LittleEndian.PutUint64(sh.buf[:], v)
+	_, _ = sh.h.Write(sh.buf[:])
+}
+
+func (sh *stateHasher) i(v int) { sh.u(uint64(int64(v))) } // #nosec G115 -- bit pattern only
+
+func (sh *stateHasher) f(v float64) { sh.u(math.Float64bits(v)) }
+
+func (sh *stateHasher) b(v bool) {
+	if v {
+		sh.u(1)
+	} else {
+		sh.u(0)
+	}
+}
+
+// digestState hashes everything a resumed battle depends on. intel and tm
+// may be nil (an open-field TestSim has no tile map).
+func digestState(tick int, soldiers []*Soldier, squads []*Squad, intel *IntelStore, tm *TileMap, rng RNGState) string {
+	sh := &stateHasher{h: fnv.New64a()}
+	sh.i(tick)
+	sh.u(uint64(rng.Seed)) // #nosec G115 -- bit pattern only
+	sh.u(rng.Draws)
+
+	for _, s := range soldiers {
+		sh.i(s.id)
+		sh.f(s.x)
+		sh.f(s.y)
+		sh.f(s.vision.Heading)
+		sh.i(len(s.vision.tracks))
+		for _, tr := range s.vision.tracks {
+			sh.f(tr.level)
+		}
+		sh.i(int(s.state))
+		sh.i(int(s.profile.Stance))
+		sh.i(s.pathIndex)
+		sh.i(len(s.path))
+		sh.i(int(s.firearm().Kind))
+		sh.i(s.magRounds)
+		sh.i(s.spareMags)
+		sh.i(s.grenades)
+		sh.i(s.smokeGrenades)
+		sh.i(s.fireCooldown)
+		sh.i(s.doorTimer)
+		sh.f(s.aimSpread)
+
+		p := &s.profile.Psych
+		sh.f(p.Fear)
+		sh.f(p.Morale)
+		sh.f(p.Composure)
+
+		bb := &s.blackboard
+		sh.i(int(bb.CurrentGoal))
+		sh.i(int(bb.SquadIntent))
+		sh.i(len(bb.Threats))
+		for _, t := range bb.Threats {
+			sh.f(t.X)
+			sh.f(t.Y)
+			sh.b(t.IsVisible)
+			sh.b(t.Uncertain)
+		}
+		sh.f(bb.SuppressLevel)
+		sh.f(bb.CombatMemoryStrength)
+		sh.i(int(bb.OfficerOrderKind))
+		sh.b(bb.OfficerOrderActive)
+		sh.b(bb.PanicLocked)
+		sh.b(bb.DisobeyingOrders)
+		sh.b(bb.PanicRetreatActive)
+		sh.b(bb.Surrendered)
+
+		for r := range s.body.HP {
+			sh.f(s.body.HP[r])
+		}
+		sh.f(s.body.BloodVolume)
+		sh.i(len(s.body.Wounds))
+		for _, w := range s.body.Wounds {
+			sh.i(int(w.Region))
+			sh.i(int(w.Severity))
+			sh.b(w.Treated)
+			sh.f(w.BleedRate)
+			sh.b(w.Stopped)
+		}
+		for _, a := range s.body.Armour {
+			sh.f(a.Condition)
+		}
+	}
+
+	for _, sq := range squads {
+		sh.i(sq.ID)
+		leader := -1
+		if sq.Leader != nil {
+			leader = sq.Leader.id
+		}
+		sh.i(leader)
+		sh.i(int(sq.Intent))
+		sh.i(int(sq.Phase))
+		sh.f(sq.Stress)
+		sh.f(sq.Cohesion)
+		sh.b(sq.Broken)
+		o := &sq.ActiveOrder
+		sh.i(o.ID)
+		sh.i(int(o.Kind))
+		sh.i(int(o.State))
+		sh.i(o.ExpiresTick)
+		sh.f(o.TargetX)
+		sh.f(o.TargetY)
+		sh.i(len(sq.radioNet.pending))
+		for _, m := range sq.radioNet.pending {
+			sh.u(m.ID)
+			sh.i(int(m.Type))
+		}
+		sh.b(sq.radioInFlight != nil)
+		sh.i(sq.radioChannelBusyUntil)
+		sh.i(sq.RadioSent)
+	}
+
+	if intel != nil {
+		for _, m := range intel.allMaps() {
+			for _, l := range append(m.layers[:], m.Paint.layers[:]...) {
+				sum := 0.0
+				for _, c := range l.cells {
+					sum += float64(c)
+				}
+				sh.f(sum)
+			}
+		}
+	}
+
+	if tm != nil {
+		for i := range tm.Tiles {
+			t := &tm.Tiles[i]
+			sh.u(uint64(t.Object)<<32 | uint64(uint16(t.Durability))<<16 | uint64(t.Flags)) // #nosec G115 -- packed for hashing
+		}
+	}
+
+	return fmt.Sprintf("%016x", sh.h.Sum64())
+}
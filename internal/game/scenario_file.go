@@ -0,0 +1,593 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ScenarioFile is a declarative description of a headless battle. It is
+// loaded from JSON so regression scenarios can be kept as data files instead
+// of hard-coded WithRedSoldier/WithBlueSoldier calls.
+//
+//	{
+//	  "name": "mutual-advance",
+//	  "map": {"width": 3072, "height": 1728, "generate": true},
+//	  "soldiers": [{"id": 0, "team": "red", "start": [80, 864], "objective": [2992, 864]}],
+//	  "squads": [{"team": "red", "members": [0], "loadout": ["lmg"], "armour": "full"}],
+//	  "platoons": [{"squads": [0, 1]}],
+//	  "intel": "radio",
+//	  "jammers": [{"team": "blue", "x": 1536, "y": 864, "radius": 400, "strength": 0.5}],
+//	  "evac_points": [{"team": "red", "x": 40, "y": 864}],
+//	  "environment": {"hour": 23, "rain": 0.4, "fog": 0.2},
+//	  "config": {"hit_stress": 0.3, "accurate_fire_range": 400},
+//	  "stop": {"max_ticks": 3600, "on_outcome": true}
+//	}
+type ScenarioFile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Map         ScenarioMap       `json:"map"`
+	Soldiers    []ScenarioSoldier `json:"soldiers"`
+	Squads      []ScenarioSquad   `json:"squads,omitempty"`
+	Platoons    []ScenarioPlatoon `json:"platoons,omitempty"`
+	Jammers     []ScenarioJammer  `json:"jammers,omitempty"`
+	EvacPoints  []ScenarioEvac    `json:"evac_points,omitempty"`
+	Intel       string            `json:"intel,omitempty"` // "shared" (default) or "radio", see IntelMode
+	Environment *ScenarioEnv      `json:"environment,omitempty"`
+	// Config overrides SimConfig parameters by name (see SimParamNames).
+	Config map[string]float64 `json:"config,omitempty"`
+	Stop   ScenarioStop       `json:"stop"`
+}
+
+// ScenarioMap describes the playfield. When Generate is set the map is built
+// by NewHeadlessBattlefield; otherwise it is an open field with the listed
+// building rectangles.
+type ScenarioMap struct {
+	Width     int            `json:"width"`
+	Height    int            `json:"height"`
+	Seed      int64          `json:"seed,omitempty"`     // 0 = use the run seed
+	Generate  bool           `json:"generate,omitempty"` // procedural battlefield
+	Buildings []ScenarioRect `json:"buildings,omitempty"`
+}
+
+// ScenarioRect is a building obstacle in world pixels (see WithBuilding).
+type ScenarioRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// ScenarioSoldier places one soldier and gives it an objective.
+type ScenarioSoldier struct {
+	ID         int              `json:"id"`
+	Team       string           `json:"team"` // "red" or "blue"
+	Start      [2]float64       `json:"start"`
+	Objective  [2]float64       `json:"objective"`
+	Profile    *ScenarioProfile `json:"profile,omitempty"`
+	RadioRelay bool             `json:"radio_relay,omitempty"` // carries a relay set (see WithRadioRelay)
+	Weapon     string           `json:"weapon,omitempty"`      // rifle (default), lmg, dmr, smg or shotgun
+	Armour     string           `json:"armour,omitempty"`      // none (default), helmet, plate or full
+}
+
+// ScenarioProfile overrides individual SoldierProfile fields. Nil fields keep
+// the DefaultProfile value.
+type ScenarioProfile struct {
+	FitnessBase  *float64 `json:"fitness_base,omitempty"`
+	Marksmanship *float64 `json:"marksmanship,omitempty"`
+	Fieldcraft   *float64 `json:"fieldcraft,omitempty"`
+	Discipline   *float64 `json:"discipline,omitempty"`
+	FirstAid     *float64 `json:"first_aid,omitempty"`
+	Experience   *float64 `json:"experience,omitempty"`
+	Morale       *float64 `json:"morale,omitempty"`
+	Fear         *float64 `json:"fear,omitempty"`
+	Composure    *float64 `json:"composure,omitempty"`
+	Stance       string   `json:"stance,omitempty"` // standing, crouching, prone
+
+	// Goal thresholds for this soldier alone; same names and ranges as the
+	// config parameters.
+	EngageShotQuality    *float64 `json:"engage_shot_quality,omitempty"`
+	LongRangeShotQuality *float64 `json:"long_range_shot_quality,omitempty"`
+	PushOnMissMomentum   *float64 `json:"push_on_miss_momentum,omitempty"`
+	HoldOnHitMomentum    *float64 `json:"hold_on_hit_momentum,omitempty"`
+	CoverFear            *float64 `json:"cover_fear,omitempty"`
+}
+
+// ScenarioSquad groups soldiers (by ID) into a squad. The first member leads.
+// Loadout issues weapons to the members in order: "standard" for the usual
+// eight-man mix (see standardSquadLoadout, cut short for a smaller squad), or
+// at most one weapon name per member. A soldier's own weapon overrides the
+// squad's. Armour dresses every member in one armour kit; a soldier's own
+// armour overrides it.
+type ScenarioSquad struct {
+	Team    string   `json:"team"`
+	Members []int    `json:"members"`
+	Loadout []string `json:"loadout,omitempty"`
+	Armour  string   `json:"armour,omitempty"` // none (default), helmet, plate or full
+}
+
+// ScenarioPlatoon groups squads (by index into squads) under one platoon
+// leader. The first squad's leader commands the platoon.
+type ScenarioPlatoon struct {
+	Squads []int `json:"squads"`
+}
+
+// ScenarioJammer places a radio jammer owned by Team (see Jammer).
+type ScenarioJammer struct {
+	Team     string  `json:"team"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Radius   float64 `json:"radius"`
+	Strength float64 `json:"strength"` // quality lost at the centre, 0-1
+}
+
+// ScenarioEvac places a team's evacuation point (see EvacPoint).
+type ScenarioEvac struct {
+	Team string  `json:"team"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// ScenarioStop controls when a scenario run ends. MaxTicks is always honoured;
+// the boolean conditions end the run early once satisfied.
+type ScenarioStop struct {
+	MaxTicks         int  `json:"max_ticks"`
+	OnOutcome        bool `json:"on_outcome,omitempty"`         // DetermineBattleOutcome is conclusive
+	OnTeamEliminated bool `json:"on_team_eliminated,omitempty"` // either side has no living soldiers
+	OnFirstContact   bool `json:"on_first_contact,omitempty"`   // any soldier has a visible enemy
+}
+
+// LoadScenarioFile reads and validates a JSON scenario from disk.
+func LoadScenarioFile(path string) (*ScenarioFile, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path supplied by the operator
+	if err != nil {
+		return nil, fmt.Errorf("read scenario %s: %w", path, err)
+	}
+	sc, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// ScenarioEnv sets weather and time of day. Omitted fields keep the clear
+// midday defaults.
+type ScenarioEnv struct {
+	Hour      *float64 `json:"hour,omitempty"`       // local time at tick 0, 0-24
+	TimeScale *float64 `json:"time_scale,omitempty"` // sim hours per real hour
+	Rain      *float64 `json:"rain,omitempty"`       // 0-1
+	Fog       *float64 `json:"fog,omitempty"`        // 0-1
+	Wind      *float64 `json:"wind,omitempty"`       // m/s
+	WindDir   *float64 `json:"wind_dir,omitempty"`   // degrees the wind blows toward
+}
+
+// ParseScenario decodes and validates a JSON scenario. Unknown fields are
+// rejected so typos in hand-written files surface immediately.
+func ParseScenario(data []byte) (*ScenarioFile, error) {
+	var sc ScenarioFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sc); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// Validate checks the scenario for structural errors.
+func (sc *ScenarioFile) Validate() error {
+	if sc.Map.Width <= 0 || sc.Map.Height <= 0 {
+		return fmt.Errorf("map size must be positive, got %dx%d", sc.Map.Width, sc.Map.Height)
+	}
+	if sc.Map.Generate && len(sc.Map.Buildings) > 0 {
+		return fmt.Errorf("map buildings cannot be combined with generate=true")
+	}
+	if sc.Stop.MaxTicks < 0 {
+		return fmt.Errorf("stop.max_ticks must be >= 0, got %d", sc.Stop.MaxTicks)
+	}
+	if err := sc.Environment.validate(); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	if _, err := sc.SimConfig(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if _, err := ParseIntelMode(sc.Intel); err != nil {
+		return fmt.Errorf("intel: %w", err)
+	}
+	teams := make(map[int]Team, len(sc.Soldiers))
+	for i, ss := range sc.Soldiers {
+		team, err := parseTeam(ss.Team)
+		if err != nil {
+			return fmt.Errorf("soldiers[%d]: %w", i, err)
+		}
+		if _, dup := teams[ss.ID]; dup {
+			return fmt.Errorf("soldiers[%d]: duplicate id %d", i, ss.ID)
+		}
+		teams[ss.ID] = team
+		if ss.Profile != nil && ss.Profile.Stance != "" {
+			if _, err := parseStance(ss.Profile.Stance); err != nil {
+				return fmt.Errorf("soldiers[%d]: %w", i, err)
+			}
+		}
+		if ss.Profile != nil {
+			if err := ss.Profile.validateThresholds(); err != nil {
+				return fmt.Errorf("soldiers[%d]: %w", i, err)
+			}
+		}
+		if _, err := ParseWeaponKind(ss.Weapon); err != nil {
+			return fmt.Errorf("soldiers[%d]: %w", i, err)
+		}
+		if _, err := ParseArmourKit(ss.Armour); err != nil {
+			return fmt.Errorf("soldiers[%d]: %w", i, err)
+		}
+	}
+	for i, sq := range sc.Squads {
+		team, err := parseTeam(sq.Team)
+		if err != nil {
+			return fmt.Errorf("squads[%d]: %w", i, err)
+		}
+		if len(sq.Members) == 0 {
+			return fmt.Errorf("squads[%d]: no members", i)
+		}
+		for _, id := range sq.Members {
+			st, ok := teams[id]
+			if !ok {
+				return fmt.Errorf("squads[%d]: unknown soldier id %d", i, id)
+			}
+			if st != team {
+				return fmt.Errorf("squads[%d]: soldier %d is not on team %s", i, id, sq.Team)
+			}
+		}
+		if _, err := sq.loadout(); err != nil {
+			return fmt.Errorf("squads[%d]: %w", i, err)
+		}
+		if _, err := ParseArmourKit(sq.Armour); err != nil {
+			return fmt.Errorf("squads[%d]: %w", i, err)
+		}
+	}
+	inPlatoon := make(map[int]bool)
+	for i, pl := range sc.Platoons {
+		if len(pl.Squads) < 2 {
+			return fmt.Errorf("platoons[%d]: needs at least two squads", i)
+		}
+		for _, idx := range pl.Squads {
+			if idx < 0 || idx >= len(sc.Squads) {
+				return fmt.Errorf("platoons[%d]: unknown squad %d", i, idx)
+			}
+			if inPlatoon[idx] {
+				return fmt.Errorf("platoons[%d]: squad %d is already in a platoon", i, idx)
+			}
+			inPlatoon[idx] = true
+			if sc.Squads[idx].Team != sc.Squads[pl.Squads[0]].Team {
+				return fmt.Errorf("platoons[%d]: squads are on different teams", i)
+			}
+		}
+	}
+	for i, j := range sc.Jammers {
+		if _, err := parseTeam(j.Team); err != nil {
+			return fmt.Errorf("jammers[%d]: %w", i, err)
+		}
+		if j.Radius <= 0 {
+			return fmt.Errorf("jammers[%d]: radius must be positive, got %g", i, j.Radius)
+		}
+		if j.Strength < 0 || j.Strength > 1 {
+			return fmt.Errorf("jammers[%d]: strength must be in [0,1], got %g", i, j.Strength)
+		}
+	}
+	for i, e := range sc.EvacPoints {
+		if _, err := parseTeam(e.Team); err != nil {
+			return fmt.Errorf("evac_points[%d]: %w", i, err)
+		}
+		if e.X < 0 || e.Y < 0 || e.X > float64(sc.Map.Width) || e.Y > float64(sc.Map.Height) {
+			return fmt.Errorf("evac_points[%d]: (%g,%g) is off the %dx%d map", i, e.X, e.Y, sc.Map.Width, sc.Map.Height)
+		}
+	}
+	return nil
+}
+
+// Options expands the scenario into SimOptions for NewTestSim. runSeed seeds
+// the simulation RNG and, when the map has no fixed seed, the map generator.
+// The scenario must pass Validate; Options panics on config overrides that
+// do not apply rather than run the defaults in their place.
+func (sc *ScenarioFile) Options(runSeed int64) []SimOption {
+	opts := []SimOption{WithSeed(runSeed)}
+	mapSeed := sc.Map.Seed
+	if mapSeed == 0 {
+		mapSeed = runSeed
+	}
+	if sc.Map.Generate {
+		opts = append(opts, WithHeadlessBattlefield(NewHeadlessBattlefield(mapSeed, sc.Map.Width, sc.Map.Height)))
+	} else {
+		opts = append(opts, WithMapSize(sc.Map.Width, sc.Map.Height))
+		for _, b := range sc.Map.Buildings {
+			opts = append(opts, WithBuilding(b.X, b.Y, b.W, b.H))
+		}
+	}
+	for _, ss := range sc.Soldiers {
+		team, _ := parseTeam(ss.Team)
+		if team == TeamRed {
+			opts = append(opts, WithRedSoldier(ss.ID, ss.Start[0], ss.Start[1], ss.Objective[0], ss.Objective[1]))
+		} else {
+			opts = append(opts, WithBlueSoldier(ss.ID, ss.Start[0], ss.Start[1], ss.Objective[0], ss.Objective[1]))
+		}
+		if ss.Profile != nil {
+			opts = append(opts, WithSoldierProfile(ss.ID, ss.Profile.apply))
+			if ss.Profile.hasThresholds() {
+				opts = append(opts, WithSoldierThresholds(ss.ID, ss.Profile.applyThresholds))
+			}
+		}
+		if ss.RadioRelay {
+			opts = append(opts, WithRadioRelay(ss.ID))
+		}
+	}
+	for _, sq := range sc.Squads {
+		team, _ := parseTeam(sq.Team)
+		if team == TeamRed {
+			opts = append(opts, WithRedSquad(sq.Members...))
+		} else {
+			opts = append(opts, WithBlueSquad(sq.Members...))
+		}
+		kinds, _ := sq.loadout()
+		for i, k := range kinds {
+			if i < len(sq.Members) {
+				opts = append(opts, WithWeapon(sq.Members[i], k))
+			}
+		}
+		if sq.Armour != "" {
+			kit, _ := ParseArmourKit(sq.Armour)
+			for _, id := range sq.Members {
+				opts = append(opts, WithArmour(id, kit))
+			}
+		}
+	}
+	for _, ss := range sc.Soldiers {
+		if ss.Weapon != "" {
+			k, _ := ParseWeaponKind(ss.Weapon)
+			opts = append(opts, WithWeapon(ss.ID, k))
+		}
+		if ss.Armour != "" {
+			kit, _ := ParseArmourKit(ss.Armour)
+			opts = append(opts, WithArmour(ss.ID, kit))
+		}
+	}
+	for _, pl := range sc.Platoons {
+		opts = append(opts, WithPlatoon(pl.Squads...))
+	}
+	if mode, _ := ParseIntelMode(sc.Intel); mode != IntelShared {
+		opts = append(opts, WithIntelMode(mode))
+	}
+	for _, j := range sc.Jammers {
+		team, _ := parseTeam(j.Team)
+		opts = append(opts, WithJammer(Jammer{Team: team, X: j.X, Y: j.Y, Radius: j.Radius, Strength: j.Strength}))
+	}
+	for _, e := range sc.EvacPoints {
+		team, _ := parseTeam(e.Team)
+		opts = append(opts, WithEvacPoint(EvacPoint{Team: team, X: e.X, Y: e.Y}))
+	}
+	if sc.Environment != nil {
+		opts = append(opts, WithEnvironment(sc.Environment.Build()))
+	}
+	if len(sc.Config) > 0 {
+		cfg, err := sc.SimConfig()
+		if err != nil {
+			panic(fmt.Sprintf("scenario %q: config: %v", sc.Name, err))
+		}
+		opts = append(opts, WithConfig(cfg))
+	}
+	return opts
+}
+
+// SimConfig returns the default balance with the scenario's overrides applied.
+func (sc *ScenarioFile) SimConfig() (*SimConfig, error) {
+	cfg := DefaultSimConfig()
+	if err := cfg.Apply(sc.Config); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// StopPredicate returns a RunUntil predicate implementing the early-stop
+// conditions. With no conditions set it never fires.
+func (sc *ScenarioFile) StopPredicate() func(*TestSim) bool {
+	stop := sc.Stop
+	return func(ts *TestSim) bool {
+		if stop.OnFirstContact {
+			for _, s := range ts.Soldiers {
+				if s.blackboard.VisibleThreatCount() > 0 {
+					return true
+				}
+			}
+		}
+		if stop.OnTeamEliminated {
+			if ts.aliveCount(TeamRed) == 0 || ts.aliveCount(TeamBlue) == 0 {
+				return true
+			}
+		}
+		if stop.OnOutcome && ts.Outcome().Outcome != OutcomeInconclusive {
+			return true
+		}
+		return false
+	}
+}
+
+// NewTestSimFromScenario builds a TestSim from a scenario file. Extra options
+// are applied after the scenario's own (e.g. WithVerbose).
+func NewTestSimFromScenario(sc *ScenarioFile, runSeed int64, extra ...SimOption) *TestSim {
+	opts := append(sc.Options(runSeed), extra...)
+	ts := NewTestSim(opts...)
+	ts.seed = runSeed
+	return ts
+}
+
+// RunScenario advances ts until a stop condition fires or maxTicks elapse.
+// A non-positive maxTicks falls back to the scenario's stop.max_ticks.
+// Returns the number of ticks actually run.
+func (ts *TestSim) RunScenario(sc *ScenarioFile, maxTicks int) int {
+	if maxTicks <= 0 {
+		maxTicks = sc.Stop.MaxTicks
+	}
+	start := ts.tick
+	ts.RunUntil(sc.StopPredicate(), maxTicks)
+	return ts.tick - start
+}
+
+// apply writes the non-nil overrides into p.
+func (sp *ScenarioProfile) apply(p *SoldierProfile) {
+	set := func(dst *float64, v *float64) {
+		if v != nil {
+			*dst = clamp01(*v)
+		}
+	}
+	set(&p.Physical.FitnessBase, sp.FitnessBase)
+	set(&p.Skills.Marksmanship, sp.Marksmanship)
+	set(&p.Skills.Fieldcraft, sp.Fieldcraft)
+	set(&p.Skills.Discipline, sp.Discipline)
+	set(&p.Skills.FirstAid, sp.FirstAid)
+	set(&p.Psych.Experience, sp.Experience)
+	set(&p.Psych.Morale, sp.Morale)
+	set(&p.Psych.Fear, sp.Fear)
+	set(&p.Psych.Composure, sp.Composure)
+	if sp.Stance != "" {
+		if st, err := parseStance(sp.Stance); err == nil {
+			p.Stance = st
+		}
+	}
+}
+
+// thresholdOverride pairs a threshold field with its SimConfig parameter name.
+type thresholdOverride struct {
+	name string
+	v    *float64
+}
+
+func (sp *ScenarioProfile) thresholdFields() []thresholdOverride {
+	return []thresholdOverride{
+		{"engage_shot_quality", sp.EngageShotQuality},
+		{"long_range_shot_quality", sp.LongRangeShotQuality},
+		{"push_on_miss_momentum", sp.PushOnMissMomentum},
+		{"hold_on_hit_momentum", sp.HoldOnHitMomentum},
+		{"cover_fear", sp.CoverFear},
+	}
+}
+
+func (sp *ScenarioProfile) hasThresholds() bool {
+	for _, f := range sp.thresholdFields() {
+		if f.v != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateThresholds range-checks the threshold overrides with SimConfig.Set.
+func (sp *ScenarioProfile) validateThresholds() error {
+	probe := DefaultSimConfig()
+	for _, f := range sp.thresholdFields() {
+		if f.v == nil {
+			continue
+		}
+		if err := probe.Set(f.name, *f.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyThresholds writes the non-nil threshold overrides into th.
+func (sp *ScenarioProfile) applyThresholds(th *GoalThresholds) {
+	probe := &SimConfig{Thresholds: *th}
+	for _, f := range sp.thresholdFields() {
+		if f.v != nil {
+			_ = probe.Set(f.name, *f.v) // validated by Validate
+		}
+	}
+	*th = probe.Thresholds
+}
+
+// Build returns the environment described by se. A nil se is a clear midday.
+func (se *ScenarioEnv) Build() *Environment {
+	env := DefaultEnvironment()
+	if se == nil {
+		return env
+	}
+	get := func(v *float64, def float64) float64 {
+		if v == nil {
+			return def
+		}
+		return *v
+	}
+	env = NewEnvironment(get(se.Hour, env.Hour), get(se.Rain, 0), get(se.Fog, 0), get(se.Wind, 0), get(se.WindDir, 0))
+	env.TimeScale = get(se.TimeScale, 0)
+	return env
+}
+
+func (se *ScenarioEnv) validate() error {
+	if se == nil {
+		return nil
+	}
+	unit := func(name string, v *float64) error {
+		if v != nil && (*v < 0 || *v > 1) {
+			return fmt.Errorf("%s must be in [0,1], got %g", name, *v)
+		}
+		return nil
+	}
+	if se.Hour != nil && (*se.Hour < 0 || *se.Hour >= 24) {
+		return fmt.Errorf("hour must be in [0,24), got %g", *se.Hour)
+	}
+	if se.TimeScale != nil && *se.TimeScale < 0 {
+		return fmt.Errorf("time_scale must be >= 0, got %g", *se.TimeScale)
+	}
+	if se.Wind != nil && *se.Wind < 0 {
+		return fmt.Errorf("wind must be >= 0, got %g", *se.Wind)
+	}
+	if err := unit("rain", se.Rain); err != nil {
+		return err
+	}
+	return unit("fog", se.Fog)
+}
+
+func parseTeam(s string) (Team, error) {
+	switch s {
+	case "red":
+		return TeamRed, nil
+	case "blue":
+		return TeamBlue, nil
+	default:
+		return TeamRed, fmt.Errorf("unknown team %q (want red or blue)", s)
+	}
+}
+
+// loadout returns the weapons the squad issues, in member order.
+func (sq *ScenarioSquad) loadout() ([]WeaponKind, error) {
+	if len(sq.Loadout) == 1 && sq.Loadout[0] == "standard" {
+		return standardSquadLoadout, nil
+	}
+	if len(sq.Loadout) > len(sq.Members) {
+		return nil, fmt.Errorf("loadout: %d weapons for %d members", len(sq.Loadout), len(sq.Members))
+	}
+	kinds := make([]WeaponKind, 0, len(sq.Loadout))
+	for _, name := range sq.Loadout {
+		k, err := ParseWeaponKind(name)
+		if err != nil {
+			return nil, fmt.Errorf("loadout: %w", err)
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
+
+func parseStance(s string) (Stance, error) {
+	switch s {
+	case "", "standing":
+		return StanceStanding, nil
+	case "crouching":
+		return StanceCrouching, nil
+	case "prone":
+		return StanceProne, nil
+	default:
+		return StanceStanding, fmt.Errorf("unknown stance %q", s)
+	}
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const testScenarioJSON = `{
+  "name": "contact-halt",
+  "map": {"width": 1280, "height": 720, "buildings": [{"x": 600, "y": 100, "w": 64, "h": 128}]},
+  "soldiers": [
+    {"id": 0, "team": "red", "start": [50, 350], "objective": [1200, 350], "profile": {"marksmanship": 0.9, "stance": "crouching"}},
+    {"id": 1, "team": "red", "start": [50, 322], "objective": [1200, 322]},
+    {"id": 2, "team": "blue", "start": [400, 350], "objective": [400, 350]}
+  ],
+  "squads": [{"team": "red", "members": [0, 1]}],
+  "stop": {"max_ticks": 600, "on_first_contact": true}
+}`
+
+func TestParseScenario_BuildsTestSim(t *testing.T) {
+	sc, err := ParseScenario([]byte(testScenarioJSON))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ts := NewTestSimFromScenario(sc, 42)
+	if ts.Width != 1280 || ts.Height != 720 {
+		t.Fatalf("expected 1280x720 map, got %dx%d", ts.Width, ts.Height)
+	}
+	if len(ts.buildings) != 1 {
+		t.Fatalf("expected 1 building, got %d", len(ts.buildings))
+	}
+	if len(ts.AllByTeam(TeamRed)) != 2 || len(ts.AllByTeam(TeamBlue)) != 1 {
+		t.Fatalf("unexpected team sizes red=%d blue=%d", len(ts.AllByTeam(TeamRed)), len(ts.AllByTeam(TeamBlue)))
+	}
+	if len(ts.Squads) != 1 || len(ts.Squads[0].Members) != 2 {
+		t.Fatalf("expected one 2-man squad, got %d squads", len(ts.Squads))
+	}
+	r0 := ts.Soldiers[0]
+	if r0.profile.Skills.Marksmanship != 0.9 || r0.profile.Stance != StanceCrouching {
+		t.Fatalf("profile override not applied: marksmanship=%.2f stance=%s", r0.profile.Skills.Marksmanship, r0.profile.Stance)
+	}
+	if ts.Soldiers[1].profile.Skills.Marksmanship != DefaultProfile().Skills.Marksmanship {
+		t.Fatalf("profile override leaked to soldier without overrides")
+	}
+
+	ran := ts.RunScenario(sc, 0)
+	if ran <= 0 || ran > sc.Stop.MaxTicks {
+		t.Fatalf("expected 1..%d ticks, ran %d", sc.Stop.MaxTicks, ran)
+	}
+	if ran < sc.Stop.MaxTicks && !sc.StopPredicate()(ts) {
+		t.Fatalf("run stopped early at %d without its stop condition holding", ran)
+	}
+}
+
+func TestParseScenario_MatchesHandBuiltSim(t *testing.T) {
+	sc, err := ParseScenario([]byte(testScenarioJSON))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fromFile := NewTestSimFromScenario(sc, 7)
+	byHand := NewTestSim(
+		WithSeed(7),
+		WithMapSize(1280, 720),
+		WithBuilding(600, 100, 64, 128),
+		WithRedSoldier(0, 50, 350, 1200, 350),
+		WithRedSoldier(1, 50, 322, 1200, 322),
+		WithBlueSoldier(2, 400, 350, 400, 350),
+		WithSoldierProfile(0, func(p *SoldierProfile) {
+			p.Skills.Marksmanship = 0.9
+			p.Stance = StanceCrouching
+		}),
+		WithRedSquad(0, 1),
+	)
+	fromFile.RunTicks(300)
+	byHand.RunTicks(300)
+	a, b := fromFile.Snapshot(), byHand.Snapshot()
+	for i := range a.Soldiers {
+		if a.Soldiers[i] != b.Soldiers[i] {
+			t.Fatalf("soldier %d diverged: file=%+v hand=%+v", i, a.Soldiers[i], b.Soldiers[i])
+		}
+	}
+}
+
+func TestParseScenario_RejectsBadInput(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":  `{"map": {"width": 10, "height": 10}, "soldierz": []}`,
+		"bad team":       `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "green"}]}`,
+		"duplicate id":   `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red"}, {"id": 0, "team": "blue"}]}`,
+		"squad mismatch": `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red"}], "squads": [{"team": "blue", "members": [0]}]}`,
+		"unknown member": `{"map": {"width": 10, "height": 10}, "soldiers": [], "squads": [{"team": "red", "members": [3]}]}`,
+		"zero map":       `{"map": {"width": 0, "height": 10}}`,
+		"bad stance":     `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red", "profile": {"stance": "kneeling"}}]}`,
+		"bad threshold":  `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red", "profile": {"cover_fear": 2}}]}`,
+		"lone platoon":   `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red"}], "squads": [{"team": "red", "members": [0]}], "platoons": [{"squads": [0]}]}`,
+		"mixed platoon":  `{"map": {"width": 10, "height": 10}, "soldiers": [{"id": 0, "team": "red"}, {"id": 1, "team": "blue"}], "squads": [{"team": "red", "members": [0]}, {"team": "blue", "members": [1]}], "platoons": [{"squads": [0, 1]}]}`,
+		"evac off map":   `{"map": {"width": 10, "height": 10}, "evac_points": [{"team": "red", "x": 20, "y": 5}]}`,
+	}
+	for name, js := range cases {
+		if _, err := ParseScenario([]byte(js)); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		} else if strings.TrimSpace(err.Error()) == "" {
+			t.Errorf("%s: empty error message", name)
+		}
+	}
+}
+
+func TestParseScenario_PerSoldierThresholds(t *testing.T) {
+	sc, err := ParseScenario([]byte(`{
+  "map": {"width": 800, "height": 400},
+  "soldiers": [
+    {"id": 0, "team": "red", "start": [100, 200], "objective": [700, 200], "profile": {"cover_fear": 0.4}},
+    {"id": 1, "team": "red", "start": [100, 228], "objective": [700, 228]}
+  ],
+  "config": {"engage_shot_quality": 0.5}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := NewTestSimFromScenario(sc, 1)
+	own, shared := ts.Soldiers[0].blackboard, ts.Soldiers[1].blackboard
+	if got := own.Internal.Thresholds; got.CoverFear != 0.4 || got.EngageShotQuality != 0.5 {
+		t.Fatalf("soldier 0 thresholds %+v, want its own cover_fear on top of the config", got)
+	}
+	if got := shared.Internal.Thresholds.CoverFear; got != defaultGoalThresholds().CoverFear {
+		t.Fatalf("soldier 1 cover_fear %.2f, want the shared default", got)
+	}
+
+	// Stress pulls drifted thresholds back toward the soldier's own, not the config's.
+	own.Internal.Thresholds.CoverFear = 0.8
+	for i := 0; i < 2000; i++ {
+		own.EvolveThresholds(GoalAdvance, 1)
+	}
+	if got := own.Internal.Thresholds.CoverFear; math.Abs(got-0.4) > 0.05 {
+		t.Fatalf("cover_fear drifted to %.2f, want it back near 0.4", got)
+	}
+}
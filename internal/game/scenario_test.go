@@ -621,7 +621,7 @@ func TestCombat_LongRangeAimingDelaysShotWhenCalm(t *testing.T) {
 
 	cm := NewCombatManager(7)
 	all := []*Soldier{shooter, target}
-	requiredAimTicks := aimingTicksForDistance(520)
+	requiredAimTicks := DefaultSimConfig().aimingTicksForDistance(nil, 520)
 
 	for i := 0; i < requiredAimTicks; i++ {
 		cm.ResetFireCounts(all)
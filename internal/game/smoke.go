@@ -0,0 +1,284 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// --- Smoke constants ---
+
+const (
+	defaultSmokeGrenadeCount = 2 // smoke grenades carried at start
+
+	smokeFuseTicks      = 90    // ticks from release to the canister starting to pour (~1.5s)
+	smokeStartRadius    = 12.0  // px, cloud radius when the canister first pours
+	smokeMaxRadius      = 72.0  // px, fully developed cloud
+	smokeGrowTicks      = 240   // ticks to reach full size and density (~4s)
+	smokeLifeTicks      = 2400  // ticks a cloud lasts (~40s)
+	smokeFadeTicks      = 900   // final ticks over which the cloud thins out
+	smokeMinWind        = 0.03  // px per tick drift
+	smokeMaxWind        = 0.12  // px per tick drift
+	smokeBlockOpacity   = 1.0   // accumulated opacity at which a ray is fully obscured
+	smokeSpreadMul      = 1.5   // extra shooter spread, as a share, aiming into smoke on the edge of full obscuration
+	smokeCoreOpacity    = 1.6   // opacity of a ray through the full diameter of a dense cloud
+	smokeScreenOffset   = 70.0  // px from the protected soldier toward the enemy
+	smokeSquadCooldown  = 900   // ticks before a squad pops smoke again
+	smokeOpenGroundMin  = 0.6   // IntelOpenGround value that counts as exposed ground
+	smokeBoundContactRg = 520.0 // px, enemy within this range makes a bound across open ground worth screening
+)
+
+// GrenadeKind distinguishes fragmentation and smoke grenades.
+type GrenadeKind int
+
+const (
+	GrenadeFrag GrenadeKind = iota
+	GrenadeSmoke
+)
+
+func (k GrenadeKind) String() string {
+	switch k {
+	case GrenadeFrag:
+		return "frag"
+	case GrenadeSmoke:
+		return "smoke"
+	default:
+		return "unknown"
+	}
+}
+
+// SmokeCloud is one drifting obscurant cloud.
+type SmokeCloud struct {
+	X, Y    float64
+	Radius  float64
+	Density float64 // 0-1
+	Age     int
+}
+
+// SmokeField holds every smoke cloud on the map and the wind that moves them.
+// A nil *SmokeField is valid and never obscures anything.
+type SmokeField struct {
+	Clouds       []*SmokeCloud
+	WindX, WindY float64 // px per tick
+}
+
+// NewSmokeField creates an empty field with a wind derived from seed, so every
+// replay of a seed drifts smoke the same way.
+func NewSmokeField(seed int64) *SmokeField {
+	s := float64(seed%100003) + 0.5
+	dir := math.Abs(math.Sin(s*12.9898)) * 2 * math.Pi
+	speed := smokeMinWind + math.Abs(math.Sin(s*78.233))*(smokeMaxWind-smokeMinWind)
+	return &SmokeField{WindX: math.Cos(dir) * speed, WindY: math.Sin(dir) * speed}
+}
+
+// Deploy starts a new cloud at (x, y).
+func (sf *SmokeField) Deploy(x, y float64) {
+	if sf == nil {
+		return
+	}
+	sf.Clouds = append(sf.Clouds, &SmokeCloud{X: x, Y: y, Radius: smokeStartRadius})
+}
+
+// Update grows, drifts and thins every cloud and drops spent ones.
+func (sf *SmokeField) Update() {
+	if sf == nil {
+		return
+	}
+	live := sf.Clouds[:0]
+	for _, c := range sf.Clouds {
+		c.Age++
+		if c.Age >= smokeLifeTicks {
+			continue
+		}
+		grow := math.Min(1, float64(c.Age)/smokeGrowTicks)
+		c.Radius = smokeStartRadius + (smokeMaxRadius-smokeStartRadius)*grow
+		c.Density = grow
+		if left := smokeLifeTicks - c.Age; left < smokeFadeTicks {
+			c.Density *= float64(left) / smokeFadeTicks
+			c.Radius *= 1 + 0.25*(1-float64(left)/smokeFadeTicks) // thins as it spreads
+		}
+		// Young clouds hug the canister; mature ones move with the wind.
+		c.X += sf.WindX * grow
+		c.Y += sf.WindY * grow
+		live = append(live, c)
+	}
+	sf.Clouds = live
+}
+
+// Opacity returns the accumulated smoke opacity along the ray from (ax, ay) to
+// (bx, by). Each cloud adds its density weighted by the fraction of its diameter
+// the ray passes through, so grazing an edge costs little and crossing the core
+// is opaque.
+func (sf *SmokeField) Opacity(ax, ay, bx, by float64) float64 {
+	if sf == nil {
+		return 0
+	}
+	total := 0.0
+	for _, c := range sf.Clouds {
+		if c.Density <= 0 {
+			continue
+		}
+		if chord := segmentCircleChord(ax, ay, bx, by, c.X, c.Y, c.Radius); chord > 0 {
+			total += smokeCoreOpacity * c.Density * chord / (2 * c.Radius)
+		}
+	}
+	return total
+}
+
+// Obscuration returns how much of the view along the ray from (ax, ay) to
+// (bx, by) the smoke takes away: the accumulated opacity, scaled so 1 is
+// fully obscured. Sight range, spotting and aim all suffer in proportion.
+func (sf *SmokeField) Obscuration(ax, ay, bx, by float64) float64 {
+	return math.Min(1, sf.Opacity(ax, ay, bx, by)/smokeBlockOpacity)
+}
+
+// Blocks reports whether the smoke along the ray from (ax, ay) to (bx, by)
+// is thick enough (smokeBlockOpacity) to hide the far end entirely.
+func (sf *SmokeField) Blocks(ax, ay, bx, by float64) bool {
+	return sf.Opacity(ax, ay, bx, by) >= smokeBlockOpacity
+}
+
+// DensityAt returns the thickest cloud density covering (x, y).
+func (sf *SmokeField) DensityAt(x, y float64) float64 {
+	if sf == nil {
+		return 0
+	}
+	best := 0.0
+	for _, c := range sf.Clouds {
+		if math.Hypot(x-c.X, y-c.Y) <= c.Radius && c.Density > best {
+			best = c.Density
+		}
+	}
+	return best
+}
+
+// Draw renders the clouds as layered translucent discs.
+func (sf *SmokeField) Draw(screen *ebiten.Image, offX, offY int) {
+	if sf == nil {
+		return
+	}
+	ox, oy := float32(offX), float32(offY)
+	for _, c := range sf.Clouds {
+		a := uint8(170 * c.Density)
+		x, y := ox+float32(c.X), oy+float32(c.Y)
+		vector.FillCircle(screen, x, y, float32(c.Radius), color.RGBA{R: 205, G: 205, B: 200, A: a / 2}, true)
+		vector.FillCircle(screen, x, y, float32(c.Radius*0.65), color.RGBA{R: 225, G: 225, B: 220, A: a / 2}, true)
+	}
+}
+
+// segmentCircleChord returns the length of the segment (ax,ay)-(bx,by) that lies
+// inside the circle centred on (cx, cy).
+func segmentCircleChord(ax, ay, bx, by, cx, cy, r float64) float64 {
+	dx, dy := bx-ax, by-ay
+	fx, fy := ax-cx, ay-cy
+	a := dx*dx + dy*dy
+	if a < 1e-12 {
+		return 0
+	}
+	b := 2 * (fx*dx + fy*dy)
+	c := fx*fx + fy*fy - r*r
+	disc := b*b - 4*a*c
+	if disc <= 0 {
+		return 0
+	}
+	sq := math.Sqrt(disc)
+	t1 := math.Max(0, (-b-sq)/(2*a))
+	t2 := math.Min(1, (-b+sq)/(2*a))
+	if t2 <= t1 {
+		return 0
+	}
+	return (t2 - t1) * math.Sqrt(a)
+}
+
+// --- Squad side: deciding when to screen ---
+
+// considerSmoke lets the squad leader pop smoke between the enemy and a member
+// who is exposed while moving: a casualty being dragged, a soldier falling back
+// under fire, or a bounding soldier crossing open ground. The leader throws if
+// able; otherwise the nearest member still carrying smoke does.
+func (sq *Squad) considerSmoke(tick int, hasContact bool, contactX, contactY float64, intel *IntelStore) {
+	if !hasContact || tick < sq.smokeCooldownUntil || sq.Leader == nil {
+		return
+	}
+
+	im := sq.intelMap(intel)
+
+	var protect *Soldier
+	reason := ""
+	for _, c := range sq.Members {
+		if c.casualty.BeingDragged && c.casualty.Dragger != nil && c.casualty.Dragger.state != SoldierStateDead {
+			protect, reason = c.casualty.Dragger, "casualty drag"
+			break
+		}
+	}
+	if protect == nil {
+		for _, m := range sq.Members {
+			if m.state == SoldierStateDead || m.state.IsIncapacitated() {
+				continue
+			}
+			bb := &m.blackboard
+			if bb.CurrentGoal == GoalFallback && (bb.IncomingFireCount > 0 || bb.SuppressLevel > 0.2) {
+				protect, reason = m, "fallback"
+				break
+			}
+			if im != nil && bb.BoundMover && bb.CurrentGoal == GoalMoveToContact &&
+				math.Hypot(contactX-m.x, contactY-m.y) <= smokeBoundContactRg &&
+				float64(im.Layer(IntelOpenGround).SampleAt(m.x, m.y)) >= smokeOpenGroundMin {
+				protect, reason = m, "bound across open ground"
+				break
+			}
+		}
+	}
+	if protect == nil {
+		return
+	}
+
+	// Screen point: a little way from the protected soldier toward the enemy.
+	dx, dy := contactX-protect.x, contactY-protect.y
+	d := math.Hypot(dx, dy)
+	if d < 1 {
+		return
+	}
+	off := math.Min(smokeScreenOffset, d*0.5)
+	aimX := protect.x + dx/d*off
+	aimY := protect.y + dy/d*off
+	if protect.smoke.DensityAt(aimX, aimY) > 0.5 {
+		return // already screened
+	}
+
+	thrower := sq.smokeThrower(aimX, aimY)
+	if thrower == nil {
+		return
+	}
+	thrower.smokeAimX, thrower.smokeAimY = aimX, aimY
+	thrower.smokeReady = true
+	sq.smokeCooldownUntil = tick + smokeSquadCooldown
+	sq.Leader.think(fmt.Sprintf("popping smoke — covering %s (soldier %d)", reason, protect.id))
+}
+
+// smokeThrower returns the leader if they can reach (x, y) with a smoke grenade,
+// otherwise the nearest able member who can.
+func (sq *Squad) smokeThrower(x, y float64) *Soldier {
+	canThrow := func(m *Soldier) bool {
+		return m != nil && m.state != SoldierStateDead && !m.state.IsIncapacitated() &&
+			m.smokeGrenades > 0 && !m.smokeReady &&
+			math.Hypot(x-m.x, y-m.y) <= grenadeMaxThrowRange
+	}
+	if canThrow(sq.Leader) {
+		return sq.Leader
+	}
+	var best *Soldier
+	bestD := math.MaxFloat64
+	for _, m := range sq.Members {
+		if !canThrow(m) {
+			continue
+		}
+		if d := math.Hypot(x-m.x, y-m.y); d < bestD {
+			best, bestD = m, d
+		}
+	}
+	return best
+}
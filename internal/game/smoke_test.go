@@ -0,0 +1,198 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// matureCloud returns a field with one fully developed cloud at (x, y) and no wind.
+func matureCloud(x, y float64) *SmokeField {
+	sf := &SmokeField{}
+	sf.Deploy(x, y)
+	for i := 0; i < smokeGrowTicks; i++ {
+		sf.Update()
+	}
+	return sf
+}
+
+func TestSmokeField_GrowsDriftsAndDecays(t *testing.T) {
+	sf := &SmokeField{WindX: 0.1}
+	sf.Deploy(100, 100)
+	sf.Update()
+	c := sf.Clouds[0]
+	if c.Radius >= smokeMaxRadius/2 || c.Density > 0.1 {
+		t.Fatalf("fresh cloud r=%.1f density=%.2f, want small and thin", c.Radius, c.Density)
+	}
+	for i := 1; i < smokeGrowTicks; i++ {
+		sf.Update()
+	}
+	if math.Abs(c.Radius-smokeMaxRadius) > 0.5 || c.Density < 0.99 {
+		t.Fatalf("mature cloud r=%.1f density=%.2f", c.Radius, c.Density)
+	}
+	if c.X <= 100 || c.Y != 100 {
+		t.Fatalf("cloud at (%.1f,%.1f), want drifted along +x", c.X, c.Y)
+	}
+	for i := smokeGrowTicks; i < smokeLifeTicks-smokeFadeTicks/2; i++ {
+		sf.Update()
+	}
+	if c.Density > 0.6 {
+		t.Fatalf("cloud density %.2f halfway through the fade", c.Density)
+	}
+	for i := 0; i < smokeFadeTicks; i++ {
+		sf.Update()
+	}
+	if len(sf.Clouds) != 0 {
+		t.Fatalf("%d clouds left after their lifetime", len(sf.Clouds))
+	}
+}
+
+func TestSmokeField_OpacityThroughCoreAndEdge(t *testing.T) {
+	sf := matureCloud(200, 200)
+	if b := sf.Obscuration(100, 200, 300, 200); b != 1 || !sf.Blocks(100, 200, 300, 200) {
+		t.Fatalf("ray through the core obscured %.2f, want 1 and blocked (opacity %.2f)", b, sf.Opacity(100, 200, 300, 200))
+	}
+	edgeY := 200 + smokeMaxRadius*0.9
+	if b := sf.Obscuration(100, edgeY, 300, edgeY); b <= 0 || b >= 1 || sf.Blocks(100, edgeY, 300, edgeY) {
+		t.Fatalf("ray grazing the edge obscured %.2f, want part of the view and not blocked", b)
+	}
+	if sf.Obscuration(100, 400, 300, 400) != 0 || sf.Blocks(100, 400, 300, 400) {
+		t.Fatal("ray clear of the cloud should not be obscured")
+	}
+	var none *SmokeField
+	if none.Obscuration(100, 200, 300, 200) != 0 || none.Blocks(100, 200, 300, 200) {
+		t.Fatal("nil field must never block")
+	}
+}
+
+// thinCloud returns a field with one still, thin cloud at (x, y) that takes
+// about two thirds of the view through its middle.
+func thinCloud(x, y float64) *SmokeField {
+	return &SmokeField{Clouds: []*SmokeCloud{{X: x, Y: y, Radius: 50, Density: 0.4}}}
+}
+
+func TestPerformVisionScan_ThinSmokeShortensSight(t *testing.T) {
+	tick := 0
+	v := NewVisionState(0)
+	smoke := thinCloud(200, 200)
+	clear := 1 - smoke.Obscuration(100, 200, 1000, 200)
+	if clear <= 0.2 || clear >= 0.5 {
+		t.Fatalf("thin cloud leaves %.2f of the view, want about a third", clear)
+	}
+	reach := v.rangeInConditions() * clear
+
+	near := newTestSoldier(1, 100+reach-50, 200, TeamBlue, &tick)
+	far := newTestSoldier(2, 100+reach+50, 200, TeamBlue, &tick)
+	v.PerformVisionScan(100, 200, []*Soldier{near, far}, nil, nil, smoke)
+	if len(v.KnownContacts) != 1 || v.KnownContacts[0] != near {
+		t.Fatalf("saw %d contacts through thin smoke, want only the one inside %.0fpx", len(v.KnownContacts), reach)
+	}
+	v.PerformVisionScan(100, 200, []*Soldier{near, far}, nil, nil, nil)
+	if len(v.KnownContacts) != 2 {
+		t.Fatal("both targets should be in sight without the smoke")
+	}
+}
+
+func TestDetection_ThinSmokeSlowsSpotting(t *testing.T) {
+	tick := 0
+	observer, target := newDetectionPair(500, &tick)
+	target.state = SoldierStateMoving
+	_, clearSpot := watchUntilSpotted(observer, target, &tick, 2000)
+
+	observer, target = newDetectionPair(500, &tick)
+	target.state = SoldierStateMoving
+	observer.smoke = thinCloud(300, 200)
+	_, smokySpot := watchUntilSpotted(observer, target, &tick, 2000)
+	if clearSpot < 0 || smokySpot < 0 {
+		t.Fatalf("target spotted at %d in clear air and %d through thin smoke; want both", clearSpot, smokySpot)
+	}
+	if smokySpot < clearSpot*2 {
+		t.Errorf("thin smoke only slowed spotting from %d to %d ticks, want at least twice as long", clearSpot, smokySpot)
+	}
+}
+
+func TestUpdateGrenadeTarget_IgnoresTargetsLostInSmoke(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	enemy := newTestSoldier(2, 250, 200, TeamBlue, &tick)
+	s.blackboard.UpdateThreats([]*Soldier{enemy}, 1)
+	s.smoke = matureCloud(175, 200)
+	s.updateGrenadeTarget()
+	if s.blackboard.HasGrenadeTarget {
+		t.Fatal("picked a grenade target the thrower cannot see through smoke")
+	}
+}
+
+func TestPerformVisionScan_BlockedBySmoke(t *testing.T) {
+	tick := 0
+	target := newTestSoldier(1, 300, 200, TeamBlue, &tick)
+	v := NewVisionState(0)
+
+	v.PerformVisionScan(100, 200, []*Soldier{target}, nil, nil, matureCloud(200, 200))
+	if len(v.KnownContacts) != 0 {
+		t.Fatal("target behind a smoke screen should not be seen")
+	}
+	v.PerformVisionScan(100, 200, []*Soldier{target}, nil, nil, matureCloud(200, 400))
+	if len(v.KnownContacts) != 1 {
+		t.Fatal("smoke off to the side should not hide the target")
+	}
+}
+
+func TestSquad_PopsSmokeToCoverFallback(t *testing.T) {
+	tick := 0
+	leader := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	runner := newTestSoldier(2, 140, 240, TeamRed, &tick)
+	enemy := newTestSoldier(3, 400, 240, TeamBlue, &tick)
+	sq := &Squad{Team: TeamRed, Leader: leader, Members: []*Soldier{leader, runner}}
+	cm := NewCombatManager(5)
+	cm.Smoke.WindX, cm.Smoke.WindY = 0, 0
+	for _, s := range []*Soldier{leader, runner, enemy} {
+		s.squad = sq
+		s.smoke = cm.Smoke
+	}
+	enemy.squad = nil
+
+	sq.considerSmoke(0, true, enemy.x, enemy.y, nil)
+	if leader.smokeReady {
+		t.Fatal("no one is exposed yet; leader should not pop smoke")
+	}
+
+	runner.blackboard.CurrentGoal = GoalFallback
+	runner.blackboard.IncomingFireCount = 3
+	sq.considerSmoke(0, true, enemy.x, enemy.y, nil)
+	if !leader.smokeReady {
+		t.Fatal("leader should pop smoke for a soldier falling back under fire")
+	}
+	if math.Abs(leader.smokeAimY-240) > 1 || leader.smokeAimX <= runner.x || leader.smokeAimX >= enemy.x {
+		t.Fatalf("screen aimed at (%.0f,%.0f), want between runner and enemy", leader.smokeAimX, leader.smokeAimY)
+	}
+
+	for i := 0; i < smokeFuseTicks+smokeGrowTicks; i++ {
+		cm.ResolveGrenades([]*Soldier{leader, runner, enemy}, nil, nil)
+	}
+	if leader.smokeGrenades != defaultSmokeGrenadeCount-1 || len(cm.Smoke.Clouds) != 1 {
+		t.Fatalf("smoke left=%d clouds=%d, want one throw and one cloud", leader.smokeGrenades, len(cm.Smoke.Clouds))
+	}
+
+	enemy.vision.Heading = math.Pi
+	enemy.UpdateVision([]*Soldier{runner}, nil)
+	if len(enemy.vision.KnownContacts) != 0 {
+		t.Fatal("enemy should lose sight of the runner behind the smoke")
+	}
+}
+
+func TestSquad_PopsSmokeForCasualtyDrag(t *testing.T) {
+	tick := 0
+	leader := newTestSoldier(1, 100, 200, TeamRed, &tick)
+	medic := newTestSoldier(2, 150, 200, TeamRed, &tick)
+	wounded := newTestSoldier(3, 150, 210, TeamRed, &tick)
+	sq := &Squad{Team: TeamRed, Leader: leader, Members: []*Soldier{leader, medic, wounded}}
+	medic.startDraggingCasualty(wounded, 50, 210)
+
+	sq.considerSmoke(0, true, 400, 200, nil)
+	if !leader.smokeReady {
+		t.Fatal("leader should screen a casualty drag")
+	}
+	if sq.considerSmoke(10, true, 400, 200, nil); sq.smokeCooldownUntil != smokeSquadCooldown {
+		t.Fatal("a second request inside the cooldown should not re-arm the timer")
+	}
+}
@@ -669,7 +669,7 @@ const (
 	SoldierStateWoundedAmbulatory                        // hit but can move and fight (degraded)
 	SoldierStateWoundedNonAmbulatory                     // cannot self-move; needs buddy drag/carry
 	SoldierStateUnconscious                              // alive but no agency; bleeds without self-aid
-	SoldierStateDead                                     // incapacitated
+	SoldierStateDead                                     // off the field: killed or evacuated
 )
 
 func (ss SoldierState) String() string {
@@ -722,10 +722,12 @@ type Soldier struct {
 	squad    *Squad
 
 	// Combat
-	body         BodyMap       // per-region health, wounds, blood volume
-	casualty     CasualtyState // medical response state
-	isMedic      bool          // designated medic role
-	fireCooldown int           // ticks until next shot allowed
+	body          BodyMap       // per-region health, wounds, blood volume
+	casualty      CasualtyState // medical response state
+	isMedic       bool          // designated medic role
+	litter        *Soldier      // casualty this soldier is carrying on a stretcher, nil otherwise
+	fireCooldown  int           // ticks until next shot allowed
+	lastFiredTick int           // tick of the latest shot; 0 = never fired
 
 	// Multi-round trigger state (burst/auto pacing).
 	burstShotsRemaining int // queued rounds left in current trigger pull
@@ -781,6 +783,8 @@ type Soldier struct {
 	radioLastContactReportTick int
 	radioLastStatusReportTick  int
 	radioLastFearReportTick    int
+	radioLastAmmoReportTick    int
+	radioRelay                 bool // carries a relay set that re-sends others' traffic
 
 	// --- Fuzzy aim ---
 	// aimSpread grows when moving and decays when still.
@@ -828,10 +832,43 @@ type Soldier struct {
 	// soldier is idle with a missing/terminal path; used to force recovery.
 	mobilityStallTicks int
 
-	// Reload pacing.
+	// Weapon and reload pacing.
+	weapon      *Weapon // nil = the standard rifle; see firearm
 	magCapacity int
 	magRounds   int
 	reloadTimer int
+	spareMags   int  // full magazines in pouches
+	dryNoted    bool // "out of ammo" already logged
+
+	// Grenades.
+	grenades      int     // fragmentation grenades carried
+	grenadeWindup int     // >0 while preparing a throw
+	grenadeReady  bool    // wind-up done; CombatManager releases the grenade next
+	grenadeAimX   float64 // aim point locked at the start of the wind-up
+	grenadeAimY   float64
+
+	// Smoke.
+	smokeGrenades int     // smoke grenades carried
+	smokeReady    bool    // squad leader asked for smoke; CombatManager throws it next
+	smokeAimX     float64 // where the screen should go
+	smokeAimY     float64
+	smoke         *SmokeField // shared obscurant field, nil when the sim has none
+
+	allies *SpatialHash // own team's living soldiers this tick, nil outside a World
+	env    *Environment // weather and time of day, nil = clear midday
+	cfg    *SimConfig   // balance constants, nil = defaults
+
+	// Sound.
+	stepX, stepY float64 // position at the last footstep sound
+	soundCell    int     // tile index last checked for a doorway
+	doorNoise    float64 // range of a door sound to emit this tick; 0 = none
+
+	// Door work (see doors.go).
+	doorTimer              int        // ticks left on the current door action
+	doorAction             doorAction // what s is doing to the door at doorCol, doorRow
+	doorCol, doorRow       int
+	inDoorway              bool // s stood in an open doorway at doorwayCol, doorwayRow
+	doorwayCol, doorwayRow int
 
 	// --- Fuzzy path-reacquisition memory ---
 	// These track short-horizon movement confidence and support a human-like
@@ -901,9 +938,13 @@ func NewSoldier(id int, x, y float64, team Team, start, end [2]float64, ng *NavG
 		aimingTargetID: -1,
 		burstTargetID:  -1,
 		pendingStance:  StanceStanding,
-		magCapacity:    defaultMagazineCapacity,
-		magRounds:      defaultMagazineCapacity,
+		grenades:       defaultGrenadeCount,
+		smokeGrenades:  defaultSmokeGrenadeCount,
+		stepX:          x,
+		stepY:          y,
+		soundCell:      -1,
 	}
+	s.issueWeapon(WeaponRifle)
 	if len(tm) > 0 && tm[0] != nil {
 		s.tacticalMap = tm[0]
 	}
@@ -915,6 +956,18 @@ func (s *Soldier) setIntel(intel *IntelStore) {
 	s.intel = intel
 }
 
+// intelMap is the map this soldier acts on: their squad's in IntelRadio
+// mode, else the team's. Nil without an IntelStore.
+func (s *Soldier) intelMap() *IntelMap {
+	if s.squad != nil && s.squad.intel != nil {
+		return s.squad.intel
+	}
+	if s.intel == nil {
+		return nil
+	}
+	return s.intel.For(s.team)
+}
+
 // health returns a scalar HP value derived from the body map for backward
 // compatibility. It maps HealthFraction back to the old 0–soldierMaxHP scale.
 func (s *Soldier) health() float64 {
@@ -1150,6 +1203,7 @@ func (s *Soldier) Update() {
 	}
 
 	// --- Wound bleeding progression ---
+	s.body.EaseBluntPain()
 	if s.body.HasUntreatedWounds() {
 		ambulatory, conscious, alive := s.body.TickBleed()
 		if !alive {
@@ -1176,6 +1230,7 @@ func (s *Soldier) Update() {
 	if s.state == SoldierStateUnconscious || s.state == SoldierStateWoundedNonAmbulatory {
 		return
 	}
+	s.updateDoors()
 
 	// Self-aid: wounded soldiers attempt to treat themselves when safe.
 	s.integrateWoundedSelfAid()
@@ -1262,7 +1317,12 @@ func (s *Soldier) Update() {
 	// --- Step 2: BELIEVE — update blackboard from vision ---
 	tick := s.tickVal()
 	bb.UpdateThreats(s.vision.KnownContacts, tick)
+	for _, t := range s.vision.Suspected {
+		bb.NoteSuspectedContact(t, tick)
+	}
 	bb.RefreshInternalGoals(&s.profile, s.x, s.y)
+	s.updateGrenadeTarget()
+	s.updateAmmoState()
 	bb.Internal.IsMedic = s.isMedic // populate medic role for goal selection
 	s.updatePsychCrisis(tick)
 
@@ -1391,39 +1451,35 @@ func (s *Soldier) Update() {
 			// Compute a local search-drive signal from intel heatmaps.
 			// High when there is nearby uncertainty/danger but also some friendly-cleared territory.
 			bb.SearchDrive = 0
-			if s.intel != nil {
-				if im := s.intel.For(s.team); im != nil {
-					lx, ly := s.x, s.y
-					unexp := float64(im.Layer(IntelUnexplored).SumInRadius(lx, ly, 220))
-					thr := float64(im.Layer(IntelThreatDensity).SumInRadius(lx, ly, 260))
-					cas := float64(im.Layer(IntelCasualtyDanger).SumInRadius(lx, ly, 260))
-					danger := float64(im.Layer(IntelDangerZone).SumInRadius(lx, ly, 220))
-					safe := float64(im.Layer(IntelSafeTerritory).SumInRadius(lx, ly, 260))
-					open := float64(im.Layer(IntelOpenGround).SampleAt(lx, ly))
-					// Normalize sums by approximate area so the signal is stable across radii.
-					unexp = clamp01(unexp / 85.0)
-					thr = clamp01(thr / 70.0)
-					cas = clamp01(cas / 60.0)
-					danger = clamp01(danger / 55.0)
-					safe = clamp01(safe / 95.0)
-					drive := clamp01(unexp*0.55 + thr*0.55 + cas*0.45 + danger*0.25)
-					// Searching is more meaningful when you have a "home" safe territory nearby.
-					drive *= 0.35 + safe*0.65
-					// Don't encourage searching while currently standing in open ground.
-					drive *= 1.0 - open*0.75
-					bb.SearchDrive = drive
-				}
+			if im := s.intelMap(); im != nil {
+				lx, ly := s.x, s.y
+				unexp := float64(im.Layer(IntelUnexplored).SumInRadius(lx, ly, 220))
+				thr := float64(im.Layer(IntelThreatDensity).SumInRadius(lx, ly, 260))
+				cas := float64(im.Layer(IntelCasualtyDanger).SumInRadius(lx, ly, 260))
+				danger := float64(im.Layer(IntelDangerZone).SumInRadius(lx, ly, 220))
+				safe := float64(im.Layer(IntelSafeTerritory).SumInRadius(lx, ly, 260))
+				open := float64(im.Layer(IntelOpenGround).SampleAt(lx, ly))
+				// Normalize sums by approximate area so the signal is stable across radii.
+				unexp = clamp01(unexp / 85.0)
+				thr = clamp01(thr / 70.0)
+				cas = clamp01(cas / 60.0)
+				danger = clamp01(danger / 55.0)
+				safe = clamp01(safe / 95.0)
+				drive := clamp01(unexp*0.55 + thr*0.55 + cas*0.45 + danger*0.25)
+				// Searching is more meaningful when you have a "home" safe territory nearby.
+				drive *= 0.35 + safe*0.65
+				// Don't encourage searching while currently standing in open ground.
+				drive *= 1.0 - open*0.75
+				bb.SearchDrive = drive
 			}
 			bx, by, bscore := s.tacticalMap.ScanBestNearby(s.x, s.y, 10, bearing, hasEnemy, claimedIdx, footprints, nil)
-			if s.intel != nil {
-				if im := s.intel.For(s.team); im != nil {
-					danger := float64(im.Layer(IntelDangerZone).SampleAt(bx, by))
-					cas := float64(im.Layer(IntelCasualtyDanger).SampleAt(bx, by))
-					open := float64(im.Layer(IntelOpenGround).SampleAt(bx, by))
-					safe := float64(im.Layer(IntelSafeTerritory).SampleAt(bx, by))
-					// Penalize danger/open/casualty; bonus for safe territory.
-					bscore += safe*0.55 - danger*0.45 - cas*0.35 - open*0.25
-				}
+			if im := s.intelMap(); im != nil {
+				danger := float64(im.Layer(IntelDangerZone).SampleAt(bx, by))
+				cas := float64(im.Layer(IntelCasualtyDanger).SampleAt(bx, by))
+				open := float64(im.Layer(IntelOpenGround).SampleAt(bx, by))
+				safe := float64(im.Layer(IntelSafeTerritory).SampleAt(bx, by))
+				// Penalize danger/open/casualty; bonus for safe territory.
+				bscore += safe*0.55 - danger*0.45 - cas*0.35 - open*0.25
 			}
 			if bscore > bb.PositionDesirability+0.15 {
 				bb.BestNearbyX = bx
@@ -1510,6 +1566,9 @@ func (s *Soldier) Update() {
 				if goal != GoalSearch {
 					bb.HasSearchTarget = false
 				}
+				if goal != GoalThrowGrenade {
+					s.grenadeWindup = 0
+				}
 				// Don't clear FlankComplete on goal switch - let it persist until cooldown expires
 				// or soldier moves significantly. This prevents flank→overwatch→flank loops.
 				if goal != GoalFlank && bb.FlankCompleteCooldown == 0 {
@@ -1711,14 +1770,12 @@ func (s *Soldier) moveToClaimedBuilding(dt float64) bool {
 			s.x, s.y, 14, bearing, hasEnemy,
 			bb.ClaimedBuildingIdx, s.buildingFootprints, nil,
 		)
-		if s.intel != nil {
-			if im := s.intel.For(s.team); im != nil {
-				danger := float64(im.Layer(IntelDangerZone).SampleAt(bx, by))
-				cas := float64(im.Layer(IntelCasualtyDanger).SampleAt(bx, by))
-				open := float64(im.Layer(IntelOpenGround).SampleAt(bx, by))
-				safe := float64(im.Layer(IntelSafeTerritory).SampleAt(bx, by))
-				bscore += safe*0.55 - danger*0.45 - cas*0.35 - open*0.25
-			}
+		if im := s.intelMap(); im != nil {
+			danger := float64(im.Layer(IntelDangerZone).SampleAt(bx, by))
+			cas := float64(im.Layer(IntelCasualtyDanger).SampleAt(bx, by))
+			open := float64(im.Layer(IntelOpenGround).SampleAt(bx, by))
+			safe := float64(im.Layer(IntelSafeTerritory).SampleAt(bx, by))
+			bscore += safe*0.55 - danger*0.45 - cas*0.35 - open*0.25
 		}
 		if bscore > -0.30 {
 			targetX, targetY = bx, by
@@ -1823,6 +1880,11 @@ func (s *Soldier) executeGoal(dt float64) {
 	}
 
 	goal := s.blackboard.CurrentGoal
+	if s.shouldStackForEntry(goal) {
+		if s.moveToEntryStack(dt) {
+			return
+		}
+	}
 	if s.shouldSeekClaimedBuilding(goal) {
 		if s.moveToClaimedBuilding(dt) {
 			s.think("under fire outside — pushing into claimed building")
@@ -1926,9 +1988,10 @@ func (s *Soldier) executeGoal(dt float64) {
 	case GoalEngage:
 		s.requestStance(StanceCrouching, false)
 		bl := &s.blackboard
-		// Only advance if genuinely out of effective fire range (beyond maxFireRange).
-		// Inside accurateFireRange, always hold and use cover — stop the suicidal rush.
-		outOfRange := bl.Internal.LastRange > maxFireRange
+		// Only advance if genuinely out of effective fire range (beyond the
+		// weapon's maximum range). Inside it, always hold and use cover — stop
+		// the suicidal rush.
+		outOfRange := bl.Internal.LastRange > s.firearm().MaxRange
 		// Poor range: beyond burstRange with a low hit chance. The soldier CAN fire
 		// but isn't effective — they need to close distance rather than idle in cover.
 		poorRange := bl.Internal.LastRange > float64(burstRange) &&
@@ -1981,7 +2044,7 @@ func (s *Soldier) executeGoal(dt float64) {
 			}
 			distToSlot := math.Hypot(s.slotTargetX-s.x, s.slotTargetY-s.y)
 			contactTooFar := bb.VisibleThreatCount() == 0 &&
-				bb.Internal.LastContactRange > float64(maxFireRange)*1.05
+				bb.Internal.LastContactRange > s.firearm().MaxRange*1.05
 			holdLimit := 40
 			if contactTooFar {
 				holdLimit = 18
@@ -2102,6 +2165,9 @@ func (s *Soldier) executeGoal(dt float64) {
 
 	case GoalSearch:
 		s.executeSearch(dt)
+
+	case GoalThrowGrenade:
+		s.executeThrowGrenade(dt)
 	}
 }
 
@@ -2123,13 +2189,7 @@ func (s *Soldier) executeSearch(dt float64) {
 		s.profile.Physical.AccumulateFatigue(0, dt)
 		return
 	}
-	if s.intel == nil {
-		bb.HasSearchTarget = false
-		s.state = SoldierStateIdle
-		s.profile.Physical.AccumulateFatigue(0, dt)
-		return
-	}
-	im := s.intel.For(s.team)
+	im := s.intelMap()
 	if im == nil {
 		bb.HasSearchTarget = false
 		s.state = SoldierStateIdle
@@ -2875,6 +2935,11 @@ func (s *Soldier) moveAlongPath(dt float64) {
 		s.state = SoldierStateIdle
 		return
 	}
+	if s.doorTimer > 0 || s.openDoorAhead() {
+		// Busy with a door; the path resumes once it is done.
+		s.state = SoldierStateIdle
+		return
+	}
 
 	// LOS-based path smoothing: skip intermediate waypoints the soldier can see.
 	// Look-ahead scales inversely with stress/suppression.
@@ -2912,6 +2977,9 @@ func (s *Soldier) moveAlongPath(dt float64) {
 		crawl := pinnedCrawlSpeedMul * (0.75 + s.profile.Skills.Discipline*0.25)
 		speed *= crawl
 	}
+	if s.litter != nil {
+		speed *= stretcherSpeedMul
+	}
 	// Leader cohesion: slow down when squad is spread out.
 	// Skip during panic retreat — fleeing soldiers don't wait for the squad.
 	if s.isLeader && s.squad != nil && !s.blackboard.PanicRetreatActive {
@@ -2931,6 +2999,7 @@ func (s *Soldier) moveAlongPath(dt float64) {
 		}
 	}
 	speed *= coverMul
+	speed *= s.env.MoveMul(s.tickVal(), s.tileMap, s.x, s.y)
 	exertion := speed / soldierSpeed
 	s.profile.Physical.AccumulateFatigue(exertion, dt)
 
@@ -2985,7 +3054,7 @@ func (s *Soldier) enforcePersonalSpace() {
 		return
 	}
 	for _, m := range s.squad.Members {
-		if m == s || m.state == SoldierStateDead {
+		if m == s || m.state == SoldierStateDead || s.sameStretcher(m) {
 			continue
 		}
 		// Resolve each pair once to avoid symmetric push jitter.
@@ -3087,10 +3156,11 @@ func (s *Soldier) UpdateVisionSpatial(enemyHash *SpatialHash, buildings []rect)
 
 	// Query only enemies within vision range using spatial hash.
 	// This is much faster than checking all enemies on the map.
+	s.vision.EnvMul = s.env.VisionMul(s.tickVal())
 	effectiveRange := s.vision.DegradeRange(s.profile.Physical.Fatigue)
 	nearbyEnemies := enemyHash.QueryRadius(s.x, s.y, effectiveRange)
 
-	s.vision.PerformVisionScan(s.x, s.y, nearbyEnemies, buildings, s.covers)
+	s.vision.PerformVisionScan(s.x, s.y, nearbyEnemies, buildings, s.covers, s.smoke)
 
 	// Corner/doorway peek: if wall-adjacent and at a corner, perform a
 	// supplementary narrow-FOV scan in peek directions.
@@ -3103,6 +3173,8 @@ func (s *Soldier) UpdateVisionSpatial(enemyHash *SpatialHash, buildings []rect)
 			}
 		}
 	}
+	s.dropContactsBehindDoors()
+	s.updateDetection(s.tickVal())
 
 	// Seeing enemies increases fear, but should not permanently prevent recovery
 	// when contact is distant and no rounds are landing.
@@ -3141,7 +3213,8 @@ func (s *Soldier) UpdateVision(enemies []*Soldier, buildings []rect) {
 	if s.state == SoldierStateDead {
 		return
 	}
-	s.vision.PerformVisionScan(s.x, s.y, enemies, buildings, s.covers)
+	s.vision.EnvMul = s.env.VisionMul(s.tickVal())
+	s.vision.PerformVisionScan(s.x, s.y, enemies, buildings, s.covers, s.smoke)
 
 	// Corner/doorway peek: if wall-adjacent and at a corner, perform a
 	// supplementary narrow-FOV scan in peek directions. This simulates
@@ -3155,6 +3228,8 @@ func (s *Soldier) UpdateVision(enemies []*Soldier, buildings []rect) {
 			}
 		}
 	}
+	s.dropContactsBehindDoors()
+	s.updateDetection(s.tickVal())
 
 	// Seeing enemies increases fear, but should not permanently prevent recovery
 	// when contact is distant and no rounds are landing.
@@ -3220,8 +3295,8 @@ func (s *Soldier) peekScan(direction, fov, maxRange float64, enemies []*Soldier,
 			continue
 		}
 
-		// LOS check through buildings and cover.
-		if HasLineOfSightWithCover(s.x, s.y, e.x, e.y, buildings, s.covers) {
+		// LOS check through buildings, cover and smoke; smoke shortens the peek.
+		if clear := LineOfSightClarity(s.x, s.y, e.x, e.y, buildings, s.covers, s.smoke); clear > 0 && dist <= maxRange*clear {
 			s.vision.KnownContacts = append(s.vision.KnownContacts, e)
 		}
 	}
@@ -3233,6 +3308,9 @@ func (s *Soldier) Draw(screen *ebiten.Image, offX, offY int) {
 	ox, oy := float32(offX), float32(offY)
 	sx, sy := ox+float32(s.x), oy+float32(s.y)
 
+	if s.casualty.Evacuated {
+		return // off the field
+	}
 	if s.state == SoldierStateDead {
 		// Pool of darkness under the body.
 		vector.FillCircle(screen, sx+1.5, sy+1.5, float32(soldierRadius)+4, color.RGBA{R: 20, G: 5, B: 5, A: 140}, false)
@@ -3745,7 +3823,7 @@ func (s *Soldier) dashOverwatchDuration(underFire bool) int {
 }
 
 func (s *Soldier) reloadDurationTicks() int {
-	ticks := float64(reloadBaseTicks)
+	ticks := float64(reloadBaseTicks) * s.firearm().ReloadMul
 	discipline := clamp01(s.profile.Skills.Discipline)
 	fitness := clamp01(s.profile.Physical.EffectiveFitness())
 	stress := clamp01(s.profile.Psych.EffectiveFear() + s.blackboard.SuppressLevel*0.65)
@@ -0,0 +1,394 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// --- Sound constants ---
+
+const (
+	soundSpeed = 23.0 // px per tick (~343 m/s at roughly 4 px per metre)
+
+	soundShoutRange      = 420.0 // px, a wounded man crying out
+	soundDoorRange       = 260.0 // px, a door banging or creaking
+	soundSoftStepRange   = 60.0  // px, footsteps on grass, dirt or sand
+	soundHardStepRange   = 110.0 // px, footsteps on paving, floors, rubble or water
+	soundGravelStepRange = 240.0 // px, footsteps on loose gravel
+	soundStrideDist      = 40.0  // px moved between footstep events
+
+	soundWallMul    = 0.45 // strength kept per wall the sound passes through
+	soundBearingErr = 0.35 // rad, worst bearing error on a faint sound
+	soundRangeErr   = 0.45 // worst range error as a fraction of true distance
+
+	soundThreatConfidence = 0.30  // heard but not seen (design/systems/cognition.md §3.2)
+	soundThreatDecay      = 0.002 // confidence lost per tick; a heard contact fades in ~2.5s
+)
+
+// SoundKind identifies what made a sound.
+type SoundKind int
+
+const (
+	SoundGunfire SoundKind = iota
+	SoundFootstep
+	SoundShout
+	SoundDoor
+)
+
+func (k SoundKind) String() string {
+	switch k {
+	case SoundGunfire:
+		return "gunfire"
+	case SoundFootstep:
+		return "footsteps"
+	case SoundShout:
+		return "shout"
+	case SoundDoor:
+		return "door"
+	default:
+		return "unknown"
+	}
+}
+
+// SoundEvent is one sound made somewhere on the map.
+type SoundEvent struct {
+	Kind   SoundKind
+	X, Y   float64
+	Team   Team     // side that made the sound; only the other side listens
+	Source *Soldier // nil for explosions
+	Tick   int
+	Range  float64 // px at which the sound fades out completely
+}
+
+// pendingSound is a sound on its way to one listener.
+type pendingSound struct {
+	ev       SoundEvent
+	listener *Soldier
+	strength float64
+	arrival  int
+}
+
+// SoundField carries sounds from where they are made to the soldiers who
+// hear them. Each sound reaches a listener after a delay set by distance,
+// muffled by walls on the way. A nil *SoundField drops every sound.
+type SoundField struct {
+	emitted []SoundEvent   // made this tick, not yet sent
+	pending []pendingSound // in flight
+}
+
+// NewSoundField returns an empty sound field.
+func NewSoundField() *SoundField {
+	return &SoundField{}
+}
+
+// Emit queues a sound to be sent on the next propagation pass.
+func (sf *SoundField) Emit(ev SoundEvent) {
+	if sf == nil {
+		return
+	}
+	sf.emitted = append(sf.emitted, ev)
+}
+
+// InFlight returns how many sounds are still travelling to a listener.
+func (sf *SoundField) InFlight() int {
+	if sf == nil {
+		return 0
+	}
+	return len(sf.pending)
+}
+
+// emitFootsteps makes a footstep sound for each soldier who has covered a
+// stride since the last one, and a door sound for each soldier who has just
+// stepped into a doorway or shut or breached a door. Loudness depends on the
+// ground and the soldier's stance. tm may be nil, in which case all ground
+// is soft.
+func (sf *SoundField) emitFootsteps(soldiers []*Soldier, tm *TileMap, tick int) {
+	for _, s := range soldiers {
+		if s.state == SoldierStateDead || s.state.IsIncapacitated() {
+			continue
+		}
+		if s.doorNoise > 0 {
+			sf.Emit(SoundEvent{Kind: SoundDoor, X: s.x, Y: s.y, Team: s.team, Source: s, Tick: tick, Range: s.doorNoise})
+			s.doorNoise = 0
+		}
+		if tm != nil {
+			col, row := WorldToCell(s.x, s.y)
+			if cell := row*tm.Cols + col; tm.inBounds(col, row) && cell != s.soundCell {
+				s.soundCell = cell
+				if tm.ObjectAt(col, row) == ObjectDoorOpen {
+					sf.Emit(SoundEvent{Kind: SoundDoor, X: s.x, Y: s.y, Team: s.team, Source: s, Tick: tick, Range: soundDoorRange})
+				}
+			}
+		}
+		if math.Hypot(s.x-s.stepX, s.y-s.stepY) < soundStrideDist {
+			continue
+		}
+		s.stepX, s.stepY = s.x, s.y
+		ground := GroundGrass
+		if tm != nil {
+			ground = tm.Ground(int(s.x)/cellSize, int(s.y)/cellSize)
+		}
+		r := footstepRange(ground)
+		switch s.profile.Stance {
+		case StanceCrouching:
+			r *= 0.6
+		case StanceProne:
+			r *= 0.3
+		}
+		sf.Emit(SoundEvent{Kind: SoundFootstep, X: s.x, Y: s.y, Team: s.team, Source: s, Tick: tick, Range: r})
+	}
+}
+
+// footstepRange returns how far a walking footstep on ground g carries.
+func footstepRange(g GroundType) float64 {
+	switch g {
+	case GroundGravel:
+		return soundGravelStepRange
+	case GroundTarmac, GroundPavement, GroundConcrete, GroundTile, GroundWood,
+		GroundWater, GroundRubbleLight, GroundRubbleHeavy:
+		return soundHardStepRange
+	default:
+		return soundSoftStepRange
+	}
+}
+
+// PropagateSound is the sound step of the tick. It turns this tick's gunfire
+// into sound events alongside footsteps, shouts and doors, sends each one
+// toward every enemy in earshot, and delivers the sounds whose wavefront has
+// arrived. Muzzle flashes travel at the speed of light and are noticed at
+// once, and a shooter's own side always knows where they fired from.
+//
+// redHash and blueHash may be nil, in which case every soldier of the
+// listening side is considered. tm may be nil, in which case buildings
+// muffle sound instead of individual walls.
+func (cm *CombatManager) PropagateSound(red, blue []*Soldier, redHash, blueHash *SpatialHash, tm *TileMap, tick int) {
+	sf := cm.Sound
+	if sf == nil {
+		return
+	}
+	listenersOf := func(team Team, x, y, r float64) []*Soldier {
+		if team == TeamRed {
+			if redHash != nil {
+				return redHash.QueryRadius(x, y, r)
+			}
+			return red
+		}
+		if blueHash != nil {
+			return blueHash.QueryRadius(x, y, r)
+		}
+		return blue
+	}
+	enemyOf := func(t Team) Team {
+		if t == TeamRed {
+			return TeamBlue
+		}
+		return TeamRed
+	}
+
+	for _, ev := range cm.Gunfires {
+		for _, s := range listenersOf(enemyOf(ev.Team), ev.X, ev.Y, gunfireHearingMaxRange) {
+			if s.state != SoldierStateDead {
+				s.noticeMuzzleFlash(ev, tick)
+			}
+		}
+		// Shooters also remember they fired (self-activation).
+		shooters := red
+		if ev.Team == TeamBlue {
+			shooters = blue
+		}
+		for _, s := range shooters {
+			if s.state == SoldierStateDead {
+				continue
+			}
+			s.blackboard.RecordGunfireWithStrength(ev.X, ev.Y, 1.0)
+		}
+		sf.Emit(SoundEvent{Kind: SoundGunfire, X: ev.X, Y: ev.Y, Team: ev.Team, Source: ev.Shooter, Tick: tick, Range: gunfireHearingMaxRange})
+	}
+	sf.emitFootsteps(red, tm, tick)
+	sf.emitFootsteps(blue, tm, tick)
+
+	for _, ev := range sf.emitted {
+		for _, s := range listenersOf(enemyOf(ev.Team), ev.X, ev.Y, ev.Range) {
+			if s.state == SoldierStateDead {
+				continue
+			}
+			strength := soundHeardStrength(ev, s, red, blue, tm)
+			if strength < gunfireMinHeardStrength {
+				continue
+			}
+			dist := math.Hypot(ev.X-s.x, ev.Y-s.y)
+			sf.pending = append(sf.pending, pendingSound{
+				ev:       ev,
+				listener: s,
+				strength: strength,
+				arrival:  tick + int(math.Ceil(dist/soundSpeed)),
+			})
+		}
+	}
+	sf.emitted = sf.emitted[:0]
+
+	waiting := sf.pending[:0]
+	for _, p := range sf.pending {
+		if p.arrival > tick {
+			waiting = append(waiting, p)
+			continue
+		}
+		if p.listener.state != SoldierStateDead {
+			p.listener.hearSound(p.ev, p.strength, tick)
+		}
+	}
+	sf.pending = waiting
+}
+
+// soundHeardStrength returns how clearly listener hears ev, 0-1, from distance
+// falloff, walls in the way, fieldcraft, nearby allies and the weather.
+func soundHeardStrength(ev SoundEvent, listener *Soldier, red, blue []*Soldier, tm *TileMap) float64 {
+	dist := math.Hypot(ev.X-listener.x, ev.Y-listener.y)
+	if ev.Range <= 0 || dist > ev.Range {
+		return 0
+	}
+
+	// Distance falloff with a small floor inside range.
+	distanceFactor := 1.0 - dist/ev.Range
+	if distanceFactor < 0.10 {
+		distanceFactor = 0.10
+	}
+
+	// Walls muffle sound; without a tile map fall back to whole buildings.
+	occlusionFactor := 1.0
+	if tm != nil {
+		occlusionFactor = math.Pow(soundWallMul, float64(tm.wallsBetween(ev.X, ev.Y, listener.x, listener.y)))
+	} else if !HasLineOfSightWithCover(ev.X, ev.Y, listener.x, listener.y, listener.buildings, listener.covers) {
+		occlusionFactor = gunfireOccludedMul
+	}
+
+	// Fieldcraft slightly improves auditory cue extraction.
+	fieldcraft := 0.0
+	if listener.profile.Skills.Fieldcraft > 0 {
+		fieldcraft = listener.profile.Skills.Fieldcraft
+	}
+	fieldcraftFactor := 0.85 + fieldcraft*0.30
+
+	// Nearby allied listeners reinforce confidence in the heard direction.
+	allies := red
+	if listener.team == TeamBlue {
+		allies = blue
+	}
+	nearbyAllies := 0
+	for _, a := range allies {
+		if a == nil || a == listener || a.state == SoldierStateDead {
+			continue
+		}
+		if math.Hypot(a.x-listener.x, a.y-listener.y) <= 220 {
+			nearbyAllies++
+		}
+	}
+	allyBoost := 1.0 + math.Min(0.12, float64(nearbyAllies)*0.04)
+
+	// Rain masks sound; wind carries it downwind.
+	envFactor := listener.env.HearingMul(listener.tickVal(), ev.X, ev.Y, listener.x, listener.y)
+
+	return clamp01(distanceFactor * occlusionFactor * fieldcraftFactor * allyBoost * envFactor)
+}
+
+// wallsBetween counts the walls, closed doors and windows a sound crosses on
+// the straight line from (ax, ay) to (bx, by). A run of adjacent wall tiles
+// counts once, so a ray grazing along a wall is not muffled many times over.
+func (tm *TileMap) wallsBetween(ax, ay, bx, by float64) int {
+	sc, sr := WorldToCell(ax, ay)
+	tc, tr := WorldToCell(bx, by)
+	dc := absInt(tc - sc)
+	dr := absInt(tr - sr)
+	xStep, yStep := 1, 1
+	if tc < sc {
+		xStep = -1
+	}
+	if tr < sr {
+		yStep = -1
+	}
+	err := dc - dr
+
+	walls := 0
+	inWall := false
+	col, row := sc, sr
+	for {
+		solid := tm.inBounds(col, row) && objectBlocksSound(tm.ObjectAt(col, row))
+		if solid && !inWall {
+			walls++
+		}
+		inWall = solid
+		if col == tc && row == tr {
+			break
+		}
+		e2 := err * 2
+		if e2 > -dr {
+			err -= dr
+			col += xStep
+		}
+		if e2 < dc {
+			err += dc
+			row += yStep
+		}
+	}
+	return walls
+}
+
+// objectBlocksSound reports whether an object muffles sound passing through it.
+func objectBlocksSound(o ObjectType) bool {
+	switch o {
+	case ObjectWall, ObjectDoor, ObjectWindow, ObjectTallWall:
+		return true
+	}
+	return false
+}
+
+// hearSound applies a sound that has reached s. The listener only gets an
+// estimate of where it came from: bearing and distance errors grow as the
+// sound gets fainter and shrink with fieldcraft. Gunfire activates the
+// soldier as before; any sound with a known source becomes an uncertain
+// threat on the blackboard.
+func (s *Soldier) hearSound(ev SoundEvent, strength float64, tick int) {
+	ex, ey := s.estimateSoundOrigin(ev, strength, tick)
+	bb := &s.blackboard
+	if ev.Kind == SoundGunfire {
+		// Single-tick flag — used by immediate decision logic.
+		bb.HeardGunfireX = ex
+		bb.HeardGunfireY = ey
+		bb.HeardGunfire = true
+		bb.HeardGunfireTick = tick
+		// Persistent memory — keeps soldier activated for ~60s after last shot.
+		bb.RecordGunfireWithStrength(ex, ey, strength)
+	}
+	if ev.Source == nil {
+		return
+	}
+	if bb.NoteHeardContact(ev.Source, ex, ey, tick) && ev.Kind != SoundGunfire {
+		s.think(fmt.Sprintf("heard %s ~%.0fpx out", ev.Kind, math.Hypot(ex-s.x, ey-s.y)))
+	}
+}
+
+// estimateSoundOrigin returns where s believes ev came from.
+func (s *Soldier) estimateSoundOrigin(ev SoundEvent, strength float64, tick int) (float64, float64) {
+	dx, dy := ev.X-s.x, ev.Y-s.y
+	dist := math.Hypot(dx, dy)
+	if dist < 1 {
+		return ev.X, ev.Y
+	}
+	vague := (1 - strength) * (1.15 - s.profile.Skills.Fieldcraft*0.3)
+	seed := float64(s.id+1)*31.7 + float64(tick)*0.173 + ev.X*0.011 + ev.Y*0.007
+	bearing := math.Atan2(dy, dx) + (soundRoll(seed)*2-1)*soundBearingErr*vague
+	r := dist * (1 + (soundRoll(seed+5.3)*2-1)*soundRangeErr*vague)
+	return s.x + math.Cos(bearing)*r, s.y + math.Sin(bearing)*r
+}
+
+// soundRoll is a deterministic 0-1 roll for sound localisation error.
+func soundRoll(seed float64) float64 {
+	v := math.Sin(seed*12.9898) * 43758.5453
+	return v - math.Floor(v)
+}
+
+// cryOut makes a wounded soldier shout, giving their position away to any
+// enemy in earshot.
+func (cm *CombatManager) cryOut(s *Soldier) {
+	cm.Sound.Emit(SoundEvent{Kind: SoundShout, X: s.x, Y: s.y, Team: s.team, Source: s, Tick: cm.tick, Range: soundShoutRange})
+}
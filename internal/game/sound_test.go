@@ -0,0 +1,156 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPropagateSound_GunfireArrivesAfterDelay(t *testing.T) {
+	tick := 0
+	shooter := newTestSoldier(1, 800, 200, TeamBlue, &tick)
+	listener := newTestSoldier(2, 100, 200, TeamRed, &tick)
+	red, blue := []*Soldier{listener}, []*Soldier{shooter}
+	cm := NewCombatManager(1)
+
+	cm.Gunfires = append(cm.Gunfires, GunfireEvent{X: shooter.x, Y: shooter.y, Team: TeamBlue, Shooter: shooter})
+	cm.PropagateSound(red, blue, nil, nil, nil, tick)
+	if listener.blackboard.HeardGunfire {
+		t.Fatal("gunfire 700px away should not be heard on the tick it was fired")
+	}
+	if cm.Sound.InFlight() != 1 {
+		t.Fatalf("expected one sound in flight, got %d", cm.Sound.InFlight())
+	}
+
+	arrival := int(math.Ceil(700 / soundSpeed))
+	for tick = 1; tick <= arrival; tick++ {
+		cm.ResetFireCounts(append(red, blue...))
+		cm.PropagateSound(red, blue, nil, nil, nil, tick)
+		if heard := listener.blackboard.HeardGunfire; heard != (tick == arrival) {
+			t.Fatalf("tick %d: heard=%v, want the shot to arrive at tick %d", tick, heard, arrival)
+		}
+	}
+
+	bb := &listener.blackboard
+	if bb.HeardGunfireX == shooter.x && bb.HeardGunfireY == shooter.y {
+		t.Fatal("listener should only get an estimate of the shooter's position")
+	}
+	if d := math.Hypot(bb.HeardGunfireX-shooter.x, bb.HeardGunfireY-shooter.y); d > 700*soundRangeErr*1.5 {
+		t.Fatalf("estimate is %.0fpx off, too far for a clearly heard shot", d)
+	}
+	if len(bb.Threats) != 1 || !bb.Threats[0].Uncertain || bb.Threats[0].Source != shooter ||
+		bb.Threats[0].Confidence != soundThreatConfidence {
+		t.Fatalf("expected one uncertain threat for the shooter, got %+v", bb.Threats)
+	}
+}
+
+func TestTileMap_WallsBetweenCountsEachWallOnce(t *testing.T) {
+	tm := NewTileMap(20, 10)
+	for row := 0; row < 10; row++ {
+		tm.SetObject(10, row, ObjectWall)
+	}
+	centre := func(col, row int) (float64, float64) {
+		return float64(col*cellSize + cellSize/2), float64(row*cellSize + cellSize/2)
+	}
+	ax, ay := centre(2, 5)
+	bx, by := centre(17, 5)
+	if n := tm.wallsBetween(ax, ay, bx, by); n != 1 {
+		t.Fatalf("ray through one wall crossed %d", n)
+	}
+	cx, cy := centre(10, 0)
+	dx, dy := centre(10, 9)
+	if n := tm.wallsBetween(cx, cy, dx, dy); n != 1 {
+		t.Fatalf("ray along a wall should count it once, got %d", n)
+	}
+	ex, ey := centre(2, 1)
+	if n := tm.wallsBetween(ax, ay, ex, ey); n != 0 {
+		t.Fatalf("clear ray crossed %d walls", n)
+	}
+
+	tick := 0
+	listener := newTestSoldier(1, bx, by, TeamRed, &tick)
+	ev := SoundEvent{Kind: SoundGunfire, X: ax, Y: ay, Team: TeamBlue, Range: gunfireHearingMaxRange}
+	open := soundHeardStrength(ev, listener, nil, nil, NewTileMap(20, 10))
+	walled := soundHeardStrength(ev, listener, nil, nil, tm)
+	if math.Abs(walled-open*soundWallMul) > 1e-9 {
+		t.Fatalf("walled strength %.3f, want %.3f", walled, open*soundWallMul)
+	}
+}
+
+func TestPropagateSound_FootstepsOnGravelGiveAwayPosition(t *testing.T) {
+	tm := NewTileMap(40, 20)
+	tick := 0
+	walker := newTestSoldier(1, 200, 160, TeamBlue, &tick)
+	listener := newTestSoldier(2, 350, 160, TeamRed, &tick)
+	red, blue := []*Soldier{listener}, []*Soldier{walker}
+	cm := NewCombatManager(1)
+
+	step := func() {
+		walker.x += soundStrideDist
+		for i := 0; i < 10; i++ {
+			tick++
+			cm.PropagateSound(red, blue, nil, nil, tm, tick)
+		}
+	}
+
+	step()
+	if len(listener.blackboard.Threats) != 0 {
+		t.Fatal("footsteps on grass should not carry 110px")
+	}
+	for c := 0; c < tm.Cols; c++ {
+		for r := 0; r < tm.Rows; r++ {
+			tm.SetGround(c, r, GroundGravel)
+		}
+	}
+	walker.profile.Stance = StanceProne
+	step()
+	if len(listener.blackboard.Threats) != 0 {
+		t.Fatal("a soldier crawling over gravel should stay quiet at this range")
+	}
+	walker.profile.Stance = StanceStanding
+	step()
+	if len(listener.blackboard.Threats) != 1 || !listener.blackboard.Threats[0].Uncertain {
+		t.Fatalf("footsteps on gravel should be heard, threats=%+v", listener.blackboard.Threats)
+	}
+	if listener.blackboard.HeardGunfire {
+		t.Fatal("footsteps are not gunfire")
+	}
+}
+
+func TestBlackboard_HeardContactIsUncertainUntilSeen(t *testing.T) {
+	tick := 0
+	enemy := newTestSoldier(1, 300, 200, TeamBlue, &tick)
+	bb := &Blackboard{}
+
+	if !bb.NoteHeardContact(enemy, 320, 210, 0) {
+		t.Fatal("first heard contact should be new")
+	}
+	if bb.NoteHeardContact(enemy, 310, 190, 5) || len(bb.Threats) != 1 || bb.Threats[0].X != 310 {
+		t.Fatalf("a second sound should move the estimate, got %+v", bb.Threats)
+	}
+
+	for tick = 6; tick < 60; tick++ {
+		bb.UpdateThreats(nil, tick)
+	}
+	if len(bb.Threats) != 1 || bb.Threats[0].Confidence >= soundThreatConfidence {
+		t.Fatalf("heard contact should fade slowly, got %+v", bb.Threats)
+	}
+
+	bb.UpdateThreats([]*Soldier{enemy}, tick)
+	if bb.Threats[0].Uncertain || bb.Threats[0].X != enemy.x {
+		t.Fatal("seeing the enemy should confirm the contact")
+	}
+	if bb.NoteHeardContact(enemy, 0, 0, tick) || bb.Threats[0].X != enemy.x {
+		t.Fatal("a sound must not override a visible threat")
+	}
+
+	for tick++; len(bb.Threats) > 0 && tick < 1000; tick++ {
+		bb.UpdateThreats(nil, tick)
+	}
+	bb.NoteHeardContact(enemy, 320, 210, tick)
+	for end := tick + int(soundThreatConfidence/soundThreatDecay) + 2; tick < end; tick++ {
+		bb.UpdateThreats(nil, tick)
+	}
+	if len(bb.Threats) != 0 {
+		t.Fatal("an unconfirmed heard contact should be forgotten")
+	}
+}
@@ -124,9 +124,14 @@ func contextualPhrase(rng *rand.Rand, s *Soldier) (string, string) {
 			fmt.Sprintf("hp:%.0f%% fear:%.0f%%", hpPct*100, ef*100)
 	}
 
+	if s.magRounds <= 0 && s.spareMags <= 0 && (threats > 0 || incoming > 0) {
+		return pickSpeechLine(rng, "I'm out!", "Ammo black!", "No rounds!", "Need a mag!"),
+			fmt.Sprintf("ammo:0 mags:%d", s.spareMags)
+	}
+
 	if s.magRounds <= max(3, s.magCapacity/6) && (threats > 0 || incoming > 0) {
 		return pickSpeechLine(rng, "Low ammo!", "Almost dry!", "Need a reload window!", "Magazine nearly empty!"),
-			fmt.Sprintf("ammo:%d/%d", s.magRounds, s.magCapacity)
+			fmt.Sprintf("ammo:%d/%d mags:%d", s.magRounds, s.magCapacity, s.spareMags)
 	}
 
 	// Panic: high fear overrides everything.
@@ -19,6 +19,10 @@ type Squad struct {
 	supportTargetY     float64
 	supportActiveUntil int
 
+	// Tasking from the platoon leader, as last heard over the platoon net.
+	// Zero when the squad is not in a platoon.
+	platoonTask PlatoonTask
+
 	// Active officer command for the squad. Commands are strong signals that
 	// bias individual utility, not hard overrides.
 	ActiveOrder OfficerOrder
@@ -35,7 +39,7 @@ type Squad struct {
 
 	// Command succession: when the leader dies the next member takes over
 	// after a delay scaled by their stress level.
-	leaderDeadTick       int  // tick when the leader was first found dead
+	leaderDeadTick       int  // tick when the leader was first found down
 	leaderSuccessionTick int  // tick when command is re-established
 	leaderSucceeding     bool // true while awaiting succession
 
@@ -53,6 +57,18 @@ type Squad struct {
 	buildingState *BuildingState
 	// Building intel: leader's mental map of enemy-occupied buildings.
 	buildingIntel *BuildingIntelMap
+	// Coordinated entry into the claimed building, nil when none is under way.
+	entryPlan *BuildingEntryPlan
+	// Earliest tick the squad will pop smoke again.
+	smokeCooldownUntil int
+	// Members who reported low ammunition, and when the next magazine can be passed.
+	ammoRequests      []int
+	ammoShareNextTick int
+	// Casualty collection point, designated when the first casualty is
+	// ready to be carried back, and the team's evacuation point (nil = none).
+	ccpX, ccpY float64
+	ccpSet     bool
+	evac       *EvacPoint
 
 	// Intent hysteresis: avoid order thrash at range boundaries.
 	intentLockUntil      int // tick until which non-critical intent changes are deferred
@@ -112,6 +128,7 @@ type Squad struct {
 
 	// Phase A radio net state.
 	radioNet                   radioNet
+	radio                      *radioMedium // terrain, jammers and relays; nil is open air
 	radioPendingStatus         map[int]int  // memberID -> deadline tick
 	radioStatusReplyQueued     map[int]bool // memberID -> true once one reply has been queued for current request
 	radioUnresponsive          map[int]bool // memberID -> no-reply inferred
@@ -134,6 +151,12 @@ type Squad struct {
 
 	// Flow-field navigation controller (hierarchical: strategic + tactical layers)
 	flowController *SquadFlowController
+
+	// paint is the team's player-painted command layer, nil without an IntelStore.
+	paint *PaintLayer
+	// intel is the squad's own picture in IntelRadio mode; nil when the team
+	// shares one map.
+	intel *IntelMap
 }
 
 const stalledOrderCooldownTicks = 90
@@ -217,7 +240,7 @@ func (sq *Squad) recoveryRetaskTarget(hasContact bool, contactX, contactY float6
 func (sq *Squad) clearStalledPathDebt() int {
 	cleared := 0
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		if m.blackboard.CurrentGoal != GoalMoveToContact && m.blackboard.CurrentGoal != GoalEngage {
@@ -252,6 +275,7 @@ func NewSquad(id int, team Team, members []*Soldier) *Squad {
 		buildingIntel:      NewBuildingIntelMap(),
 		radioNet: radioNet{
 			netID: id + 1,
+			name:  squadNetName(id),
 		},
 		radioPendingStatus:     make(map[int]int),
 		radioStatusReplyQueued: make(map[int]bool),
@@ -294,9 +318,9 @@ func (sq *Squad) InitializeFlowField(navGrid *NavGrid, tacticalMap *TacticalMap)
 const successionDelayTicks = 180 // 3 seconds at 60TPS base
 
 const (
-	engageEnterDist = 500.0 // must be this close to enter engage (within accurateFireRange)
-	engageExitDist  = 600.0 // can stay engaged until this distance
-	stalemateRange  = accurateFireRange * 1.05
+	engageEnterDist   = 500.0 // must be this close to enter engage (within accurateFireRange)
+	engageExitDist    = 600.0 // can stay engaged until this distance
+	stalemateRangeMul = 1.05  // × accurate fire range
 
 	leaderPreferredForwardMin = 72.0
 	leaderPreferredForwardMax = 170.0
@@ -334,7 +358,7 @@ func (sq *Squad) updateStalematePressure(tick int, hasContact bool, anyVisibleTh
 	}
 
 	leaderUnderFire := sq.Leader.blackboard.IncomingFireCount > 0 || sq.Leader.blackboard.IsSuppressed()
-	outOfEffectiveRange := closestDist > stalemateRange
+	outOfEffectiveRange := closestDist > sq.Leader.config().AccurateFireRange*stalemateRangeMul
 	limitedVisualContact := anyVisibleThreats == 0
 	manyTerminalStalled := aliveCount >= 2 && terminalStalledCount*2 >= aliveCount
 
@@ -361,7 +385,7 @@ func (sq *Squad) updateStalematePressure(tick int, hasContact bool, anyVisibleTh
 }
 
 func (sq *Squad) leaderObservedPhaseSteer(hasContact bool, closestDist float64, anyVisibleThreats int) (SquadPhase, bool) {
-	if sq.Leader == nil || sq.Leader.state == SoldierStateDead {
+	if sq.Leader == nil || sq.Leader.offField() {
 		return sq.Phase, false
 	}
 	bb := &sq.Leader.blackboard
@@ -573,10 +597,32 @@ func (sq *Squad) syncOfficerOrder(tick int, hasContact bool, contactX, contactY
 
 	leaderX, leaderY := sq.Leader.x, sq.Leader.y
 	goalX, goalY := sq.Leader.endTarget[0], sq.Leader.endTarget[1]
+	// Player paint overrides the mission objective: the nearest painted
+	// objective first, otherwise the nearest hold zone.
+	painted := true
+	if x, y, ok := sq.paint.AreaNear(PaintObjective, leaderX, leaderY); ok {
+		goalX, goalY = x, y
+	} else if x, y, ok := sq.paint.AreaNear(PaintHold, leaderX, leaderY); ok {
+		goalX, goalY = x, y
+	} else {
+		painted = false
+	}
 	goalDist := math.Hypot(goalX-leaderX, goalY-leaderY)
 
+	// The platoon leader's tasking comes next, below the player's paint.
+	if !painted && sq.followPlatoonTask(tick, forceProactive) {
+		sq.expireActiveOrder(tick)
+		return
+	}
+
 	switch sq.Intent {
 	case IntentAdvance:
+		if !hasContact && sq.paint.In(PaintHold, leaderX, leaderY) {
+			// Reached a painted hold zone: stop here and hold it.
+			sq.Formation = FormationLine
+			sq.issueOfficerOrder(tick, CmdHold, leaderX, leaderY, 150, sq.Formation, 0.75, 0.88, 240)
+			break
+		}
 		form := FormationWedge
 		if !hasContact && goalDist > 650 {
 			form = FormationColumn
@@ -649,21 +695,37 @@ func (sq *Squad) syncOfficerOrder(tick int, hasContact bool, contactX, contactY
 		}
 	}
 
+	sq.expireActiveOrder(tick)
+}
+
+func (sq *Squad) expireActiveOrder(tick int) {
 	if sq.ActiveOrder.State == OfficerOrderActive && sq.ActiveOrder.ExpiresTick > 0 && tick > sq.ActiveOrder.ExpiresTick {
 		sq.ActiveOrder.State = OfficerOrderExpired
 	}
 }
 
+// intelMap is the map the squad acts on: its own in IntelRadio mode, else
+// the team's.
+func (sq *Squad) intelMap(intel *IntelStore) *IntelMap {
+	if sq.intel != nil {
+		return sq.intel
+	}
+	if intel == nil {
+		return nil
+	}
+	return intel.For(sq.Team)
+}
+
 // SquadThink runs the leader's squad-level decision loop.
 // It evaluates the leader's blackboard and sets Intent + orders for members.
 // intel is the world IntelStore; may be nil (degrades gracefully to blackboard-only).
 func (sq *Squad) SquadThink(intel *IntelStore) {
-	leaderIncapacitated := sq.Leader == nil || sq.Leader.state == SoldierStateDead || sq.Leader.state == SoldierStateUnconscious
+	leaderIncapacitated := sq.Leader == nil || sq.Leader.offField() || sq.Leader.state == SoldierStateUnconscious
 	if leaderIncapacitated {
 		// Find capable candidates (alive and not incapacitated)
 		var candidates []*Soldier
 		for _, m := range sq.Members {
-			if m.state != SoldierStateDead && m.state != SoldierStateUnconscious &&
+			if !m.offField() && m.state != SoldierStateUnconscious &&
 				m.state != SoldierStateWoundedNonAmbulatory {
 				candidates = append(candidates, m)
 			}
@@ -695,7 +757,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 			sq.Intent = IntentHold
 			// Propagate a holding intent but don't update the leader pointer yet.
 			for _, m := range sq.Members {
-				if m.state == SoldierStateDead {
+				if m.offField() {
 					continue
 				}
 				m.blackboard.SquadIntent = IntentHold
@@ -710,11 +772,14 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		candidate.think("command established")
 	}
 
+	sq.paint = intel.PaintFor(sq.Team)
+
 	// Update flow-field controller with enemy positions for threat costs
 	if sq.flowController != nil {
+		sq.flowController.SetPaint(sq.paint)
 		enemies := make([]*Soldier, 0, 32)
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			for _, t := range m.blackboard.Threats {
@@ -738,7 +803,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	var contactX, contactY float64
 	hasContact := false
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		visibleByMember := 0
@@ -766,7 +831,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	// Also include non-visible but high-confidence threats for contact tracking.
 	if !hasContact {
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			for _, t := range m.blackboard.Threats {
@@ -786,7 +851,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	// Fall back to heard gunfire as a contact source (infinite range sound).
 	if !hasContact {
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if m.blackboard.HeardGunfire {
@@ -803,7 +868,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	if !hasContact {
 		bestMem := 0.0
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if m.blackboard.CombatMemoryStrength > bestMem {
@@ -836,7 +901,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	injuredAliveCount := 0
 	underFireCount := 0
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		aliveCount++
@@ -920,7 +985,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	// This is critical for responsive buddy-aid / medic-aid behavior.
 	if (newInjuries > 0 || newDeaths > 0) && sq.Leader != nil {
 		for _, m := range sq.Members {
-			if m == nil || m.state == SoldierStateDead {
+			if m == nil || m.offField() {
 				continue
 			}
 			m.blackboard.ensureInternalDefaults()
@@ -986,7 +1051,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	// Both default to 0 when intel is not yet available.
 	var dangerAtPos, contactAhead float32
 	if intel != nil {
-		im := intel.For(sq.Team)
+		im := sq.intelMap(intel)
 		if im != nil {
 			lx, ly := sq.Leader.x, sq.Leader.y
 			dangerAtPos = im.Layer(IntelDangerZone).SumInRadius(lx, ly, 120)
@@ -999,7 +1064,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	}
 	lowPressure := !hasContact && anyVisibleThreats == 0 && !sq.Broken && spread < 200 && underFireCount == 0 && sq.Stress < 0.35 && sq.Leader.profile.Psych.EffectiveFear() < 0.45
 	if lowPressure && intel != nil {
-		im := intel.For(sq.Team)
+		im := sq.intelMap(intel)
 		if im != nil {
 			dangerLayer := im.Layer(IntelDangerZone)
 			friendlyLayer := im.Layer(IntelFriendlyPresence)
@@ -1187,7 +1252,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		case IntentEngage, IntentAdvance:
 			if hasContact {
 				sq.flowController.SetStrategicGoal(contactX, contactY)
-			} else {
+			} else if !sq.flowController.SetPaintedGoals() {
 				sq.flowController.SetStrategicGoal(sq.Leader.endTarget[0], sq.Leader.endTarget[1])
 			}
 		case IntentRegroup:
@@ -1223,7 +1288,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	stalledPriorityScore := 0.0
 	if hasContact && spread < 260 {
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if sq.lastStalledOrderID == m.id && tick-sq.lastStalledOrderTick < stalledOrderCooldownTicks {
@@ -1268,7 +1333,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	// Left = bearing - 90°, right = bearing + 90°.
 	flankIdx := 0
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		if flankIdx%2 == 0 {
@@ -1325,10 +1390,12 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		posture = -1.0
 	}
 
+	sq.designateCCP()
+
 	// Write orders to all members' blackboards, including shared contact position.
 	orderIdx := 0
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		m.blackboard.SquadIntent = sq.Intent
@@ -1449,7 +1516,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 			sq.boundCycleTick = tick
 			grpIdx := 0
 			for _, m := range sq.Members {
-				if m.state == SoldierStateDead {
+				if m.offField() {
 					continue
 				}
 				m.blackboard.BoundGroup = grpIdx % 2
@@ -1462,7 +1529,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		// If so, swap groups so the overwatchers become movers.
 		allMoversSettled := true
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if m.blackboard.BoundGroup != sq.BoundMovingGroup {
@@ -1490,7 +1557,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 
 		// Write bound role to each member's blackboard.
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			m.blackboard.BoundMover = m.blackboard.BoundGroup == sq.BoundMovingGroup
@@ -1499,7 +1566,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		sq.boundCycleActive = false
 		// Clear bound roles — everyone can move freely.
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			m.blackboard.BoundMover = true
@@ -1515,7 +1582,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	if sq.ClaimedBuildingIdx >= 0 && sq.ClaimedBuildingIdx < len(sq.buildingFootprints) {
 		occupants := 0
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if m.blackboard.AtInterior {
@@ -1582,7 +1649,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 	}
 	// Propagate claim to all alive members.
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
 		m.blackboard.ClaimedBuildingIdx = sq.ClaimedBuildingIdx
@@ -1592,6 +1659,9 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 			m.blackboard.ClaimedBuildingY = float64(fp.y) + float64(fp.h)/2
 		}
 	}
+	sq.updateEntryPlan(tick, hasContact)
+	sq.considerSmoke(tick, hasContact, contactX, contactY, intel)
+	sq.redistributeAmmo(tick)
 
 	// --- Morale-driven reinforcement ---
 	// The leader identifies the most-stressed alive member and directs calm
@@ -1602,7 +1672,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		var distressedMember *Soldier
 		worstFear := 0.35 // minimum threshold to be considered distressed
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead || m == sq.Leader {
+			if m.offField() || m == sq.Leader {
 				continue
 			}
 			ef := m.profile.Psych.EffectiveFear()
@@ -1615,7 +1685,7 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 		if distressedMember != nil {
 			// Direct calm members toward the distressed one.
 			for _, m := range sq.Members {
-				if m.state == SoldierStateDead || m == distressedMember {
+				if m.offField() || m == distressedMember {
 					continue
 				}
 				mf := m.profile.Psych.EffectiveFear()
@@ -1641,13 +1711,13 @@ func (sq *Squad) SquadThink(intel *IntelStore) {
 func (sq *Squad) visibleAlliesFor(self *Soldier) int {
 	count := 0
 	for _, m := range sq.Members {
-		if m == self || m.state == SoldierStateDead {
+		if m == self || m.offField() {
 			continue
 		}
 		if !self.vision.InCone(self.x, self.y, m.x, m.y) {
 			continue
 		}
-		if HasLineOfSightWithCover(self.x, self.y, m.x, m.y, self.buildings, self.covers) {
+		if HasClearLineOfSight(self.x, self.y, m.x, m.y, self.buildings, self.covers, self.smoke) {
 			count++
 		}
 	}
@@ -1658,13 +1728,13 @@ func (sq *Squad) avgVisibleAllyFearFor(self *Soldier) float64 {
 	var sum float64
 	count := 0
 	for _, m := range sq.Members {
-		if m == self || m.state == SoldierStateDead {
+		if m == self || m.offField() {
 			continue
 		}
 		if !self.vision.InCone(self.x, self.y, m.x, m.y) {
 			continue
 		}
-		if !HasLineOfSightWithCover(self.x, self.y, m.x, m.y, self.buildings, self.covers) {
+		if !HasClearLineOfSight(self.x, self.y, m.x, m.y, self.buildings, self.covers, self.smoke) {
 			continue
 		}
 		sum += m.profile.Psych.EffectiveFear()
@@ -1683,7 +1753,7 @@ func (sq *Squad) closeAllyPressureFor(self *Soldier) float64 {
 	pressure := 0.0
 	samples := 0.0
 	for _, m := range sq.Members {
-		if m == self || m.state == SoldierStateDead {
+		if m == self || m.offField() {
 			continue
 		}
 		dx := m.x - self.x
@@ -1710,7 +1780,7 @@ const buildingClaimInterval = 300 // ~5s at 60TPS
 // (a) between the squad and its advance target, (b) close to the squad,
 // (c) not already behind the squad.
 func (sq *Squad) evaluateBuildings() {
-	if sq.Leader == nil || sq.Leader.state == SoldierStateDead {
+	if sq.Leader == nil || sq.Leader.offField() {
 		return
 	}
 	if len(sq.buildingFootprints) == 0 {
@@ -1784,7 +1854,7 @@ func (sq *Squad) evaluateBuildings() {
 		}
 		overlapCount := 0
 		for _, m := range sq.Members {
-			if m.state == SoldierStateDead {
+			if m.offField() {
 				continue
 			}
 			if m.x >= float64(fp.x)-float64(cellSize) && m.x <= float64(fp.x+fp.w)+float64(cellSize) &&
@@ -2083,17 +2153,22 @@ func (sq *Squad) squadCentroid() (float64, float64) {
 // hasCasualtiesNeedingAid returns true if the squad has wounded members requiring medical aid.
 func (sq *Squad) hasCasualtiesNeedingAid() bool {
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.offField() {
 			continue
 		}
-		// Unconscious soldiers always need aid (airway management, monitoring).
-		if m.state == SoldierStateUnconscious {
+		// Unconscious soldiers need aid (airway management, monitoring)
+		// until they are stabilised for evacuation.
+		if m.state == SoldierStateUnconscious && m.casualty.Phase != PhaseTACEVAC {
 			return true
 		}
 		// Injured soldiers with untreated wounds need aid.
 		if m.body.IsInjured() && m.body.HasUntreatedWounds() {
 			return true
 		}
+		// Stabilised stretcher cases need bearers to carry them back.
+		if m.needsStretcher() {
+			return true
+		}
 	}
 	return false
 }
@@ -2105,7 +2180,7 @@ func (sq *Squad) squadSpread() float64 {
 	}
 	max2 := 0.0
 	for _, m := range sq.Members {
-		if m == sq.Leader || m.state == SoldierStateDead {
+		if m == sq.Leader || m.offField() {
 			continue
 		}
 		dx := m.x - sq.Leader.x
@@ -2189,7 +2264,7 @@ func (sq *Squad) UpdateFormation() {
 	assigned := make(map[int][2]float64, len(sq.Members))
 
 	for i, m := range sq.Members {
-		if i == 0 || !m.formationMember || m.state == SoldierStateDead || i >= len(offsets) {
+		if i == 0 || !m.formationMember || m.offField() || i >= len(offsets) {
 			continue
 		}
 		// Don't clobber paths for members who are actively engaging or closing on contact.
@@ -2287,7 +2362,7 @@ func formationTargetCost(wx, wy, desiredX, desiredY float64, leader *Soldier, me
 
 	minSep := float64(soldierRadius) * 3.0
 	for _, m := range members {
-		if m == nil || m.state == SoldierStateDead {
+		if m == nil || m.offField() {
 			continue
 		}
 		mx, my := m.x, m.y
@@ -2316,22 +2391,23 @@ func abs(v int) int {
 	return v
 }
 
-// Alive returns members that are not incapacitated.
+// Alive returns members still on the field.
 func (sq *Squad) Alive() []*Soldier {
 	var alive []*Soldier
 	for _, m := range sq.Members {
-		if m.state != SoldierStateDead {
+		if !m.offField() {
 			alive = append(alive, m)
 		}
 	}
 	return alive
 }
 
-// CasualtyCount returns how many squad members are dead or incapacitated.
+// CasualtyCount returns how many squad members have been killed. Evacuated
+// members are not counted: their loss was already felt when they went down.
 func (sq *Squad) CasualtyCount() int {
 	count := 0
 	for _, m := range sq.Members {
-		if m.state == SoldierStateDead {
+		if m.killed() {
 			count++
 		}
 	}
@@ -2341,7 +2417,7 @@ func (sq *Squad) CasualtyCount() int {
 // LeaderPosition returns the leader's current position, or the squad
 // centroid if the leader is down.
 func (sq *Squad) LeaderPosition() (float64, float64) {
-	if sq.Leader != nil && sq.Leader.state != SoldierStateDead {
+	if sq.Leader != nil && !sq.Leader.offField() {
 		return sq.Leader.x, sq.Leader.y
 	}
 	// Fallback: centroid of alive members.
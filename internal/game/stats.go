@@ -52,6 +52,7 @@ type PhysicalStats struct {
 	FitnessBase float64 // 0-1, innate physical capability
 	Fatigue     float64 // 0-1, current exhaustion (0 = fresh, 1 = collapsed)
 	SprintPool  float64 // seconds of sprint remaining
+	Load        float64 // kg of armour worn; heavier loads tire faster and recover slower
 }
 
 // EffectiveFitness returns fitness degraded by fatigue.
@@ -59,14 +60,19 @@ func (p *PhysicalStats) EffectiveFitness() float64 {
 	return p.FitnessBase * (1.0 - p.Fatigue*0.8)
 }
 
+// fatiguePerKg is the extra fatigue rate each kg of Load adds.
+const fatiguePerKg = 0.025
+
 // AccumulateFatigue adds fatigue based on exertion level (0-1) per tick.
-// Recovery happens at a slower rate when exertion is 0.
+// Recovery happens at a slower rate when exertion is 0. Load scales both:
+// an armoured soldier tires faster and recovers slower.
 func (p *PhysicalStats) AccumulateFatigue(exertion float64, dt float64) {
+	burden := 1 + p.Load*fatiguePerKg
 	if exertion > 0 {
-		rate := 0.01 * exertion / p.FitnessBase // less fit soldiers tire faster
+		rate := 0.01 * exertion / p.FitnessBase * burden // less fit soldiers tire faster
 		p.Fatigue = math.Min(1.0, p.Fatigue+rate*dt)
 	} else {
-		recovery := 0.003 * p.FitnessBase // fitter soldiers recover faster
+		recovery := 0.003 * p.FitnessBase / burden // fitter soldiers recover faster
 		p.Fatigue = math.Max(0.0, p.Fatigue-recovery*dt)
 	}
 }
@@ -26,6 +26,9 @@ type TacticalMap struct {
 	// Negative = bad place to stop (in a doorway, open ground near buildings).
 	// Zero = neutral open ground.
 	desirability []float64
+	// opened lists the cells fire has broken open since the map was built, in
+	// order (see openCell).
+	opened []int
 }
 
 // NewTacticalMap analyses building walls, windows, and footprints to produce a TacticalMap.
@@ -217,6 +220,18 @@ func NewTacticalMap(mapW, mapH int, buildings []rect, windows []rect, footprints
 	return tm
 }
 
+// openCell reclassifies a wall or window cell that fire has broken open as
+// a gap in the wall run: a doorway, and a poor place to stop.
+func (tm *TacticalMap) openCell(cx, cy int) {
+	if cx < 0 || cy < 0 || cx >= tm.cols || cy >= tm.rows {
+		return
+	}
+	idx := cy*tm.cols + cx
+	tm.traits[idx] = tm.traits[idx]&^CellTraitWindow | CellTraitDoorway
+	tm.desirability[idx] = -0.6
+	tm.opened = append(tm.opened, idx)
+}
+
 // ScanBestNearby searches nearby walkable cells for the best tactical position.
 // It considers desirability, distance to enemy (prefer closer/perpendicular), and
 // whether the cell is inside a claimed building. Returns world coords and score.
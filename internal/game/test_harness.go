@@ -22,28 +22,30 @@ type effectivenessProbe struct {
 }
 
 // TestSim is a headless simulation harness used exclusively by tests.
-// It mirrors Game.Update but has no Ebiten dependency and supports
-// deterministic seeding and structured logging.
+// It steps the same World as Game, adding deterministic seeding and
+// structured logging around each tick.
 type TestSim struct {
+	*World
+
 	Width        int
 	Height       int
-	buildings    []rect
-	covers       []*CoverObject
-	TacticalMap  *TacticalMap
-	NavGrid      *NavGrid
 	Soldiers     []*Soldier // all soldiers across both teams
 	Squads       []*Squad
 	SimLog       *SimLog
-	Reporter     *SimReporter
+	Reporter     *SimReporter // the World's reporter
 	Tick         int
 	rng          *rand.Rand
-	combat       *CombatManager
+	rngSrc       *countingSource // rng's source, for save states
 	effProbes    map[int]*effectivenessProbe
 	PerfTrackers map[int]*PerfTracker
 
+	// Save-state metadata: the scenario run seed (set by
+	// NewTestSimFromScenario) and any Branch reseeds.
+	seed     int64
+	branches []SaveBranch
+
 	// internal counters
 	nextID int
-	tick   int // pointer target for soldiers
 }
 
 func (ts *TestSim) logCombatEffectiveness(tick int, s *Soldier) {
@@ -106,7 +108,9 @@ type simOptionKind int
 const (
 	simOptInfra   simOptionKind = iota // map size, buildings, seed, verbose — applied first
 	simOptSoldier                      // add soldiers — applied after navgrid is built
-	simOptSquad                        // form squads — applied after soldiers exist
+	simOptProfile                      // tweak soldier profiles — applied after soldiers exist
+	simOptSquad                        // form squads — applied after profiles are final
+	simOptPlatoon                      // group squads into platoons — applied after squads exist
 )
 
 // SimOption is a builder function applied to a TestSim during construction.
@@ -139,17 +143,60 @@ func WithHeadlessBattlefield(bf *HeadlessBattlefield) SimOption {
 		}
 		ts.Width = bf.Width
 		ts.Height = bf.Height
+		ts.tileMap = bf.TileMap
 		ts.buildings = append([]rect(nil), bf.Buildings...)
+		ts.windows = append([]rect(nil), bf.Windows...)
+		ts.buildingFootprints = append([]rect(nil), bf.BuildingFootprints...)
 		ts.covers = append([]*CoverObject(nil), bf.Covers...)
-		ts.NavGrid = bf.NavGrid
-		ts.TacticalMap = bf.TacticalMap
+		ts.navGrid = bf.NavGrid
+		ts.tacticalMap = bf.TacticalMap
 	}}
 }
 
 // WithSeed sets the RNG seed for deterministic runs.
 func WithSeed(seed int64) SimOption {
 	return SimOption{simOptInfra, func(ts *TestSim) {
-		ts.rng = rand.New(rand.NewSource(seed)) // #nosec G404 -- test harness
+		ts.rngSrc = newCountingSource(seed)
+		ts.rng = rand.New(ts.rngSrc) // #nosec G404 -- test harness
+	}}
+}
+
+// WithEnvironment sets the weather and time of day. Without it the sim runs
+// on a calm, clear midday.
+func WithEnvironment(env *Environment) SimOption {
+	return SimOption{simOptInfra, func(ts *TestSim) {
+		ts.env = env
+	}}
+}
+
+// WithConfig runs the sim on cfg instead of the default balance. The sim
+// keeps the pointer, so cfg must not be shared with a sim that is running.
+func WithConfig(cfg *SimConfig) SimOption {
+	return SimOption{simOptInfra, func(ts *TestSim) {
+		ts.config = cfg
+	}}
+}
+
+// WithJammer adds a radio jammer that degrades the other team's nets.
+func WithJammer(j Jammer) SimOption {
+	return SimOption{simOptInfra, func(ts *TestSim) {
+		ts.jammers = append(ts.jammers, j)
+	}}
+}
+
+// WithEvacPoint gives a team an evacuation point. Stabilised casualties
+// carried there leave the field. A team's first point is the one used.
+func WithEvacPoint(e EvacPoint) SimOption {
+	return SimOption{simOptInfra, func(ts *TestSim) {
+		ts.evacPoints = append(ts.evacPoints, e)
+	}}
+}
+
+// WithIntelMode sets how soldiers' observations are shared. The default is
+// IntelShared.
+func WithIntelMode(m IntelMode) SimOption {
+	return SimOption{simOptInfra, func(ts *TestSim) {
+		ts.intelMode = m
 	}}
 }
 
@@ -174,6 +221,78 @@ func WithBlueSoldier(id int, sx, sy, tx, ty float64) SimOption {
 	}}
 }
 
+// WithSoldierProfile edits the profile of the soldier with the given ID.
+// Commitment thresholds are re-derived from the (possibly new) discipline.
+func WithSoldierProfile(id int, fn func(*SoldierProfile)) SimOption {
+	return SimOption{simOptProfile, func(ts *TestSim) {
+		for _, s := range ts.Soldiers {
+			if s.id == id {
+				fn(&s.profile)
+				s.blackboard.InitCommitment(s.profile.Skills.Discipline)
+				return
+			}
+		}
+	}}
+}
+
+// WithRadioRelay gives the soldier with the given ID a relay set: their
+// team's traffic can route through them when the direct link is worse.
+func WithRadioRelay(id int) SimOption {
+	return SimOption{simOptProfile, func(ts *TestSim) {
+		for _, s := range ts.Soldiers {
+			if s.id == id {
+				s.radioRelay = true
+				return
+			}
+		}
+	}}
+}
+
+// WithWeapon arms the soldier with the given ID with a weapon of class k and
+// a full load of its ammunition.
+func WithWeapon(id int, k WeaponKind) SimOption {
+	return SimOption{simOptProfile, func(ts *TestSim) {
+		for _, s := range ts.Soldiers {
+			if s.id == id {
+				s.issueWeapon(k)
+				return
+			}
+		}
+	}}
+}
+
+// WithArmour dresses the soldier with the given ID in armour kit k.
+func WithArmour(id int, k ArmourKit) SimOption {
+	return SimOption{simOptProfile, func(ts *TestSim) {
+		for _, s := range ts.Soldiers {
+			if s.id == id {
+				s.issueArmour(k)
+				return
+			}
+		}
+	}}
+}
+
+// WithSoldierThresholds gives the soldier with the given ID its own goal
+// thresholds, starting from the sim's config and edited by fn. The soldier
+// drifts back toward these rather than the shared ones.
+func WithSoldierThresholds(id int, fn func(*GoalThresholds)) SimOption {
+	return SimOption{simOptProfile, func(ts *TestSim) {
+		for _, s := range ts.Soldiers {
+			if s.id == id {
+				th := s.blackboard.baseThresholds()
+				if ts.config != nil {
+					th = ts.config.Thresholds
+				}
+				fn(&th)
+				s.blackboard.ownThresholds = &th
+				s.blackboard.Internal.Thresholds = th
+				return
+			}
+		}
+	}}
+}
+
 // WithRedSquad groups existing red soldiers (by ID) into a squad.
 func WithRedSquad(ids ...int) SimOption {
 	return SimOption{simOptSquad, func(ts *TestSim) {
@@ -188,26 +307,39 @@ func WithBlueSquad(ids ...int) SimOption {
 	}}
 }
 
-// NewTestSim constructs a TestSim from the given options in three ordered passes:
+// WithPlatoon groups existing squads (by squad ID, in creation order) into a
+// platoon led by the first squad's leader.
+func WithPlatoon(squadIDs ...int) SimOption {
+	return SimOption{simOptPlatoon, func(ts *TestSim) {
+		ts.formPlatoon(squadIDs)
+	}}
+}
+
+// NewTestSim constructs a TestSim from the given options in ordered passes:
 //  1. Infrastructure (map size, buildings, seed, verbose)
 //  2. Build NavGrid
 //  3. Soldiers
-//  4. Squads
+//  4. Profile overrides
+//  5. Squads
+//  6. Platoons
 func NewTestSim(opts ...SimOption) *TestSim {
 	ts := &TestSim{
+		World:        &World{},
 		Width:        1280,
 		Height:       720,
 		SimLog:       NewSimLog(false),
-		rng:          rand.New(rand.NewSource(1)), // #nosec G404 -- test harness default
+		rngSrc:       newCountingSource(1), // test harness default
 		effProbes:    make(map[int]*effectivenessProbe),
 		PerfTrackers: make(map[int]*PerfTracker),
 	}
+	ts.rng = rand.New(ts.rngSrc) // #nosec G404 -- test harness
 	for _, o := range opts {
 		if o.kind == simOptInfra {
 			o.fn(ts)
 		}
 	}
-	if ts.NavGrid == nil {
+	ts.gameWidth, ts.gameHeight = ts.Width, ts.Height
+	if ts.navGrid == nil {
 		ts.buildNavGrid()
 	}
 	for _, o := range opts {
@@ -215,13 +347,24 @@ func NewTestSim(opts ...SimOption) *TestSim {
 			o.fn(ts)
 		}
 	}
+	for _, o := range opts {
+		if o.kind == simOptProfile {
+			o.fn(ts)
+		}
+	}
 	for _, o := range opts {
 		if o.kind == simOptSquad {
 			o.fn(ts)
 		}
 	}
-	ts.combat = NewCombatManager(ts.rng.Int63())
+	for _, o := range opts {
+		if o.kind == simOptPlatoon {
+			o.fn(ts)
+		}
+	}
+	ts.startSim(ts.rng.Int63(), ts.env) // WithEnvironment fills in ts.env
 	ts.Reporter = NewSimReporter(reportWindowTicks, true)
+	ts.reporter = ts.Reporter
 	hasBuildings := len(ts.buildings) > 0
 	for _, s := range ts.Soldiers {
 		ts.PerfTrackers[s.id] = NewPerfTracker(s, hasBuildings)
@@ -233,10 +376,10 @@ func NewTestSim(opts ...SimOption) *TestSim {
 // buildings have been added. Must be called before soldiers are added if you
 // want their initial paths to be correct — NewTestSim handles this automatically.
 func (ts *TestSim) buildNavGrid() {
-	ts.NavGrid = NewNavGrid(ts.Width, ts.Height, ts.buildings, soldierRadius, ts.covers, nil)
+	ts.navGrid = NewNavGrid(ts.Width, ts.Height, ts.buildings, soldierRadius, ts.covers, nil)
 	// Re-path any soldiers that were added before the grid was built.
 	for _, s := range ts.Soldiers {
-		s.navGrid = ts.NavGrid
+		s.navGrid = ts.navGrid
 		s.recomputePath()
 	}
 }
@@ -244,8 +387,13 @@ func (ts *TestSim) buildNavGrid() {
 // addSoldier is the internal helper used by WithRedSoldier / WithBlueSoldier.
 func (ts *TestSim) addSoldier(id int, x, y float64, team Team, start, end [2]float64) {
 	tl := NewThoughtLog() // per-sim log; not rendered
-	s := NewSoldier(id, x, y, team, start, end, ts.NavGrid, ts.covers, ts.buildings, tl, &ts.tick, ts.TacticalMap)
+	s := NewSoldier(id, x, y, team, start, end, ts.navGrid, ts.covers, ts.buildings, tl, &ts.tick, ts.tacticalMap)
 	ts.Soldiers = append(ts.Soldiers, s)
+	if team == TeamRed {
+		ts.soldiers = append(ts.soldiers, s)
+	} else {
+		ts.opfor = append(ts.opfor, s)
+	}
 	ts.effProbes[s.id] = &effectivenessProbe{lastX: s.x, lastY: s.y}
 	ts.PerfTrackers[s.id] = NewPerfTracker(s, len(ts.buildings) > 0)
 	if id >= ts.nextID {
@@ -269,7 +417,22 @@ func (ts *TestSim) formSquad(team Team, ids []int) {
 	}
 	sqID := len(ts.Squads)
 	sq := NewSquad(sqID, team, members)
-	ts.Squads = append(ts.Squads, sq)
+	ts.squads = append(ts.squads, sq)
+	ts.Squads = ts.squads
+}
+
+// formPlatoon groups squads into a platoon. Unknown IDs are skipped.
+func (ts *TestSim) formPlatoon(squadIDs []int) {
+	var squads []*Squad
+	for _, id := range squadIDs {
+		if id >= 0 && id < len(ts.squads) {
+			squads = append(squads, ts.squads[id])
+		}
+	}
+	if len(squads) == 0 {
+		return
+	}
+	ts.platoons = append(ts.platoons, NewPlatoon(len(ts.platoons), squads[0].Team, squads))
 }
 
 // AllByTeam returns all soldiers for a given team.
@@ -283,26 +446,45 @@ func (ts *TestSim) AllByTeam(team Team) []*Soldier {
 	return out
 }
 
+// SquadsByTeam returns the squads belonging to a given team.
+func (ts *TestSim) SquadsByTeam(team Team) []*Squad {
+	var out []*Squad
+	for _, sq := range ts.Squads {
+		if sq.Team == team {
+			out = append(out, sq)
+		}
+	}
+	return out
+}
+
+// Outcome evaluates DetermineBattleOutcome for the current state.
+func (ts *TestSim) Outcome() BattleOutcomeReason {
+	return DetermineBattleOutcome(ts.AllByTeam(TeamRed), ts.AllByTeam(TeamBlue),
+		ts.SquadsByTeam(TeamRed), ts.SquadsByTeam(TeamBlue))
+}
+
+func (ts *TestSim) aliveCount(team Team) int {
+	n := 0
+	for _, s := range ts.Soldiers {
+		if s.team == team && s.state != SoldierStateDead {
+			n++
+		}
+	}
+	return n
+}
+
 // RunTicks advances the simulation n ticks, logging events to SimLog.
 func (ts *TestSim) RunTicks(n int) {
-	reds := ts.AllByTeam(TeamRed)
-	blues := ts.AllByTeam(TeamBlue)
-
 	for i := 0; i < n; i++ {
-		ts.tick++
-		ts.runOneTick(reds, blues)
+		ts.runOneTick()
 	}
 }
 
 // RunUntil advances the simulation up to maxTicks, stopping early if predicate
 // returns true. Returns the tick at which the predicate was satisfied, or -1.
 func (ts *TestSim) RunUntil(predicate func(*TestSim) bool, maxTicks int) int {
-	reds := ts.AllByTeam(TeamRed)
-	blues := ts.AllByTeam(TeamBlue)
-
 	for i := 0; i < maxTicks; i++ {
-		ts.tick++
-		ts.runOneTick(reds, blues)
+		ts.runOneTick()
 		if predicate(ts) {
 			return ts.tick
 		}
@@ -310,10 +492,8 @@ func (ts *TestSim) RunUntil(predicate func(*TestSim) bool, maxTicks int) int {
 	return -1
 }
 
-// runOneTick mirrors Game.Update for the headless harness.
-func (ts *TestSim) runOneTick(reds, blues []*Soldier) {
-	tick := ts.tick
-
+// runOneTick steps the World and logs what changed.
+func (ts *TestSim) runOneTick() {
 	// Snapshot previous goals/intents for change detection.
 	prevGoals := make(map[int]GoalKind, len(ts.Soldiers))
 	for _, s := range ts.Soldiers {
@@ -348,38 +528,8 @@ func (ts *TestSim) runOneTick(reds, blues []*Soldier) {
 		prevSquadBroken[sq.ID] = sq.Broken
 	}
 
-	// 1. SENSE
-	for _, s := range reds {
-		s.UpdateVision(blues, ts.buildings)
-	}
-	for _, s := range blues {
-		s.UpdateVision(reds, ts.buildings)
-	}
-
-	// 2. COMBAT
-	ts.combat.ResetFireCounts(ts.Soldiers)
-	ts.combat.tick = tick
-	ts.combat.ResolveCombat(reds, blues, reds, ts.buildings, ts.Soldiers)
-	ts.combat.ResolveCombat(blues, reds, blues, ts.buildings, ts.Soldiers)
-	ts.combat.UpdateTracers()
-
-	// 2.1. SOUND
-	ts.combat.BroadcastGunfire(reds, blues, tick)
-
-	// 3. SQUAD THINK
-	for _, sq := range ts.Squads {
-		sq.SquadThink(nil)
-	}
-
-	// Formation pass
-	for _, sq := range ts.Squads {
-		sq.UpdateFormation()
-	}
-
-	// 4+5. INDIVIDUAL THINK + ACT
-	for _, s := range ts.Soldiers {
-		s.Update()
-	}
+	ts.Step()
+	tick := ts.tick
 
 	// --- Post-tick logging ---
 
@@ -442,8 +592,12 @@ func (ts *TestSim) runOneTick(reds, blues []*Soldier) {
 
 		// State changes.
 		if s.state != prevStates[s.id] {
+			now := s.state.String()
+			if s.casualty.Evacuated {
+				now = "evacuated" // not a death; see Soldier.killed
+			}
 			ts.SimLog.Add(tick, s.label, tStr, "state", "change",
-				fmt.Sprintf("%s → %s", prevStates[s.id], s.state), 0)
+				fmt.Sprintf("%s → %s", prevStates[s.id], now), 0)
 		}
 
 		// Vision contact changes.
@@ -500,11 +654,31 @@ func (ts *TestSim) runOneTick(reds, blues []*Soldier) {
 			pt.Update(s)
 		}
 	}
+}
 
-	// Analytics: collect behaviour report every ~1s.
-	if tick%60 == 0 && ts.Reporter != nil {
-		ts.Reporter.Collect(tick, reds, blues, ts.Squads)
+// StartRecording begins capturing every tick for playback in a ReplayViewer.
+// m describes the map the sim was built on (see ScenarioFile.Map); seed fills
+// in m.Seed when it is zero. An open-field map with no buildings listed picks
+// up the sim's own WithBuilding obstacles.
+func (ts *TestSim) StartRecording(name string, seed int64, m ScenarioMap) {
+	if m.Width <= 0 || m.Height <= 0 {
+		m.Width, m.Height = ts.Width, ts.Height
+	}
+	if !m.Generate && len(m.Buildings) == 0 {
+		for _, b := range ts.buildings {
+			m.Buildings = append(m.Buildings, ScenarioRect{X: b.x, Y: b.y, W: b.w, H: b.h})
+		}
+	}
+	ts.recorder = NewRecorder("headless", name, seed, m, ts.Soldiers, ts.Squads)
+	ts.recorder.Capture(ts.tick, ts.Soldiers, ts.Squads, nil)
+}
+
+// Recording returns the recording in progress, or nil if not recording.
+func (ts *TestSim) Recording() *Recording {
+	if ts.recorder == nil {
+		return nil
 	}
+	return ts.recorder.Recording()
 }
 
 // teamLabel returns a short string for a team.
@@ -530,6 +704,11 @@ func (ts *TestSim) CurrentTick() int {
 	return ts.tick
 }
 
+// Environment returns the weather and time of day the sim runs under.
+func (ts *TestSim) Environment() *Environment {
+	return ts.env
+}
+
 // Snapshot captures a lightweight state summary.
 type SimSnapshot struct {
 	Tick     int
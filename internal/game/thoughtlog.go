@@ -103,6 +103,12 @@ func (tl *ThoughtLog) Add(tick int, label string, team Team, msg string, cat Log
 	}
 }
 
+// Clear drops all entries but keeps the category filters.
+func (tl *ThoughtLog) Clear() {
+	tl.head = 0
+	tl.count = 0
+}
+
 // Recent returns entries in chronological order (oldest first).
 func (tl *ThoughtLog) Recent() []ThoughtEntry {
 	result := make([]ThoughtEntry, 0, tl.count)
@@ -81,6 +81,23 @@ func groundCoverValue(g GroundType) float64 {
 	}
 }
 
+// groundConcealment returns the share of a crouching or prone soldier that
+// stays visible on a ground type; vegetation and broken ground hide the rest.
+func groundConcealment(g GroundType) float64 {
+	switch g {
+	case GroundGrassLong:
+		return 0.5
+	case GroundScrub:
+		return 0.55
+	case GroundRubbleHeavy:
+		return 0.75
+	case GroundCrater:
+		return 0.7
+	default:
+		return 1.0
+	}
+}
+
 // ObjectType identifies an object sitting on a tile.
 type ObjectType uint8
 
@@ -156,6 +173,27 @@ func objectLOSOpacity(o ObjectType) float64 {
 	}
 }
 
+// objectRadioLoss returns how much of a wall's worth of signal the object
+// absorbs. 1.0 = masonry, 0.0 = no effect.
+func objectRadioLoss(o ObjectType) float64 {
+	switch o {
+	case ObjectWall, ObjectTallWall, ObjectPillar, ObjectATBarrier:
+		return 1.0
+	case ObjectVehicleWreck:
+		return 0.8
+	case ObjectWallDamaged, ObjectChestWall:
+		return 0.6
+	case ObjectDoor, ObjectSandbag:
+		return 0.4
+	case ObjectTreeTrunk, ObjectHedgerow, ObjectRubblePile:
+		return 0.25
+	case ObjectWindow, ObjectTreeCanopy, ObjectCrate:
+		return 0.15
+	default:
+		return 0.0
+	}
+}
+
 // objectCoverValue returns the cover defence fraction for the object.
 func objectCoverValue(o ObjectType) float64 {
 	switch o {
@@ -237,6 +275,8 @@ func objectMovementMul(o ObjectType) float64 {
 // 0 means unbreakable.
 func objectDefaultDurability(o ObjectType) int16 {
 	switch o {
+	case ObjectWallDamaged:
+		return 200
 	case ObjectWindow:
 		return 30
 	case ObjectDoor:
@@ -271,6 +311,7 @@ const (
 	TileFlagDamaged                        // ground damaged by explosion
 	TileFlagTrench                         // part of a trench system
 	TileFlagRoof                           // has overhead cover
+	TileFlagLocked                         // door is locked and must be forced
 )
 
 // Tile represents one cell of the battlefield.
@@ -357,7 +398,9 @@ func (tm *TileMap) MovementCost(col, row int) float64 {
 }
 
 // LOSOpacity returns the opacity of this tile for line-of-sight checks.
-// 0 = transparent, 1 = opaque.
+// 0 = transparent, 1 = opaque. Smoke is left out: it is not a tile property
+// but clouds that drift across cells, summed along each ray by
+// SmokeField.Opacity. Sight checks combine the two in LineOfSightClarity.
 func (tm *TileMap) LOSOpacity(col, row int) float64 {
 	if !tm.inBounds(col, row) {
 		return 0
@@ -365,6 +408,15 @@ func (tm *TileMap) LOSOpacity(col, row int) float64 {
 	return objectLOSOpacity(tm.Tiles[row*tm.Cols+col].Object)
 }
 
+// RadioLoss returns the radio attenuation of this tile in wall units.
+// 0 = open air, 1 = a masonry wall.
+func (tm *TileMap) RadioLoss(col, row int) float64 {
+	if !tm.inBounds(col, row) {
+		return 0
+	}
+	return objectRadioLoss(tm.Tiles[row*tm.Cols+col].Object)
+}
+
 // CoverValue returns the total cover defence fraction at (col, row).
 func (tm *TileMap) CoverValue(col, row int) float64 {
 	if !tm.inBounds(col, row) {
@@ -467,6 +519,9 @@ func (tm *TileMap) DamageTile(col, row int, dmg int) {
 	if t.Durability <= 0 {
 		t.Durability = 0
 		switch t.Object {
+		case ObjectWallDamaged:
+			t.Object = ObjectRubblePile
+			t.Ground = GroundRubbleHeavy
 		case ObjectWindow:
 			t.Object = ObjectWindowBroken
 		case ObjectDoor:
@@ -13,9 +13,16 @@ type VisionState struct {
 	Heading  float64 // radians, 0 = right, pi/2 = down
 	FOV      float64 // radians, total arc width
 	MaxRange float64 // pixels
+	// EnvMul scales MaxRange for ambient light and weather. Zero means 1, so
+	// a zero-value state sees as on a clear day.
+	EnvMul float64
 
-	// KnownContacts are soldiers this agent can currently see.
+	// KnownContacts are soldiers this agent can currently see and has made
+	// out. Suspected are in sight but not yet detected (see detection.go).
 	KnownContacts []*Soldier
+	Suspected     []*Soldier
+
+	tracks []detectionTrack
 }
 
 // NewVisionState creates a vision state with defaults.
@@ -35,7 +42,8 @@ func (v *VisionState) InCone(ox, oy, px, py float64) bool {
 	dy := py - oy
 	// Quick check: is the target within max range? Uses squared distance to avoid sqrt.
 	dist2 := dx*dx + dy*dy
-	maxRange2 := v.MaxRange * v.MaxRange
+	maxRange := v.rangeInConditions()
+	maxRange2 := maxRange * maxRange
 	// 1e-12 is the square of 1e-6, a small distance to prevent treating near-zero distances as in-cone.
 	if dist2 > maxRange2 || dist2 < 1e-12 {
 		return false
@@ -73,7 +81,9 @@ func normalizeAngle(a float64) float64 {
 // PerformVisionScan clears known contacts and checks all candidates
 // for line-of-sight within the vision cone.
 // covers is the map's cover object list; tall walls block LOS.
-func (v *VisionState) PerformVisionScan(ox, oy float64, candidates []*Soldier, buildings []rect, covers []*CoverObject) {
+// smoke may be nil; smoke along the ray shortens how far the observer sees
+// in proportion to how much of the view it blocks.
+func (v *VisionState) PerformVisionScan(ox, oy float64, candidates []*Soldier, buildings []rect, covers []*CoverObject, smoke *SmokeField) {
 	v.KnownContacts = v.KnownContacts[:0]
 	for _, c := range candidates {
 		// Never keep dead soldiers as live contacts.
@@ -83,14 +93,24 @@ func (v *VisionState) PerformVisionScan(ox, oy float64, candidates []*Soldier, b
 		if !v.InCone(ox, oy, c.x, c.y) {
 			continue
 		}
-		// Cone check passed — now do hard LOS (building + tall-wall occlusion).
-		if HasLineOfSightWithCover(ox, oy, c.x, c.y, buildings, covers) {
+		// Cone check passed — now do hard LOS (building + tall-wall occlusion),
+		// then check the ray reaches the candidate through any smoke.
+		clear := LineOfSightClarity(ox, oy, c.x, c.y, buildings, covers, smoke)
+		if clear > 0 && math.Hypot(c.x-ox, c.y-oy) <= v.rangeInConditions()*clear {
 			v.KnownContacts = append(v.KnownContacts, c)
 		}
 	}
 }
 
-// DegradeRange reduces effective vision range based on fatigue and wounds.
+// DegradeRange reduces effective vision range based on fatigue, light and weather.
 func (v *VisionState) DegradeRange(fatigue float64) float64 {
-	return v.MaxRange * (1.0 - fatigue*0.3)
+	return v.rangeInConditions() * (1.0 - fatigue*0.3)
+}
+
+// rangeInConditions returns MaxRange scaled by EnvMul.
+func (v *VisionState) rangeInConditions() float64 {
+	if v.EnvMul <= 0 {
+		return v.MaxRange
+	}
+	return v.MaxRange * v.EnvMul
 }
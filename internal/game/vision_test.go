@@ -125,7 +125,7 @@ func TestPerformVisionScan_NoBuildings(t *testing.T) {
 		NewNavGrid(640, 480, nil, 0, nil, nil), nil, nil, NewThoughtLog(), &tick)
 	target := NewSoldier(1, 100, 0, TeamBlue, [2]float64{100, 0}, [2]float64{0, 0},
 		NewNavGrid(640, 480, nil, 0, nil, nil), nil, nil, NewThoughtLog(), &tick)
-	v.PerformVisionScan(0, 0, []*Soldier{target}, nil, nil)
+	v.PerformVisionScan(0, 0, []*Soldier{target}, nil, nil, nil)
 	if len(v.KnownContacts) != 1 {
 		t.Fatalf("expected 1 contact, got %d", len(v.KnownContacts))
 	}
@@ -138,7 +138,7 @@ func TestPerformVisionScan_BlockedByBuilding(t *testing.T) {
 	tick := 0
 	target := NewSoldier(1, 100, 0, TeamBlue, [2]float64{100, 0}, [2]float64{0, 0},
 		NewNavGrid(640, 480, buildings, 0, nil, nil), nil, nil, NewThoughtLog(), &tick)
-	v.PerformVisionScan(0, 0, []*Soldier{target}, buildings, nil)
+	v.PerformVisionScan(0, 0, []*Soldier{target}, buildings, nil, nil)
 	if len(v.KnownContacts) != 0 {
 		t.Fatalf("expected 0 contacts (building blocks LOS), got %d", len(v.KnownContacts))
 	}
@@ -0,0 +1,289 @@
+package game
+
+import "fmt"
+
+// ---------------------------------------------------------------------------
+// Weapons — per-soldier firearms and squad loadouts
+// ---------------------------------------------------------------------------
+//
+// Every soldier carries one Weapon, which sets their range bands, damage,
+// magazine, spread and the fire modes they can choose from, and where they
+// switch between them. The standard rifle reproduces the package constants
+// (accurateFireRange, maxFireRange, autoRange, burstRange, fireModeTable,
+// defaultMagazineCapacity), so a squad of riflemen fights exactly as before.
+// The others trade against it: the light machine gun is clumsy until it is
+// on its bipod, the marksman rifle reaches further but fires slowly, the SMG
+// and shotgun are deadly in a room and poor beyond it. A weapon also nudges
+// its carrier's goal choice toward the job it suits.
+//
+// Squads in the game are issued standardSquadLoadout, as are the squads of
+// the headless mutual-advance scenario; TestSim squads otherwise carry rifles
+// unless a scenario or WithWeapon says different.
+
+// WeaponKind identifies a weapon class.
+type WeaponKind int
+
+const (
+	WeaponRifle   WeaponKind = iota // service rifle — the default
+	WeaponLMG                       // light machine gun, belt-fed, bipod
+	WeaponDMR                       // designated marksman rifle
+	WeaponSMG                       // submachine gun
+	WeaponShotgun                   // pump shotgun
+)
+
+func (k WeaponKind) String() string {
+	switch k {
+	case WeaponRifle:
+		return "rifle"
+	case WeaponLMG:
+		return "lmg"
+	case WeaponDMR:
+		return "dmr"
+	case WeaponSMG:
+		return "smg"
+	case WeaponShotgun:
+		return "shotgun"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseWeaponKind parses a weapon name as written by String. The empty
+// string is the rifle.
+func ParseWeaponKind(s string) (WeaponKind, error) {
+	if s == "" {
+		return WeaponRifle, nil
+	}
+	for k := WeaponRifle; k <= WeaponShotgun; k++ {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return WeaponRifle, fmt.Errorf("unknown weapon %q", s)
+}
+
+// Weapon is the fixed description of a weapon class. Soldiers share the
+// entries of weaponTable and never modify them.
+type Weapon struct {
+	Kind          WeaponKind
+	AccurateRange float64 // px, reliable engagement envelope
+	MaxRange      float64 // px, end of aimed fire; pot shots reach twice this
+	Damage        float64 // per round before range and CQB multipliers
+	MagCapacity   int     // rounds per magazine (or belt, or tube)
+	SpareMags     int     // full magazines carried besides the loaded one
+	ReloadMul     float64 // reload time relative to the rifle
+	SpreadMul     float64 // shooter spread relative to the rifle
+	BipodMul      float64 // spread multiplier when fired prone; 1 = no bipod
+	CQBBonus      float64 // extra damage share at point-blank, fading out at cqbRange
+	MinDamageMul  float64 // damage share left at MaxRange; 1 = no fall-off
+	Pattern       float64 // radians added to the target's size by a spread of pellets
+	AutoRange     float64 // px, automatic fire considered inside this; always at half of it
+	BurstRange    float64 // px, bursts preferred inside this, single shots beyond
+	AutoSightline float64 // local sightline score below which a position counts as cramped
+	modes         map[FireMode]fireModeParams
+	goalBias      map[GoalKind]float64 // utility added to goals the weapon suits
+}
+
+// weaponTable holds every weapon class. The rifle matches the package
+// constants; the others are tuned against it.
+var weaponTable = map[WeaponKind]*Weapon{
+	WeaponRifle: {
+		Kind: WeaponRifle, AccurateRange: accurateFireRange, MaxRange: maxFireRange,
+		Damage: baseDamage, MagCapacity: defaultMagazineCapacity, SpareMags: defaultSpareMagazines,
+		ReloadMul: 1, SpreadMul: 1, BipodMul: 1, CQBBonus: 0.8, MinDamageMul: 1,
+		AutoRange: autoRange, BurstRange: burstRange, AutoSightline: autoSightlineThresh,
+		modes: fireModeTable,
+	},
+	WeaponLMG: {
+		Kind: WeaponLMG, AccurateRange: 500, MaxRange: 1000,
+		Damage: baseDamage, MagCapacity: 100, SpareMags: 4,
+		ReloadMul: 2.5, SpreadMul: 1.35, BipodMul: 0.5, CQBBonus: 0.8, MinDamageMul: 1,
+		AutoRange: 320, BurstRange: 800, AutoSightline: 0.60,
+		modes: map[FireMode]fireModeParams{
+			FireModeBurst: {shots: 5, interval: 16, spreadRad: 0.05, accMul: 0.85, damageMul: 1.0, missScatter: 24},
+			FireModeAuto:  {shots: 8, interval: 10, spreadRad: 0.07, accMul: 0.72, damageMul: 1.0, missScatter: 32},
+		},
+		goalBias: map[GoalKind]float64{
+			GoalOverwatch: 0.20, GoalHoldPosition: 0.10, GoalEngage: 0.10,
+			GoalMoveToContact: -0.10, GoalFlank: -0.25,
+		},
+	},
+	WeaponDMR: {
+		Kind: WeaponDMR, AccurateRange: 700, MaxRange: 1200,
+		Damage: 34, MagCapacity: 20, SpareMags: 6,
+		ReloadMul: 1.1, SpreadMul: 0.7, BipodMul: 1, CQBBonus: 0.6, MinDamageMul: 1,
+		AutoRange: 80, BurstRange: 120, AutoSightline: 0.20,
+		modes: map[FireMode]fireModeParams{
+			FireModeSingle: {shots: 1, interval: 48, spreadRad: 0, accMul: 1.1, damageMul: 1.0, missScatter: 12},
+		},
+		goalBias: map[GoalKind]float64{
+			GoalOverwatch: 0.25, GoalEngage: 0.05,
+			GoalMoveToContact: -0.10, GoalFlank: -0.15,
+		},
+	},
+	WeaponSMG: {
+		Kind: WeaponSMG, AccurateRange: 220, MaxRange: 450,
+		Damage: 18, MagCapacity: 32, SpareMags: 6,
+		ReloadMul: 0.85, SpreadMul: 1.15, BipodMul: 1, CQBBonus: 1.0, MinDamageMul: 0.6,
+		AutoRange: 240, BurstRange: 360, AutoSightline: 0.60,
+		modes: map[FireMode]fireModeParams{
+			FireModeSingle: {shots: 1, interval: 30, spreadRad: 0, accMul: 1.0, damageMul: 1.0, missScatter: missScatterSingle},
+			FireModeBurst:  {shots: 3, interval: 14, spreadRad: 0.05, accMul: 0.85, damageMul: 1.0, missScatter: missScatterBurst},
+			FireModeAuto:   {shots: 6, interval: 8, spreadRad: 0.08, accMul: 0.72, damageMul: 1.0, missScatter: missScatterAuto},
+		},
+		goalBias: map[GoalKind]float64{
+			GoalMoveToContact: 0.10, GoalFlank: 0.15, GoalOverwatch: -0.15,
+		},
+	},
+	WeaponShotgun: {
+		Kind: WeaponShotgun, AccurateRange: 120, MaxRange: 300,
+		Damage: 55, MagCapacity: 8, SpareMags: 4,
+		ReloadMul: 1.8, SpreadMul: 1, BipodMul: 1, CQBBonus: 1.2, MinDamageMul: 0.25, Pattern: 0.06,
+		AutoRange: autoRange, BurstRange: burstRange, AutoSightline: autoSightlineThresh,
+		modes: map[FireMode]fireModeParams{
+			FireModeSingle: {shots: 1, interval: 45, spreadRad: 0, accMul: 1.0, damageMul: 1.0, missScatter: 30},
+		},
+		goalBias: map[GoalKind]float64{
+			GoalMoveToContact: 0.15, GoalFlank: 0.15, GoalOverwatch: -0.20, GoalHoldPosition: -0.05,
+		},
+	},
+}
+
+// weaponFor returns the shared description of weapon class k.
+func weaponFor(k WeaponKind) *Weapon {
+	if w, ok := weaponTable[k]; ok {
+		return w
+	}
+	return weaponTable[WeaponRifle]
+}
+
+// standardSquadLoadout is the weapon issue for an eight-man squad, in member
+// order: the leader and medic keep rifles, and the squad carries one light
+// machine gun, one marksman rifle and one SMG for the point man.
+var standardSquadLoadout = []WeaponKind{
+	WeaponRifle, WeaponRifle, WeaponLMG, WeaponRifle,
+	WeaponDMR, WeaponRifle, WeaponSMG, WeaponRifle,
+}
+
+// hasMode reports whether w can fire in mode m.
+func (w *Weapon) hasMode(m FireMode) bool {
+	_, ok := w.modes[m]
+	return ok
+}
+
+// nearestMode returns m if w has it, otherwise the closest mode w does have:
+// a weapon without automatic fire bursts instead, one without single shots
+// fires short bursts.
+func (w *Weapon) nearestMode(m FireMode) FireMode {
+	if w.hasMode(m) {
+		return m
+	}
+	order := []FireMode{FireModeSingle, FireModeBurst, FireModeAuto}
+	if m == FireModeAuto {
+		order = []FireMode{FireModeBurst, FireModeSingle}
+	}
+	for _, o := range order {
+		if w.hasMode(o) {
+			return o
+		}
+	}
+	return m
+}
+
+// fireMode returns the parameters for firing w in mode m, or in the nearest
+// mode w has.
+func (w *Weapon) fireMode(m FireMode) fireModeParams {
+	return w.modes[w.nearestMode(m)]
+}
+
+// cqbDamageMul returns the extra damage multiplier for close-range fights.
+// Represents higher hit probability on vital areas and terminal ballistics at
+// short range. Uses a smooth fuzzy ramp: 1 at cqbRange, 1+CQBBonus at
+// point-blank.
+func (w *Weapon) cqbDamageMul(dist float64) float64 {
+	if dist >= cqbRange {
+		return 1.0
+	}
+	t := 1.0 - dist/cqbRange // 0 at cqbRange, 1 at 0
+	return 1.0 + w.CQBBonus*t
+}
+
+// falloffMul returns the share of damage a round keeps at dist: all of it
+// inside AccurateRange, falling to MinDamageMul at MaxRange.
+func (w *Weapon) falloffMul(dist float64) float64 {
+	if w.MinDamageMul >= 1 || dist <= w.AccurateRange {
+		return 1
+	}
+	t := clamp01((dist - w.AccurateRange) / (w.MaxRange - w.AccurateRange))
+	return 1 - (1-w.MinDamageMul)*t
+}
+
+// weaponRanges returns w's accurate, maximum and pot-shot ranges. The
+// accurate_fire_range parameter is the rifle's; other weapons scale with it.
+// A nil w is the rifle.
+func (c *SimConfig) weaponRanges(w *Weapon) (accurate, maxRange, potShot float64) {
+	if w == nil {
+		w = weaponFor(WeaponRifle)
+	}
+	accurate = c.AccurateFireRange * (w.AccurateRange / accurateFireRange)
+	maxRange = w.MaxRange
+	if accurate > maxRange-1 {
+		accurate = maxRange - 1
+	}
+	return accurate, maxRange, maxRange * potShotRangeMul
+}
+
+// firearm returns the weapon s carries, the rifle if none was issued.
+func (s *Soldier) firearm() *Weapon {
+	if s.weapon == nil {
+		return weaponFor(WeaponRifle)
+	}
+	return s.weapon
+}
+
+// issueWeapon arms s with weapon class k and a full load of its ammunition.
+func (s *Soldier) issueWeapon(k WeaponKind) {
+	w := weaponFor(k)
+	s.weapon = w
+	s.blackboard.weapon = w
+	s.magCapacity = w.MagCapacity
+	s.magRounds = w.MagCapacity
+	s.spareMags = w.SpareMags
+	s.currentFireMode = w.nearestMode(s.currentFireMode)
+	s.desiredFireMode = s.currentFireMode
+}
+
+// issueLoadout arms the squad's members in order from kinds. Members past
+// the end of kinds keep what they carry.
+func (sq *Squad) issueLoadout(kinds []WeaponKind) {
+	for i, m := range sq.Members {
+		if i < len(kinds) {
+			m.issueWeapon(kinds[i])
+		}
+	}
+}
+
+// firearm returns the weapon of the soldier owning bb, the rifle if unset.
+func (bb *Blackboard) firearm() *Weapon {
+	if bb.weapon == nil {
+		return weaponFor(WeaponRifle)
+	}
+	return bb.weapon
+}
+
+// weaponGoalBias is the utility a weapon adds to goal: a machine gunner
+// leans toward overwatch, a man with a shotgun toward closing in.
+func weaponGoalBias(goal GoalKind, bb *Blackboard) float64 {
+	return bb.firearm().goalBias[goal]
+}
+
+// weaponSpreadMul returns how much wider than the rifle s's weapon throws rounds
+// in their current stance.
+func (s *Soldier) weaponSpreadMul() float64 {
+	w := s.firearm()
+	m := w.SpreadMul
+	if s.profile.Stance == StanceProne {
+		m *= w.BipodMul
+	}
+	return m
+}
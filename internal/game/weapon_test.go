@@ -0,0 +1,172 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWeapon_RifleMatchesPackageConstants(t *testing.T) {
+	cfg := DefaultSimConfig()
+	rifle := weaponFor(WeaponRifle)
+	acc, maxR, pot := cfg.weaponRanges(rifle)
+	if acc != cfg.AccurateFireRange || maxR != maxFireRange || pot != maxFireRange*potShotRangeMul {
+		t.Fatalf("rifle ranges %.0f/%.0f/%.0f, want %.0f/%.0f/%.0f",
+			acc, maxR, pot, cfg.AccurateFireRange, float64(maxFireRange), maxFireRange*potShotRangeMul)
+	}
+	if rifle.AutoRange != autoRange || rifle.BurstRange != burstRange || rifle.AutoSightline != autoSightlineThresh {
+		t.Errorf("rifle mode bands %.0f/%.0f/%.2f, want %d/%d/%.2f",
+			rifle.AutoRange, rifle.BurstRange, rifle.AutoSightline, autoRange, burstRange, autoSightlineThresh)
+	}
+	for m, want := range fireModeTable {
+		if got := rifle.fireMode(m); got != want {
+			t.Errorf("rifle %s params %+v, want %+v", m, got, want)
+		}
+	}
+
+	tick := 0
+	s := newTestSoldier(0, 100, 100, TeamRed, &tick)
+	if s.firearm() != rifle || s.magCapacity != defaultMagazineCapacity || s.spareMags != defaultSpareMagazines {
+		t.Fatalf("new soldier carries %s with %d x %d, want the rifle", s.firearm().Kind, s.magCapacity, s.spareMags)
+	}
+}
+
+func TestWeapon_LMGBurstsOnlyAndSteadiesOnBipod(t *testing.T) {
+	tick := 0
+	s := newTestSoldier(0, 100, 100, TeamRed, &tick)
+	s.issueWeapon(WeaponLMG)
+	if s.currentFireMode != FireModeBurst {
+		t.Fatalf("LMG starts in %s, want burst", s.currentFireMode)
+	}
+	if s.magCapacity != 100 || s.magRounds != 100 {
+		t.Fatalf("LMG belt %d/%d, want 100", s.magRounds, s.magCapacity)
+	}
+	cm := NewCombatManager(1)
+	if got := cm.selectFireMode(s, maxFireRange*0.9); got == FireModeSingle {
+		t.Error("LMG chose single shots it cannot fire")
+	}
+
+	standing := s.weaponSpreadMul()
+	s.profile.Stance = StanceProne
+	prone := s.weaponSpreadMul()
+	if standing <= 1 || prone >= 1 {
+		t.Errorf("LMG spread standing %.2f, prone %.2f; want worse than a rifle standing and better prone", standing, prone)
+	}
+}
+
+func TestWeapon_FireModeBandsArePerWeapon(t *testing.T) {
+	tick := 0
+	cm := NewCombatManager(1)
+	rifleman := newTestSoldier(0, 100, 100, TeamRed, &tick)
+	gunner := newTestSoldier(1, 100, 100, TeamRed, &tick)
+	gunner.issueWeapon(WeaponSMG)
+	for _, s := range []*Soldier{rifleman, gunner} {
+		s.blackboard.LocalSightlineScore = 1 // open ground
+		s.currentFireMode = FireModeSingle
+	}
+
+	// 100px in the open is point-blank for an SMG but not for a rifle.
+	for i := 0; i < 20; i++ {
+		if got := cm.rangeFireMode(rifleman, 100); got != FireModeBurst {
+			t.Fatalf("rifle at 100px in the open chose %s, want burst", got)
+		}
+		if got := cm.rangeFireMode(gunner, 100); got != FireModeAuto {
+			t.Fatalf("SMG at 100px chose %s, want auto", got)
+		}
+	}
+
+	// An LMG keeps bursting well past the rifle's burst band.
+	lmg := weaponFor(WeaponLMG)
+	if lmg.BurstRange <= burstRange || lmg.AutoRange <= autoRange {
+		t.Errorf("LMG bands %.0f/%.0f should reach past the rifle's %d/%d", lmg.AutoRange, lmg.BurstRange, autoRange, burstRange)
+	}
+}
+
+func TestWeapon_ShotgunDeadlyCloseAndUselessFar(t *testing.T) {
+	cfg := DefaultSimConfig()
+	shotgun := weaponFor(WeaponShotgun)
+	rifle := weaponFor(WeaponRifle)
+	p := DefaultProfile()
+
+	if shotgun.Damage*shotgun.cqbDamageMul(40) <= rifle.Damage*rifle.cqbDamageMul(40) {
+		t.Error("shotgun should hit harder than a rifle across a room")
+	}
+	if shotgun.falloffMul(shotgun.MaxRange) >= 0.5 {
+		t.Errorf("shotgun keeps %.2f of its damage at max range, want well under half", shotgun.falloffMul(shotgun.MaxRange))
+	}
+	if sg, rf := estimateHitChanceAtRange(cfg, shotgun, &p, 400), estimateHitChanceAtRange(cfg, rifle, &p, 400); sg >= rf {
+		t.Errorf("estimated hit chance at 400px: shotgun %.2f, rifle %.2f; want the rifle better", sg, rf)
+	}
+
+	tick := 0
+	s := newTestSoldier(0, 100, 200, TeamRed, &tick)
+	s.issueWeapon(WeaponShotgun)
+	target := newTestSoldier(1, 100+shotgun.MaxRange*potShotRangeMul+50, 200, TeamBlue, &tick)
+	s.vision.KnownContacts = []*Soldier{target}
+	cm := NewCombatManager(2)
+	for i := 0; i < 120; i++ {
+		cm.ResolveCombat([]*Soldier{s}, nil, []*Soldier{s}, nil, []*Soldier{s, target})
+	}
+	if s.magRounds != shotgun.MagCapacity {
+		t.Errorf("shotgun fired %d rounds at a target beyond its reach", shotgun.MagCapacity-s.magRounds)
+	}
+	target.x = 100 + shotgun.AccurateRange*0.5
+	for i := 0; i < 120 && s.magRounds == shotgun.MagCapacity; i++ {
+		cm.ResolveCombat([]*Soldier{s}, nil, []*Soldier{s}, nil, []*Soldier{s, target})
+	}
+	if s.magRounds == shotgun.MagCapacity {
+		t.Error("shotgun never fired at a target inside its accurate range")
+	}
+}
+
+func TestWeapon_GoalBiasAndMagazinesFollowWeapon(t *testing.T) {
+	lmg := &Blackboard{weapon: weaponFor(WeaponLMG)}
+	shotgun := &Blackboard{weapon: weaponFor(WeaponShotgun)}
+	if weaponGoalBias(GoalOverwatch, lmg) <= weaponGoalBias(GoalOverwatch, shotgun) {
+		t.Error("a machine gunner should lean toward overwatch more than a shotgunner")
+	}
+	if weaponGoalBias(GoalOverwatch, &Blackboard{}) != 0 {
+		t.Error("the rifle should not bias goal choice")
+	}
+
+	tick := 0
+	r := newTestSoldier(0, 100, 100, TeamRed, &tick)
+	gunner := newTestSoldier(1, 120, 100, TeamRed, &tick)
+	gunner.issueWeapon(WeaponLMG)
+	sq := NewSquad(0, TeamRed, []*Soldier{gunner, r})
+	r.spareMags = 0
+	if d := sq.ammoDonorFor(r); d != nil {
+		t.Fatalf("%s passed belts to a rifleman", d.label)
+	}
+}
+
+func TestScenario_SquadLoadout(t *testing.T) {
+	data := strings.Replace(testScenarioJSON,
+		`"squads": [{"team": "red", "members": [0, 1]}]`,
+		`"squads": [{"team": "red", "members": [0, 1], "loadout": ["rifle", "lmg"]}]`, 1)
+	data = strings.Replace(data, `{"id": 0, "team": "red", "start": [50, 350], "objective": [1200, 350],`,
+		`{"id": 0, "team": "red", "weapon": "dmr", "start": [50, 350], "objective": [1200, 350],`, 1)
+	sc, err := ParseScenario([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ts := NewTestSimFromScenario(sc, 7)
+	if k := ts.Soldiers[0].firearm().Kind; k != WeaponDMR {
+		t.Errorf("soldier 0 carries %s, want the dmr their own entry names", k)
+	}
+	if k := ts.Soldiers[1].firearm().Kind; k != WeaponLMG {
+		t.Errorf("soldier 1 carries %s, want the squad's lmg", k)
+	}
+
+	bad := strings.Replace(data, `"lmg"`, `"bazooka"`, 1)
+	if _, err := ParseScenario([]byte(bad)); err == nil || !strings.Contains(err.Error(), "bazooka") {
+		t.Errorf("unknown weapon accepted: %v", err)
+	}
+	long := strings.Replace(data, `["rifle", "lmg"]`, `["rifle", "lmg", "smg"]`, 1)
+	if _, err := ParseScenario([]byte(long)); err == nil || !strings.Contains(err.Error(), "3 weapons for 2 members") {
+		t.Errorf("loadout longer than the squad accepted: %v", err)
+	}
+	standard := strings.Replace(data, `["rifle", "lmg"]`, `["standard"]`, 1)
+	if _, err := ParseScenario([]byte(standard)); err != nil {
+		t.Errorf("the standard loadout should fit any squad: %v", err)
+	}
+}
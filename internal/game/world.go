@@ -0,0 +1,244 @@
+package game
+
+import "math/rand"
+
+// World owns every piece of simulation state — terrain, soldiers, squads,
+// combat, intel and weather — and the one tick pipeline that advances it.
+// It has no Ebiten dependency. Game draws a World and adds input on top;
+// TestSim and the laboratory drive one headlessly; HeadlessBattlefield is a
+// snapshot of a World's terrain. Because they all call Step, a headless run
+// is the battle the GUI would have shown.
+type World struct {
+	gameWidth  int // battlefield width in px
+	gameHeight int // battlefield height in px
+
+	tileMap            *TileMap          // per-tile terrain; nil on open-field test maps
+	buildings          []rect            // individual wall segments (1-cell wide), used for LOS/nav
+	windows            []rect            // window segments: block movement, transparent to LOS
+	buildingFootprints []rect            // overall floor area of each structure
+	buildingQualities  []BuildingQuality // pre-computed tactical metrics per footprint
+	covers             []*CoverObject
+	jammers            []Jammer    // enemy radio jamming; set before startSim
+	evacPoints         []EvacPoint // where casualties leave the field; set before startSim
+	navGrid            *NavGrid
+	tacticalMap        *TacticalMap
+
+	soldiers []*Soldier // red
+	opfor    []*Soldier // blue
+	squads   []*Squad
+	platoons []*Platoon
+
+	combat     *CombatManager
+	intel      *IntelStore
+	env        *Environment
+	config     *SimConfig // balance constants shared by every soldier
+	intelMode  IntelMode  // set before startSim
+	thoughtLog *ThoughtLog
+	reporter   *SimReporter // nil disables analytics
+	recorder   *Recorder    // nil unless recording
+	tick       int          // soldiers hold a pointer to this
+
+	// Spatial partitioning, rebuilt every tick.
+	spatialHashRed  *SpatialHash
+	spatialHashBlue *SpatialHash
+}
+
+// newWorldMap generates the seeded battlefield terrain. The GUI and headless
+// runs both build their maps here, so one seed gives one map everywhere.
+func newWorldMap(seed int64, battleW, battleH int) *World {
+	// The terrain generators are Game methods; a bare Game over the new
+	// World lends them out without touching any rendering state.
+	w := &World{gameWidth: battleW, gameHeight: battleH}
+	g := &Game{World: w}
+
+	mapRng := rand.New(rand.NewSource(seed + seedOffsetMap)) // #nosec G404 -- deterministic sim
+	// Create the TileMap first — grid roads and buildings write directly into it.
+	w.tileMap = NewTileMap(battleW/cellSize, battleH/cellSize)
+	generateGridRoads(w.tileMap, mapRng, defaultRoadConfig)
+	g.initBuildings(mapRng)
+	g.initCover(rand.New(rand.NewSource(seed + seedOffsetCover))) // #nosec G404 -- deterministic sim
+	g.initTileMap()                                               // stamp buildings/cover into tileMap after generation
+	generateBiome(w.tileMap, mapRng, defaultBiomeConfig)
+	generateFortifications(w.tileMap, mapRng, defaultFortConfig)
+
+	w.navGrid = NewNavGrid(battleW, battleH, w.buildings, soldierRadius, w.covers, w.windows)
+	w.navGrid.syncDoors(w.tileMap)
+	w.tacticalMap = NewTacticalMap(battleW, battleH, w.buildings, w.windows, w.buildingFootprints)
+	w.buildingQualities = ComputeBuildingQualities(w.buildingFootprints, w.buildings, w.windows, battleW, battleH, w.navGrid)
+	return w
+}
+
+// startSim creates the combat, intel and weather systems once the soldiers
+// are in place and wires every soldier to them. A nil env means a calm,
+// clear midday; a nil config means the default balance.
+func (w *World) startSim(combatSeed int64, env *Environment) {
+	w.combat = NewCombatManager(combatSeed)
+	w.intel = NewIntelStore(w.gameWidth, w.gameHeight)
+	w.intel.SetTileMap(w.tileMap)
+	w.intel.SetMode(w.intelMode)
+	if env == nil {
+		env = DefaultEnvironment()
+	}
+	w.env = env
+	if env.WindSpeed > 0 {
+		w.combat.Smoke.WindX, w.combat.Smoke.WindY = env.WindVector()
+	}
+	if w.thoughtLog == nil {
+		w.thoughtLog = NewThoughtLog()
+	}
+	if w.config == nil {
+		w.config = DefaultSimConfig()
+	}
+	for _, s := range w.allSoldiers() {
+		s.setConfig(w.config)
+	}
+	if w.intelMode == IntelRadio {
+		for _, sq := range w.squads {
+			sq.intel = w.intel.NewSquadMap(sq.Team)
+		}
+	}
+	w.connect()
+}
+
+// connect wires the soldiers and squads to the world's shared systems and
+// builds what is derived from them. A restored save state comes back through
+// here too.
+func (w *World) connect() {
+	w.combat.cfg = w.config
+	w.combat.SetTerrain(w.tileMap, w.navGrid, w.tacticalMap)
+	// Cell size = max vision range for optimal queries.
+	w.spatialHashRed = NewSpatialHash(defaultViewDist)
+	w.spatialHashBlue = NewSpatialHash(defaultViewDist)
+	for _, s := range w.allSoldiers() {
+		s.cfg = w.config
+		s.blackboard.cfg = w.config
+		s.setIntel(w.intel)
+		s.smoke = w.combat.Smoke
+		s.env = w.env
+		s.tileMap = w.tileMap
+		s.buildingFootprints = w.buildingFootprints
+		s.allies = w.spatialHashRed
+		if s.team == TeamBlue {
+			s.allies = w.spatialHashBlue
+		}
+	}
+	radio := newRadioMedium(w.tileMap, w.jammers, w.allSoldiers())
+	for _, sq := range w.squads {
+		sq.radio = radio
+		sq.buildingFootprints = w.buildingFootprints
+		sq.buildingQualities = w.buildingQualities
+		for i := range w.evacPoints {
+			if w.evacPoints[i].Team == sq.Team {
+				sq.evac = &w.evacPoints[i]
+				break
+			}
+		}
+	}
+	for _, p := range w.platoons {
+		p.radio = radio
+	}
+}
+
+// allSoldiers returns red then blue in a fresh slice.
+func (w *World) allSoldiers() []*Soldier {
+	return append(w.soldiers[:len(w.soldiers):len(w.soldiers)], w.opfor...)
+}
+
+// Step advances the world by one tick.
+func (w *World) Step() {
+	w.tick++
+
+	// 0. SPATIAL HASH: populate spatial partitioning structures for efficient queries.
+	w.spatialHashRed.Clear()
+	w.spatialHashBlue.Clear()
+	for _, s := range w.soldiers {
+		if s.state != SoldierStateDead {
+			w.spatialHashRed.Insert(s)
+		}
+	}
+	for _, s := range w.opfor {
+		if s.state != SoldierStateDead {
+			w.spatialHashBlue.Insert(s)
+		}
+	}
+
+	// 1. SENSE: each soldier scans for enemies using spatial hash.
+	for _, s := range w.soldiers {
+		s.UpdateVisionSpatial(w.spatialHashBlue, w.buildings)
+	}
+	for _, s := range w.opfor {
+		s.UpdateVisionSpatial(w.spatialHashRed, w.buildings)
+	}
+
+	// 2. COMBAT: fire decisions and resolution.
+	all := w.allSoldiers()
+	w.combat.ResetFireCounts(all)
+	w.combat.tick = w.tick
+	w.combat.ResolveExchange(w.soldiers, w.opfor, w.buildings, all)
+	w.combat.ResolveGrenades(all, w.buildings, w.tileMap)
+	w.combat.UpdateTracers()
+
+	// 2.1. SOUND: propagate gunfire, footsteps, shouts and doors using spatial hash for performance.
+	w.combat.PropagateSound(w.soldiers, w.opfor, w.spatialHashRed, w.spatialHashBlue, w.tileMap, w.tick)
+
+	// 2.4. WEATHER: ground wetness and mud.
+	w.env.Update(w.tick, w.tileMap)
+
+	// 2.5. INTEL: update all heatmap layers from current soldier state.
+	w.intel.Update(w.soldiers, w.opfor, w.buildings)
+	w.intel.UpdatePaint(w.tick)
+
+	// 3. SQUAD THINK: leaders evaluate and set intent/orders.
+	for _, sq := range w.squads {
+		sq.SquadThink(w.intel)
+	}
+
+	// 3.2 PLATOON THINK: platoon leaders read SITREPs and task their squads.
+	for _, p := range w.platoons {
+		p.Think(w.tick)
+	}
+
+	// 3.5 + 3.6 COMMS PLAN/RESOLVE: phase-A squad radio messaging, then the
+	// platoon nets above them.
+	for _, sq := range w.squads {
+		sq.PlanComms(w.tick)
+		sq.ResolveComms(w.tick, w.thoughtLog)
+	}
+	for _, p := range w.platoons {
+		p.ResolveComms(w.tick, w.thoughtLog)
+	}
+
+	// Formation pass: update slot targets before soldiers decide to move.
+	for _, sq := range w.squads {
+		sq.UpdateFormation()
+	}
+
+	// 4+5. INDIVIDUAL THINK + ACT.
+	for _, s := range w.soldiers {
+		s.Update()
+	}
+	for _, s := range w.opfor {
+		s.Update()
+	}
+
+	// 5.5. MEDICAL AID: advance treatment for wounded soldiers.
+	integrateBuddyAidTick(w.soldiers, w.tick)
+	integrateBuddyAidTick(w.opfor, w.tick)
+
+	// 6. SQUAD POLL: periodic summary to thought log (~every 5s).
+	if w.tick%squadPollInterval == 0 {
+		for _, sq := range w.squads {
+			w.thoughtLog.AddSquadPoll(w.tick, sq)
+		}
+	}
+
+	// 7. ANALYTICS: collect behaviour report every ~1s.
+	if w.tick%60 == 0 && w.reporter != nil {
+		w.reporter.Collect(w.tick, w.soldiers, w.opfor, w.squads)
+	}
+
+	// 8. REPLAY: record end-of-tick state for the replay viewer.
+	if w.recorder != nil {
+		w.recorder.Capture(w.tick, all, w.squads, w.combat.Shots)
+	}
+}
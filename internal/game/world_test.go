@@ -0,0 +1,42 @@
+package game
+
+import "testing"
+
+// TestWorld_TestSimRunsTheGamePipeline checks that the headless harness now
+// gets the systems that used to be GUI-only: intel heatmaps, squad comms and
+// the shared tick counter.
+func TestWorld_TestSimRunsTheGamePipeline(t *testing.T) {
+	ts := NewTestSim(
+		WithRedSoldier(1, 100, 300, 1100, 300),
+		WithRedSoldier(2, 100, 330, 1100, 330),
+		WithBlueSoldier(3, 1180, 300, 200, 300),
+		WithRedSquad(1, 2),
+		WithBlueSquad(3),
+	)
+	if ts.intel == nil || ts.Soldiers[0].intel != ts.intel {
+		t.Fatal("soldiers should share the World's intel store")
+	}
+	if len(ts.squads) != 2 || len(ts.soldiers) != 2 || len(ts.opfor) != 1 {
+		t.Fatalf("World holds %d squads, %d red, %d blue", len(ts.squads), len(ts.soldiers), len(ts.opfor))
+	}
+
+	ts.RunTicks(30)
+	if ts.tick != 30 || ts.Soldiers[0].tickVal() != 30 {
+		t.Fatalf("world tick %d, soldier sees %d, want 30", ts.tick, ts.Soldiers[0].tickVal())
+	}
+	red := ts.Soldiers[0]
+	if v := ts.intel.For(TeamRed).Layer(IntelFriendlyPresence).SampleAt(red.x, red.y); v <= 0 {
+		t.Fatal("intel should be updated on headless ticks")
+	}
+}
+
+func TestWorld_HeadlessBattlefieldBringsItsTerrain(t *testing.T) {
+	bf := NewHeadlessBattlefield(777, 1280, 720)
+	ts := NewTestSim(WithHeadlessBattlefield(bf), WithRedSoldier(1, 100, 100, 600, 100))
+	if ts.tileMap != bf.TileMap || len(ts.windows) != len(bf.Windows) {
+		t.Fatal("the sim should run on the battlefield's tile map and windows")
+	}
+	if ts.intel.tileMap != bf.TileMap {
+		t.Fatal("intel should read the battlefield's tile map")
+	}
+}
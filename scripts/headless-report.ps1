@@ -7,6 +7,10 @@ $runs = 5
 $ticks = 3600
 $seedBase = 42
 $seedStep = 1
+$scenario = 'mutual-advance'
+$scenarioFile = ''
+$format = 'text'
+$out = ''
 
 foreach ($pair in $Overrides) {
     if ([string]::IsNullOrWhiteSpace($pair)) {
@@ -26,10 +30,14 @@ foreach ($pair in $Overrides) {
         'TICKS' { $ticks = [int]$value }
         'SEED_BASE' { $seedBase = [int64]$value }
         'SEED_STEP' { $seedStep = [int64]$value }
+        'SCENARIO' { $scenario = $value }
+        'SCENARIO_FILE' { $scenarioFile = $value }
+        'FORMAT' { $format = $value }
+        'OUT' { $out = $value }
     }
 }
 
-go run ./cmd/headless-report -runs $runs -ticks $ticks -seed-base $seedBase -seed-step $seedStep
+go run ./cmd/headless-report -runs $runs -ticks $ticks -seed-base $seedBase -seed-step $seedStep -scenario $scenario -scenario-file $scenarioFile -format $format -out $out
 if ($LASTEXITCODE -ne 0) {
     exit $LASTEXITCODE
 }
@@ -2,11 +2,17 @@
 # Run headless mutual-advance simulation and print AAR-ready report lines.
 # Accepts overrides as KEY=VALUE arguments, e.g.:
 #   sh scripts/headless-report.sh RUNS=20 TICKS=3600 SEED_BASE=42 SEED_STEP=1
+#   sh scripts/headless-report.sh SCENARIO_FILE=path/to/scenario.json
+#   sh scripts/headless-report.sh FORMAT=csv OUT=results/   # machine-readable tables
 
 RUNS=5
 TICKS=3600
 SEED_BASE=42
 SEED_STEP=1
+SCENARIO=mutual-advance
+SCENARIO_FILE=
+FORMAT=text
+OUT=
 
 for pair in "$@"; do
     key="${pair%%=*}"
@@ -16,7 +22,12 @@ for pair in "$@"; do
         TICKS)     TICKS="$value" ;;
         SEED_BASE) SEED_BASE="$value" ;;
         SEED_STEP) SEED_STEP="$value" ;;
+        SCENARIO)  SCENARIO="$value" ;;
+        SCENARIO_FILE) SCENARIO_FILE="$value" ;;
+        FORMAT)    FORMAT="$value" ;;
+        OUT)       OUT="$value" ;;
     esac
 done
 
-go run ./cmd/headless-report -runs "$RUNS" -ticks "$TICKS" -seed-base "$SEED_BASE" -seed-step "$SEED_STEP"
+go run ./cmd/headless-report -runs "$RUNS" -ticks "$TICKS" -seed-base "$SEED_BASE" -seed-step "$SEED_STEP" \
+    -scenario "$SCENARIO" -scenario-file "$SCENARIO_FILE" -format "$FORMAT" -out "$OUT"